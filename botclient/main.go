@@ -5,36 +5,54 @@ package botclient
 
 import (
 	"encoding/json"
-	"fmt"
 	"log"
 	"time"
 
 	"github.com/devblac/chinchon/chinchon"
 	"github.com/devblac/chinchon/server"
-	"github.com/gorilla/websocket"
 )
 
-func Bot(playerID int, address string, bot chinchon.Bot) {
-	// Open the WebSocket connection, and send a hello message.
-	conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%v/ws", address), nil)
+// Bot plays as the player in the server's game with the given ID (the default game if
+// empty), with the session token for the seat if the server requires one to rejoin it.
+func Bot(gameID string, playerID int, token string, address string, bot chinchon.Bot) {
+	// Open the WebSocket connection, and send a hello message, meant to tell the server who
+	// we are, and request game state. Game could be in progress (this could be a
+	// reconnection).
+	hello := server.NewMessageHelloForGame(gameID, playerID, token)
+	hello.Deltas = true
+	conn, messageType, message, err := server.WsJoin(server.ServerURL(address, "/ws", true), hello)
 	if err != nil {
 		log.Fatalf("Failed to connect to WebSocket server: %v", err)
 	}
 	defer conn.Close()
 
-	// Hello message is meant to tell the server who we are, and request game state.
-	// Game could be in progress (this could be a reconnection).
-	if err := server.WsSend(conn, server.NewMessageHello(playerID)); err != nil {
-		log.Fatal(err)
-	}
+	var states server.StateAssembler
 
 	// On each iteration
-	for {
-		clientGameState, err := server.WsReadMessage[chinchon.ClientGameState, server.MessageHeresGameState](conn, server.MessageTypeHeresGameState)
+	for ; ; messageType, message, err = server.WsReadAnyMessage(conn) {
 		if err != nil {
 			log.Fatal(err)
 		}
 
+		// Bots don't care about emotes or any other chatter.
+		if messageType != server.MessageTypeHeresGameState && messageType != server.MessageTypeGameStateDelta {
+			continue
+		}
+
+		clientGameState, err := states.ClientGameState(messageType, message)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Never act on a state that doesn't match what the server sent; ask for it again.
+		if !clientGameState.IsInSync() {
+			log.Println("Game state out of sync, requesting it again")
+			if err := server.WsSend(conn, server.NewMessageGimmeGameState()); err != nil {
+				log.Fatal(err)
+			}
+			continue
+		}
+
 		if clientGameState.IsGameEnded {
 			return
 		}
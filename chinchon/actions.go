@@ -2,7 +2,6 @@ package chinchon
 
 import (
 	"fmt"
-	"strings"
 )
 
 // Action names constants
@@ -12,6 +11,14 @@ const (
 	DISCARD_CARD           = "discard_card"
 	CLOSE_ROUND            = "close_round"
 	CONFIRM_ROUND_FINISHED = "confirm_round_finished"
+	LAY_OFF_CARD           = "lay_off_card"
+	FINISH_LAY_OFF         = "finish_lay_off"
+	ACCEPT_REENTER         = "accept_reenter"
+	DECLINE_REENTER        = "decline_reenter"
+	RESIGN                 = "resign"
+	RESIGN_ROUND           = "resign_round"
+	REORDER_HAND           = "reorder_hand"
+	SET_HAND_SORT          = "set_hand_sort"
 )
 
 type act struct {
@@ -41,14 +48,18 @@ func (a act) AllowLowerPriority() bool {
 func (a act) Enrich(g GameState) {}
 
 func (a act) String() string {
-	name := strings.ReplaceAll(strings.TrimPrefix(a.Name, ""), "_", " ")
-	return fmt.Sprintf("Player %v %v", a.PlayerID, name)
+	return NewCatalog(DefaultLocale).message("log."+a.Name, fmt.Sprintf("Player %v", a.PlayerID))
 }
 
 func (a act) YieldsTurn(g GameState) bool {
 	return true
 }
 
+// defaultActionString describes the action in DefaultLocale, for logs and errors.
+func defaultActionString(a Action) string {
+	return NewCatalog(DefaultLocale).ActionLog(a, fmt.Sprintf("Player %v", a.GetPlayerID()))
+}
+
 // ActionDrawFromDeck represents drawing a card from the deck
 type ActionDrawFromDeck struct {
 	act
@@ -68,14 +79,18 @@ func (a ActionDrawFromDeck) IsPossible(g GameState) bool {
 	if g.HasDrawnCard {
 		return false // Already drawn this turn
 	}
-	return !g.DrawPile.isEmpty()
+	// An empty draw pile is refilled from the discard pile
+	return !g.DrawPile.isEmpty() || len(g.DiscardPile) > 1
 }
 
 func (a ActionDrawFromDeck) Run(g *GameState) error {
 	if !a.IsPossible(*g) {
-		return errActionNotPossible
+		return ErrActionNotPossible
 	}
 
+	if g.DrawPile.isEmpty() {
+		g.reshuffleDiscardPile()
+	}
 	card, err := g.DrawPile.drawCard()
 	if err != nil {
 		return err
@@ -83,6 +98,7 @@ func (a ActionDrawFromDeck) Run(g *GameState) error {
 
 	g.Players[a.PlayerID].Hand.AddCard(card)
 	g.HasDrawnCard = true
+	g.emitCardMoved(a.PlayerID, card, LOCATION_DECK, LOCATION_HAND, false)
 
 	return nil
 }
@@ -92,7 +108,7 @@ func (a ActionDrawFromDeck) YieldsTurn(g GameState) bool {
 }
 
 func (a ActionDrawFromDeck) String() string {
-	return fmt.Sprintf("Player %v draws from deck", a.PlayerID)
+	return defaultActionString(a)
 }
 
 // ActionDrawFromDiscard represents drawing a card from the discard pile
@@ -119,7 +135,7 @@ func (a ActionDrawFromDiscard) IsPossible(g GameState) bool {
 
 func (a ActionDrawFromDiscard) Run(g *GameState) error {
 	if !a.IsPossible(*g) {
-		return errActionNotPossible
+		return ErrActionNotPossible
 	}
 
 	if len(g.DiscardPile) == 0 {
@@ -132,6 +148,8 @@ func (a ActionDrawFromDiscard) Run(g *GameState) error {
 
 	g.Players[a.PlayerID].Hand.AddCard(card)
 	g.HasDrawnCard = true
+	g.recordDiscardPickup(a.PlayerID)
+	g.emitCardMoved(a.PlayerID, card, LOCATION_DISCARD_PILE, LOCATION_HAND, true)
 
 	return nil
 }
@@ -141,7 +159,7 @@ func (a ActionDrawFromDiscard) YieldsTurn(g GameState) bool {
 }
 
 func (a ActionDrawFromDiscard) String() string {
-	return fmt.Sprintf("Player %v draws from discard pile", a.PlayerID)
+	return defaultActionString(a)
 }
 
 // ActionDiscardCard represents discarding a card
@@ -174,7 +192,7 @@ func (a ActionDiscardCard) IsPossible(g GameState) bool {
 
 func (a ActionDiscardCard) Run(g *GameState) error {
 	if !a.IsPossible(*g) {
-		return errActionNotPossible
+		return ErrActionNotPossible
 	}
 
 	err := g.Players[a.PlayerID].Hand.RemoveCard(a.Card)
@@ -184,6 +202,8 @@ func (a ActionDiscardCard) Run(g *GameState) error {
 
 	// Add card to discard pile
 	g.DiscardPile = append(g.DiscardPile, a.Card)
+	g.recordDiscard(a.PlayerID, a.Card)
+	g.emitCardMoved(a.PlayerID, a.Card, LOCATION_HAND, LOCATION_DISCARD_PILE, true)
 
 	return nil
 }
@@ -193,16 +213,20 @@ func (a ActionDiscardCard) YieldsTurn(g GameState) bool {
 }
 
 func (a ActionDiscardCard) String() string {
-	return fmt.Sprintf("Player %v discards %v", a.PlayerID, a.Card)
+	return defaultActionString(a)
 }
 
-// ActionClose represents closing the round
+// ActionClose represents closing the round, by discarding Card face-down
 type ActionClose struct {
 	act
+	Card Card `json:"card"`
+
+	// Groups are the declared groups, with RuleDeclaredMelds. Otherwise, they're ignored.
+	Groups [][]Card `json:"groups,omitempty"`
 }
 
-func NewActionClose(playerID int) Action {
-	return &ActionClose{act: act{Name: CLOSE_ROUND, PlayerID: playerID}}
+func NewActionClose(card Card, playerID int) Action {
+	return &ActionClose{act: act{Name: CLOSE_ROUND, PlayerID: playerID}, Card: card}
 }
 
 func (a ActionClose) IsPossible(g GameState) bool {
@@ -215,15 +239,36 @@ func (a ActionClose) IsPossible(g GameState) bool {
 	if !g.HasDrawnCard {
 		return false // Must draw before closing
 	}
+	if g.turnsBeforeClose(a.PlayerID) > 0 {
+		return false
+	}
 
-	return g.CanClose(a.PlayerID)
+	// The cards left after the discard must meet the close condition
+	if g.RuleDeclaredMelds {
+		return g.isValidDeclaration(a.PlayerID, a.Card, a.Groups)
+	}
+	for _, card := range g.closingDiscards(a.PlayerID) {
+		if card == a.Card {
+			return true
+		}
+	}
+	return false
 }
 
 func (a ActionClose) Run(g *GameState) error {
 	if !a.IsPossible(*g) {
-		return errActionNotPossible
+		return ErrActionNotPossible
 	}
 
+	if err := g.Players[a.PlayerID].Hand.RemoveCard(a.Card); err != nil {
+		return err
+	}
+	g.DiscardPile = append(g.DiscardPile, a.Card)
+	g.recordDiscard(a.PlayerID, a.Card)
+	g.emitCardMoved(a.PlayerID, a.Card, LOCATION_HAND, LOCATION_DISCARD_PILE, false)
+	if g.RuleDeclaredMelds {
+		g.DeclaredGroups = a.Groups
+	}
 	g.CloseRound(a.PlayerID)
 
 	return nil
@@ -234,7 +279,7 @@ func (a ActionClose) YieldsTurn(g GameState) bool {
 }
 
 func (a ActionClose) String() string {
-	return fmt.Sprintf("Player %v closes the round", a.PlayerID)
+	return defaultActionString(a)
 }
 
 // ActionConfirmRoundFinished represents confirming that the round is finished
@@ -247,7 +292,7 @@ func NewActionConfirmRoundFinished(playerID int) Action {
 }
 
 func (a ActionConfirmRoundFinished) IsPossible(g GameState) bool {
-	if !g.IsRoundFinished || g.IsGameEnded {
+	if !g.IsRoundFinished || g.IsGameEnded || g.IsLayingOff {
 		return false
 	}
 
@@ -257,7 +302,7 @@ func (a ActionConfirmRoundFinished) IsPossible(g GameState) bool {
 
 func (a ActionConfirmRoundFinished) Run(g *GameState) error {
 	if !a.IsPossible(*g) {
-		return errActionNotPossible
+		return ErrActionNotPossible
 	}
 
 	g.RoundFinishedConfirmedPlayerIDs[a.PlayerID] = true
@@ -270,5 +315,316 @@ func (a ActionConfirmRoundFinished) YieldsTurn(g GameState) bool {
 }
 
 func (a ActionConfirmRoundFinished) String() string {
-	return fmt.Sprintf("Player %v confirms round finished", a.PlayerID)
+	return defaultActionString(a)
+}
+
+// ActionLayOffCard represents attaching a card to one of the closing player's groups
+type ActionLayOffCard struct {
+	act
+	Card Card `json:"card"`
+
+	// GroupIndex is the index of the group in LayOffGroups.
+	GroupIndex int `json:"groupIndex"`
+}
+
+func NewActionLayOffCard(card Card, groupIndex int, playerID int) Action {
+	return &ActionLayOffCard{
+		act:        act{Name: LAY_OFF_CARD, PlayerID: playerID},
+		Card:       card,
+		GroupIndex: groupIndex,
+	}
+}
+
+func (a ActionLayOffCard) IsPossible(g GameState) bool {
+	if !g.canLayOff(a.PlayerID) {
+		return false
+	}
+	if a.GroupIndex < 0 || a.GroupIndex >= len(g.LayOffGroups) {
+		return false
+	}
+	if !g.Players[a.PlayerID].Hand.HasCard(a.Card) {
+		return false
+	}
+	return g.handRules().isValidGroup(append(append([]Card{}, g.LayOffGroups[a.GroupIndex]...), a.Card))
+}
+
+func (a ActionLayOffCard) Run(g *GameState) error {
+	if !a.IsPossible(*g) {
+		return ErrActionNotPossible
+	}
+
+	if err := g.Players[a.PlayerID].Hand.RemoveCard(a.Card); err != nil {
+		return err
+	}
+	g.LayOffGroups[a.GroupIndex] = append(g.LayOffGroups[a.GroupIndex], a.Card)
+	g.emitCardMoved(a.PlayerID, a.Card, LOCATION_HAND, LOCATION_GROUPS, true)
+
+	return nil
+}
+
+func (a ActionLayOffCard) YieldsTurn(g GameState) bool {
+	return false
+}
+
+func (a ActionLayOffCard) String() string {
+	return defaultActionString(a)
+}
+
+// ActionFinishLayOff represents being done laying off cards
+type ActionFinishLayOff struct {
+	act
+}
+
+func NewActionFinishLayOff(playerID int) Action {
+	return &ActionFinishLayOff{act: act{Name: FINISH_LAY_OFF, PlayerID: playerID}}
+}
+
+func (a ActionFinishLayOff) IsPossible(g GameState) bool {
+	return g.canLayOff(a.PlayerID)
+}
+
+func (a ActionFinishLayOff) Run(g *GameState) error {
+	if !a.IsPossible(*g) {
+		return ErrActionNotPossible
+	}
+
+	g.LayOffFinishedPlayerIDs[a.PlayerID] = true
+	if len(g.layOffPlayerIDs()) == 0 {
+		g.IsLayingOff = false
+		g.scoreRound(g.CurrentRoundClosedByPlayerID, -1)
+	}
+
+	return nil
+}
+
+func (a ActionFinishLayOff) YieldsTurn(g GameState) bool {
+	return false
+}
+
+func (a ActionFinishLayOff) String() string {
+	return defaultActionString(a)
+}
+
+// ActionAcceptReenter represents a busted player re-entering the game (see WithReenter)
+type ActionAcceptReenter struct {
+	act
+}
+
+func NewActionAcceptReenter(playerID int) Action {
+	return &ActionAcceptReenter{act: act{Name: ACCEPT_REENTER, PlayerID: playerID}}
+}
+
+func (a ActionAcceptReenter) IsPossible(g GameState) bool {
+	return !g.IsGameEnded && g.ReenterOfferedPlayerID == a.PlayerID
+}
+
+func (a ActionAcceptReenter) Run(g *GameState) error {
+	if !a.IsPossible(*g) {
+		return ErrActionNotPossible
+	}
+
+	g.Players[a.PlayerID].Score = g.highestRemainingScore()
+	g.ReenteredPlayerIDs[a.PlayerID] = true
+	g.ReenterOfferedPlayerID = -1
+
+	return nil
+}
+
+func (a ActionAcceptReenter) YieldsTurn(g GameState) bool {
+	return false
+}
+
+func (a ActionAcceptReenter) String() string {
+	return defaultActionString(a)
+}
+
+// ActionDeclineReenter represents a busted player declining to re-enter, which ends the game
+type ActionDeclineReenter struct {
+	act
+}
+
+func NewActionDeclineReenter(playerID int) Action {
+	return &ActionDeclineReenter{act: act{Name: DECLINE_REENTER, PlayerID: playerID}}
+}
+
+func (a ActionDeclineReenter) IsPossible(g GameState) bool {
+	return !g.IsGameEnded && g.ReenterOfferedPlayerID == a.PlayerID
+}
+
+func (a ActionDeclineReenter) Run(g *GameState) error {
+	if !a.IsPossible(*g) {
+		return ErrActionNotPossible
+	}
+
+	g.ReenterOfferedPlayerID = -1
+	g.endGame(g.lowestScorer(a.PlayerID), a.PlayerID)
+
+	return nil
+}
+
+func (a ActionDeclineReenter) YieldsTurn(g GameState) bool {
+	return false
+}
+
+func (a ActionDeclineReenter) String() string {
+	return defaultActionString(a)
+}
+
+// ActionResign represents forfeiting the game. Resigning is always possible, even out of
+// turn, so it isn't listed in PossibleActions.
+type ActionResign struct {
+	act
+}
+
+func NewActionResign(playerID int) Action {
+	return &ActionResign{act: act{Name: RESIGN, PlayerID: playerID}}
+}
+
+func (a ActionResign) IsPossible(g GameState) bool {
+	_, ok := g.Players[a.PlayerID]
+	return ok && !g.IsGameEnded
+}
+
+func (a ActionResign) Run(g *GameState) error {
+	if !a.IsPossible(*g) {
+		return ErrActionNotPossible
+	}
+
+	// The other team with the fewest points wins
+	team := g.Players[a.PlayerID].Team
+	g.RoundsLog[g.RoundNumber].WasResigned = true
+	g.RoundsLog[g.RoundNumber].LoserPlayerID = a.PlayerID
+	g.endGame(g.lowestScorer(team), team)
+
+	return nil
+}
+
+func (a ActionResign) YieldsTurn(g GameState) bool {
+	return false
+}
+
+func (a ActionResign) String() string {
+	return defaultActionString(a)
+}
+
+// ActionResignRound represents forfeiting the round, for the penalty set with
+// WithResignRound. Like ActionResign, it isn't listed in PossibleActions.
+type ActionResignRound struct {
+	act
+}
+
+func NewActionResignRound(playerID int) Action {
+	return &ActionResignRound{act: act{Name: RESIGN_ROUND, PlayerID: playerID}}
+}
+
+func (a ActionResignRound) IsPossible(g GameState) bool {
+	_, ok := g.Players[a.PlayerID]
+	return ok && g.RuleResignRoundPenalty > 0 && !g.IsRoundFinished && !g.IsGameEnded
+}
+
+func (a ActionResignRound) Run(g *GameState) error {
+	if !a.IsPossible(*g) {
+		return ErrActionNotPossible
+	}
+
+	g.IsRoundFinished = true
+	g.emit(EVENT_ROUND_CLOSED, a.PlayerID)
+	g.Players[a.PlayerID].Score += g.RuleResignRoundPenalty
+
+	round := g.RoundsLog[g.RoundNumber]
+	round.LoserPlayerID = a.PlayerID
+	round.ScoreChanges = map[int]int{a.PlayerID: g.RuleResignRoundPenalty}
+	g.notify(func(o Observer) { o.OnRoundEnd(g, round) })
+
+	return nil
+}
+
+func (a ActionResignRound) YieldsTurn(g GameState) bool {
+	return false
+}
+
+func (a ActionResignRound) String() string {
+	return defaultActionString(a)
+}
+
+// ActionReorderHand represents arranging the player's hand in the given order, so that it's
+// kept across updates, with drawn cards added at the end. It turns off the player's
+// HandSort. It can be run at any time and doesn't change the game, so it isn't listed in
+// PossibleActions nor logged.
+type ActionReorderHand struct {
+	act
+	Cards []Card `json:"cards"`
+}
+
+func NewActionReorderHand(cards []Card, playerID int) Action {
+	return &ActionReorderHand{act: act{Name: REORDER_HAND, PlayerID: playerID}, Cards: cards}
+}
+
+func (a ActionReorderHand) IsPossible(g GameState) bool {
+	player, ok := g.Players[a.PlayerID]
+	if !ok || player.Hand == nil || len(a.Cards) != len(player.Hand.Cards) || g.IsGameEnded {
+		return false
+	}
+	// The cards must be the hand's, each exactly once
+	rest := player.Hand.DeepCopy()
+	for _, card := range a.Cards {
+		if rest.RemoveCard(card) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (a ActionReorderHand) Run(g *GameState) error {
+	if !a.IsPossible(*g) {
+		return ErrActionNotPossible
+	}
+	g.Players[a.PlayerID].Hand.Cards = append([]Card{}, a.Cards...)
+	g.Players[a.PlayerID].HandSort = SORT_NONE
+	return nil
+}
+
+func (a ActionReorderHand) YieldsTurn(g GameState) bool {
+	return false
+}
+
+func (a ActionReorderHand) String() string {
+	return defaultActionString(a)
+}
+
+// ActionSetHandSort represents choosing how the player's hand is sorted when sent to them,
+// from then on. Like ActionReorderHand, it can be run at any time and isn't listed in
+// PossibleActions nor logged.
+type ActionSetHandSort struct {
+	act
+	Sort string `json:"sort"`
+}
+
+func NewActionSetHandSort(sort string, playerID int) Action {
+	return &ActionSetHandSort{act: act{Name: SET_HAND_SORT, PlayerID: playerID}, Sort: sort}
+}
+
+func (a ActionSetHandSort) IsPossible(g GameState) bool {
+	_, ok := g.Players[a.PlayerID]
+	switch a.Sort {
+	case SORT_NONE, SORT_BY_SUIT, SORT_BY_NUMBER:
+		return ok && !g.IsGameEnded
+	}
+	return false
+}
+
+func (a ActionSetHandSort) Run(g *GameState) error {
+	if !a.IsPossible(*g) {
+		return ErrActionNotPossible
+	}
+	g.Players[a.PlayerID].HandSort = a.Sort
+	return nil
+}
+
+func (a ActionSetHandSort) YieldsTurn(g GameState) bool {
+	return false
+}
+
+func (a ActionSetHandSort) String() string {
+	return defaultActionString(a)
 }
@@ -0,0 +1,29 @@
+package chinchon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CardBackAssetID is the asset identifier of the back of the cards, e.g. for hidden hands.
+const CardBackAssetID = "back"
+
+// AssetID returns the card's stable asset identifier, e.g. "oro_07", so that graphical
+// clients name their card images consistently. The server serves a default card set at
+// /cards/{assetID}.svg.
+func (c Card) AssetID() string {
+	return fmt.Sprintf("%v_%02d", c.Suit, c.Number)
+}
+
+// CardFromAssetID returns the card with the given asset identifier, of any deck type (e.g.
+// "oro_13" is the French deck's king).
+func CardFromAssetID(assetID string) (Card, error) {
+	suit, number, ok := strings.Cut(assetID, "_")
+	n, err := strconv.Atoi(number)
+	card := Card{Suit: suit, Number: n}
+	if !ok || err != nil || len(number) != 2 || !isInDeck(DECK_FRENCH_52, card) {
+		return Card{}, fmt.Errorf("invalid card asset ID [%v]", assetID)
+	}
+	return card, nil
+}
@@ -4,11 +4,59 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand"
+	"time"
 )
 
 // DefaultMaxPoints is the points a player must reach to lose the game.
 const DefaultMaxPoints = 100
 
+// Close bonus modes, for when the closing player has every card grouped
+const (
+	// CLOSE_BONUS_OPPONENTS_PLUS_10 adds 10 points to every opponent's score.
+	CLOSE_BONUS_OPPONENTS_PLUS_10 = "opponents_plus_10"
+
+	// CLOSE_BONUS_CLOSER_MINUS_10 subtracts 10 points from the closing player's score.
+	CLOSE_BONUS_CLOSER_MINUS_10 = "closer_minus_10"
+)
+
+// Close tie modes, for when the closing player ties with the opponent with the fewest
+// penalty points
+const (
+	// CLOSE_TIE_PENALTIES gives every player their penalty points, as if the closer lost.
+	CLOSE_TIE_PENALTIES = "penalties"
+
+	// CLOSE_TIE_CLOSER_WINS scores the round as if the closing player had fewer points.
+	CLOSE_TIE_CLOSER_WINS = "closer_wins"
+
+	// CLOSE_TIE_REPLAY scores nothing, so the round is just dealt again.
+	CLOSE_TIE_REPLAY = "replay"
+
+	// CLOSE_TIE_SPLIT gives every player half their penalty points, rounded down.
+	CLOSE_TIE_SPLIT = "split"
+)
+
+// Chinchón modes, for how a chinchón (every card in a single run) is rewarded
+const (
+	// CHINCHON_WINS_GAME ends the game, won by the player (or team) with the chinchón.
+	CHINCHON_WINS_GAME = "wins_game"
+
+	// CHINCHON_WINS_ROUND wins the round, scored as closing with every card grouped.
+	CHINCHON_WINS_ROUND = "wins_round"
+
+	// CHINCHON_MINUS_25 wins the round, and subtracts 25 points from the player's score.
+	CHINCHON_MINUS_25 = "minus_25"
+)
+
+// DefaultCloseThreshold is the most a card left ungrouped may be worth to close the round.
+const DefaultCloseThreshold = 5
+
+// Hand sizes, for WithHandSize
+const (
+	DefaultHandSize = 7
+	MinHandSize     = 3
+)
+
 // GameState represents the state of a Chinchón game.
 type GameState struct {
 	// RoundNumber is the number of the current round, starting from 1.
@@ -17,7 +65,8 @@ type GameState struct {
 	// TurnPlayerID is the player ID of the player whose turn it is to play an action.
 	TurnPlayerID int `json:"turnPlayerID"`
 
-	// TurnOpponentPlayerID is the player ID of the opponent of the player whose turn it is.
+	// TurnOpponentPlayerID is the player ID of the player who plays next. In a 2 player
+	// game, it's the opponent of the player whose turn it is.
 	TurnOpponentPlayerID int `json:"turnOpponentPlayerID"`
 
 	// Players is a map of player IDs to their respective hands and scores.
@@ -29,6 +78,10 @@ type GameState struct {
 	// DiscardPile is the pile of discarded cards (face up)
 	DiscardPile []Card `json:"discardPile"`
 
+	// DiscardHistory is every card placed on the discard pile during the round, in order,
+	// with who discarded and who picked each one.
+	DiscardHistory []DiscardEntry `json:"discardHistory"`
+
 	// PossibleActions is a list of possible actions that the current player can take.
 	PossibleActions []json.RawMessage `json:"possibleActions"`
 
@@ -38,12 +91,22 @@ type GameState struct {
 	// IsGameEnded is true if the whole game is ended.
 	IsGameEnded bool `json:"isGameEnded"`
 
+	// IsGameAborted is true if the game was ended with Abort, without a winner.
+	IsGameAborted bool `json:"isGameAborted,omitempty"`
+
 	// WinnerPlayerID is the player ID of the player who won the game.
 	WinnerPlayerID int `json:"winnerPlayerID"`
 
 	// LoserPlayerID is the player ID of the player who lost the game (reached max points).
 	LoserPlayerID int `json:"loserPlayerID"`
 
+	// WinnerTeamID is the team ID of the team who won the game. Without teams, every player
+	// is a team of their own, so it's the same as WinnerPlayerID.
+	WinnerTeamID int `json:"winnerTeamID"`
+
+	// LoserTeamID is the team ID of the team who lost the game.
+	LoserTeamID int `json:"loserTeamID"`
+
 	// RoundsLog is the ordered list of logs of each round that was played in the game.
 	RoundsLog []*RoundLog `json:"roundsLog"`
 
@@ -53,11 +116,124 @@ type GameState struct {
 	// RuleMaxPoints is the maximum points before a player loses
 	RuleMaxPoints int `json:"ruleMaxPoints"`
 
+	// RuleMaxRounds is the number of rounds after which the game ends, 0 for no limit (see
+	// WithMaxRounds).
+	RuleMaxRounds int `json:"ruleMaxRounds"`
+
+	// RuleCloseThreshold is the most the ungrouped card may be worth to close the round.
+	RuleCloseThreshold int `json:"ruleCloseThreshold"`
+
+	// RuleCloseBonusMode is how closing with every card grouped is rewarded, either
+	// CLOSE_BONUS_OPPONENTS_PLUS_10 or CLOSE_BONUS_CLOSER_MINUS_10.
+	RuleCloseBonusMode string `json:"ruleCloseBonusMode"`
+
+	// RuleCloseTieMode is how the round is scored when the closing player ties with the best
+	// opponent, e.g. CLOSE_TIE_PENALTIES (see WithCloseTieMode).
+	RuleCloseTieMode string `json:"ruleCloseTieMode"`
+
+	// RuleChinchonMode is how a chinchón is rewarded, e.g. CHINCHON_WINS_GAME (see
+	// WithChinchonMode).
+	RuleChinchonMode string `json:"ruleChinchonMode"`
+
+	// RuleReenter is true if busted players may re-enter the game once (see WithReenter).
+	RuleReenter bool `json:"ruleReenter"`
+
+	// ReenterOfferedPlayerID is the busted player who's deciding whether to re-enter, -1 if none.
+	ReenterOfferedPlayerID int `json:"reenterOfferedPlayerID"`
+
+	// ReenteredPlayerIDs tracks which players have already re-entered the game.
+	ReenteredPlayerIDs map[int]bool `json:"reenteredPlayerIDs"`
+
+	// FirstPlayerID is the player who starts the first round. Later rounds rotate from them.
+	FirstPlayerID int `json:"firstPlayerID"`
+
+	// Match is the series the game is part of, if any (see NewMatch).
+	Match *MatchState `json:"match,omitempty"`
+
+	// RuleResignRoundPenalty is the score a player gets for resigning a round, 0 if only
+	// resigning the whole game is allowed (see WithResignRound).
+	RuleResignRoundPenalty int `json:"ruleResignRoundPenalty"`
+
+	// RuleMinTurnsBeforeClose is the number of turns a player must have finished in the
+	// round before they may close it (see WithMinTurnsBeforeClose).
+	RuleMinTurnsBeforeClose int `json:"ruleMinTurnsBeforeClose"`
+
+	// RuleHandSize is the number of cards dealt to each player, 7 by default (see WithHandSize).
+	RuleHandSize int `json:"ruleHandSize"`
+
+	// RuleDeckType is the deck the game is played with, e.g. DECK_SPANISH_48 (see WithDeckType).
+	RuleDeckType string `json:"ruleDeckType"`
+
+	// RuleAceWraparound is true if runs may connect the 12 to the ace (see WithAceWraparound).
+	RuleAceWraparound bool `json:"ruleAceWraparound"`
+
+	// RuleDeclaredMelds is true if the closing player declares their groups, rather than
+	// having them found (see WithDeclaredMelds).
+	RuleDeclaredMelds bool `json:"ruleDeclaredMelds"`
+
+	// DeclaredGroups are the groups the closing player declared, with RuleDeclaredMelds.
+	DeclaredGroups [][]Card `json:"declaredGroups"`
+
+	// RuleTurnTimeout is how long players have for each turn, 0 for no limit (see
+	// WithTurnTimeout).
+	RuleTurnTimeout time.Duration `json:"ruleTurnTimeout"`
+
+	// RuleTurnTimeoutMode is what happens when a turn times out, e.g. TIMEOUT_AUTO_PLAY.
+	RuleTurnTimeoutMode string `json:"ruleTurnTimeoutMode"`
+
+	// TurnStartedAt is when the current turn started.
+	TurnStartedAt time.Time `json:"turnStartedAt"`
+
+	// RuleUndo is true if actions can be taken back with Undo (see WithUndo).
+	RuleUndo bool `json:"ruleUndo"`
+
+	// RuleLayOff is true if opponents may lay off cards after a round is closed (see WithLayOff).
+	RuleLayOff bool `json:"ruleLayOff"`
+
+	// IsLayingOff is true while opponents lay off cards onto the closing player's groups,
+	// before the round's scores are calculated.
+	IsLayingOff bool `json:"isLayingOff"`
+
+	// LayOffGroups are the closing player's groups, which cards can be laid off onto.
+	LayOffGroups [][]Card `json:"layOffGroups"`
+
+	// LayOffFinishedPlayerIDs tracks which players are done laying off cards.
+	LayOffFinishedPlayerIDs map[int]bool `json:"layOffFinishedPlayerIDs"`
+
 	// CurrentRoundClosedByPlayerID is the player who closed the current round, -1 if none
 	CurrentRoundClosedByPlayerID int `json:"currentRoundClosedByPlayerID"`
 
 	// HasDrawnCard indicates if the current player has drawn a card this turn
 	HasDrawnCard bool `json:"hasDrawnCard"`
+
+	// RuleTeams is true for team games, where scores are pooled per team.
+	RuleTeams bool `json:"ruleTeams"`
+
+	// RuleVerifiableShuffle is true if players may audit each round's deal (see
+	// WithVerifiableShuffle).
+	RuleVerifiableShuffle bool `json:"ruleVerifiableShuffle"`
+
+	// Events are the ordered changes to the table made by the last action (or by the deal,
+	// for a new game), for animating them.
+	Events []Event `json:"events"`
+
+	// runningTakeoverAction is true while running an action through RunTakeoverAction.
+	runningTakeoverAction bool
+
+	// observers are notified as the game progresses (see WithObserver).
+	observers []Observer
+
+	// clock returns the current time, for turn timeouts. If nil, it's time.Now.
+	clock func() time.Time
+
+	// undoSnapshots are the saved states before each action of the round, with RuleUndo.
+	undoSnapshots [][]byte
+
+	// dealHands are the hands to deal in the first round (see WithDealHands).
+	dealHands map[int][]Card
+
+	// validate is true to check the game's invariants after every action (see WithValidation).
+	validate bool
 }
 
 type Player struct {
@@ -66,6 +242,39 @@ type Player struct {
 
 	// Score is the player's penalty score (from 0 to MaxPoints).
 	Score int `json:"score"`
+
+	// Team is the player's team ID. Without teams, it's the player's own ID.
+	Team int `json:"team"`
+
+	// HandSort is how the player's hand is sorted when sent to them, e.g. SORT_BY_SUIT, or
+	// SORT_NONE to keep it as arranged (see ActionSetHandSort).
+	HandSort string `json:"handSort,omitempty"`
+
+	// Name and Avatar are how the player is shown to everyone, if set (see
+	// SetPlayerProfile).
+	Name   string `json:"name,omitempty"`
+	Avatar string `json:"avatar,omitempty"`
+}
+
+// sortedHand returns the player's cards sorted as they prefer (see HandSort).
+func (p Player) sortedHand() []Card {
+	hand := p.Hand.DeepCopy()
+	switch p.HandSort {
+	case SORT_BY_SUIT:
+		hand.SortBySuit()
+	case SORT_BY_NUMBER:
+		hand.SortByNumber()
+	}
+	return hand.Cards
+}
+
+// newPlayers seats count players, each in their own team.
+func newPlayers(count int) map[int]*Player {
+	players := map[int]*Player{}
+	for id := 0; id < count; id++ {
+		players[id] = &Player{Hand: nil, Score: 0, Team: id}
+	}
+	return players
 }
 
 // RoundLog is a log of a round that was played in the game
@@ -82,14 +291,39 @@ type RoundLog struct {
 	// PenaltyPoints is a map of penalty points awarded to each player
 	PenaltyPoints map[int]int `json:"penaltyPoints"`
 
+	// Groups is a map from PlayerID to the runs and sets their hand was scored with.
+	Groups map[int][][]Card `json:"groups,omitempty"`
+
+	// UngroupedCards is a map from PlayerID to the cards that made up their penalty points.
+	UngroupedCards map[int][]Card `json:"ungroupedCards,omitempty"`
+
+	// ScoreChanges is a map of the points actually added to each player's score, which
+	// depend on who closed the round.
+	ScoreChanges map[int]int `json:"scoreChanges"`
+
 	// ClosedByPlayerID is the player who closed the round, -1 if none
 	ClosedByPlayerID int `json:"closedByPlayerID"`
 
 	// WasChinchon indicates if the round was won with a Chinchón
 	WasChinchon bool `json:"wasChinchon"`
 
+	// WasResigned indicates if the game ended in this round because LoserPlayerID resigned
+	WasResigned bool `json:"wasResigned"`
+
 	// ActionsLog is the ordered list of actions of this round.
 	ActionsLog []ActionLog `json:"actionsLog"`
+
+	// DeckDealt is the whole deck, from the top, before this round was dealt.
+	DeckDealt []Card `json:"deckDealt,omitempty"`
+
+	// ShuffleCommitment commits to DeckDealt with RuleVerifiableShuffle, and ShuffleSeed is
+	// the seed it was derived from, only revealed to players once the round is finished.
+	ShuffleCommitment string `json:"shuffleCommitment,omitempty"`
+	ShuffleSeed       string `json:"shuffleSeed,omitempty"`
+
+	// Refills are the draw piles (from the top) made of the discard pile during this round,
+	// in order, for when it ran out.
+	Refills [][]Card `json:"refills,omitempty"`
 }
 
 // ActionLog is a log of an action that was run in a round.
@@ -99,6 +333,9 @@ type ActionLog struct {
 
 	// Action is a JSON-serialized action.
 	Action json.RawMessage `json:"action"`
+
+	// PlayedByBot is true if a bot chose the action while standing in for the player.
+	PlayedByBot bool `json:"playedByBot,omitempty"`
 }
 
 // WithMaxPoints sets the maximum points required to lose the game.
@@ -108,20 +345,231 @@ func WithMaxPoints(maxPoints int) func(*GameState) {
 	}
 }
 
+// WithMaxRounds ends the game after n rounds, won by the player (or team) with the fewest
+// points, unless someone reaches the maximum points first. If several tie for the fewest,
+// rounds go on until the tie is broken.
+func WithMaxRounds(n int) func(*GameState) {
+	return func(gs *GameState) {
+		gs.RuleMaxRounds = n
+	}
+}
+
+// WithCloseThreshold sets the most a card left ungrouped may be worth to close the round
+// (DefaultCloseThreshold by default). With 0, every card must be grouped.
+func WithCloseThreshold(points int) func(*GameState) {
+	return func(gs *GameState) {
+		gs.RuleCloseThreshold = points
+	}
+}
+
+// WithCloseBonusMode sets how closing with every card grouped is rewarded: either with
+// CLOSE_BONUS_OPPONENTS_PLUS_10 (the default) or CLOSE_BONUS_CLOSER_MINUS_10.
+func WithCloseBonusMode(mode string) func(*GameState) {
+	return func(gs *GameState) {
+		gs.RuleCloseBonusMode = mode
+	}
+}
+
+// WithCloseTieMode sets how the round is scored when the closing player has as few penalty
+// points as the best opponent: CLOSE_TIE_PENALTIES (the default), CLOSE_TIE_CLOSER_WINS,
+// CLOSE_TIE_REPLAY or CLOSE_TIE_SPLIT.
+func WithCloseTieMode(mode string) func(*GameState) {
+	return func(gs *GameState) {
+		gs.RuleCloseTieMode = mode
+	}
+}
+
+// WithChinchonMode sets how a chinchón is rewarded: CHINCHON_WINS_GAME (the default),
+// CHINCHON_WINS_ROUND or CHINCHON_MINUS_25. It panics on other modes.
+func WithChinchonMode(mode string) func(*GameState) {
+	return func(gs *GameState) {
+		switch mode {
+		case CHINCHON_WINS_GAME, CHINCHON_WINS_ROUND, CHINCHON_MINUS_25:
+		default:
+			panic(fmt.Sprintf("chinchon: invalid chinchón mode %v", mode))
+		}
+		gs.RuleChinchonMode = mode
+	}
+}
+
+// WithReenter enables the "reenganche" rule: a player who reaches the maximum points may
+// re-enter the game once, with the score of the highest remaining player, instead of
+// losing. The next round waits until they accept or decline. It doesn't apply to teams.
+func WithReenter() func(*GameState) {
+	return func(gs *GameState) {
+		gs.RuleReenter = true
+	}
+}
+
+// WithResignRound lets players resign a round rather than the whole game, getting penalty
+// points (e.g. 25) while everyone else gets none.
+func WithResignRound(penalty int) func(*GameState) {
+	return func(gs *GameState) {
+		gs.RuleResignRoundPenalty = penalty
+	}
+}
+
+// WithMinTurnsBeforeClose forbids closing the round until the player has finished n turns
+// in it, e.g. 1 to forbid closing on their first turn.
+func WithMinTurnsBeforeClose(n int) func(*GameState) {
+	return func(gs *GameState) {
+		gs.RuleMinTurnsBeforeClose = n
+	}
+}
+
+// WithFirstPlayer makes the player start the first round, instead of player 0.
+func WithFirstPlayer(playerID int) func(*GameState) {
+	return func(gs *GameState) {
+		gs.FirstPlayerID = playerID
+	}
+}
+
+// WithDeckType plays the game with the given deck type: DECK_SPANISH_48 (the default),
+// DECK_SPANISH_40, whose 7s and 10s are consecutive, or DECK_FRENCH_52. It panics on other
+// types.
+func WithDeckType(deckType string) func(*GameState) {
+	return func(gs *GameState) {
+		switch deckType {
+		case DECK_SPANISH_40, DECK_SPANISH_48, DECK_FRENCH_52:
+		default:
+			panic(fmt.Sprintf("chinchon: invalid deck type %v", deckType))
+		}
+		gs.RuleDeckType = deckType
+		gs.DrawPile.deckType = deckType
+	}
+}
+
+// WithHandSize deals n cards to each player instead of 7, e.g. 10 for rummy-style variants.
+// A Chinchón then needs all n cards in a run, and closing leaves n cards after the discard.
+func WithHandSize(n int) func(*GameState) {
+	return func(gs *GameState) {
+		if n < MinHandSize {
+			panic(fmt.Sprintf("chinchon: hands need at least %d cards, got %d", MinHandSize, n))
+		}
+		gs.RuleHandSize = n
+	}
+}
+
+// WithAceWraparound lets runs connect the 12 (or the king, with a French deck) to the ace,
+// e.g. 12-1-2, as in some house rules. This includes Chinchón.
+func WithAceWraparound() func(*GameState) {
+	return func(gs *GameState) {
+		gs.RuleAceWraparound = true
+	}
+}
+
+// WithDeclaredMelds makes the closing player declare their groups, as when playing
+// physically: penalties are counted on the cards they leave out, even if they could have
+// been grouped. The declaration must still meet the close condition.
+func WithDeclaredMelds() func(*GameState) {
+	return func(gs *GameState) {
+		gs.RuleDeclaredMelds = true
+	}
+}
+
+// WithLayOff enables the lay-off phase: once a round is closed, and before penalties are
+// counted, opponents may attach their ungrouped cards to the closing player's groups.
+func WithLayOff() func(*GameState) {
+	return func(gs *GameState) {
+		gs.RuleLayOff = true
+	}
+}
+
+// WithSeed makes every deal of the game reproducible: two games created with the
+// same seed receive the same cards, as long as players take the same actions.
+func WithSeed(seed int64) func(*GameState) {
+	return func(gs *GameState) {
+		source := newSeededSource(seed, 0)
+		gs.DrawPile.rng = rand.New(source)
+		gs.DrawPile.seeded = source
+	}
+}
+
+// WithRandSource shuffles every deal of the game with the given source instead of the
+// global math/rand one, e.g. for tests, replays and tournaments that need exact deals.
+// Unlike WithSeed, the source isn't saved with Save.
+func WithRandSource(source rand.Source) func(*GameState) {
+	return func(gs *GameState) {
+		gs.DrawPile.rng = rand.New(source)
+		gs.DrawPile.seeded = nil
+	}
+}
+
+// WithDeck deals the first round from the given deck, from the top, instead of a shuffled
+// one, e.g. for tests and puzzles that need a known deal. Each player is dealt their cards in
+// turn, and the next card starts the discard pile. Later rounds are shuffled as usual.
+func WithDeck(cards []Card) func(*GameState) {
+	return func(gs *GameState) {
+		gs.DrawPile.stacked = append([]Deal{{Cards: append([]Card{}, cards...)}}, gs.DrawPile.stacked...)
+	}
+}
+
+// WithDealHands deals the given hands (a map from PlayerID to its cards) in the first
+// round, and shuffles the remaining cards into the draw pile. Later rounds are shuffled as
+// usual.
+func WithDealHands(hands map[int][]Card) func(*GameState) {
+	return func(gs *GameState) {
+		gs.dealHands = hands
+	}
+}
+
+// Limits for WithPlayers
+const (
+	MinPlayers = 2
+	MaxPlayers = 6
+)
+
+// WithPlayers seats count players, from MinPlayers to MaxPlayers, who play in order of
+// player ID. It panics on other counts. To combine it with WithTeams, pass it first.
+func WithPlayers(count int) func(*GameState) {
+	return func(gs *GameState) {
+		if count < MinPlayers || count > MaxPlayers {
+			panic(fmt.Sprintf("chinchon: invalid player count %d", count))
+		}
+		gs.Players = newPlayers(count)
+	}
+}
+
+// WithTeams makes it a team game, where teammates alternate (players 0, 2, ... are team 0,
+// players 1, 3, ... are team 1), and scores are pooled per team. It's a 2v2 game unless
+// WithPlayers seated more players, in which case it panics if they can't be split evenly.
+func WithTeams() func(*GameState) {
+	return func(gs *GameState) {
+		if len(gs.Players) == 2 {
+			gs.Players = newPlayers(4)
+		}
+		if len(gs.Players)%2 != 0 {
+			panic(fmt.Sprintf("chinchon: can't split %d players in 2 teams", len(gs.Players)))
+		}
+		gs.RuleTeams = true
+		for id, player := range gs.Players {
+			player.Team = id % 2
+		}
+	}
+}
+
 func New(opts ...func(*GameState)) *GameState {
 	gs := &GameState{
-		RoundNumber: 0,
-		Players: map[int]*Player{
-			0: {Hand: nil, Score: 0},
-			1: {Hand: nil, Score: 0},
-		},
+		RoundNumber:                     0,
+		Players:                         newPlayers(2),
 		DrawPile:                        newDeck(),
 		DiscardPile:                     []Card{},
 		IsGameEnded:                     false,
 		WinnerPlayerID:                  -1,
 		LoserPlayerID:                   -1,
+		WinnerTeamID:                    -1,
+		LoserTeamID:                     -1,
 		RoundsLog:                       []*RoundLog{{}}, // initialised with an empty round to be 1-indexed
 		RuleMaxPoints:                   DefaultMaxPoints,
+		RuleCloseThreshold:              DefaultCloseThreshold,
+		RuleDeckType:                    DECK_SPANISH_48,
+		RuleHandSize:                    DefaultHandSize,
+		RuleCloseBonusMode:              CLOSE_BONUS_OPPONENTS_PLUS_10,
+		RuleCloseTieMode:                CLOSE_TIE_PENALTIES,
+		RuleChinchonMode:                CHINCHON_WINS_GAME,
+		ReenterOfferedPlayerID:          -1,
+		ReenteredPlayerIDs:              map[int]bool{},
+		LayOffFinishedPlayerIDs:         map[int]bool{},
 		CurrentRoundClosedByPlayerID:    -1,
 		RoundFinishedConfirmedPlayerIDs: map[int]bool{},
 		HasDrawnCard:                    false,
@@ -130,6 +578,17 @@ func New(opts ...func(*GameState)) *GameState {
 	for _, opt := range opts {
 		opt(gs)
 	}
+	if cards := 4 * len(deckNumbers(gs.RuleDeckType)); len(gs.Players)*gs.RuleHandSize >= cards {
+		panic(fmt.Sprintf("chinchon: %d cards aren't enough for %d players", cards, len(gs.Players)))
+	}
+	if gs.dealHands != nil {
+		gs.stackDealHands()
+	}
+	if len(gs.DrawPile.stacked) > 0 {
+		if err := gs.validateDeck(gs.DrawPile.stacked[0].Cards); err != nil {
+			panic(fmt.Sprintf("chinchon: invalid deck: %v", err))
+		}
+	}
 
 	gs.startNewRound()
 
@@ -137,29 +596,39 @@ func New(opts ...func(*GameState)) *GameState {
 }
 
 func (g *GameState) startNewRound() {
-	g.DrawPile.shuffle()
+	shuffleSeed, shuffleCommitment := g.shuffleRound()
+	deckDealt := append([]Card{}, g.DrawPile.cards...)
 	g.RoundNumber++
 
-	// Alternate who starts each round
-	if g.RoundNumber == 1 {
-		g.TurnPlayerID = 0
-	} else {
-		g.TurnPlayerID = g.OpponentOf(g.TurnPlayerID)
-	}
-	g.TurnOpponentPlayerID = g.OpponentOf(g.TurnPlayerID)
+	// Rotate who starts each round
+	g.TurnPlayerID = (g.FirstPlayerID + g.RoundNumber - 1) % len(g.Players)
+	g.TurnOpponentPlayerID = g.nextPlayer(g.TurnPlayerID)
+	g.TurnStartedAt = g.now()
+
+	g.emit(EVENT_ROUND_STARTED, g.TurnPlayerID)
 
-	// Deal 7 cards to each player
-	g.Players[0].Hand = g.DrawPile.dealHand()
-	g.Players[1].Hand = g.DrawPile.dealHand()
+	// Deal 7 cards (or RuleHandSize) to each player
+	for id := 0; id < len(g.Players); id++ {
+		g.Players[id].Hand = g.DrawPile.dealHand(g.RuleHandSize)
+		for _, card := range g.Players[id].Hand.Cards {
+			g.emitCardMoved(id, card, LOCATION_DECK, LOCATION_HAND, false)
+		}
+	}
 
 	// Place one card face up to start the discard pile
+	g.DiscardHistory = []DiscardEntry{}
 	if !g.DrawPile.isEmpty() {
 		topCard, _ := g.DrawPile.drawCard()
 		g.DiscardPile = []Card{topCard}
+		g.recordDiscard(-1, topCard)
+		g.emitCardMoved(-1, topCard, LOCATION_DECK, LOCATION_DISCARD_PILE, true)
 	}
 
 	g.IsRoundFinished = false
 	g.CurrentRoundClosedByPlayerID = -1
+	g.undoSnapshots = nil
+	g.DeclaredGroups = nil
+	g.LayOffGroups = nil
 	g.RoundFinishedConfirmedPlayerIDs = map[int]bool{}
 	g.HasDrawnCard = false
 
@@ -170,16 +639,21 @@ func (g *GameState) startNewRound() {
 	}
 
 	g.RoundsLog = append(g.RoundsLog, &RoundLog{
-		HandsDealt:       handsDealt,
-		WinnerPlayerID:   -1,
-		LoserPlayerID:    -1,
-		PenaltyPoints:    map[int]int{},
-		ClosedByPlayerID: -1,
-		WasChinchon:      false,
-		ActionsLog:       []ActionLog{},
+		HandsDealt:        handsDealt,
+		DeckDealt:         deckDealt,
+		ShuffleCommitment: shuffleCommitment,
+		ShuffleSeed:       shuffleSeed,
+		WinnerPlayerID:    -1,
+		LoserPlayerID:     -1,
+		PenaltyPoints:     map[int]int{},
+		ScoreChanges:      map[int]int{},
+		ClosedByPlayerID:  -1,
+		WasChinchon:       false,
+		ActionsLog:        []ActionLog{},
 	})
 
 	g.PossibleActions = _serializeActions(g.CalculatePossibleActions())
+	g.notify(func(o Observer) { o.OnRoundStart(g) })
 }
 
 func (g *GameState) RunAction(action Action) error {
@@ -187,55 +661,69 @@ func (g *GameState) RunAction(action Action) error {
 		return nil
 	}
 
+	if g.validate || debugValidation {
+		defer func() { g.mustValidate(action) }()
+	}
+
 	if g.IsGameEnded {
-		return fmt.Errorf("%w trying to run [%v]", errGameIsEnded, action)
+		return fmt.Errorf("%w trying to run [%v]", ErrGameIsEnded, action)
 	}
 
-	if !g.IsRoundFinished && action.GetPlayerID() != g.TurnPlayerID {
-		return errNotYourTurn
+	if !g.IsRoundFinished && action.GetPlayerID() != g.TurnPlayerID && !isOutOfTurn(action) {
+		return ErrNotYourTurn
 	}
 
 	if !action.IsPossible(*g) {
-		return fmt.Errorf("%w trying to run [%v]", errActionNotPossible, action)
+		return fmt.Errorf("%w trying to run [%v]", ErrActionNotPossible, action)
 	}
 
+	pushedUndoSnapshot := g.pushUndoSnapshot(action)
+	g.Events = []Event{}
 	err := action.Run(g)
 	if err != nil {
+		if pushedUndoSnapshot {
+			g.undoSnapshots = g.undoSnapshots[:len(g.undoSnapshots)-1]
+		}
 		return fmt.Errorf("%w trying to run [%v] after checking it was possible", err, action)
 	}
+	defer g.notify(func(o Observer) { o.OnAction(g, action) })
 
-	if action.GetName() != CONFIRM_ROUND_FINISHED {
+	if action.GetName() != CONFIRM_ROUND_FINISHED && !isCosmetic(action) {
 		g.RoundsLog[g.RoundNumber].ActionsLog = append(g.RoundsLog[g.RoundNumber].ActionsLog, ActionLog{
-			PlayerID: g.TurnPlayerID,
-			Action:   SerializeAction(action),
+			PlayerID:    action.GetPlayerID(),
+			Action:      SerializeAction(action),
+			PlayedByBot: g.runningTakeoverAction,
 		})
 	}
 
-	// Start new round if current round is finished
-	if !g.IsGameEnded && g.IsRoundFinished && len(g.RoundFinishedConfirmedPlayerIDs) == 2 {
-		g.startNewRound()
-		return nil
+	// Start new round if current round is finished, unless another busted player is yet to
+	// be offered to re-enter
+	if !g.IsGameEnded && g.IsRoundFinished && len(g.RoundFinishedConfirmedPlayerIDs) == len(g.Players) && g.ReenterOfferedPlayerID == -1 {
+		g.checkMaxPoints()
+		if !g.IsGameEnded && g.ReenterOfferedPlayerID == -1 {
+			g.startNewRound()
+			return nil
+		}
 	}
 
 	// Switch player turn within current round (unless current action doesn't yield turn)
 	if !g.IsGameEnded && !g.IsRoundFinished && action.YieldsTurn(*g) {
-		g.TurnPlayerID, g.TurnOpponentPlayerID = g.TurnOpponentPlayerID, g.TurnPlayerID
-		g.HasDrawnCard = false // Reset draw state for new turn
+		g.changeTurn()
 	}
 
-	if !g.IsGameEnded && g.IsRoundFinished && len(g.RoundFinishedConfirmedPlayerIDs) == 1 {
-		if g.RoundFinishedConfirmedPlayerIDs[g.TurnPlayerID] {
+	// Once the round is finished, the turn goes to the players who haven't confirmed yet
+	if !g.IsGameEnded && g.IsRoundFinished {
+		for i := 0; i < len(g.Players) && g.RoundFinishedConfirmedPlayerIDs[g.TurnPlayerID]; i++ {
 			g.changeTurn()
 		}
 	}
 
 	// Handle end of game due to score
-	for playerID := range g.Players {
-		if g.Players[playerID].Score >= g.RuleMaxPoints {
-			g.IsGameEnded = true
-			g.LoserPlayerID = playerID
-			g.WinnerPlayerID = g.OpponentOf(playerID)
-		}
+	if !g.IsGameEnded {
+		g.checkMaxPoints()
+	}
+	if !g.IsGameEnded {
+		g.checkMaxRounds()
 	}
 
 	possibleActions := g.CalculatePossibleActions()
@@ -250,9 +738,166 @@ func (g *GameState) RunAction(action Action) error {
 	return nil
 }
 
+// isOutOfTurn returns true for the actions that players may run at any time.
+func isOutOfTurn(action Action) bool {
+	return action.GetName() == RESIGN || action.GetName() == RESIGN_ROUND || isCosmetic(action)
+}
+
+// isCosmetic returns true for the actions that only change how a player's hand is shown,
+// which aren't logged nor undone.
+func isCosmetic(action Action) bool {
+	return action.GetName() == REORDER_HAND || action.GetName() == SET_HAND_SORT
+}
+
+// PlayerActions returns the player's possible actions, in the order of
+// CalculatePossibleActions, for RunActionByIndex.
+func (g GameState) PlayerActions(playerID int) []Action {
+	actions := []Action{}
+	for _, action := range g.CalculatePossibleActions() {
+		if action.GetPlayerID() == playerID {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// RunActionByIndex runs the player's possible action at the index in PlayerActions, e.g. for
+// bots and scripts that pick actions by number. Since the order is stable, the same index
+// picks the same action for the same state.
+func (g *GameState) RunActionByIndex(playerID, idx int) error {
+	actions := g.PlayerActions(playerID)
+	if idx < 0 || idx >= len(actions) {
+		return fmt.Errorf("%w: player %d has no action #%d, only %d", ErrActionNotPossible, playerID, idx, len(actions))
+	}
+	return g.RunAction(actions[idx])
+}
+
+// RunTakeoverAction runs an action chosen by a bot standing in for the player (e.g. because
+// they are idle), marking it as such in the action log.
+func (g *GameState) RunTakeoverAction(action Action) error {
+	g.runningTakeoverAction = true
+	defer func() { g.runningTakeoverAction = false }()
+	return g.RunAction(action)
+}
+
 func (g *GameState) changeTurn() {
-	g.TurnPlayerID, g.TurnOpponentPlayerID = g.TurnOpponentPlayerID, g.TurnPlayerID
+	g.TurnPlayerID = g.TurnOpponentPlayerID
+	g.TurnOpponentPlayerID = g.nextPlayer(g.TurnPlayerID)
 	g.HasDrawnCard = false
+	g.TurnStartedAt = g.now()
+}
+
+// nextPlayer returns the player who plays after the given one. Players play in order of ID.
+func (g GameState) nextPlayer(playerID int) int {
+	return (playerID + 1) % len(g.Players)
+}
+
+// TeamScores returns the pooled score of each team.
+func (g GameState) TeamScores() map[int]int {
+	scores := map[int]int{}
+	for _, player := range g.Players {
+		scores[player.Team] += player.Score
+	}
+	return scores
+}
+
+// checkMaxPoints ends the game if a team reached the maximum points. The team with the
+// most points loses, and the one with the fewest wins, ties going to the lowest team ID.
+// With the re-enter rule, the losing player is offered to re-enter instead, if they
+// haven't already.
+func (g *GameState) checkMaxPoints() {
+	if g.ReenterOfferedPlayerID != -1 {
+		return // Waiting for them to decide
+	}
+	scores := g.TeamScores()
+	loser := -1
+	for team, score := range scores {
+		if score >= g.RuleMaxPoints && (loser == -1 || score > scores[loser] || (score == scores[loser] && team < loser)) {
+			loser = team
+		}
+	}
+	if loser == -1 {
+		return
+	}
+	winner := g.lowestScorer(loser)
+	if g.RuleReenter && !g.RuleTeams && !g.ReenteredPlayerIDs[loser] && g.highestRemainingScore() != -1 {
+		g.ReenterOfferedPlayerID = loser
+		return
+	}
+	g.endGame(winner, loser)
+}
+
+// checkMaxRounds ends the game once the last round allowed by RuleMaxRounds is scored.
+func (g *GameState) checkMaxRounds() {
+	if g.RuleMaxRounds == 0 || g.RoundNumber < g.RuleMaxRounds || !g.IsRoundFinished || g.IsLayingOff || g.ReenterOfferedPlayerID != -1 {
+		return
+	}
+	scores := g.TeamScores()
+	winner := uniqueExtreme(scores, func(a, b int) bool { return a < b })
+	if winner == -1 {
+		return // Tied, so another round is played
+	}
+	g.endGame(winner, g.highestScorer(-1))
+}
+
+// highestRemainingScore returns the highest score among players below the maximum points,
+// or -1 if there are none.
+func (g GameState) highestRemainingScore() int {
+	highest := -1
+	for _, player := range g.Players {
+		if player.Score < g.RuleMaxPoints && player.Score > highest {
+			highest = player.Score
+		}
+	}
+	return highest
+}
+
+// lowestScorer returns the team with the fewest points other than the given one (none,
+// with -1). Ties go to the lowest team ID, so that games replay the same.
+func (g GameState) lowestScorer(except int) int {
+	scores := g.TeamScores()
+	lowest := -1
+	for team, score := range scores {
+		if team != except && (lowest == -1 || score < scores[lowest] || (score == scores[lowest] && team < lowest)) {
+			lowest = team
+		}
+	}
+	return lowest
+}
+
+// highestScorer returns the team with the most points other than the given one (none, with
+// -1). Ties go to the lowest team ID, as with lowestScorer.
+func (g GameState) highestScorer(except int) int {
+	scores := g.TeamScores()
+	highest := -1
+	for team, score := range scores {
+		if team != except && (highest == -1 || score > scores[highest] || (score == scores[highest] && team < highest)) {
+			highest = team
+		}
+	}
+	return highest
+}
+
+// Abort ends the game without a winner, e.g. for a server operator to finish a stuck game.
+// Nothing is scored, and no more actions are possible.
+func (g *GameState) Abort() {
+	if g.IsGameEnded {
+		return
+	}
+	g.IsGameAborted = true
+	g.endGame(-1, -1)
+}
+
+// endGame ends the game with the given winning and losing teams.
+func (g *GameState) endGame(winnerTeamID, loserTeamID int) {
+	g.IsGameEnded = true
+	g.WinnerTeamID = winnerTeamID
+	g.LoserTeamID = loserTeamID
+	if !g.RuleTeams {
+		g.WinnerPlayerID = winnerTeamID
+		g.LoserPlayerID = loserTeamID
+	}
+	g.notify(func(o Observer) { o.OnGameEnd(g) })
 }
 
 func (g GameState) countActionsOfTurnPlayer() int {
@@ -265,15 +910,22 @@ func (g GameState) countActionsOfTurnPlayer() int {
 	return count
 }
 
+// OpponentOf returns the first player after the given one (in turn order) who isn't
+// their teammate. In a 2 player game, it's simply the other player.
 func (g GameState) OpponentOf(playerID int) int {
-	for id := range g.Players {
-		if id != playerID {
+	for id := g.nextPlayer(playerID); id != playerID; id = g.nextPlayer(id) {
+		if !g.AreTeammates(id, playerID) {
 			return id
 		}
 	}
 	return -1 // Unreachable
 }
 
+// AreTeammates returns true if both players are on the same team (or are the same player).
+func (g GameState) AreTeammates(playerID, otherPlayerID int) bool {
+	return g.Players[playerID].Team == g.Players[otherPlayerID].Team
+}
+
 func (g GameState) Serialize() ([]byte, error) {
 	return json.Marshal(g)
 }
@@ -295,106 +947,222 @@ func (g GameState) GetTopDiscardCard() (Card, error) {
 	return g.DiscardPile[len(g.DiscardPile)-1], nil
 }
 
-// CanClose returns true if the current player can close the round
+// CanClose returns true if the player can close the round: after drawing, they must be
+// able to discard a card and have at most one ungrouped card left, worth no more than
+// RuleCloseThreshold.
 func (g GameState) CanClose(playerID int) bool {
 	if g.IsRoundFinished {
 		return false
 	}
 
+	return len(g.closingDiscards(playerID)) > 0
+}
+
+// handRules returns the game's rules for grouping hands.
+func (g GameState) handRules() handRules {
+	return handRules{aceWraparound: g.RuleAceWraparound, deckType: g.RuleDeckType, handSize: g.RuleHandSize}
+}
+
+// closingDiscards returns the cards the player may close the round by discarding.
+func (g GameState) closingDiscards(playerID int) []Card {
+	// The player closes after drawing, so they hold an extra card
 	hand := g.Players[playerID].Hand
-	if hand == nil || len(hand.Cards) != 7 {
-		return false
+	if hand == nil || len(hand.Cards) != g.RuleHandSize+1 {
+		return nil
 	}
 
-	// Check if player can form valid groups with all cards except one (which will be discarded)
-	// For simplicity, we'll check if they can group 6 cards (leaving 1 for discard)
-	validGroups := hand.ValidGroups()
-	groupedCards := make(map[Card]bool)
-	for _, group := range validGroups {
-		for _, card := range group {
-			groupedCards[card] = true
-		}
-	}
+	return g.handRules().closingDiscards(hand.Cards, g.RuleCloseThreshold)
+}
 
-	ungroupedCount := 0
-	for _, card := range hand.Cards {
-		if !groupedCards[card] {
-			ungroupedCount++
+// turnsBeforeClose returns how many more turns the player must finish in the round before
+// they may close it, with RuleMinTurnsBeforeClose.
+func (g GameState) turnsBeforeClose(playerID int) int {
+	// Every turn but the one closing the round ends with a discard
+	turns := 0
+	for _, log := range g.RoundsLog[g.RoundNumber].ActionsLog {
+		if action, err := DeserializeAction(log.Action); err == nil && log.PlayerID == playerID && action.GetName() == DISCARD_CARD {
+			turns++
 		}
 	}
-
-	// Can close if at most 1 card is ungrouped (will be discarded)
-	return ungroupedCount <= 1
+	if turns >= g.RuleMinTurnsBeforeClose {
+		return 0
+	}
+	return g.RuleMinTurnsBeforeClose - turns
 }
 
 // CloseRound closes the current round and calculates scores
 func (g *GameState) CloseRound(closingPlayerID int) {
 	g.IsRoundFinished = true
 	g.CurrentRoundClosedByPlayerID = closingPlayerID
+	g.emit(EVENT_ROUND_CLOSED, closingPlayerID)
+
+	// Players are checked in order of ID, so that two chinchóns are scored the same every time
+	for playerID := 0; playerID < len(g.Players); playerID++ {
+		player := g.Players[playerID]
+		if player.Hand != nil && g.handRules().isChinchon(player.Hand.Cards) {
+			g.RoundsLog[g.RoundNumber].WasChinchon = true
+			if !g.chinchonEndsGame() {
+				// Scored as if the player with the chinchón closed, without laying off
+				g.scoreRound(closingPlayerID, playerID)
+				return
+			}
+			// Chinchón ends the game immediately
+			g.RoundsLog[g.RoundNumber].WinnerPlayerID = playerID
+			g.RoundsLog[g.RoundNumber].ClosedByPlayerID = closingPlayerID
+			g.notify(func(o Observer) { o.OnRoundEnd(g, g.RoundsLog[g.RoundNumber]) })
+			g.endGame(player.Team, g.highestScorer(player.Team))
+			return
+		}
+	}
+
+	// With the lay-off rule, scores wait until opponents attach what they can to the
+	// closing player's groups
+	if g.RuleLayOff && closingPlayerID != -1 {
+		g.startLayOff(closingPlayerID)
+		return
+	}
+	g.scoreRound(closingPlayerID, -1)
+}
+
+// chinchonEndsGame returns true if a chinchón ends the game. Zero values (e.g. from games
+// saved by older versions) are the default CHINCHON_WINS_GAME.
+func (g GameState) chinchonEndsGame() bool {
+	return g.RuleChinchonMode != CHINCHON_WINS_ROUND && g.RuleChinchonMode != CHINCHON_MINUS_25
+}
+
+// scoreRound calculates the round's scores, once it's closed. A chinchón (-1 if none) is
+// scored as if its player closed, but the round is still logged as closed by the closer.
+func (g *GameState) scoreRound(closingPlayerID, chinchonPlayerID int) {
+	scorer := closingPlayerID
+	if chinchonPlayerID != -1 {
+		scorer = chinchonPlayerID
+	}
 
 	// Calculate penalty points for each player
 	penaltyPoints := make(map[int]int)
-
+	groups := make(map[int][][]Card)
+	ungroupedCards := make(map[int][]Card)
 	for playerID, player := range g.Players {
 		if player.Hand == nil {
 			continue
 		}
 
-		// Check for Chinchón
-		if player.Hand.IsChinchon() {
-			// Chinchón ends the game immediately
-			g.IsGameEnded = true
-			g.WinnerPlayerID = playerID
-			g.LoserPlayerID = g.OpponentOf(playerID)
-			g.RoundsLog[g.RoundNumber].WasChinchon = true
-			return
-		}
-
 		// Calculate penalty points based on ungrouped cards
-		validGroups := player.Hand.ValidGroups()
-		penalty := player.Hand.PenaltyPoints(validGroups)
+		playerGroups, penalty := g.scoredPartition(playerID)
 		penaltyPoints[playerID] = penalty
+		groups[playerID] = playerGroups
+		ungroupedCards[playerID] = player.Hand.UngroupedCards(playerGroups)
 	}
 
-	// Determine round winner (player with fewer penalty points)
-	player0Penalty := penaltyPoints[0]
-	player1Penalty := penaltyPoints[1]
+	// Determine round winner (player with fewer penalty points) and loser (with the most).
+	// On a tie, there is no winner (or loser).
+	roundWinner := uniqueExtreme(penaltyPoints, func(a, b int) bool { return a < b })
+	roundLoser := uniqueExtreme(penaltyPoints, func(a, b int) bool { return a > b })
 
-	var roundWinner, roundLoser int
-	if player0Penalty < player1Penalty {
-		roundWinner = 0
-		roundLoser = 1
-	} else if player1Penalty < player0Penalty {
-		roundWinner = 1
-		roundLoser = 0
-	} else {
-		// Tie - both players get their penalty points
-		roundWinner = -1
-		roundLoser = -1
+	// The closing player wins the round if they have fewer penalty points than every opponent,
+	// and ties with the best opponent are scored according to the rules
+	closerWon, tied := scorer != -1, false
+	for playerID, penalty := range penaltyPoints {
+		if scorer == -1 || g.AreTeammates(playerID, scorer) {
+			continue
+		}
+		if penalty < penaltyPoints[scorer] {
+			closerWon, tied = false, false
+			break
+		}
+		if penalty == penaltyPoints[scorer] {
+			closerWon, tied = false, true
+		}
+	}
+	if tied && g.RuleCloseTieMode == CLOSE_TIE_CLOSER_WINS {
+		closerWon, roundWinner = true, scorer
+	}
+	wasChinchon := g.RoundsLog[g.RoundNumber].WasChinchon
+	if wasChinchon {
+		closerWon, tied, roundWinner = true, false, scorer
 	}
 
 	// Award penalty points
-	if closingPlayerID != -1 && roundWinner == closingPlayerID {
-		// Player who closed won - opponent gets penalty points
-		opponentID := g.OpponentOf(closingPlayerID)
-		g.Players[opponentID].Score += penaltyPoints[opponentID]
-
-		// If closing player grouped all cards perfectly, opponent gets 10 extra points
-		if penaltyPoints[closingPlayerID] == 0 {
-			g.Players[opponentID].Score += 10
+	scoreChanges := map[int]int{}
+	switch {
+	case tied && g.RuleCloseTieMode == CLOSE_TIE_REPLAY:
+		// Nobody scores
+	case tied && g.RuleCloseTieMode == CLOSE_TIE_SPLIT:
+		for playerID, penalty := range penaltyPoints {
+			scoreChanges[playerID] = penalty / 2
+		}
+	case closerWon:
+		// Player who closed won - opponents get penalty points
+		for playerID, penalty := range penaltyPoints {
+			if g.AreTeammates(playerID, scorer) {
+				continue
+			}
+			scoreChanges[playerID] = penalty
+
+			// If closing player grouped all cards perfectly, opponents get 10 extra points
+			if penaltyPoints[scorer] == 0 && g.RuleCloseBonusMode != CLOSE_BONUS_CLOSER_MINUS_10 {
+				scoreChanges[playerID] += 10
+			}
 		}
-	} else {
+		// ...or the closing player gets 10 points off, depending on the rules
+		if penaltyPoints[scorer] == 0 && g.RuleCloseBonusMode == CLOSE_BONUS_CLOSER_MINUS_10 {
+			scoreChanges[scorer] = -10
+		}
+		if wasChinchon && g.RuleChinchonMode == CHINCHON_MINUS_25 {
+			for playerID, penalty := range penaltyPoints {
+				if !g.AreTeammates(playerID, scorer) {
+					scoreChanges[playerID] = penalty
+				}
+			}
+			scoreChanges[scorer] = -25
+		}
+	default:
 		// Normal scoring - everyone gets their penalty points
 		for playerID, penalty := range penaltyPoints {
-			g.Players[playerID].Score += penalty
+			scoreChanges[playerID] = penalty
 		}
 	}
+	for playerID, points := range scoreChanges {
+		g.Players[playerID].Score += points
+	}
 
 	// Update round log
 	g.RoundsLog[g.RoundNumber].WinnerPlayerID = roundWinner
 	g.RoundsLog[g.RoundNumber].LoserPlayerID = roundLoser
 	g.RoundsLog[g.RoundNumber].PenaltyPoints = penaltyPoints
+	g.RoundsLog[g.RoundNumber].Groups = groups
+	g.RoundsLog[g.RoundNumber].UngroupedCards = ungroupedCards
+	g.RoundsLog[g.RoundNumber].ScoreChanges = scoreChanges
 	g.RoundsLog[g.RoundNumber].ClosedByPlayerID = closingPlayerID
+	g.notify(func(o Observer) { o.OnRoundEnd(g, g.RoundsLog[g.RoundNumber]) })
+}
+
+// scoredPartition returns the player's groups as scored, and their penalty points: grouped
+// in the best possible way, or as declared by the closing player.
+func (g GameState) scoredPartition(playerID int) ([][]Card, int) {
+	hand := g.Players[playerID].Hand
+	if g.RuleDeclaredMelds && playerID == g.CurrentRoundClosedByPlayerID {
+		return g.DeclaredGroups, hand.PenaltyPoints(g.DeclaredGroups)
+	}
+	return g.handRules().bestPartition(hand.Cards)
+}
+
+// uniqueExtreme returns the player whose points beat everyone else's according to better,
+// or -1 if several players tie for it.
+func uniqueExtreme(points map[int]int, better func(a, b int) bool) int {
+	best, tied := -1, false
+	for playerID, p := range points {
+		switch {
+		case best == -1 || better(p, points[best]):
+			best, tied = playerID, false
+		case p == points[best]:
+			tied = true
+		}
+	}
+	if tied {
+		return -1
+	}
+	return best
 }
 
 type Action interface {
@@ -409,12 +1177,18 @@ type Action interface {
 	fmt.Stringer
 }
 
+// Errors returned by RunAction, to check with errors.Is. ValidateAction tells why in detail.
 var (
-	errActionNotPossible = errors.New("action not possible")
-	errGameIsEnded       = errors.New("game is ended")
-	errNotYourTurn       = errors.New("not your turn")
+	ErrActionNotPossible = errors.New("action not possible")
+	ErrGameIsEnded       = errors.New("game is ended")
+	ErrNotYourTurn       = errors.New("not your turn")
+	ErrNothingToUndo     = errors.New("nothing to undo")
 )
 
+// CalculatePossibleActions returns the actions that may be run now, in a stable order that
+// only depends on the game's state (not on how players arranged their hands): drawing,
+// discarding and closing (by card, sorted by suit), confirming the round (by player),
+// laying off, and re-entering.
 func (g GameState) CalculatePossibleActions() []Action {
 	allActions := []Action{}
 
@@ -428,21 +1202,49 @@ func (g GameState) CalculatePossibleActions() []Action {
 
 	// Add discarding actions (if player has drawn)
 	if g.HasDrawnCard && !g.IsRoundFinished {
-		for _, card := range g.Players[g.TurnPlayerID].Hand.Cards {
+		for _, card := range sortedBySuit(g.Players[g.TurnPlayerID].Hand.Cards) {
 			allActions = append(allActions, NewActionDiscardCard(card, g.TurnPlayerID))
 		}
 	}
 
 	// Add close actions (if player can close)
 	if g.CanClose(g.TurnPlayerID) && g.HasDrawnCard {
-		allActions = append(allActions, NewActionClose(g.TurnPlayerID))
+		for _, card := range sortedBySuit(g.closingDiscards(g.TurnPlayerID)) {
+			action := NewActionClose(card, g.TurnPlayerID)
+			if g.RuleDeclaredMelds {
+				// Suggest declaring the best groups, although any valid declaration is possible
+				rest := &Hand{Cards: append([]Card{}, g.Players[g.TurnPlayerID].Hand.Cards...)}
+				_ = rest.RemoveCard(card)
+				action.(*ActionClose).Groups, _ = g.handRules().bestPartition(rest.Cards)
+			}
+			allActions = append(allActions, action)
+		}
 	}
 
 	// Add confirm round finished actions
-	allActions = append(allActions,
-		NewActionConfirmRoundFinished(g.TurnPlayerID),
-		NewActionConfirmRoundFinished(g.TurnOpponentPlayerID),
-	)
+	for playerID := 0; playerID < len(g.Players); playerID++ {
+		allActions = append(allActions, NewActionConfirmRoundFinished(playerID))
+	}
+
+	// Add lay-off actions (if the round was closed with the lay-off rule)
+	if g.IsLayingOff {
+		for _, playerID := range g.layOffPlayerIDs() {
+			for _, card := range sortedBySuit(g.Players[playerID].Hand.Cards) {
+				for i := range g.LayOffGroups {
+					allActions = append(allActions, NewActionLayOffCard(card, i, playerID))
+				}
+			}
+			allActions = append(allActions, NewActionFinishLayOff(playerID))
+		}
+	}
+
+	// Add re-enter actions (if a busted player was offered to)
+	if g.ReenterOfferedPlayerID != -1 {
+		allActions = append(allActions,
+			NewActionAcceptReenter(g.ReenterOfferedPlayerID),
+			NewActionDeclineReenter(g.ReenterOfferedPlayerID),
+		)
+	}
 
 	possibleActions := []Action{}
 	priority := 0
@@ -463,17 +1265,23 @@ func (g GameState) CalculatePossibleActions() []Action {
 	return possibleActions
 }
 
+// SerializeAction serializes the action with the current SchemaVersion.
 func SerializeAction(action Action) []byte {
 	bs, _ := json.Marshal(action)
-	return bs
+	return withSchemaVersion(bs)
 }
 
+// DeserializeAction deserializes an action, migrating it from older schema versions.
 func DeserializeAction(bs []byte) (Action, error) {
 	var actionName struct {
 		Name string `json:"name"`
 	}
 
-	err := json.Unmarshal(bs, &actionName)
+	bs, err := migrate(bs, actionMigrations)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(bs, &actionName)
 	if err != nil {
 		return nil, err
 	}
@@ -490,6 +1298,22 @@ func DeserializeAction(bs []byte) (Action, error) {
 		action = &ActionClose{}
 	case CONFIRM_ROUND_FINISHED:
 		action = &ActionConfirmRoundFinished{}
+	case LAY_OFF_CARD:
+		action = &ActionLayOffCard{}
+	case FINISH_LAY_OFF:
+		action = &ActionFinishLayOff{}
+	case RESIGN:
+		action = &ActionResign{}
+	case RESIGN_ROUND:
+		action = &ActionResignRound{}
+	case ACCEPT_REENTER:
+		action = &ActionAcceptReenter{}
+	case DECLINE_REENTER:
+		action = &ActionDeclineReenter{}
+	case REORDER_HAND:
+		action = &ActionReorderHand{}
+	case SET_HAND_SORT:
+		action = &ActionSetHandSort{}
 	default:
 		return nil, fmt.Errorf("unknown action: [%v]", string(bs))
 	}
@@ -510,6 +1334,24 @@ func _serializeActions(as []Action) []json.RawMessage {
 	return _as
 }
 
+// DrawPileSize returns the number of cards left in the draw pile.
+func (g GameState) DrawPileSize() int {
+	return g.DrawPile.remainingCards()
+}
+
+// Actions deserializes PossibleActions, failing on the first one that can't be.
+func (c ClientGameState) Actions() ([]Action, error) {
+	actions := []Action{}
+	for _, bs := range c.PossibleActions {
+		action, err := DeserializeAction(bs)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
 func (g *GameState) ToClientGameState(youPlayerID int) ClientGameState {
 	themPlayerID := g.OpponentOf(youPlayerID)
 
@@ -527,24 +1369,89 @@ func (g *GameState) ToClientGameState(youPlayerID int) ClientGameState {
 		topDiscardCard = &card
 	}
 
+	// Hands are revealed once the round is scored, so that players can check it
+	isRevealed := g.IsRoundFinished && !g.IsLayingOff
+	players := []ClientPlayer{}
+	for playerID := 0; playerID < len(g.Players); playerID++ {
+		player := ClientPlayer{
+			PlayerID: playerID,
+			Team:     g.Players[playerID].Team,
+			Score:    g.Players[playerID].Score,
+			HandSize: len(g.Players[playerID].Hand.Cards),
+			Name:     g.Players[playerID].Name,
+			Avatar:   g.Players[playerID].Avatar,
+		}
+		if isRevealed {
+			player.Hand = g.Players[playerID].Hand.Cards
+			player.Groups, player.PenaltyPoints = g.scoredPartition(playerID)
+		}
+		players = append(players, player)
+	}
+
 	cgs := ClientGameState{
-		RoundNumber:     g.RoundNumber,
-		TurnPlayerID:    g.TurnPlayerID,
-		YouPlayerID:     youPlayerID,
-		ThemPlayerID:    themPlayerID,
-		YourScore:       g.Players[youPlayerID].Score,
-		TheirScore:      g.Players[themPlayerID].Score,
-		YourHand:        g.Players[youPlayerID].Hand.Cards,
-		TheirHandSize:   len(g.Players[themPlayerID].Hand.Cards),
-		TopDiscardCard:  topDiscardCard,
-		DrawPileSize:    g.DrawPile.remainingCards(),
-		PossibleActions: _serializeActions(filteredPossibleActions),
-		IsGameEnded:     g.IsGameEnded,
-		IsRoundFinished: g.IsRoundFinished,
-		WinnerPlayerID:  g.WinnerPlayerID,
-		LoserPlayerID:   g.LoserPlayerID,
-		RuleMaxPoints:   g.RuleMaxPoints,
-		HasDrawnCard:    g.HasDrawnCard,
+		RoundNumber:             g.RoundNumber,
+		TurnPlayerID:            g.TurnPlayerID,
+		YouPlayerID:             youPlayerID,
+		ThemPlayerID:            themPlayerID,
+		YourScore:               g.Players[youPlayerID].Score,
+		TheirScore:              g.Players[themPlayerID].Score,
+		YourHand:                g.Players[youPlayerID].sortedHand(),
+		TheirHandSize:           len(g.Players[themPlayerID].Hand.Cards),
+		TopDiscardCard:          topDiscardCard,
+		DrawPileSize:            g.DrawPileSize(),
+		DiscardHistory:          g.DiscardHistory,
+		PossibleActions:         _serializeActions(filteredPossibleActions),
+		IsGameEnded:             g.IsGameEnded,
+		IsGameAborted:           g.IsGameAborted,
+		IsRoundFinished:         g.IsRoundFinished,
+		WinnerPlayerID:          g.WinnerPlayerID,
+		LoserPlayerID:           g.LoserPlayerID,
+		RuleMaxPoints:           g.RuleMaxPoints,
+		RuleMaxRounds:           g.RuleMaxRounds,
+		RuleCloseThreshold:      g.RuleCloseThreshold,
+		RuleCloseBonusMode:      g.RuleCloseBonusMode,
+		RuleCloseTieMode:        g.RuleCloseTieMode,
+		RuleChinchonMode:        g.RuleChinchonMode,
+		RuleReenter:             g.RuleReenter,
+		RuleLayOff:              g.RuleLayOff,
+		RuleDeclaredMelds:       g.RuleDeclaredMelds,
+		DeclaredGroups:          g.DeclaredGroups,
+		RuleAceWraparound:       g.RuleAceWraparound,
+		RuleDeckType:            g.RuleDeckType,
+		RuleResignRoundPenalty:  g.RuleResignRoundPenalty,
+		RuleHandSize:            g.RuleHandSize,
+		RuleMinTurnsBeforeClose: g.RuleMinTurnsBeforeClose,
+		TurnsBeforeYouCanClose:  g.turnsBeforeClose(youPlayerID),
+		TurnTimeRemainingMs:     g.turnTimeRemaining().Milliseconds(),
+		IsLayingOff:             g.IsLayingOff,
+		LayOffGroups:            g.LayOffGroups,
+		ReenterOfferedPlayerID:  g.ReenterOfferedPlayerID,
+		HasDrawnCard:            g.HasDrawnCard,
+		Players:                 players,
+		YourTeamID:              g.Players[youPlayerID].Team,
+		TeamScores:              g.TeamScores(),
+		WinnerTeamID:            g.WinnerTeamID,
+		LoserTeamID:             g.LoserTeamID,
+		RuleTeams:               g.RuleTeams,
+		RuleVerifiableShuffle:   g.RuleVerifiableShuffle,
+		Events:                  g.eventsFor(youPlayerID),
+	}
+	if isRevealed {
+		cgs.TheirHand = g.Players[themPlayerID].Hand.Cards
+	}
+	if g.IsGameEnded {
+		stats := g.Stats()
+		cgs.Stats = &stats
+	}
+	if g.Match != nil {
+		match := *g.Match
+		cgs.Match = &match
+	}
+	if round := g.RoundsLog[g.RoundNumber]; round.ShuffleCommitment != "" {
+		cgs.ShuffleCommitment = round.ShuffleCommitment
+		if g.IsRoundFinished && !g.IsLayingOff {
+			cgs.ShuffleSeed = round.ShuffleSeed
+		}
 	}
 
 	if len(g.RoundsLog[g.RoundNumber].ActionsLog) > 0 {
@@ -552,6 +1459,9 @@ func (g *GameState) ToClientGameState(youPlayerID int) ClientGameState {
 		cgs.LastActionLog = &actionsLog[len(actionsLog)-1]
 	}
 
+	cgs.SchemaVersion = SchemaVersion
+	cgs.StateHash = cgs.Fingerprint()
+
 	return cgs
 }
 
@@ -565,14 +1475,23 @@ type ClientGameState struct {
 	YourScore    int `json:"yourScore"`
 	TheirScore   int `json:"theirScore"`
 
-	YourHand       []Card `json:"yourHand"`
-	TheirHandSize  int    `json:"theirHandSize"`
-	TopDiscardCard *Card  `json:"topDiscardCard"`
-	DrawPileSize   int    `json:"drawPileSize"`
+	YourHand      []Card `json:"yourHand"`
+	TheirHandSize int    `json:"theirHandSize"`
+
+	// TheirHand is revealed once the round is finished (and scored), and empty until then.
+	TheirHand []Card `json:"theirHand,omitempty"`
+
+	TopDiscardCard *Card `json:"topDiscardCard"`
+	DrawPileSize   int   `json:"drawPileSize"`
+
+	// DiscardHistory is every card placed on the discard pile during the round, in order,
+	// with who discarded and who picked each one.
+	DiscardHistory []DiscardEntry `json:"discardHistory"`
 
 	PossibleActions []json.RawMessage `json:"possibleActions"`
 
 	IsGameEnded     bool `json:"isGameEnded"`
+	IsGameAborted   bool `json:"isGameAborted,omitempty"`
 	IsRoundFinished bool `json:"isRoundFinished"`
 
 	WinnerPlayerID int `json:"winnerPlayerID"`
@@ -580,8 +1499,90 @@ type ClientGameState struct {
 
 	LastActionLog *ActionLog `json:"lastActionLog"`
 
-	RuleMaxPoints int  `json:"ruleMaxPoints"`
-	HasDrawnCard  bool `json:"hasDrawnCard"`
+	RuleMaxPoints           int    `json:"ruleMaxPoints"`
+	RuleMaxRounds           int    `json:"ruleMaxRounds"`
+	RuleCloseThreshold      int    `json:"ruleCloseThreshold"`
+	RuleCloseBonusMode      string `json:"ruleCloseBonusMode"`
+	RuleCloseTieMode        string `json:"ruleCloseTieMode"`
+	RuleChinchonMode        string `json:"ruleChinchonMode"`
+	RuleReenter             bool   `json:"ruleReenter"`
+	RuleLayOff              bool   `json:"ruleLayOff"`
+	RuleDeclaredMelds       bool   `json:"ruleDeclaredMelds"`
+	RuleAceWraparound       bool   `json:"ruleAceWraparound"`
+	RuleDeckType            string `json:"ruleDeckType"`
+	RuleResignRoundPenalty  int    `json:"ruleResignRoundPenalty"`
+	RuleHandSize            int    `json:"ruleHandSize"`
+	RuleMinTurnsBeforeClose int    `json:"ruleMinTurnsBeforeClose"`
+	HasDrawnCard            bool   `json:"hasDrawnCard"`
+
+	// TurnsBeforeYouCanClose is how many more turns you must finish before you may close the
+	// round, with RuleMinTurnsBeforeClose.
+	TurnsBeforeYouCanClose int `json:"turnsBeforeYouCanClose"`
+
+	// TurnTimeRemainingMs is the time left for the current turn, in milliseconds, with a
+	// turn timeout. It's 0 otherwise.
+	TurnTimeRemainingMs int64 `json:"turnTimeRemainingMs"`
+
+	// DeclaredGroups are the groups the closing player declared, with RuleDeclaredMelds.
+	DeclaredGroups [][]Card `json:"declaredGroups"`
+
+	// IsLayingOff is true while opponents lay off cards onto LayOffGroups, the closing
+	// player's groups, before the round's scores are calculated.
+	IsLayingOff  bool     `json:"isLayingOff"`
+	LayOffGroups [][]Card `json:"layOffGroups"`
+
+	// ReenterOfferedPlayerID is the busted player who's deciding whether to re-enter, -1 if none.
+	ReenterOfferedPlayerID int `json:"reenterOfferedPlayerID"`
+
+	// Players lists every player at the table (including you), in turn order.
+	Players []ClientPlayer `json:"players"`
+
+	// Match is the series score, if the game is part of a match.
+	Match *MatchState `json:"match,omitempty"`
+
+	// Stats are the game's statistics, once it's ended.
+	Stats *Stats `json:"stats,omitempty"`
+
+	// With RuleVerifiableShuffle, ShuffleCommitment commits to the round's deck, and
+	// ShuffleSeed reveals it once the round is scored, after any lay-off, to check the hand
+	// you were dealt with VerifyShuffle.
+	RuleVerifiableShuffle bool   `json:"ruleVerifiableShuffle"`
+	ShuffleCommitment     string `json:"shuffleCommitment,omitempty"`
+	ShuffleSeed           string `json:"shuffleSeed,omitempty"`
+
+	YourTeamID   int         `json:"yourTeamID"`
+	TeamScores   map[int]int `json:"teamScores"`
+	WinnerTeamID int         `json:"winnerTeamID"`
+	LoserTeamID  int         `json:"loserTeamID"`
+	RuleTeams    bool        `json:"ruleTeams"`
+
+	// Events are the changes to the table since the previous state, for animating them.
+	// Clients that miss a state (e.g. slow ones) only get the latest events.
+	Events []Event `json:"events"`
+
+	// SchemaVersion is the version of this state's format, for clients to detect changes.
+	SchemaVersion int `json:"schemaVersion"`
+
+	// StateHash is the Fingerprint of this state, for clients to detect desyncs.
+	StateHash string `json:"stateHash"`
+}
+
+// ClientPlayer is the public information about a player.
+type ClientPlayer struct {
+	PlayerID int `json:"playerID"`
+	Team     int `json:"team"`
+	Score    int `json:"score"`
+	HandSize int `json:"handSize"`
+
+	// Name and Avatar are the player's display name and avatar emoji, if they have any.
+	Name   string `json:"name,omitempty"`
+	Avatar string `json:"avatar,omitempty"`
+
+	// Hand, Groups (as scored) and PenaltyPoints are revealed once the round is finished
+	// (and scored), and empty until then.
+	Hand          []Card   `json:"hand,omitempty"`
+	Groups        [][]Card `json:"groups,omitempty"`
+	PenaltyPoints int      `json:"penaltyPoints,omitempty"`
 }
 
 type Bot interface {
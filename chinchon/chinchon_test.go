@@ -1,7 +1,14 @@
 package chinchon
 
 import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewGameState(t *testing.T) {
@@ -163,3 +170,1629 @@ func TestBasicGameFlow(t *testing.T) {
 		t.Error("Turn should have switched after discard")
 	}
 }
+
+func TestWithSeedDealsSameCards(t *testing.T) {
+	gs1 := New(WithSeed(42))
+	gs2 := New(WithSeed(42))
+
+	for playerID := range gs1.Players {
+		if !reflect.DeepEqual(gs1.Players[playerID].Hand, gs2.Players[playerID].Hand) {
+			t.Errorf("Player %d should be dealt the same hand with the same seed", playerID)
+		}
+	}
+	if !reflect.DeepEqual(gs1.DiscardPile, gs2.DiscardPile) {
+		t.Error("Discard pile should be the same with the same seed")
+	}
+}
+
+type countingSource struct {
+	rand.Source
+	calls int
+}
+
+func (s *countingSource) Int63() int64 {
+	s.calls++
+	return s.Source.Int63()
+}
+
+func TestWithRandSource(t *testing.T) {
+	source := &countingSource{Source: rand.NewSource(42)}
+	gs := New(WithRandSource(source), WithResignRound(25))
+	if source.calls == 0 {
+		t.Fatal("Expected the deal to be shuffled with the given source")
+	}
+	seeded := New(WithSeed(42), WithResignRound(25))
+	if !reflect.DeepEqual(gs.Players[0].Hand, seeded.Players[0].Hand) || !reflect.DeepEqual(gs.DrawPile.cards, seeded.DrawPile.cards) {
+		t.Error("Expected a source to deal the same cards as a seed it was created with")
+	}
+
+	calls := source.calls
+	for _, g := range []*GameState{gs, seeded} {
+		_ = g.RunAction(NewActionResignRound(g.TurnPlayerID))
+		_ = g.RunAction(NewActionConfirmRoundFinished(0))
+		_ = g.RunAction(NewActionConfirmRoundFinished(1))
+	}
+	if gs.RoundNumber != 2 || source.calls == calls {
+		t.Fatal("Expected the next round to be shuffled with the given source too")
+	}
+	if !reflect.DeepEqual(gs.Players[0].Hand, seeded.Players[0].Hand) {
+		t.Error("Expected the next round to be dealt the same cards as with the seed")
+	}
+}
+
+func TestPuzzleSolve(t *testing.T) {
+	puzzle := Puzzle{
+		Kind: PUZZLE_DISCARD,
+		Hand: []Card{
+			{Suit: COPA, Number: 1},
+			{Suit: COPA, Number: 2},
+			{Suit: COPA, Number: 3},
+			{Suit: ORO, Number: 10},
+			{Suit: ESPADA, Number: 10},
+			{Suit: BASTO, Number: 4},
+			{Suit: ESPADA, Number: 12},
+			{Suit: ORO, Number: 6},
+		},
+	}
+
+	// The 10s and the 12 are all worth 10 points, so any of them is an optimal discard.
+	if !puzzle.Check(NewActionDiscardCard(Card{Suit: ESPADA, Number: 12}, 0)) {
+		t.Error("Discarding 12 de espada should be an optimal play")
+	}
+	if puzzle.Check(NewActionDiscardCard(Card{Suit: COPA, Number: 2}, 0)) {
+		t.Error("Breaking up the run should not be an optimal play")
+	}
+
+	// Either card left over lets the player close, so closing with either is optimal.
+	puzzle.Hand = []Card{
+		{Suit: COPA, Number: 1}, {Suit: COPA, Number: 2}, {Suit: COPA, Number: 3},
+		{Suit: ORO, Number: 4}, {Suit: ORO, Number: 5}, {Suit: ORO, Number: 6},
+		{Suit: BASTO, Number: 1}, {Suit: ESPADA, Number: 2},
+	}
+	for _, card := range []Card{{Suit: BASTO, Number: 1}, {Suit: ESPADA, Number: 2}} {
+		if !puzzle.Check(NewActionClose(card, 0)) {
+			t.Errorf("Closing by discarding %v should be an optimal play", card)
+		}
+	}
+}
+
+func TestClientGameStateFingerprint(t *testing.T) {
+	gs := New(WithSeed(7))
+	cgs := gs.ToClientGameState(0)
+
+	if !cgs.IsInSync() {
+		t.Error("Client game state should be in sync with its own hash")
+	}
+
+	if New(WithSeed(7)).Fingerprint() != gs.Fingerprint() {
+		t.Error("Games with the same seed should have the same fingerprint")
+	}
+
+	_ = gs.RunAction(NewActionDrawFromDeck(0))
+	bs, _ := json.Marshal(gs.ToClientGameState(0))
+	var received ClientGameState
+	if err := json.Unmarshal(bs, &received); err != nil {
+		t.Fatal(err)
+	}
+	if !received.IsInSync() {
+		t.Error("Client game state should still be in sync after a JSON round trip")
+	}
+
+	cgs.YourHand = cgs.YourHand[1:]
+	if cgs.IsInSync() {
+		t.Error("Client game state with a missing card should be out of sync")
+	}
+}
+
+func TestTeamsGame(t *testing.T) {
+	gs := New(WithTeams())
+
+	if len(gs.Players) != 4 {
+		t.Fatalf("Expected 4 players, got %d", len(gs.Players))
+	}
+	if !gs.AreTeammates(0, 2) || gs.AreTeammates(0, 1) {
+		t.Error("Players sitting across should be teammates")
+	}
+
+	// Turns go around the table
+	for _, expectedNext := range []int{1, 2, 3, 0} {
+		_ = gs.RunAction(NewActionDrawFromDeck(gs.TurnPlayerID))
+		_ = gs.RunAction(NewActionDiscardCard(gs.Players[gs.TurnPlayerID].Hand.Cards[0], gs.TurnPlayerID))
+		if gs.TurnPlayerID != expectedNext {
+			t.Fatalf("Expected player %d's turn, got %d", expectedNext, gs.TurnPlayerID)
+		}
+	}
+
+	// Scores are pooled: neither player reached the max, but their team did
+	gs.Players[0].Score = 60
+	gs.Players[2].Score = 50
+	gs.checkMaxPoints()
+	if !gs.IsGameEnded || gs.LoserTeamID != 0 || gs.WinnerTeamID != 1 {
+		t.Errorf("Team 0 should have lost, got winner team %d and loser team %d", gs.WinnerTeamID, gs.LoserTeamID)
+	}
+}
+
+func TestNewFromScenario(t *testing.T) {
+	scenario, err := LoadScenario(strings.NewReader(`{
+		"hands": {
+			"0": [{"suit": "oro", "number": 1}, {"suit": "oro", "number": 2}, {"suit": "oro", "number": 3},
+				{"suit": "oro", "number": 4}, {"suit": "oro", "number": 5}, {"suit": "oro", "number": 6},
+				{"suit": "copa", "number": 12}],
+			"1": [{"suit": "basto", "number": 1}, {"suit": "basto", "number": 2}, {"suit": "basto", "number": 3},
+				{"suit": "basto", "number": 4}, {"suit": "basto", "number": 5}, {"suit": "basto", "number": 6},
+				{"suit": "basto", "number": 12}]
+		},
+		"discardPile": [{"suit": "espada", "number": 9}],
+		"drawPile": [{"suit": "oro", "number": 7}],
+		"scores": {"1": 42},
+		"turnPlayerID": 1
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gs, err := NewFromScenario(scenario)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gs.TurnPlayerID != 1 || gs.Players[1].Score != 42 {
+		t.Errorf("Expected player 1's turn with 42 points, got player %d's with %d", gs.TurnPlayerID, gs.Players[1].Score)
+	}
+	if gs.DrawPile.remainingCards() != 1 {
+		t.Errorf("Expected 1 card in the draw pile, got %d", gs.DrawPile.remainingCards())
+	}
+
+	scenario.DiscardPile = []Card{{Suit: ORO, Number: 1}}
+	if _, err := NewFromScenario(scenario); err == nil {
+		t.Error("A scenario with duplicate cards should be rejected")
+	}
+}
+
+func TestTutorial(t *testing.T) {
+	f, err := os.Open("../tutorials/basics.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	tutorial, err := LoadTutorial(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := NewTutorialSession(tutorial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := session.Play(NewActionDrawFromDeck(0)); ok {
+		t.Error("Expected the wrong action to be rejected")
+	}
+	for !session.IsFinished() {
+		expected, _ := DeserializeAction(session.CurrentStep().ExpectedAction)
+		if ok, err := session.Play(expected); !ok || err != nil {
+			t.Fatalf("Expected step %d to be played, got %v", session.Step+1, err)
+		}
+	}
+}
+
+func TestEventsHideCardsDrawnFromDeck(t *testing.T) {
+	gs := New(WithSeed(1))
+	if len(gs.ToClientGameState(0).Events) != 1+2*7+1 {
+		t.Errorf("Expected the deal's events, got %v", gs.ToClientGameState(0).Events)
+	}
+
+	if err := gs.RunAction(NewActionDrawFromDeck(gs.TurnPlayerID)); err != nil {
+		t.Fatal(err)
+	}
+	drawer, other := gs.TurnPlayerID, gs.OpponentOf(gs.TurnPlayerID)
+	if events := gs.ToClientGameState(drawer).Events; len(events) != 1 || events[0].Card == nil {
+		t.Errorf("Expected the drawer to see their card, got %v", events)
+	}
+	if events := gs.ToClientGameState(other).Events; len(events) != 1 || events[0].Card != nil {
+		t.Errorf("Expected the card to be hidden from the opponent, got %v", events)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	discard := NewActionDiscardCard(Card{Suit: ORO, Number: 4}, 1)
+	es := NewCatalog(LOCALE_ES)
+
+	if got := es.ActionLog(discard, es.PlayerName(1, 0, 2)); got != "Oponente descartó 4 de oro" {
+		t.Errorf("Unexpected Spanish log: %v", got)
+	}
+	if got := NewCatalog("xx").ActionChoice(discard); got != "Discard 4 of coins" {
+		t.Errorf("Expected unknown locales to fall back to English, got %v", got)
+	}
+	if got := discard.String(); got != "Player 1 discards 4 of coins" {
+		t.Errorf("Unexpected String: %v", got)
+	}
+}
+
+func TestDescribeState(t *testing.T) {
+	gs, err := NewFromScenario(Scenario{
+		Hands: map[int][]Card{
+			0: {{ORO, 4}, {ORO, 1}, {ORO, 3}, {COPA, 7}, {ESPADA, 7}, {BASTO, 7}, {COPA, 12}},
+			1: {{BASTO, 1}, {BASTO, 2}, {BASTO, 3}, {BASTO, 4}, {BASTO, 5}, {BASTO, 6}, {BASTO, 12}},
+		},
+		DiscardPile: []Card{{COPA, 11}},
+		Scores:      map[int]int{1: 30},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	description := NewCatalog(LOCALE_EN).DescribeState(gs.ToClientGameState(0))
+	expected := "Round 1. Scores: You: 0 points, Opponent: 30 points. You hold 1, 3, 4 of coins; 7, 12 of cups; " +
+		"7 of swords; 7 of clubs. Top of the discard pile: 11 of cups. 33 cards left in the deck. It's your turn. " +
+		"You can: Draw from deck; Draw from discard pile."
+	if description != expected {
+		t.Errorf("Expected\n%v\ngot\n%v", expected, description)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	gs := New(WithSeed(1))
+	_ = gs.RunAction(NewActionDrawFromDeck(gs.TurnPlayerID))
+
+	bs, err := gs.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Fingerprint() != gs.Fingerprint() {
+		t.Error("Expected the loaded game to be the same as the saved one")
+	}
+
+	// Confirmations and round logs survive too, e.g. between rounds
+	gs.CloseRound(-1)
+	_ = gs.RunAction(NewActionConfirmRoundFinished(0))
+	bs, _ = gs.Save()
+	loaded, err = Load(bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.RoundFinishedConfirmedPlayerIDs[0] || !reflect.DeepEqual(loaded.RoundsLog, gs.RoundsLog) {
+		t.Error("Expected the confirmations and round logs to survive saving and loading")
+	}
+}
+
+func TestSaveAndLoadSeed(t *testing.T) {
+	gs := New(WithSeed(7), WithResignRound(25))
+	bs, _ := gs.Save()
+	loaded, err := Load(bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, g := range []*GameState{gs, loaded} {
+		_ = g.RunAction(NewActionResignRound(g.TurnPlayerID))
+		_ = g.RunAction(NewActionConfirmRoundFinished(0))
+		_ = g.RunAction(NewActionConfirmRoundFinished(1))
+	}
+	if loaded.RoundNumber != 2 || !reflect.DeepEqual(loaded.Players[0].Hand, gs.Players[0].Hand) || !reflect.DeepEqual(loaded.DrawPile.cards, gs.DrawPile.cards) {
+		t.Error("Expected a seeded game to deal the same cards after being saved and loaded")
+	}
+
+	// Other random sources aren't saved
+	gs = New(WithRandSource(rand.NewSource(7)))
+	bs, _ = gs.Save()
+	if loaded, _ = Load(bs); loaded.DrawPile.rng != nil {
+		t.Error("Expected a game with a random source not to restore it")
+	}
+}
+
+func TestHandHistory(t *testing.T) {
+	gs := New(WithSeed(1))
+	_ = gs.RunAction(NewActionDrawFromDeck(gs.TurnPlayerID))
+	gs.CloseRound(-1)
+
+	history := NewCatalog(LOCALE_EN).HandHistory(*gs)
+	for _, expected := range []string{"## Round 1", "1. Player 1 draws from deck", "| Player | Penalty | Points | Total |"} {
+		if !strings.Contains(history, expected) {
+			t.Errorf("Expected the history to contain %q, got\n%v", expected, history)
+		}
+	}
+}
+
+func TestCardAssetIDs(t *testing.T) {
+	for _, deckType := range []string{DECK_SPANISH_40, DECK_SPANISH_48, DECK_FRENCH_52} {
+		for _, card := range makeCards(deckType, nil) {
+			parsed, err := CardFromAssetID(card.AssetID())
+			if err != nil || parsed != card {
+				t.Errorf("Expected %v of the %v deck to round trip through %v, got %v, %v", card, deckType, card.AssetID(), parsed, err)
+			}
+		}
+	}
+	if id := (Card{Suit: ORO, Number: 7}).AssetID(); id != "oro_07" {
+		t.Errorf("Expected oro_07, got %v", id)
+	}
+	for _, assetID := range []string{"oro_14", "oro_00", "oro_7", "rey_01", "oro"} {
+		if _, err := CardFromAssetID(assetID); err == nil {
+			t.Errorf("Expected %v to be invalid", assetID)
+		}
+	}
+}
+
+func TestSixPlayersRefillDrawPile(t *testing.T) {
+	gs := New(WithPlayers(6), WithSeed(1))
+	if len(gs.Players) != 6 || gs.DrawPile.remainingCards() != 48-6*7-1 {
+		t.Fatalf("Expected 6 players and 5 cards left, got %d and %d", len(gs.Players), gs.DrawPile.remainingCards())
+	}
+
+	for turn := 0; turn < 12; turn++ {
+		playerID := gs.TurnPlayerID
+		if err := gs.RunAction(NewActionDrawFromDeck(playerID)); err != nil {
+			t.Fatalf("Turn %d: %v", turn, err)
+		}
+		hand := gs.Players[playerID].Hand.Cards
+		if err := gs.RunAction(NewActionDiscardCard(hand[len(hand)-1], playerID)); err != nil {
+			t.Fatalf("Turn %d: %v", turn, err)
+		}
+		if gs.TurnPlayerID != (playerID+1)%6 {
+			t.Fatalf("Expected player %d's turn, got %d's", (playerID+1)%6, gs.TurnPlayerID)
+		}
+	}
+	if cards := gs.DrawPile.remainingCards() + len(gs.DiscardPile); cards != 6 {
+		t.Errorf("Expected the 6 cards outside hands to be in the piles, got %d", cards)
+	}
+}
+
+func TestBestPartitionDoesNotReuseCards(t *testing.T) {
+	// The 7 of oro can be in the run or in the set of 7s, but not in both
+	hand := Hand{Cards: []Card{{ORO, 5}, {ORO, 6}, {ORO, 7}, {COPA, 7}, {ESPADA, 7}, {BASTO, 1}, {BASTO, 2}}}
+
+	if _, deadwood := hand.BestPartition(); deadwood != 5+6+1+2 {
+		t.Errorf("Expected 14 penalty points, got %d", deadwood)
+	}
+	if ungrouped := hand.MinUngroupedCards(); ungrouped != 4 {
+		t.Errorf("Expected 4 ungrouped cards, got %d", ungrouped)
+	}
+
+	gs, err := NewFromScenario(Scenario{
+		Hands: map[int][]Card{
+			0: append(append([]Card{}, hand.Cards...), Card{COPA, 12}),
+			1: {{COPA, 1}, {COPA, 2}, {COPA, 3}, {ESPADA, 1}, {ESPADA, 2}, {ESPADA, 3}, {BASTO, 12}},
+		},
+		HasDrawnCard: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gs.CanClose(0) {
+		t.Error("Expected player 0 not to be able to close")
+	}
+	if gs.CanClose(1) {
+		t.Error("Expected player 1 not to be able to close before drawing")
+	}
+}
+
+func TestCloseThreshold(t *testing.T) {
+	scenario := Scenario{
+		Hands: map[int][]Card{
+			0: {{ORO, 1}, {ORO, 2}, {ORO, 3}, {COPA, 4}, {COPA, 5}, {COPA, 6}, {ESPADA, 7}, {BASTO, 12}},
+			1: {{COPA, 1}, {COPA, 2}, {COPA, 3}, {ESPADA, 1}, {ESPADA, 2}, {ESPADA, 3}, {BASTO, 11}},
+		},
+		HasDrawnCard: true,
+	}
+
+	gs, _ := NewFromScenario(scenario)
+	if NewActionClose(Card{BASTO, 12}, 0).IsPossible(*gs) {
+		t.Error("Expected closing with an ungrouped 7 not to be possible by default")
+	}
+	gs, _ = NewFromScenario(scenario, WithCloseThreshold(7))
+	if NewActionClose(Card{ESPADA, 7}, 0).IsPossible(*gs) {
+		t.Error("Expected closing with an ungrouped 12 not to be possible with a threshold of 7")
+	}
+	if err := gs.RunAction(NewActionClose(Card{BASTO, 12}, 0)); err != nil {
+		t.Fatalf("Expected closing with an ungrouped 7 to be possible with a threshold of 7: %v", err)
+	}
+	if len(gs.Players[0].Hand.Cards) != 7 || gs.RoundsLog[1].PenaltyPoints[0] != 7 {
+		t.Errorf("Expected the closing card to be discarded before scoring, got %d cards and %d penalty points",
+			len(gs.Players[0].Hand.Cards), gs.RoundsLog[1].PenaltyPoints[0])
+	}
+}
+
+func TestCloseBonusMode(t *testing.T) {
+	scenario := Scenario{
+		Hands: map[int][]Card{
+			0: {{ORO, 1}, {ORO, 2}, {ORO, 3}, {ORO, 4}, {COPA, 5}, {COPA, 6}, {COPA, 7}, {COPA, 8}},
+			1: {{COPA, 1}, {COPA, 2}, {COPA, 3}, {ESPADA, 1}, {ESPADA, 2}, {ESPADA, 3}, {BASTO, 4}},
+		},
+		Scores:       map[int]int{0: 20},
+		HasDrawnCard: true,
+	}
+
+	gs, _ := NewFromScenario(scenario)
+	gs.CloseRound(0)
+	if gs.Players[0].Score != 20 || gs.Players[1].Score != 4+10 {
+		t.Errorf("Expected the opponent to get 10 extra points, got scores %d and %d", gs.Players[0].Score, gs.Players[1].Score)
+	}
+
+	gs, _ = NewFromScenario(scenario, WithCloseBonusMode(CLOSE_BONUS_CLOSER_MINUS_10))
+	gs.CloseRound(0)
+	if gs.Players[0].Score != 20-10 || gs.Players[1].Score != 4 {
+		t.Errorf("Expected the closer to get 10 points off, got scores %d and %d", gs.Players[0].Score, gs.Players[1].Score)
+	}
+}
+
+func TestReenter(t *testing.T) {
+	newBustedGame := func() *GameState {
+		gs, err := NewFromScenario(Scenario{
+			Hands: map[int][]Card{
+				0: {{ORO, 12}, {COPA, 11}, {ESPADA, 10}, {BASTO, 12}, {ORO, 9}, {COPA, 8}, {ESPADA, 6}},
+				1: {{COPA, 1}, {COPA, 2}, {COPA, 3}, {ESPADA, 1}, {ESPADA, 2}, {ESPADA, 3}, {BASTO, 4}},
+			},
+			Scores: map[int]int{0: 90, 1: 40},
+		}, WithReenter())
+		if err != nil {
+			t.Fatal(err)
+		}
+		gs.CloseRound(-1)
+		for playerID := range gs.Players {
+			if err := gs.RunAction(NewActionConfirmRoundFinished(playerID)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return gs
+	}
+
+	gs := newBustedGame()
+	if gs.IsGameEnded || gs.ReenterOfferedPlayerID != 0 || gs.RoundNumber != 1 {
+		t.Fatalf("Expected player 0 to be offered to re-enter before the next round, got %v", gs.ReenterOfferedPlayerID)
+	}
+	if err := gs.RunAction(NewActionAcceptReenter(0)); err != nil {
+		t.Fatal(err)
+	}
+	if gs.Players[0].Score != 44 || gs.RoundNumber != 2 {
+		t.Errorf("Expected player 0 to re-enter with 44 points in round 2, got %d in round %d", gs.Players[0].Score, gs.RoundNumber)
+	}
+
+	gs = newBustedGame()
+	if err := gs.RunAction(NewActionDeclineReenter(0)); err != nil {
+		t.Fatal(err)
+	}
+	if !gs.IsGameEnded || gs.LoserPlayerID != 0 {
+		t.Errorf("Expected player 0 to lose after declining, got ended=%v loser=%d", gs.IsGameEnded, gs.LoserPlayerID)
+	}
+
+	// With the others tied, the first of them wins, every time.
+	for i := 0; i < 20; i++ {
+		gs = New(WithPlayers(3), WithReenter())
+		gs.Players[0].Score, gs.Players[1].Score, gs.Players[2].Score = 110, 20, 20
+		gs.ReenterOfferedPlayerID = 0
+		if err := gs.RunAction(NewActionDeclineReenter(0)); err != nil {
+			t.Fatal(err)
+		}
+		if gs.WinnerPlayerID != 1 {
+			t.Fatalf("Expected player 1 to win the tie after player 0 declined, got %d", gs.WinnerPlayerID)
+		}
+	}
+}
+
+func TestReenterTwoBusts(t *testing.T) {
+	gs, err := NewFromScenario(Scenario{
+		Hands: map[int][]Card{
+			0: {{ORO, 12}, {COPA, 11}, {ESPADA, 10}, {BASTO, 12}, {ORO, 9}, {COPA, 8}, {ESPADA, 6}},
+			1: {{ORO, 11}, {COPA, 12}, {ESPADA, 12}, {BASTO, 11}, {ORO, 8}, {COPA, 9}, {BASTO, 6}},
+			2: {{COPA, 1}, {COPA, 2}, {COPA, 3}, {ESPADA, 1}, {ESPADA, 2}, {ESPADA, 3}, {BASTO, 4}},
+		},
+		Scores: map[int]int{0: 90, 1: 85, 2: 40},
+	}, WithPlayers(3), WithReenter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs.CloseRound(-1)
+	for playerID := range gs.Players {
+		if err := gs.RunAction(NewActionConfirmRoundFinished(playerID)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if gs.ReenterOfferedPlayerID != 0 {
+		t.Fatalf("Expected player 0 to be offered to re-enter first, got %v", gs.ReenterOfferedPlayerID)
+	}
+	if err := gs.RunAction(NewActionAcceptReenter(0)); err != nil {
+		t.Fatal(err)
+	}
+	if gs.IsGameEnded || gs.ReenterOfferedPlayerID != 1 || gs.RoundNumber != 1 {
+		t.Fatalf("Expected player 1 to be offered to re-enter before the next round, got %v in round %d", gs.ReenterOfferedPlayerID, gs.RoundNumber)
+	}
+	if err := gs.RunAction(NewActionAcceptReenter(1)); err != nil {
+		t.Fatal(err)
+	}
+	if gs.RoundNumber != 2 || gs.Players[1].Score >= gs.RuleMaxPoints {
+		t.Errorf("Expected both players to re-enter in round 2, got player 1 with %d in round %d", gs.Players[1].Score, gs.RoundNumber)
+	}
+}
+
+func TestMaxPointsTies(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		gs := New(WithPlayers(4))
+		gs.Players[0].Score, gs.Players[1].Score, gs.Players[2].Score, gs.Players[3].Score = 110, 110, 20, 20
+		gs.checkMaxPoints()
+		if !gs.IsGameEnded || gs.LoserPlayerID != 0 || gs.WinnerPlayerID != 2 {
+			t.Fatalf("Expected the first tied players to lose and win, got loser %d and winner %d", gs.LoserPlayerID, gs.WinnerPlayerID)
+		}
+
+		gs = New()
+		gs.Players[0].Score, gs.Players[1].Score = 100, 100
+		gs.checkMaxPoints()
+		if !gs.IsGameEnded || gs.LoserPlayerID != 0 || gs.WinnerPlayerID != 1 {
+			t.Fatalf("Expected player 0 to lose and player 1 to win when all are tied, got loser %d and winner %d", gs.LoserPlayerID, gs.WinnerPlayerID)
+		}
+	}
+}
+
+func TestLayOff(t *testing.T) {
+	gs, err := NewFromScenario(Scenario{
+		Hands: map[int][]Card{
+			0: {{COPA, 4}, {ORO, 12}, {COPA, 11}, {ESPADA, 10}, {BASTO, 12}, {ORO, 9}, {ESPADA, 6}},
+			1: {{COPA, 1}, {COPA, 2}, {COPA, 3}, {ESPADA, 1}, {ESPADA, 2}, {ESPADA, 3}, {BASTO, 4}},
+		},
+	}, WithLayOff())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs.CloseRound(1)
+	if !gs.IsLayingOff || len(gs.RoundsLog[1].PenaltyPoints) != 0 {
+		t.Fatalf("Expected scoring to wait for the lay-off phase")
+	}
+	if err := gs.RunAction(NewActionLayOffCard(Card{ORO, 12}, 0, 0)); err == nil {
+		t.Errorf("Expected laying off a card that doesn't fit to fail")
+	}
+	if err := gs.RunAction(NewActionLayOffCard(Card{COPA, 1}, 0, 1)); err == nil {
+		t.Errorf("Expected the closing player not to be able to lay off")
+	}
+
+	groupIndex := 0
+	if gs.LayOffGroups[0][0].Suit != COPA {
+		groupIndex = 1
+	}
+	if err := gs.RunAction(NewActionLayOffCard(Card{COPA, 4}, groupIndex, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gs.RunAction(NewActionFinishLayOff(0)); err != nil {
+		t.Fatal(err)
+	}
+	if gs.IsLayingOff || gs.RoundsLog[1].PenaltyPoints[0] != 55 {
+		t.Errorf("Expected player 0 to get 55 penalty points after laying off, got %d", gs.RoundsLog[1].PenaltyPoints[0])
+	}
+}
+
+type recordingObserver struct {
+	calls []string
+}
+
+func (o *recordingObserver) OnAction(g *GameState, action Action) {
+	o.calls = append(o.calls, action.GetName())
+}
+func (o *recordingObserver) OnRoundStart(g *GameState) {
+	o.calls = append(o.calls, "round start")
+}
+func (o *recordingObserver) OnRoundEnd(g *GameState, round *RoundLog) {
+	o.calls = append(o.calls, "round end")
+}
+func (o *recordingObserver) OnGameEnd(g *GameState) { o.calls = append(o.calls, "game end") }
+
+func TestObserver(t *testing.T) {
+	observer := &recordingObserver{}
+	gs := New(WithObserver(observer), WithSeed(1))
+	_ = gs.RunAction(NewActionDrawFromDeck(gs.TurnPlayerID))
+	gs.CloseRound(-1)
+	for playerID := range gs.Players {
+		_ = gs.RunAction(NewActionConfirmRoundFinished(playerID))
+	}
+
+	expected := []string{"round start", DRAW_FROM_DECK, "round end", CONFIRM_ROUND_FINISHED, "round start", CONFIRM_ROUND_FINISHED}
+	if !reflect.DeepEqual(observer.calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, observer.calls)
+	}
+}
+
+func TestAceWraparound(t *testing.T) {
+	hand := []Card{{ORO, 9}, {ORO, 10}, {ORO, 11}, {ORO, 12}, {ORO, 1}, {ORO, 2}, {ORO, 3}}
+	if defaultHandRules.isChinchon(hand) {
+		t.Error("Expected 9 to 3 not to be a Chinchón by default")
+	}
+
+	gs := New(WithAceWraparound())
+	if !gs.handRules().isChinchon(hand) {
+		t.Error("Expected 9 to 3 to be a Chinchón with ace wraparound")
+	}
+	if _, penalty := gs.handRules().bestPartition([]Card{{COPA, 12}, {COPA, 1}, {COPA, 2}, {ESPADA, 5}}); penalty != 5 {
+		t.Errorf("Expected 12-1-2 to be grouped with ace wraparound, got %d penalty points", penalty)
+	}
+}
+
+func TestDeckTypes(t *testing.T) {
+	gs := New(WithDeckType(DECK_SPANISH_40))
+	if cards := gs.DrawPile.remainingCards() + len(gs.DiscardPile) + 14; cards != 40 {
+		t.Errorf("Expected a 40-card deck, got %d cards", cards)
+	}
+	if _, penalty := gs.handRules().bestPartition([]Card{{ORO, 6}, {ORO, 7}, {ORO, 10}}); penalty != 0 {
+		t.Errorf("Expected 6-7-10 to be a run without 8s and 9s, got %d penalty points", penalty)
+	}
+
+	gs = New(WithDeckType(DECK_FRENCH_52))
+	if _, penalty := gs.handRules().bestPartition([]Card{{ORO, 11}, {ORO, 12}, {ORO, 13}}); penalty != 0 {
+		t.Errorf("Expected J-Q-K to be a run in a French deck, got %d penalty points", penalty)
+	}
+	if (Card{ORO, 13}).PenaltyValue() != 10 {
+		t.Error("Expected the king to be worth 10 points")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected 6 players not to fit a 40-card deck")
+		}
+	}()
+	New(WithPlayers(6), WithDeckType(DECK_SPANISH_40))
+}
+
+func TestMatch(t *testing.T) {
+	m := NewMatch(3, WithSeed(1))
+	if err := m.NextGame(); err == nil {
+		t.Error("Expected the next game not to start before the current one ends")
+	}
+
+	m.GameState.endGame(1, 0)
+	m.recordGame()
+	if err := m.NextGame(); err != nil {
+		t.Fatal(err)
+	}
+	if m.GameState.TurnPlayerID != 1 || m.GameState.ToClientGameState(0).Match.GamesWon[1] != 1 {
+		t.Errorf("Expected player 1 to open game 2, with a game won, got %+v", m.MatchState)
+	}
+
+	m.GameState.endGame(1, 0)
+	m.recordGame()
+	if !m.IsMatchEnded || m.WinnerTeamID != 1 {
+		t.Errorf("Expected player 1 to win the match 2-0, got %+v", m.MatchState)
+	}
+}
+
+func TestResign(t *testing.T) {
+	gs := New(WithResignRound(25))
+	notInTurn := gs.nextPlayer(gs.TurnPlayerID)
+	if err := gs.RunAction(NewActionResignRound(notInTurn)); err != nil {
+		t.Fatal(err)
+	}
+	if !gs.IsRoundFinished || gs.Players[notInTurn].Score != 25 || gs.RoundsLog[1].LoserPlayerID != notInTurn {
+		t.Errorf("Expected player %d to lose the round with 25 points, got %d", notInTurn, gs.Players[notInTurn].Score)
+	}
+
+	gs = New()
+	if NewActionResignRound(0).IsPossible(*gs) {
+		t.Error("Expected resigning the round not to be possible by default")
+	}
+	if err := gs.RunAction(NewActionResign(1)); err != nil {
+		t.Fatal(err)
+	}
+	if !gs.IsGameEnded || gs.WinnerPlayerID != 0 || !gs.RoundsLog[1].WasResigned {
+		t.Errorf("Expected player 0 to win after player 1 resigned, got winner %d", gs.WinnerPlayerID)
+	}
+
+	// Opponents tied at 0-0: the first of them wins, every time, and replays agree.
+	for i := 0; i < 20; i++ {
+		gs = New(WithPlayers(5))
+		if err := gs.RunAction(NewActionResign(0)); err != nil {
+			t.Fatal(err)
+		}
+		if gs.WinnerPlayerID != 1 || gs.WinnerTeamID != 1 {
+			t.Fatalf("Expected player 1 to win the tie after player 0 resigned, got %d", gs.WinnerPlayerID)
+		}
+		replayed, err := gs.ReplayFile().Replay()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if replayed.WinnerPlayerID != gs.WinnerPlayerID || replayed.WinnerTeamID != gs.WinnerTeamID {
+			t.Fatalf("Expected the replay to have winner %d, got %d", gs.WinnerPlayerID, replayed.WinnerPlayerID)
+		}
+		notation, err := ExportNotation(gs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := ParseNotation(strings.NewReader(notation))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.WinnerPlayerID != gs.WinnerPlayerID || parsed.WinnerTeamID != gs.WinnerTeamID {
+			t.Fatalf("Expected the parsed notation to have winner %d, got %d", gs.WinnerPlayerID, parsed.WinnerPlayerID)
+		}
+	}
+}
+
+func TestDeclaredMelds(t *testing.T) {
+	gs, err := NewFromScenario(Scenario{
+		Hands: map[int][]Card{
+			0: {{ORO, 1}, {ORO, 2}, {ORO, 3}, {ORO, 4}, {COPA, 5}, {COPA, 6}, {COPA, 7}, {BASTO, 12}},
+			1: {{COPA, 1}, {COPA, 2}, {COPA, 3}, {ESPADA, 1}, {ESPADA, 2}, {ESPADA, 3}, {BASTO, 11}},
+		},
+		HasDrawnCard: true,
+	}, WithDeclaredMelds())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := &ActionClose{act: act{Name: CLOSE_ROUND, PlayerID: 0}, Card: Card{BASTO, 12},
+		Groups: [][]Card{{{ORO, 1}, {ORO, 2}, {ORO, 3}}}}
+	if invalid.IsPossible(*gs) {
+		t.Error("Expected a declaration leaving 4 cards ungrouped not to be possible")
+	}
+
+	underDeclared := &ActionClose{act: act{Name: CLOSE_ROUND, PlayerID: 0}, Card: Card{BASTO, 12},
+		Groups: [][]Card{{{ORO, 1}, {ORO, 2}, {ORO, 3}}, {{COPA, 5}, {COPA, 6}, {COPA, 7}}}}
+	if err := gs.RunAction(underDeclared); err != nil {
+		t.Fatal(err)
+	}
+	if penalty := gs.RoundsLog[1].PenaltyPoints[0]; penalty != 4 {
+		t.Errorf("Expected the undeclared 4 of coins to count, got %d penalty points", penalty)
+	}
+
+	// An opponent's chinchón wins the round, but the declaration still scores the closer.
+	gs, err = NewFromScenario(Scenario{
+		Hands: map[int][]Card{
+			0: {{ORO, 1}, {ORO, 2}, {ORO, 3}, {ORO, 4}, {COPA, 5}, {COPA, 6}, {COPA, 7}, {BASTO, 12}},
+			1: {{ESPADA, 1}, {ESPADA, 2}, {ESPADA, 3}, {ESPADA, 4}, {ESPADA, 5}, {ESPADA, 6}, {ESPADA, 7}},
+		},
+		HasDrawnCard: true,
+	}, WithDeclaredMelds(), WithChinchonMode(CHINCHON_WINS_ROUND))
+	if err != nil {
+		t.Fatal(err)
+	}
+	underDeclared.Groups = [][]Card{{{ORO, 1}, {ORO, 2}, {ORO, 3}}, {{COPA, 5}, {COPA, 6}, {COPA, 7}}}
+	if err := gs.RunAction(underDeclared); err != nil {
+		t.Fatal(err)
+	}
+	round := gs.RoundsLog[1]
+	if gs.IsGameEnded || round.ClosedByPlayerID != 0 || round.WinnerPlayerID != 1 {
+		t.Errorf("Expected player 0 to close and player 1 to win the round, got closer %d and winner %d", round.ClosedByPlayerID, round.WinnerPlayerID)
+	}
+	if round.PenaltyPoints[0] != 4 || gs.Players[0].Score != 4+10 || gs.Players[1].Score != 0 {
+		t.Errorf("Expected player 0 to score their declaration's 4 points plus 10, got scores %d and %d", gs.Players[0].Score, gs.Players[1].Score)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	gs := New(WithPlayers(3), WithResignRound(30))
+	for i := 0; i < 300 && !gs.IsGameEnded; i++ {
+		// Close the round as soon as possible, and otherwise vary the actions played, resigning
+		// long rounds
+		actions := gs.CalculatePossibleActions()
+		action := actions[i%len(actions)]
+		if i%70 == 69 && !gs.IsRoundFinished {
+			action = NewActionResignRound(gs.TurnPlayerID)
+		}
+		for _, a := range actions {
+			if a.GetName() == CLOSE_ROUND {
+				action = a
+			}
+		}
+		if err := gs.RunAction(action); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for gs.IsRoundFinished && !gs.IsGameEnded {
+		gs.confirmRoundFinished()
+	}
+
+	replayed, err := Replay(gs.RoundsLog, WithPlayers(3), WithResignRound(30))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.Fingerprint() != gs.Fingerprint() {
+		t.Errorf("Expected the replayed game to be the same as the played one")
+	}
+}
+
+func TestReplayFile(t *testing.T) {
+	gs := New(WithTeams(), WithDeckType(DECK_SPANISH_40), WithMaxPoints(50), WithFirstPlayer(1))
+	for i := 0; i < 500 && !gs.IsGameEnded; i++ {
+		actions := gs.CalculatePossibleActions()
+		if err := gs.RunAction(actions[i%len(actions)]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bs, err := json.Marshal(gs.ReplayFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file ReplayFile
+	if err := json.Unmarshal(bs, &file); err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := file.Replay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.Fingerprint() != gs.Fingerprint() {
+		t.Errorf("Expected the game replayed from its file to be the same as the played one")
+	}
+}
+
+func TestStackDeals(t *testing.T) {
+	gs := New(WithPlayers(6), WithVerifiableShuffle(), WithResignRound(25))
+	snapshot, err := gs.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Log every action with the deals it made, as a write-ahead log would.
+	type entry struct {
+		action Action
+		deals  []Deal
+	}
+	entries := []entry{}
+	for i := 0; i < 400 && !gs.IsGameEnded; i++ {
+		roundNumber, refills := gs.RoundNumber, len(gs.RoundsLog[gs.RoundNumber].Refills)
+		actions := gs.CalculatePossibleActions()
+		action := actions[i%len(actions)]
+		if i%90 == 89 && !gs.IsRoundFinished {
+			action = NewActionResignRound(gs.TurnPlayerID)
+		}
+		if err := gs.RunAction(action); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry{action, gs.DealsSince(roundNumber, refills)})
+	}
+
+	restored, err := Load(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		restored.StackDeals(e.deals...)
+		if err := restored.RunAction(e.action); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if gs.RoundNumber < 2 {
+		t.Fatal("Expected the game to deal more than one round")
+	}
+	if restored.Fingerprint() != gs.Fingerprint() || restored.RoundsLog[2].ShuffleSeed != gs.RoundsLog[2].ShuffleSeed {
+		t.Errorf("Expected the game restored with its logged deals to be the same as the played one")
+	}
+}
+
+func TestRulesValidate(t *testing.T) {
+	if err := New(WithPlayers(4), WithTeams(), WithDeckType(DECK_FRENCH_52)).Rules().Validate(); err != nil {
+		t.Errorf("Expected the rules of a game to be valid, got %v", err)
+	}
+
+	invalid := map[string]func(r *Rules){
+		"max points":    func(r *Rules) { r.MaxPoints = 0 },
+		"first player":  func(r *Rules) { r.FirstPlayerID = 2 },
+		"close bonus":   func(r *Rules) { r.CloseBonusMode = "double" },
+		"chinchón mode": func(r *Rules) { r.ChinchonMode = "wins_match" },
+		"deck type":     func(r *Rules) { r.DeckType = "tarot" },
+		"hand size":     func(r *Rules) { r.HandSize = 30 },
+		"player count":  func(r *Rules) { r.Players = 9 },
+		"turn timeout":  func(r *Rules) { r.TurnTimeout = time.Minute },
+	}
+	for name, invalidate := range invalid {
+		rules := New().Rules()
+		invalidate(&rules)
+		if err := rules.Validate(); err == nil {
+			t.Errorf("Expected rules with an invalid %v to be invalid", name)
+		}
+	}
+}
+
+func TestAbort(t *testing.T) {
+	gs := New(WithPlayers(3))
+	gs.Abort()
+
+	if !gs.IsGameEnded || !gs.IsGameAborted {
+		t.Fatal("Expected the game to be ended and aborted")
+	}
+	if gs.WinnerPlayerID != -1 || gs.WinnerTeamID != -1 {
+		t.Errorf("Expected no winner, got player %d and team %d", gs.WinnerPlayerID, gs.WinnerTeamID)
+	}
+	if err := gs.RunAction(NewActionDrawFromDeck(gs.TurnPlayerID)); !errors.Is(err, ErrGameIsEnded) {
+		t.Errorf("Expected actions to fail with ErrGameIsEnded, got %v", err)
+	}
+	if !gs.ToClientGameState(0).IsGameAborted {
+		t.Errorf("Expected players to be told the game was aborted")
+	}
+}
+
+func TestValidateAction(t *testing.T) {
+	gs := New()
+	tests := []struct {
+		action   Action
+		expected RejectionReason
+	}{
+		{NewActionDrawFromDeck(gs.TurnPlayerID), REASON_NONE},
+		{NewActionDrawFromDeck(gs.TurnOpponentPlayerID), REASON_NOT_YOUR_TURN},
+		{NewActionDiscardCard(gs.Players[gs.TurnPlayerID].Hand.Cards[0], gs.TurnPlayerID), REASON_MUST_DRAW_FIRST},
+		{NewActionDiscardCard(gs.DiscardPile[0], gs.TurnPlayerID), REASON_CARD_NOT_IN_HAND},
+		{NewActionConfirmRoundFinished(gs.TurnPlayerID), REASON_ROUND_NOT_FINISHED},
+	}
+	for _, test := range tests {
+		if reason := gs.ValidateAction(test.action); reason != test.expected {
+			t.Errorf("Expected %v to be rejected with %q, got %q", test.action, test.expected, reason)
+		}
+	}
+
+	err := gs.RunAction(NewActionDrawFromDeck(gs.TurnOpponentPlayerID))
+	if !errors.Is(err, ErrNotYourTurn) {
+		t.Errorf("Expected ErrNotYourTurn, got %v", err)
+	}
+}
+
+func TestUndo(t *testing.T) {
+	gs := New(WithUndo())
+	before := gs.Fingerprint()
+	if err := gs.RunAction(NewActionDrawFromDeck(gs.TurnPlayerID)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gs.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if gs.Fingerprint() != before {
+		t.Error("Expected undoing the draw to restore the game as it was")
+	}
+	if err := gs.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Expected nothing to undo at the start of the round, got %v", err)
+	}
+
+	gs = New()
+	_ = gs.RunAction(NewActionDrawFromDeck(gs.TurnPlayerID))
+	if err := gs.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Expected undo not to be possible by default, got %v", err)
+	}
+}
+
+func TestTurnTimeout(t *testing.T) {
+	gs := New(WithTurnTimeout(30*time.Second, TIMEOUT_AUTO_PLAY))
+	start := gs.TurnStartedAt
+	playerID := gs.TurnPlayerID
+
+	if actions, _ := gs.TickClock(start.Add(10 * time.Second)); len(actions) != 0 {
+		t.Errorf("Expected no timeout after 10 seconds, got %v", actions)
+	}
+	actions, err := gs.TickClock(start.Add(31 * time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 2 || gs.TurnPlayerID == playerID || !gs.RoundsLog[1].ActionsLog[1].PlayedByBot {
+		t.Errorf("Expected the bot to draw and discard for player %d, got %v", playerID, actions)
+	}
+
+	gs = New(WithTurnTimeout(30*time.Second, TIMEOUT_FORFEIT))
+	if _, err := gs.TimeoutCurrentPlayer(); err != nil {
+		t.Fatal(err)
+	}
+	if !gs.IsGameEnded || gs.LoserPlayerID != 0 {
+		t.Errorf("Expected player 0 to forfeit on timeout")
+	}
+}
+
+func TestRevealHandsAtRoundEnd(t *testing.T) {
+	gs := New()
+	if cgs := gs.ToClientGameState(0); len(cgs.TheirHand) != 0 || len(cgs.Players[1].Hand) != 0 {
+		t.Error("Expected hands to be hidden during the round")
+	}
+
+	gs.CloseRound(-1)
+	cgs := gs.ToClientGameState(0)
+	if !reflect.DeepEqual(cgs.TheirHand, gs.Players[1].Hand.Cards) {
+		t.Errorf("Expected their hand to be revealed once the round is finished, got %v", cgs.TheirHand)
+	}
+	if cgs.Players[1].PenaltyPoints != gs.RoundsLog[1].PenaltyPoints[1] {
+		t.Errorf("Expected %d penalty points for player 1, got %d", gs.RoundsLog[1].PenaltyPoints[1], cgs.Players[1].PenaltyPoints)
+	}
+}
+
+func TestHandEvaluator(t *testing.T) {
+	e := NewHandEvaluator()
+	hand := []Card{{COPA, 1}, {COPA, 2}, {COPA, 3}, {ORO, 5}, {ESPADA, 5}, {BASTO, 5}, {BASTO, 2}, {ORO, 12}}
+
+	if _, deadwood := e.BestPartition(hand[:7]); deadwood != 2 {
+		t.Errorf("Expected 2 penalty points, got %d", deadwood)
+	}
+	if card := e.SuggestDiscard(hand); card != (Card{ORO, 12}) {
+		t.Errorf("Expected to discard the 12 de oro, got %v", card)
+	}
+	if !e.CanCloseAfterDiscard(hand, Card{ORO, 12}) {
+		t.Error("Discarding the 12 de oro should allow closing")
+	}
+	if e.CanCloseAfterDiscard(hand, Card{COPA, 1}) {
+		t.Error("Breaking up the run should not allow closing")
+	}
+}
+
+func TestRoundLogPenaltyBreakdown(t *testing.T) {
+	gs, _ := NewFromScenario(Scenario{
+		Hands: map[int][]Card{
+			0: {{ORO, 1}, {ORO, 2}, {ORO, 3}, {COPA, 4}, {COPA, 5}, {COPA, 6}, {ESPADA, 7}, {BASTO, 1}},
+			1: {{COPA, 1}, {COPA, 2}, {COPA, 3}, {ESPADA, 1}, {ESPADA, 2}, {ESPADA, 3}, {BASTO, 11}},
+		},
+		HasDrawnCard: true,
+	})
+	if err := gs.RunAction(NewActionClose(Card{ESPADA, 7}, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	round := gs.RoundsLog[1]
+	if !reflect.DeepEqual(round.UngroupedCards[0], []Card{{BASTO, 1}}) || len(round.Groups[0]) != 2 {
+		t.Errorf("Expected player 0 to score 2 groups and the 1 de basto, got %v and %v", round.Groups[0], round.UngroupedCards[0])
+	}
+	if !reflect.DeepEqual(round.UngroupedCards[1], []Card{{BASTO, 11}}) || len(round.Groups[1]) != 2 {
+		t.Errorf("Expected player 1 to score 2 groups and the 11 de basto, got %v and %v", round.Groups[1], round.UngroupedCards[1])
+	}
+}
+
+func TestWithDeckAndDealHands(t *testing.T) {
+	deck := makeCards(DECK_SPANISH_48, nil)
+	gs := New(WithDeck(deck))
+	if !reflect.DeepEqual(gs.Players[0].Hand.Cards, deck[:7]) || !reflect.DeepEqual(gs.Players[1].Hand.Cards, deck[7:14]) {
+		t.Error("Expected hands to be dealt from the top of the given deck")
+	}
+	if gs.DiscardPile[0] != deck[14] || gs.DrawPile.remainingCards() != len(deck)-15 {
+		t.Error("Expected the discard pile to start with the next card, and the rest to be the draw pile")
+	}
+
+	hands := map[int][]Card{0: deck[:7], 1: deck[7:14]}
+	gs = New(WithDealHands(hands), WithSeed(3))
+	if !reflect.DeepEqual(gs.Players[0].Hand.Cards, hands[0]) || !reflect.DeepEqual(gs.Players[1].Hand.Cards, hands[1]) {
+		t.Error("Expected the given hands to be dealt")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a deck with repeated cards to panic")
+		}
+	}()
+	New(WithDeck(append(deck[:20:20], deck[0])))
+}
+
+func TestMinTurnsBeforeClose(t *testing.T) {
+	gs, _ := NewFromScenario(Scenario{
+		Hands: map[int][]Card{
+			0: {{ORO, 1}, {ORO, 2}, {ORO, 3}, {COPA, 4}, {COPA, 5}, {COPA, 6}, {ESPADA, 7}, {BASTO, 1}},
+			1: {{COPA, 1}, {COPA, 2}, {COPA, 3}, {ESPADA, 1}, {ESPADA, 2}, {ESPADA, 3}, {BASTO, 11}},
+		},
+		HasDrawnCard: true,
+	}, WithMinTurnsBeforeClose(1))
+
+	if cgs := gs.ToClientGameState(0); cgs.TurnsBeforeYouCanClose != 1 {
+		t.Errorf("Expected 1 turn before player 0 can close, got %d", cgs.TurnsBeforeYouCanClose)
+	}
+	if reason := gs.ValidateAction(NewActionClose(Card{ESPADA, 7}, 0)); reason != REASON_TOO_EARLY_TO_CLOSE {
+		t.Errorf("Expected closing on the first turn to be too early, got %q", reason)
+	}
+
+	for _, action := range []Action{
+		NewActionDiscardCard(Card{ESPADA, 7}, 0),
+		NewActionDrawFromDiscard(1),
+		NewActionDiscardCard(Card{ESPADA, 7}, 1),
+		NewActionDrawFromDiscard(0),
+	} {
+		if err := gs.RunAction(action); err != nil {
+			t.Fatalf("Failed to run %v: %v", action, err)
+		}
+	}
+	if err := gs.RunAction(NewActionClose(Card{ESPADA, 7}, 0)); err != nil {
+		t.Errorf("Expected closing on the second turn to be possible: %v", err)
+	}
+}
+
+func TestHandSize(t *testing.T) {
+	gs := New(WithHandSize(10))
+	if len(gs.Players[0].Hand.Cards) != 10 || len(gs.Players[1].Hand.Cards) != 10 {
+		t.Fatal("Expected 10 cards to be dealt to each player")
+	}
+
+	hand := []Card{{ORO, 1}, {ORO, 2}, {ORO, 3}, {ORO, 4}, {ORO, 5}, {ORO, 6}, {ORO, 7}, {ORO, 8}, {ORO, 9}, {ORO, 10}}
+	if !gs.handRules().isChinchon(hand) || gs.handRules().isChinchon(hand[:7]) {
+		t.Error("Expected a Chinchón to need all 10 cards in a run")
+	}
+
+	gs, err := NewFromScenario(Scenario{
+		Hands: map[int][]Card{
+			0: append(append([]Card{}, hand[:9]...), Card{ESPADA, 12}, Card{ESPADA, 4}),
+			1: {{COPA, 1}, {COPA, 2}, {COPA, 3}, {ESPADA, 1}, {ESPADA, 2}, {ESPADA, 3}, {BASTO, 1}, {BASTO, 2}, {BASTO, 3}, {COPA, 12}},
+		},
+		HasDrawnCard: true,
+	}, WithHandSize(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if NewActionClose(Card{ESPADA, 4}, 0).IsPossible(*gs) {
+		t.Error("Expected closing with an ungrouped 12 not to be possible")
+	}
+	if err := gs.RunAction(NewActionClose(Card{ESPADA, 12}, 0)); err != nil {
+		t.Errorf("Expected closing with 10 cards left to be possible: %v", err)
+	}
+}
+
+func TestReorderHand(t *testing.T) {
+	gs := New()
+	notInTurn := gs.nextPlayer(gs.TurnPlayerID)
+	hand := gs.Players[notInTurn].Hand.Cards
+	reordered := []Card{hand[6], hand[5], hand[4], hand[3], hand[2], hand[1], hand[0]}
+
+	if err := gs.RunAction(NewActionReorderHand(reordered, notInTurn)); err != nil {
+		t.Fatalf("Expected reordering out of turn to be possible: %v", err)
+	}
+	if !reflect.DeepEqual(gs.ToClientGameState(notInTurn).YourHand, reordered) {
+		t.Error("Expected the hand to be delivered in the new order")
+	}
+	if len(gs.RoundsLog[1].ActionsLog) != 0 {
+		t.Error("Expected reordering not to be logged")
+	}
+	if NewActionReorderHand(reordered[1:], notInTurn).IsPossible(*gs) {
+		t.Error("Expected reordering to need every card of the hand")
+	}
+}
+
+func TestHandSort(t *testing.T) {
+	hand := Hand{Cards: []Card{{BASTO, 3}, {ORO, 12}, {COPA, 3}, {ORO, 1}}}
+	hand.SortBySuit()
+	if !reflect.DeepEqual(hand.Cards, []Card{{ORO, 1}, {ORO, 12}, {COPA, 3}, {BASTO, 3}}) {
+		t.Errorf("Unexpected hand sorted by suit: %v", hand.Cards)
+	}
+	hand.SortByNumber()
+	if !reflect.DeepEqual(hand.Cards, []Card{{ORO, 1}, {COPA, 3}, {BASTO, 3}, {ORO, 12}}) {
+		t.Errorf("Unexpected hand sorted by number: %v", hand.Cards)
+	}
+
+	gs := New()
+	if err := gs.RunAction(NewActionSetHandSort(SORT_BY_NUMBER, 0)); err != nil {
+		t.Fatal(err)
+	}
+	sorted := gs.Players[0].Hand.DeepCopy()
+	sorted.SortByNumber()
+	if !reflect.DeepEqual(gs.ToClientGameState(0).YourHand, sorted.Cards) {
+		t.Error("Expected the hand to be delivered sorted by number")
+	}
+}
+
+func TestCloseTieMode(t *testing.T) {
+	scenario := Scenario{
+		Hands: map[int][]Card{
+			0: {{ORO, 1}, {ORO, 2}, {ORO, 3}, {COPA, 4}, {COPA, 5}, {COPA, 6}, {ESPADA, 5}},
+			1: {{COPA, 1}, {COPA, 2}, {COPA, 3}, {ESPADA, 1}, {ESPADA, 2}, {ESPADA, 3}, {BASTO, 5}},
+		},
+	}
+
+	for mode, expected := range map[string][2]int{
+		CLOSE_TIE_PENALTIES:   {5, 5},
+		CLOSE_TIE_CLOSER_WINS: {0, 5},
+		CLOSE_TIE_REPLAY:      {0, 0},
+		CLOSE_TIE_SPLIT:       {2, 2},
+	} {
+		gs, _ := NewFromScenario(scenario, WithCloseTieMode(mode))
+		gs.CloseRound(0)
+		if scores := [2]int{gs.Players[0].Score, gs.Players[1].Score}; scores != expected {
+			t.Errorf("Expected scores %v with %v, got %v", expected, mode, scores)
+		}
+	}
+}
+
+func TestMaxRounds(t *testing.T) {
+	gs, _ := NewFromScenario(Scenario{
+		Hands: map[int][]Card{
+			0: {{ORO, 1}, {ORO, 2}, {ORO, 3}, {COPA, 4}, {COPA, 5}, {COPA, 6}, {ESPADA, 7}, {BASTO, 1}},
+			1: {{COPA, 1}, {COPA, 2}, {COPA, 3}, {ESPADA, 1}, {ESPADA, 2}, {ESPADA, 3}, {BASTO, 11}},
+		},
+		HasDrawnCard: true,
+	}, WithMaxRounds(1))
+
+	if err := gs.RunAction(NewActionClose(Card{ESPADA, 7}, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if !gs.IsGameEnded || gs.WinnerPlayerID != 0 || gs.LoserPlayerID != 1 {
+		t.Errorf("Expected player 0 to win after the only round, got ended %v and winner %d", gs.IsGameEnded, gs.WinnerPlayerID)
+	}
+}
+
+func TestStats(t *testing.T) {
+	gs, _ := NewFromScenario(Scenario{
+		Hands: map[int][]Card{
+			0: {{ORO, 1}, {ORO, 2}, {ORO, 3}, {COPA, 4}, {COPA, 5}, {COPA, 6}, {ESPADA, 7}},
+			1: {{COPA, 1}, {COPA, 2}, {COPA, 3}, {ESPADA, 1}, {ESPADA, 2}, {ESPADA, 3}, {BASTO, 11}},
+		},
+		DiscardPile: []Card{{BASTO, 1}},
+	}, WithMaxRounds(1))
+
+	for _, action := range []Action{NewActionDrawFromDiscard(0), NewActionClose(Card{ESPADA, 7}, 0)} {
+		if err := gs.RunAction(action); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats := gs.ToClientGameState(1).Stats
+	if stats == nil {
+		t.Fatal("Expected stats once the game ended")
+	}
+	if p := stats.Players[0]; p.DrawsFromDiscard != 1 || p.RoundsClosed != 1 || p.AverageDeadwoodAtClose != 1 {
+		t.Errorf("Unexpected stats for player 0: %+v", *p)
+	}
+	if p := stats.Players[1]; p.RoundsClosed != 0 || p.AverageDeadwoodAtClose != 10 {
+		t.Errorf("Unexpected stats for player 1: %+v", *p)
+	}
+}
+
+func TestEncodeAction(t *testing.T) {
+	for encoded := 0; encoded < ActionSpaceSize; encoded++ {
+		action := DecodeAction(encoded, 1)
+		if action == nil || action.GetPlayerID() != 1 || EncodeAction(action) != encoded {
+			t.Fatalf("Expected %d to decode to an action that encodes back to it, got %v", encoded, action)
+		}
+	}
+	if EncodeAction(NewActionDiscardCard(Card{ESPADA, 12}, 0)) != ENCODED_DISCARD_CARD+2*13+11 {
+		t.Error("Unexpected encoding for discarding the 12 de espada")
+	}
+	if EncodeAction(NewActionResign(0)) != -1 || DecodeAction(ActionSpaceSize, 0) != nil {
+		t.Error("Expected actions outside the action space not to be encoded")
+	}
+}
+
+func TestClientGameStateActions(t *testing.T) {
+	cgs := New().ToClientGameState(0)
+	actions, err := cgs.Actions()
+	if err != nil || len(actions) != len(cgs.PossibleActions) {
+		t.Fatalf("Expected every possible action to be deserialized, got %v and %v", actions, err)
+	}
+
+	cgs.PossibleActions = append(cgs.PossibleActions, []byte(`{"name":"unknown"}`))
+	if _, err := cgs.Actions(); err == nil {
+		t.Error("Expected an error for an unknown action")
+	}
+}
+
+func TestSchemaVersionMigration(t *testing.T) {
+	bs, _ := New().Save()
+	var legacy map[string]any
+	_ = json.Unmarshal(bs, &legacy)
+	delete(legacy, "schemaVersion")
+	delete(legacy, "ruleHandSize")
+	delete(legacy, "reenterOfferedPlayerID")
+	delete(legacy, "ruleCloseThreshold")
+	bs, _ = json.Marshal(legacy)
+
+	gs, err := Load(bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gs.RuleHandSize != DefaultHandSize || gs.ReenterOfferedPlayerID != -1 {
+		t.Errorf("Expected a legacy game to get the defaults, got hand size %d and %d", gs.RuleHandSize, gs.ReenterOfferedPlayerID)
+	}
+	if gs.RuleCloseThreshold != DefaultCloseThreshold {
+		t.Errorf("Expected a legacy game to close with up to %d points, got %d", DefaultCloseThreshold, gs.RuleCloseThreshold)
+	}
+
+	if _, err := DeserializeAction([]byte(`{"name":"draw_from_deck","playerID":1}`)); err != nil {
+		t.Errorf("Expected an unversioned action to be deserialized: %v", err)
+	}
+	if _, err := DeserializeAction([]byte(`{"schemaVersion":999,"name":"draw_from_deck","playerID":1}`)); err == nil {
+		t.Error("Expected an action from a newer schema version to be rejected")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	gs := New(WithSeed(1), WithValidation())
+	if err := gs.Validate(); err != nil {
+		t.Fatalf("Expected a new game to be valid, got %v", err)
+	}
+	_ = gs.RunAction(NewActionDrawFromDeck(gs.TurnPlayerID))
+	if err := gs.Validate(); err != nil {
+		t.Fatalf("Expected the game to be valid after drawing, got %v", err)
+	}
+
+	gs.DiscardPile = append(gs.DiscardPile, gs.Players[0].Hand.Cards[0])
+	if err := gs.Validate(); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Expected a duplicate card to be invalid, got %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected WithValidation to panic on an invalid state")
+		}
+	}()
+	_ = gs.RunAction(NewActionDiscardCard(gs.Players[gs.TurnPlayerID].Hand.Cards[1], gs.TurnPlayerID))
+}
+
+func TestRunActionByIndex(t *testing.T) {
+	gs := New(WithSeed(1))
+	playerID := gs.TurnPlayerID
+	if err := gs.RunActionByIndex(playerID, 0); err != nil || !gs.HasDrawnCard {
+		t.Fatalf("Expected action #0 to draw from the deck, got %v", err)
+	}
+
+	before := gs.PlayerActions(playerID)
+	gs.Players[playerID].Hand.SortByNumber()
+	if !reflect.DeepEqual(before, gs.PlayerActions(playerID)) {
+		t.Error("Expected the actions' order not to depend on the hand's order")
+	}
+
+	if err := gs.RunActionByIndex(playerID, len(before)); !errors.Is(err, ErrActionNotPossible) {
+		t.Errorf("Expected an out of range index to be rejected, got %v", err)
+	}
+}
+
+func TestChinchonMode(t *testing.T) {
+	scenario := Scenario{
+		Hands: map[int][]Card{
+			0: {{ORO, 1}, {ORO, 2}, {ORO, 3}, {ORO, 4}, {ORO, 5}, {ORO, 6}, {ORO, 7}, {BASTO, 12}},
+			1: {{COPA, 1}, {COPA, 2}, {COPA, 3}, {ESPADA, 1}, {ESPADA, 2}, {ESPADA, 3}, {BASTO, 11}},
+		},
+		HasDrawnCard: true,
+	}
+
+	gs, _ := NewFromScenario(scenario)
+	_ = gs.RunAction(NewActionClose(Card{BASTO, 12}, 0))
+	if !gs.IsGameEnded || gs.WinnerPlayerID != 0 {
+		t.Error("Expected a chinchón to win the game by default")
+	}
+
+	gs, _ = NewFromScenario(scenario, WithChinchonMode(CHINCHON_MINUS_25))
+	_ = gs.RunAction(NewActionClose(Card{BASTO, 12}, 0))
+	if gs.IsGameEnded || !gs.RoundsLog[1].WasChinchon || gs.Players[0].Score != -25 || gs.Players[1].Score != 10 {
+		t.Errorf("Expected the chinchón to take 25 points off, got scores %d and %d", gs.Players[0].Score, gs.Players[1].Score)
+	}
+
+	gs, _ = NewFromScenario(scenario, WithChinchonMode(CHINCHON_WINS_ROUND))
+	_ = gs.RunAction(NewActionClose(Card{BASTO, 12}, 0))
+	if gs.IsGameEnded || gs.RoundsLog[1].WinnerPlayerID != 0 || gs.Players[0].Score != 0 || gs.Players[1].Score != 20 {
+		t.Errorf("Expected the chinchón to win the round with the bonus, got scores %d and %d", gs.Players[0].Score, gs.Players[1].Score)
+	}
+}
+
+func TestTwoChinchons(t *testing.T) {
+	scenario := Scenario{
+		Hands: map[int][]Card{
+			0: {{COPA, 1}, {COPA, 2}, {COPA, 3}, {ESPADA, 1}, {ESPADA, 2}, {ESPADA, 3}, {BASTO, 11}},
+			1: {{ORO, 1}, {ORO, 2}, {ORO, 3}, {ORO, 4}, {ORO, 5}, {ORO, 6}, {ORO, 7}},
+			2: {{BASTO, 1}, {BASTO, 2}, {BASTO, 3}, {BASTO, 4}, {BASTO, 5}, {BASTO, 6}, {BASTO, 7}},
+		},
+	}
+	for i := 0; i < 20; i++ {
+		gs, err := NewFromScenario(scenario, WithPlayers(3))
+		if err != nil {
+			t.Fatal(err)
+		}
+		gs.CloseRound(0)
+		if !gs.IsGameEnded || gs.WinnerPlayerID != 1 {
+			t.Fatalf("Expected the first player with a chinchón to win the game, got %d", gs.WinnerPlayerID)
+		}
+
+		gs, _ = NewFromScenario(scenario, WithPlayers(3), WithChinchonMode(CHINCHON_WINS_ROUND))
+		gs.CloseRound(0)
+		if gs.RoundsLog[1].WinnerPlayerID != 1 {
+			t.Fatalf("Expected the first player with a chinchón to win the round, got %d", gs.RoundsLog[1].WinnerPlayerID)
+		}
+	}
+}
+
+func TestChinchonLoser(t *testing.T) {
+	hands := map[int][]Card{
+		0: {{COPA, 1}, {COPA, 2}, {COPA, 3}, {ESPADA, 1}, {ESPADA, 2}, {ESPADA, 3}, {BASTO, 11}},
+		1: {{COPA, 10}, {COPA, 11}, {COPA, 12}, {ESPADA, 10}, {ESPADA, 11}, {ESPADA, 12}, {BASTO, 12}},
+		2: {{BASTO, 1}, {BASTO, 2}, {BASTO, 3}, {BASTO, 4}, {BASTO, 5}, {BASTO, 6}, {BASTO, 7}},
+	}
+	for _, test := range []struct {
+		scores map[int]int
+		loser  int
+	}{
+		{scores: map[int]int{0: 10, 1: 50, 2: 20}, loser: 1},
+		{scores: map[int]int{0: 50, 1: 10, 2: 20}, loser: 0},
+		{scores: map[int]int{0: 30, 1: 30, 2: 80}, loser: 0},
+	} {
+		for i := 0; i < 20; i++ {
+			gs, err := NewFromScenario(Scenario{Hands: hands, Scores: test.scores}, WithPlayers(3))
+			if err != nil {
+				t.Fatal(err)
+			}
+			gs.CloseRound(0)
+			if !gs.IsGameEnded || gs.WinnerPlayerID != 2 || gs.LoserPlayerID != test.loser {
+				t.Fatalf("Expected player %d to lose to the chinchón with scores %v, got %d", test.loser, test.scores, gs.LoserPlayerID)
+			}
+		}
+	}
+}
+
+func TestVerifiableShuffle(t *testing.T) {
+	gs := New(WithVerifiableShuffle(), WithResignRound(25))
+	cgs := gs.ToClientGameState(1)
+	if cgs.ShuffleCommitment == "" || cgs.ShuffleSeed != "" {
+		t.Fatal("Expected a commitment to the deck, and the seed to be hidden until the round finishes")
+	}
+	dealt := cgs.YourHand
+
+	_ = gs.RunAction(NewActionResignRound(0))
+	seed := gs.ToClientGameState(1).ShuffleSeed
+	if err := VerifyShuffle(cgs.ShuffleCommitment, seed, map[int][]Card{1: dealt}); err != nil {
+		t.Errorf("Expected the deal to be verified, got %v", err)
+	}
+
+	dealt = append([]Card{}, dealt...)
+	dealt[0] = gs.RoundsLog[1].HandsDealt[0].Cards[0]
+	if err := VerifyShuffle(cgs.ShuffleCommitment, seed, map[int][]Card{1: dealt}); err == nil {
+		t.Error("Expected a tampered deal not to be verified")
+	}
+	if err := VerifyShuffle(cgs.ShuffleCommitment, "not the seed", nil); err == nil {
+		t.Error("Expected a wrong seed not to be verified")
+	}
+}
+
+func TestVerifiableShuffleLayOff(t *testing.T) {
+	gs := New(WithVerifiableShuffle(), WithLayOff())
+	gs.Players[0].Hand.Cards = []Card{{COPA, 4}, {ORO, 12}, {COPA, 11}, {ESPADA, 10}, {BASTO, 12}, {ORO, 9}, {ESPADA, 6}}
+	gs.Players[1].Hand.Cards = []Card{{COPA, 1}, {COPA, 2}, {COPA, 3}, {ESPADA, 1}, {ESPADA, 2}, {ESPADA, 3}, {BASTO, 4}}
+	gs.CloseRound(1)
+	if !gs.IsLayingOff || gs.ToClientGameState(0).ShuffleSeed != "" {
+		t.Fatal("Expected the seed to be hidden while players lay off")
+	}
+	if err := gs.RunAction(NewActionFinishLayOff(0)); err != nil {
+		t.Fatal(err)
+	}
+	if gs.ToClientGameState(0).ShuffleSeed != gs.RoundsLog[1].ShuffleSeed {
+		t.Error("Expected the seed to be revealed once the lay-off phase is over")
+	}
+}
+
+func TestSpectatorGameState(t *testing.T) {
+	gs := New()
+	sgs := gs.ToSpectatorGameState(false)
+	for _, player := range sgs.Players {
+		if len(player.Hand) > 0 || player.HandSize != DefaultHandSize {
+			t.Errorf("Expected player %d's hand to be hidden, got %v", player.PlayerID, player.Hand)
+		}
+	}
+	for _, event := range sgs.Events {
+		if event.Card != nil && !event.FaceUp {
+			t.Errorf("Expected face down cards to be hidden, got %v", event)
+		}
+	}
+
+	sgs = gs.ToSpectatorGameState(true)
+	if !reflect.DeepEqual(sgs.Players[1].Hand, gs.Players[1].Hand.Cards) || !reflect.DeepEqual(sgs.Events, gs.Events) {
+		t.Error("Expected every hand to be revealed for broadcasting")
+	}
+}
+
+func TestDiscardHistory(t *testing.T) {
+	gs := New(WithSeed(1))
+	first := gs.TurnPlayerID
+	top := gs.DiscardPile[0]
+	_ = gs.RunAction(NewActionDrawFromDiscard(first))
+	discarded := gs.Players[first].Hand.Cards[0]
+	_ = gs.RunAction(NewActionDiscardCard(discarded, first))
+
+	expected := []DiscardEntry{
+		{Card: top, PlayerID: -1, PickedByPlayerID: first},
+		{Card: discarded, PlayerID: first, PickedByPlayerID: -1},
+	}
+	if history := gs.ToClientGameState(0).DiscardHistory; !reflect.DeepEqual(history, expected) {
+		t.Errorf("Expected discard history %v, got %v", expected, history)
+	}
+}
+
+func TestOdds(t *testing.T) {
+	gs, _ := NewFromScenario(Scenario{
+		Hands: map[int][]Card{
+			0: {{ORO, 1}, {ORO, 2}, {COPA, 4}, {COPA, 5}, {COPA, 6}, {ESPADA, 7}, {BASTO, 1}},
+			1: {{COPA, 1}, {COPA, 2}, {COPA, 3}, {ESPADA, 1}, {ESPADA, 2}, {ESPADA, 3}, {BASTO, 11}},
+		},
+		DiscardPile: []Card{{ESPADA, 12}},
+	})
+	state := gs.ToClientGameState(0)
+
+	if odds := Odds(state, []Card{{ORO, 1}, {ORO, 2}, {ORO, 3}}); odds != 1.0/40 {
+		t.Errorf("Expected 1 in 40 unseen cards to complete the run, got %v", odds)
+	}
+	if odds := Odds(state, []Card{{COPA, 4}, {COPA, 5}, {COPA, 6}}); odds != 1 {
+		t.Errorf("Expected a group already in hand to be certain, got %v", odds)
+	}
+	if odds := Odds(state, []Card{{ESPADA, 10}, {ESPADA, 11}, {ESPADA, 12}}); odds != 0 {
+		t.Errorf("Expected a group missing more than one card to be impossible, got %v", odds)
+	}
+	if odds := Odds(state, []Card{{ORO, 1}, {ORO, 2}, {ORO, 4}}); odds != 0 {
+		t.Errorf("Expected an invalid group to be impossible, got %v", odds)
+	}
+}
+
+func TestNotation(t *testing.T) {
+	gs := New(WithPlayers(3), WithResignRound(30), WithLayOff())
+	for i := 0; i < 300 && !gs.IsGameEnded; i++ {
+		actions := gs.CalculatePossibleActions()
+		action := actions[i%len(actions)]
+		if i%70 == 69 && !gs.IsRoundFinished {
+			action = NewActionResignRound(gs.TurnPlayerID)
+		}
+		for _, a := range actions {
+			if a.GetName() == CLOSE_ROUND {
+				action = a
+			}
+		}
+		_ = gs.RunAction(action)
+	}
+	for gs.IsRoundFinished && !gs.IsGameEnded {
+		// The game may have stopped while cards are laid off, before confirming is possible
+		for _, playerID := range gs.layOffPlayerIDs() {
+			_ = gs.RunAction(NewActionFinishLayOff(playerID))
+		}
+		gs.confirmRoundFinished()
+	}
+
+	notation, err := ExportNotation(gs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(notation, "[Players \"3\"]") || !strings.Contains(notation, "1. deck ") {
+		t.Errorf("Expected the rules and the deal to be written, got %v", notation)
+	}
+	imported, err := ParseNotation(strings.NewReader(notation))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported.Fingerprint() != gs.Fingerprint() {
+		t.Error("Expected the imported game to be the same as the exported one")
+	}
+
+	// Verifiable shuffles keep their seeds and commitments, for the imported game to be audited
+	gs = New(WithVerifiableShuffle(), WithResignRound(25))
+	for i := 0; i < 3; i++ {
+		_ = gs.RunAction(NewActionResignRound(gs.TurnPlayerID))
+		gs.confirmRoundFinished()
+	}
+	notation, err = ExportNotation(gs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, err = ParseNotation(strings.NewReader(notation))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported.Fingerprint() != gs.Fingerprint() || !imported.RuleVerifiableShuffle {
+		t.Error("Expected the imported game with verifiable shuffles to be the same as the exported one")
+	}
+	round := imported.RoundsLog[1]
+	if err := VerifyShuffle(round.ShuffleCommitment, round.ShuffleSeed, map[int][]Card{0: round.DeckDealt[:7]}); err != nil {
+		t.Errorf("Expected the imported round to verify, got %v", err)
+	}
+	tampered := strings.Replace(notation, "commitment="+round.ShuffleCommitment, "commitment="+strings.Repeat("0", 64), 1)
+	if _, err := ParseNotation(strings.NewReader(tampered)); err == nil {
+		t.Error("Expected a deck not matching its commitment to be rejected")
+	}
+
+	if _, err := ParseNotation(strings.NewReader("[Players \"2\"]\n1. deck 1o\n1. 0:Z")); err == nil {
+		t.Error("Expected an invalid move to be rejected")
+	}
+}
+
+func TestPlayerProfile(t *testing.T) {
+	gs := New(WithSeed(1))
+	fingerprint := gs.Fingerprint()
+	if err := gs.SetPlayerProfile(1, "Juan", "🧉"); err != nil {
+		t.Fatal(err)
+	}
+	for _, invalid := range []struct{ name, avatar string }{
+		{" Juan", ""},
+		{strings.Repeat("a", MaxNameLength+1), ""},
+		{"Juan\n", ""},
+		{"Juan", "J"},
+	} {
+		if err := gs.SetPlayerProfile(1, invalid.name, invalid.avatar); err == nil {
+			t.Errorf("Expected profile %q %q to be rejected", invalid.name, invalid.avatar)
+		}
+	}
+	if gs.Fingerprint() != fingerprint {
+		t.Error("Expected profiles not to change the fingerprint")
+	}
+
+	state := gs.ToClientGameState(0)
+	if state.Players[1].Name != "Juan" || state.Players[1].Avatar != "🧉" {
+		t.Errorf("Expected the profile in the client game state, got %+v", state.Players[1])
+	}
+	en := NewCatalog(LOCALE_EN)
+	if name := en.PlayerNameIn(state, 1); name != "Juan" {
+		t.Errorf("Expected Juan, got %v", name)
+	}
+	if name := en.PlayerNameIn(gs.ToClientGameState(1), 1); name != en.PlayerName(1, 1, 2) {
+		t.Errorf("Expected players to be called you, got %v", name)
+	}
+}
@@ -0,0 +1,78 @@
+package chinchon
+
+import "time"
+
+// What happens when a turn times out, for WithTurnTimeout
+const (
+	TIMEOUT_AUTO_PLAY = "auto_play" // The player draws from the deck and discards their worst card
+	TIMEOUT_FORFEIT   = "forfeit"   // The player resigns the game
+)
+
+// WithTurnTimeout limits how long players have for each turn. The engine doesn't keep
+// time by itself: whoever hosts the game calls TickClock, e.g. every second, to enforce it.
+func WithTurnTimeout(timeout time.Duration, mode string) func(*GameState) {
+	return func(gs *GameState) {
+		gs.RuleTurnTimeout = timeout
+		gs.RuleTurnTimeoutMode = mode
+	}
+}
+
+// TickClock times out the current turn if it has run out of time by now, and returns the
+// actions run for the player (e.g. to send or journal them), if any.
+func (g *GameState) TickClock(now time.Time) ([]Action, error) {
+	if g.RuleTurnTimeout == 0 || g.IsGameEnded || g.IsRoundFinished {
+		return nil, nil
+	}
+	if now.Sub(g.TurnStartedAt) < g.RuleTurnTimeout {
+		return nil, nil
+	}
+	return g.TimeoutCurrentPlayer()
+}
+
+// TimeoutCurrentPlayer ends the current turn as if its time ran out, according to
+// RuleTurnTimeoutMode, and returns the actions run for the player.
+func (g *GameState) TimeoutCurrentPlayer() ([]Action, error) {
+	playerID := g.TurnPlayerID
+	if g.RuleTurnTimeoutMode == TIMEOUT_FORFEIT {
+		action := NewActionResign(playerID)
+		return []Action{action}, g.RunAction(action)
+	}
+
+	actions := []Action{}
+	if !g.HasDrawnCard {
+		draw := NewActionDrawFromDeck(playerID)
+		if !draw.IsPossible(*g) {
+			draw = NewActionDrawFromDiscard(playerID)
+		}
+		if err := g.RunTakeoverAction(draw); err != nil {
+			return actions, err
+		}
+		actions = append(actions, draw)
+	}
+
+	cards, _ := g.handRules().bestDiscard(g.Players[playerID].Hand.Cards)
+	discard := NewActionDiscardCard(cards[0], playerID)
+	if err := g.RunTakeoverAction(discard); err != nil {
+		return actions, err
+	}
+	return append(actions, discard), nil
+}
+
+// turnTimeRemaining returns the time left for the current turn, or 0 without a timeout.
+func (g GameState) turnTimeRemaining() time.Duration {
+	if g.RuleTurnTimeout == 0 || g.IsGameEnded || g.IsRoundFinished {
+		return 0
+	}
+	remaining := g.RuleTurnTimeout - g.now().Sub(g.TurnStartedAt)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+func (g GameState) now() time.Time {
+	if g.clock != nil {
+		return g.clock()
+	}
+	return time.Now()
+}
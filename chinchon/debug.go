@@ -0,0 +1,7 @@
+//go:build chinchondebug
+// +build chinchondebug
+
+package chinchon
+
+// debugValidation validates every game after every action, as with WithValidation.
+const debugValidation = true
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"sort"
 )
 
 const (
@@ -13,33 +14,114 @@ const (
 	BASTO  = "basto"
 )
 
+// Deck types, for WithDeckType
+const (
+	DECK_SPANISH_40 = "spanish_40" // Spanish deck without 8s and 9s
+	DECK_SPANISH_48 = "spanish_48" // Spanish deck with 8s and 9s, the default
+	DECK_FRENCH_52  = "french_52"  // French deck, with the J, Q and K numbered 11, 12 and 13
+)
+
+// deckNumbers returns the card numbers of each suit in the deck type, in run order. Zero
+// values (e.g. from games saved by older versions) are the default deck.
+func deckNumbers(deckType string) []int {
+	switch deckType {
+	case DECK_SPANISH_40:
+		return []int{1, 2, 3, 4, 5, 6, 7, 10, 11, 12}
+	case DECK_FRENCH_52:
+		return []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}
+	default:
+		return []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+	}
+}
+
+// isInDeck returns true if the deck type has the card.
+func isInDeck(deckType string, card Card) bool {
+	switch card.Suit {
+	case ORO, COPA, ESPADA, BASTO:
+	default:
+		return false
+	}
+	for _, number := range deckNumbers(deckType) {
+		if card.Number == number {
+			return true
+		}
+	}
+	return false
+}
+
 // Card represents a Spanish deck card.
 type Card struct {
 	// Suit is the card's suit, which can be "oro", "copa", "espada" or "basto".
 	Suit string `json:"suit"`
 
-	// Number is the card's number, from 1 to 12 (including 8 and 9 for Chinchón).
+	// Number is the card's number, from 1 to 12 (including 8 and 9 for Chinchón), or up
+	// to 13 with a French deck (whose suits are mapped to the Spanish ones).
 	Number int `json:"number"`
 }
 
 func (c Card) String() string {
-	return fmt.Sprintf("%d de %s", c.Number, c.Suit)
+	return NewCatalog(DefaultLocale).Card(c)
 }
 
-// PenaltyValue returns the penalty points for this card in Chinchón scoring
+// PenaltyValue returns the penalty points for this card in Chinchón scoring, with any
+// deck type
 func (c Card) PenaltyValue() int {
 	if c.Number >= 10 {
-		return 10 // Face cards (10, 11, 12) are worth 10 points
+		return 10 // Face cards (10, 11, 12, or the French 10, J, Q, K) are worth 10 points
 	}
 	return c.Number // Number cards are worth their face value
 }
 
 type deck struct {
 	cards        []Card
-	dealHandFunc func() *Hand
+	dealHandFunc func(size int) *Hand
+	rng          *rand.Rand
+	deckType     string
+
+	// seeded is rng's source with WithSeed, for Save to restore where it's at.
+	seeded *seededSource
+
+	// stacked are the deals that the next shuffles and refills must produce, e.g. when
+	// replaying a game.
+	stacked []Deal
+}
+
+// Deal is a deck dealt in a game, from the top: a round's deck, or a draw pile refilled
+// from the discard pile.
+type Deal struct {
+	Cards []Card `json:"cards"`
+
+	// ShuffleSeed is the seed a round's deck was derived from, with RuleVerifiableShuffle.
+	ShuffleSeed string `json:"shuffleSeed,omitempty"`
+}
+
+// StackDeals makes the next rounds and refills of the game deal the given deals, in order,
+// rather than shuffled decks, e.g. to run logged actions again and deal what they dealt the
+// first time (see DealsSince).
+func (g *GameState) StackDeals(deals ...Deal) {
+	for _, deal := range deals {
+		g.DrawPile.stacked = append(g.DrawPile.stacked, Deal{Cards: append([]Card{}, deal.Cards...), ShuffleSeed: deal.ShuffleSeed})
+	}
+}
+
+// DealsSince returns the deals made since the given round had the given number of refills,
+// from the rounds log, for StackDeals.
+func (g *GameState) DealsSince(roundNumber, refills int) []Deal {
+	deals := []Deal{}
+	for number := roundNumber; number < len(g.RoundsLog); number++ {
+		round := g.RoundsLog[number]
+		if number > roundNumber {
+			deals = append(deals, Deal{Cards: round.DeckDealt, ShuffleSeed: round.ShuffleSeed})
+			refills = 0
+		}
+		for _, refill := range round.Refills[min(refills, len(round.Refills)):] {
+			deals = append(deals, Deal{Cards: refill})
+		}
+	}
+	return deals
 }
 
-// Hand represents a player's hand in Chinchón. Players have 7 cards.
+// Hand represents a player's hand in Chinchón. Players have 7 cards (see WithHandSize).
 type Hand struct {
 	Cards []Card `json:"cards"`
 }
@@ -70,7 +152,44 @@ func (h *Hand) RemoveCard(card Card) error {
 			return nil
 		}
 	}
-	return errCardNotInHand
+	return ErrCardNotInHand
+}
+
+// Hand sorting preferences, for ActionSetHandSort
+const (
+	SORT_NONE      = ""
+	SORT_BY_SUIT   = "suit"
+	SORT_BY_NUMBER = "number"
+)
+
+// suitOrder is the order suits are sorted in, as in a new deck.
+var suitOrder = map[string]int{ORO: 0, COPA: 1, ESPADA: 2, BASTO: 3}
+
+// SortBySuit sorts the hand by suit, and by number within each suit.
+func (h *Hand) SortBySuit() {
+	sort.SliceStable(h.Cards, func(i, j int) bool {
+		if h.Cards[i].Suit != h.Cards[j].Suit {
+			return suitOrder[h.Cards[i].Suit] < suitOrder[h.Cards[j].Suit]
+		}
+		return h.Cards[i].Number < h.Cards[j].Number
+	})
+}
+
+// sortedBySuit returns a copy of the cards, sorted as by SortBySuit.
+func sortedBySuit(cards []Card) []Card {
+	h := &Hand{Cards: append([]Card{}, cards...)}
+	h.SortBySuit()
+	return h.Cards
+}
+
+// SortByNumber sorts the hand by number, and by suit among equal numbers.
+func (h *Hand) SortByNumber() {
+	sort.SliceStable(h.Cards, func(i, j int) bool {
+		if h.Cards[i].Number != h.Cards[j].Number {
+			return h.Cards[i].Number < h.Cards[j].Number
+		}
+		return suitOrder[h.Cards[i].Suit] < suitOrder[h.Cards[j].Suit]
+	})
 }
 
 // ValidGroups returns all valid runs and sets in the hand
@@ -166,45 +285,21 @@ func (h Hand) findSets() [][]Card {
 
 // IsChinchon returns true if the hand contains a Chinchón (7 consecutive cards of same suit)
 func (h Hand) IsChinchon() bool {
-	if len(h.Cards) != 7 {
-		return false
-	}
-
-	suitCards := make(map[string][]Card)
-	for _, card := range h.Cards {
-		suitCards[card.Suit] = append(suitCards[card.Suit], card)
-	}
-
-	// Check if all 7 cards are of the same suit
-	for _, cards := range suitCards {
-		if len(cards) == 7 {
-			// Sort cards by number
-			for i := 0; i < len(cards)-1; i++ {
-				for j := i + 1; j < len(cards); j++ {
-					if cards[i].Number > cards[j].Number {
-						cards[i], cards[j] = cards[j], cards[i]
-					}
-				}
-			}
+	return defaultHandRules.isChinchon(h.Cards)
+}
 
-			// Check if consecutive
-			consecutive := true
-			for i := 1; i < len(cards); i++ {
-				if cards[i].Number != cards[i-1].Number+1 {
-					consecutive = false
-					break
-				}
-			}
-			return consecutive
-		}
+// PenaltyPoints calculates penalty points for ungrouped cards
+func (h Hand) PenaltyPoints(groups [][]Card) int {
+	penalty := 0
+	for _, card := range h.UngroupedCards(groups) {
+		penalty += card.PenaltyValue()
 	}
 
-	return false
+	return penalty
 }
 
-// PenaltyPoints calculates penalty points for ungrouped cards
-func (h Hand) PenaltyPoints(groups [][]Card) int {
-	// Create a map of grouped cards
+// UngroupedCards returns the cards of the hand that are not in any of the groups
+func (h Hand) UngroupedCards(groups [][]Card) []Card {
 	groupedCards := make(map[Card]bool)
 	for _, group := range groups {
 		for _, card := range group {
@@ -212,32 +307,42 @@ func (h Hand) PenaltyPoints(groups [][]Card) int {
 		}
 	}
 
-	penalty := 0
+	ungrouped := []Card{}
 	for _, card := range h.Cards {
 		if !groupedCards[card] {
-			penalty += card.PenaltyValue()
+			ungrouped = append(ungrouped, card)
 		}
 	}
-
-	return penalty
+	return ungrouped
 }
 
 var (
-	errCardNotInHand = errors.New("card not in hand")
+	// ErrCardNotInHand is returned when removing a card the hand doesn't have.
+	ErrCardNotInHand = errors.New("card not in hand")
 )
 
-// makeSpanishCards creates a full 40-card Spanish deck (including 8s and 9s)
-func makeSpanishCards() []Card {
+// makeSpanishCards creates a full, shuffled 48-card Spanish deck (including 8s and 9s).
+// If rng is nil, the global math/rand source is used.
+func makeSpanishCards(rng *rand.Rand) []Card {
+	return makeCards(DECK_SPANISH_48, rng)
+}
+
+// makeCards creates a full, shuffled deck of the given type. If rng is nil, the global
+// math/rand source is used.
+func makeCards(deckType string, rng *rand.Rand) []Card {
 	cards := []Card{}
 	suits := []string{ORO, COPA, ESPADA, BASTO}
 	for _, suit := range suits {
-		for i := 1; i <= 12; i++ {
-			// Include all cards from 1 to 12 for Chinchón (including 8 and 9)
-			cards = append(cards, Card{Suit: suit, Number: i})
+		for _, number := range deckNumbers(deckType) {
+			cards = append(cards, Card{Suit: suit, Number: number})
 		}
 	}
 
-	rand.Shuffle(len(cards), func(i, j int) {
+	shuffle := rand.Shuffle
+	if rng != nil {
+		shuffle = rng.Shuffle
+	}
+	shuffle(len(cards), func(i, j int) {
 		cards[i], cards[j] = cards[j], cards[i]
 	})
 
@@ -245,23 +350,64 @@ func makeSpanishCards() []Card {
 }
 
 func newDeck() *deck {
-	d := deck{cards: makeSpanishCards()}
+	d := deck{cards: makeSpanishCards(nil), deckType: DECK_SPANISH_48}
 	d.dealHandFunc = d.defaultDealHand
 	return &d
 }
 
 func (d *deck) shuffle() {
-	d.cards = makeSpanishCards()
+	d.cards = makeCards(d.deckType, d.rng)
+}
+
+// popStacked sets the deck's cards to the next stacked deal, if any, and returns it.
+func (d *deck) popStacked() (Deal, bool) {
+	if len(d.stacked) == 0 {
+		return Deal{}, false
+	}
+	deal := d.stacked[0]
+	d.cards = append([]Card{}, deal.Cards...)
+	d.stacked = d.stacked[1:]
+	return deal, true
+}
+
+// refill replaces the deck's cards with the given ones, shuffled.
+func (d *deck) refill(cards []Card) {
+	if _, ok := d.popStacked(); ok {
+		return
+	}
+	shuffle := rand.Shuffle
+	if d.rng != nil {
+		shuffle = d.rng.Shuffle
+	}
+	shuffle(len(cards), func(i, j int) {
+		cards[i], cards[j] = cards[j], cards[i]
+	})
+	d.cards = cards
+}
+
+// reshuffleDiscardPile turns every discarded card but the top one into a new draw pile, for
+// when the draw pile runs out, which is common with many players.
+func (g *GameState) reshuffleDiscardPile() {
+	top := len(g.DiscardPile) - 1
+	cards := append([]Card{}, g.DiscardPile[:top]...)
+	g.DiscardPile = []Card{g.DiscardPile[top]}
+	g.recordDiscardReshuffle()
+	g.DrawPile.refill(cards)
+	round := g.RoundsLog[g.RoundNumber]
+	round.Refills = append(round.Refills, append([]Card{}, g.DrawPile.cards...))
+	for _, card := range cards {
+		g.emitCardMoved(-1, card, LOCATION_DISCARD_PILE, LOCATION_DECK, false)
+	}
 }
 
-func (d *deck) dealHand() *Hand {
-	return d.dealHandFunc()
+func (d *deck) dealHand(size int) *Hand {
+	return d.dealHandFunc(size)
 }
 
-// defaultDealHand deals 7 cards for Chinchón
-func (d *deck) defaultDealHand() *Hand {
+// defaultDealHand deals size cards, usually 7 for Chinchón
+func (d *deck) defaultDealHand(size int) *Hand {
 	hand := &Hand{}
-	for i := 0; i < 7; i++ {
+	for i := 0; i < size; i++ {
 		if len(d.cards) > 0 {
 			hand.Cards = append(hand.Cards, d.cards[0])
 			d.cards = d.cards[1:]
@@ -289,3 +435,44 @@ func (d *deck) isEmpty() bool {
 func (d *deck) remainingCards() int {
 	return len(d.cards)
 }
+
+// stackDealHands stacks a first deal with the hands of WithDealHands, followed by the rest of
+// the cards, shuffled.
+func (g *GameState) stackDealHands() {
+	deck := []Card{}
+	used := map[Card]bool{}
+	for id := 0; id < len(g.Players); id++ {
+		if len(g.dealHands[id]) != g.RuleHandSize {
+			panic(fmt.Sprintf("chinchon: player %d should be dealt %d cards, got %d", id, g.RuleHandSize, len(g.dealHands[id])))
+		}
+		for _, card := range g.dealHands[id] {
+			deck = append(deck, card)
+			used[card] = true
+		}
+	}
+	for _, card := range makeCards(g.RuleDeckType, g.DrawPile.rng) {
+		if !used[card] {
+			deck = append(deck, card)
+		}
+	}
+	g.DrawPile.stacked = append([]Deal{{Cards: deck}}, g.DrawPile.stacked...)
+}
+
+// validateDeck checks that a deck to deal from only has cards of the game's deck type,
+// without repeats, and enough of them to deal every hand and start the discard pile.
+func (g GameState) validateDeck(cards []Card) error {
+	seen := map[Card]bool{}
+	for _, card := range cards {
+		if !isInDeck(g.RuleDeckType, card) {
+			return fmt.Errorf("invalid card %v", card)
+		}
+		if seen[card] {
+			return fmt.Errorf("card %v appears more than once", card)
+		}
+		seen[card] = true
+	}
+	if needed := len(g.Players)*g.RuleHandSize + 1; len(cards) < needed {
+		return fmt.Errorf("expected at least %d cards, got %d", needed, len(cards))
+	}
+	return nil
+}
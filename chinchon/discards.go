@@ -0,0 +1,49 @@
+package chinchon
+
+// DiscardEntry is a card that was placed on the discard pile during the round.
+type DiscardEntry struct {
+	Card Card `json:"card"`
+
+	// PlayerID is the player who discarded the card, or -1 for the card that started the pile.
+	PlayerID int `json:"playerID"`
+
+	// PickedByPlayerID is the player who drew the card from the discard pile, -1 if none.
+	PickedByPlayerID int `json:"pickedByPlayerID"`
+
+	// Reshuffled is true if the card was shuffled back into the draw pile, when it ran out.
+	Reshuffled bool `json:"reshuffled,omitempty"`
+}
+
+// recordDiscard logs a card placed on the discard pile, in DiscardHistory.
+func (g *GameState) recordDiscard(playerID int, card Card) {
+	g.DiscardHistory = append(g.DiscardHistory, DiscardEntry{Card: card, PlayerID: playerID, PickedByPlayerID: -1})
+}
+
+// recordDiscardPickup logs that the player drew the top card of the discard pile, in
+// DiscardHistory.
+func (g *GameState) recordDiscardPickup(playerID int) {
+	for i := len(g.DiscardHistory) - 1; i >= 0; i-- {
+		entry := &g.DiscardHistory[i]
+		if entry.PickedByPlayerID == -1 && !entry.Reshuffled {
+			entry.PickedByPlayerID = playerID
+			return
+		}
+	}
+}
+
+// recordDiscardReshuffle logs that every card in the discard pile but the top one was
+// shuffled into the draw pile, in DiscardHistory.
+func (g *GameState) recordDiscardReshuffle() {
+	isTop := true
+	for i := len(g.DiscardHistory) - 1; i >= 0; i-- {
+		entry := &g.DiscardHistory[i]
+		if entry.PickedByPlayerID != -1 || entry.Reshuffled {
+			continue
+		}
+		if isTop {
+			isTop = false
+			continue
+		}
+		entry.Reshuffled = true
+	}
+}
@@ -0,0 +1,70 @@
+package chinchon
+
+// The action space for EncodeAction and DecodeAction, in order: drawing from the deck and
+// from the discard pile, discarding each card, closing with each card, and confirming the
+// round is finished. Cards are indexed by suit (ORO, COPA, ESPADA, BASTO) and then by
+// number, from 1 to 13 with any deck type, so the space has the same size for every game.
+const (
+	ENCODED_DRAW_FROM_DECK    = 0
+	ENCODED_DRAW_FROM_DISCARD = 1
+	ENCODED_DISCARD_CARD      = 2                                   // Plus the card's index
+	ENCODED_CLOSE_ROUND       = ENCODED_DISCARD_CARD + encodedCards // Plus the card's index
+	ENCODED_CONFIRM_ROUND     = ENCODED_CLOSE_ROUND + encodedCards
+
+	// ActionSpaceSize is the number of encoded actions, e.g. for fixed-size action vectors.
+	ActionSpaceSize = ENCODED_CONFIRM_ROUND + 1
+)
+
+const encodedCards = 4 * 13
+
+// EncodeAction returns the action's position in the action space, e.g. for machine
+// learning frameworks, or -1 for actions outside it (like laying off cards or resigning).
+// The player isn't encoded.
+func EncodeAction(action Action) int {
+	card, hasCard := actionCard(action)
+	index := encodeCard(card)
+	switch {
+	case action.GetName() == DRAW_FROM_DECK:
+		return ENCODED_DRAW_FROM_DECK
+	case action.GetName() == DRAW_FROM_DISCARD:
+		return ENCODED_DRAW_FROM_DISCARD
+	case action.GetName() == DISCARD_CARD && hasCard && index != -1:
+		return ENCODED_DISCARD_CARD + index
+	case action.GetName() == CLOSE_ROUND && hasCard && index != -1:
+		return ENCODED_CLOSE_ROUND + index
+	case action.GetName() == CONFIRM_ROUND_FINISHED:
+		return ENCODED_CONFIRM_ROUND
+	}
+	return -1
+}
+
+// DecodeAction returns the action at the position of the action space for the player, or
+// nil if the position is out of it. Closing actions have no declared groups.
+func DecodeAction(encoded int, playerID int) Action {
+	switch {
+	case encoded == ENCODED_DRAW_FROM_DECK:
+		return NewActionDrawFromDeck(playerID)
+	case encoded == ENCODED_DRAW_FROM_DISCARD:
+		return NewActionDrawFromDiscard(playerID)
+	case encoded >= ENCODED_DISCARD_CARD && encoded < ENCODED_CLOSE_ROUND:
+		return NewActionDiscardCard(decodeCard(encoded-ENCODED_DISCARD_CARD), playerID)
+	case encoded >= ENCODED_CLOSE_ROUND && encoded < ENCODED_CONFIRM_ROUND:
+		return NewActionClose(decodeCard(encoded-ENCODED_CLOSE_ROUND), playerID)
+	case encoded == ENCODED_CONFIRM_ROUND:
+		return NewActionConfirmRoundFinished(playerID)
+	}
+	return nil
+}
+
+func encodeCard(card Card) int {
+	suit, ok := suitOrder[card.Suit]
+	if !ok || card.Number < 1 || card.Number > 13 {
+		return -1
+	}
+	return suit*13 + card.Number - 1
+}
+
+func decodeCard(index int) Card {
+	suits := []string{ORO, COPA, ESPADA, BASTO}
+	return Card{Suit: suits[index/13], Number: index%13 + 1}
+}
@@ -0,0 +1,55 @@
+package chinchon
+
+// HandEvaluator groups and scores hands under a game's rules, for bots and UIs. Get one
+// for a game's rules with GameState.HandEvaluator or ClientGameState.HandEvaluator.
+type HandEvaluator struct {
+	rules          handRules
+	closeThreshold int
+}
+
+// NewHandEvaluator returns an evaluator for the default rules.
+func NewHandEvaluator() HandEvaluator {
+	return HandEvaluator{rules: defaultHandRules, closeThreshold: DefaultCloseThreshold}
+}
+
+// HandEvaluator returns an evaluator for the game's rules.
+func (g GameState) HandEvaluator() HandEvaluator {
+	return HandEvaluator{rules: g.handRules(), closeThreshold: g.RuleCloseThreshold}
+}
+
+// HandEvaluator returns an evaluator for the game's rules.
+func (c ClientGameState) HandEvaluator() HandEvaluator {
+	return HandEvaluator{
+		rules:          handRules{aceWraparound: c.RuleAceWraparound, deckType: c.RuleDeckType, handSize: c.RuleHandSize},
+		closeThreshold: c.RuleCloseThreshold,
+	}
+}
+
+// BestPartition returns the non-overlapping runs and sets that leave the fewest penalty
+// points ungrouped (the deadwood), and those points.
+func (e HandEvaluator) BestPartition(hand []Card) ([][]Card, int) {
+	return e.rules.bestPartition(hand)
+}
+
+// SuggestDiscard returns the card whose discard leaves the fewest penalty points, preferring
+// the highest card on ties. It returns the zero Card for an empty hand.
+func (e HandEvaluator) SuggestDiscard(hand []Card) Card {
+	var suggestion Card
+	cards, _ := e.rules.bestDiscard(hand)
+	for i, card := range cards {
+		if i == 0 || card.PenaltyValue() > suggestion.PenaltyValue() {
+			suggestion = card
+		}
+	}
+	return suggestion
+}
+
+// CanCloseAfterDiscard returns true if the hand can close the round by discarding the card.
+func (e HandEvaluator) CanCloseAfterDiscard(hand []Card, card Card) bool {
+	for _, discard := range e.rules.closingDiscards(hand, e.closeThreshold) {
+		if discard == card {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package chinchon
+
+// Event types
+const (
+	// EVENT_CARD_MOVED is a card moving between the deck, the discard pile and hands.
+	EVENT_CARD_MOVED = "card_moved"
+
+	// EVENT_ROUND_CLOSED is a player closing the round (PlayerID is -1 if nobody closed it).
+	EVENT_ROUND_CLOSED = "round_closed"
+
+	// EVENT_ROUND_STARTED is a new round starting, before its cards are dealt.
+	EVENT_ROUND_STARTED = "round_started"
+)
+
+// Card locations for EVENT_CARD_MOVED
+const (
+	LOCATION_DECK         = "deck"
+	LOCATION_DISCARD_PILE = "discard_pile"
+	LOCATION_HAND         = "hand"
+	LOCATION_GROUPS       = "groups" // The closing player's groups, in the lay-off phase
+)
+
+// Event is a fine-grained change to the table, for graphical clients to animate the
+// transition between two states instead of re-rendering them.
+type Event struct {
+	Type string `json:"type"`
+
+	// PlayerID is the owner of the hand the card moves from or to, or the player who
+	// triggered the event.
+	PlayerID int `json:"playerID"`
+
+	// Card is the moved card. It's nil for clients who can't see it, e.g. a card drawn from
+	// the deck by someone else.
+	Card *Card `json:"card,omitempty"`
+
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+
+	// FaceUp is true if the card is visible to everyone while it moves.
+	FaceUp bool `json:"faceUp"`
+}
+
+func (g *GameState) emitCardMoved(playerID int, card Card, from, to string, faceUp bool) {
+	g.Events = append(g.Events, Event{Type: EVENT_CARD_MOVED, PlayerID: playerID, Card: &card, From: from, To: to, FaceUp: faceUp})
+}
+
+func (g *GameState) emit(eventType string, playerID int) {
+	g.Events = append(g.Events, Event{Type: eventType, PlayerID: playerID})
+}
+
+// eventsFor returns the events as seen by the given player, hiding the face down cards
+// that aren't theirs.
+func (g GameState) eventsFor(youPlayerID int) []Event {
+	events := []Event{}
+	for _, event := range g.Events {
+		if event.Card != nil && !event.FaceUp && event.PlayerID != youPlayerID {
+			event.Card = nil
+		}
+		events = append(events, event)
+	}
+	return events
+}
@@ -0,0 +1,67 @@
+package chinchon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// Fingerprint returns a hash of the normalized game state, including the draw pile but not
+// the turn's start time or the players' profiles. Two GameStates with the same fingerprint are the same game at the
+// same point.
+func (g GameState) Fingerprint() string {
+	normalized := struct {
+		GameState
+		DrawPile []Card `json:"drawPile"`
+	}{GameState: g, DrawPile: g.DrawPile.cards}
+
+	players := map[int]*Player{}
+	for id, player := range g.Players {
+		p := *player
+		p.Name, p.Avatar = "", ""
+		if p.Hand != nil {
+			hand := Hand{Cards: sortedCards(p.Hand.Cards)}
+			p.Hand = &hand
+		}
+		players[id] = &p
+	}
+	normalized.Players = players
+	normalized.TurnStartedAt = time.Time{}
+
+	return hashJSON(normalized)
+}
+
+// Fingerprint returns a hash of the normalized client game state, ignoring its StateHash
+// field and the turn's remaining time. Clients that keep their own copy of the state can compare it with the StateHash
+// sent by the server, and ask for the full state again on mismatch.
+func (c ClientGameState) Fingerprint() string {
+	c.StateHash = ""
+	c.TurnTimeRemainingMs = 0
+	c.YourHand = sortedCards(c.YourHand)
+	return hashJSON(c)
+}
+
+// IsInSync returns true if the state matches the StateHash it was sent with.
+func (c ClientGameState) IsInSync() bool {
+	return c.StateHash == c.Fingerprint()
+}
+
+func sortedCards(cards []Card) []Card {
+	sorted := append([]Card{}, cards...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Suit != sorted[j].Suit {
+			return sorted[i].Suit < sorted[j].Suit
+		}
+		return sorted[i].Number < sorted[j].Number
+	})
+	return sorted
+}
+
+// hashJSON hashes the JSON encoding of v, which is canonical since encoding/json sorts map keys.
+func hashJSON(v any) string {
+	bs, _ := json.Marshal(v)
+	sum := sha256.Sum256(bs)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,103 @@
+package chinchon
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HandHistory renders the game as a human-readable Markdown hand history, with every
+// round's deal, plays and scoring, and the final result, e.g. for sharing it in forums and
+// chats. Games in progress are rendered up to their current point.
+func (c Catalog) HandHistory(g GameState) string {
+	var b strings.Builder
+	playerIDs := make([]int, len(g.Players))
+	for id := range playerIDs {
+		playerIDs[id] = id
+	}
+	name := func(playerID int) string {
+		return c.message("player", playerID+1)
+	}
+
+	fmt.Fprintf(&b, "# %v\n\n", c.message("history.title"))
+	players := []string{}
+	for _, id := range playerIDs {
+		if g.RuleTeams {
+			players = append(players, c.message("history.playerInTeam", name(id), g.Players[id].Team+1))
+		} else {
+			players = append(players, name(id))
+		}
+	}
+	fmt.Fprintf(&b, "%v\n", c.message("history.players", strings.Join(players, ", ")))
+
+	// Scores before each round are worked out backwards from the current ones, since games
+	// may not start from 0 (e.g. scenarios).
+	scores := map[int]int{}
+	for _, id := range playerIDs {
+		scores[id] = g.Players[id].Score
+		for _, round := range g.RoundsLog[1:] {
+			scores[id] -= round.ScoreChanges[id]
+		}
+	}
+
+	for number, round := range g.RoundsLog {
+		if number == 0 {
+			continue // RoundsLog is 1-indexed
+		}
+		fmt.Fprintf(&b, "\n## %v\n\n", c.message("history.round", number))
+
+		fmt.Fprintf(&b, "%v\n\n", c.message("history.dealt"))
+		for _, id := range playerIDs {
+			if hand := round.HandsDealt[id]; hand != nil {
+				fmt.Fprintf(&b, "- %v: %v\n", name(id), c.Cards(hand.Cards))
+			}
+		}
+
+		if len(round.ActionsLog) > 0 {
+			fmt.Fprintf(&b, "\n%v\n\n", c.message("history.plays"))
+		}
+		for i, log := range round.ActionsLog {
+			action, err := DeserializeAction(log.Action)
+			if err != nil {
+				continue
+			}
+			line := c.ActionLog(action, name(log.PlayerID))
+			if log.PlayedByBot {
+				line += " " + c.message("history.bot")
+			}
+			fmt.Fprintf(&b, "%d. %v\n", i+1, line)
+		}
+
+		isLastRound := number == len(g.RoundsLog)-1
+		switch {
+		case round.WasChinchon && g.chinchonEndsGame():
+			fmt.Fprintf(&b, "\n%v\n", c.message("history.chinchon"))
+			continue
+		case round.WasChinchon:
+			fmt.Fprintf(&b, "\n%v\n", c.message("history.chinchonBy", name(round.WinnerPlayerID)))
+		case round.WasResigned:
+			fmt.Fprintf(&b, "\n%v\n", c.message("history.resigned", name(round.LoserPlayerID)))
+			continue
+		case isLastRound && !g.IsRoundFinished:
+			fmt.Fprintf(&b, "\n%v\n", c.message("history.inProgress"))
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n%v\n", c.message("history.table"))
+		fmt.Fprintf(&b, "|---|---|---|---|\n")
+		for _, id := range playerIDs {
+			scores[id] += round.ScoreChanges[id]
+			fmt.Fprintf(&b, "| %v | %d | +%d | %d |\n", name(id), round.PenaltyPoints[id], round.ScoreChanges[id], scores[id])
+		}
+	}
+
+	if g.IsGameEnded {
+		fmt.Fprintf(&b, "\n## %v\n\n", c.message("history.result"))
+		if g.RuleTeams {
+			fmt.Fprintf(&b, "%v\n", c.message("history.teamWinner", g.WinnerTeamID+1))
+		} else {
+			fmt.Fprintf(&b, "%v\n", c.message("history.winner", name(g.WinnerPlayerID)))
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package chinchon
+
+// startLayOff starts the lay-off phase, with the closing player's best groups.
+func (g *GameState) startLayOff(closingPlayerID int) {
+	groups, _ := g.handRules().bestPartition(g.Players[closingPlayerID].Hand.Cards)
+	if g.RuleDeclaredMelds {
+		groups = append([][]Card{}, g.DeclaredGroups...)
+	}
+	g.IsLayingOff = true
+	g.LayOffGroups = groups
+	g.LayOffFinishedPlayerIDs = map[int]bool{}
+	if len(g.layOffPlayerIDs()) == 0 {
+		g.IsLayingOff = false
+		g.scoreRound(closingPlayerID, -1)
+	}
+}
+
+// layOffPlayerIDs returns the players who may still lay off cards: the closing player's
+// opponents who haven't finished.
+func (g GameState) layOffPlayerIDs() []int {
+	playerIDs := []int{}
+	if !g.IsLayingOff {
+		return playerIDs
+	}
+	for playerID := 0; playerID < len(g.Players); playerID++ {
+		if !g.AreTeammates(playerID, g.CurrentRoundClosedByPlayerID) && !g.LayOffFinishedPlayerIDs[playerID] {
+			playerIDs = append(playerIDs, playerID)
+		}
+	}
+	return playerIDs
+}
+
+func (g GameState) canLayOff(playerID int) bool {
+	if g.IsGameEnded {
+		return false
+	}
+	for _, id := range g.layOffPlayerIDs() {
+		if id == playerID {
+			return true
+		}
+	}
+	return false
+}
+
+// isValidGroup returns true if the cards form a run (3 or more consecutive cards of the
+// same suit) or a set (3 or 4 cards of the same number).
+func (r handRules) isValidGroup(cards []Card) bool {
+	if len(cards) < 3 {
+		return false
+	}
+
+	isSet := len(cards) <= 4
+	suits := map[string]bool{}
+	for _, card := range cards {
+		if card.Number != cards[0].Number || suits[card.Suit] {
+			isSet = false
+		}
+		suits[card.Suit] = true
+	}
+	if isSet {
+		return true
+	}
+
+	return r.isRun(cards)
+}
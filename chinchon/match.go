@@ -0,0 +1,92 @@
+package chinchon
+
+import "errors"
+
+var errMatchNotReady = errors.New("the game isn't over, or the match is")
+
+// MatchState is the score of a match.
+type MatchState struct {
+	// BestOf is the number of games ("chicos") of the match, e.g. 3.
+	BestOf int `json:"bestOf"`
+
+	// GameNumber is the game being played, starting from 1.
+	GameNumber int `json:"gameNumber"`
+
+	// GamesWon is a map from team ID (the player ID, without teams) to the games it won.
+	GamesWon map[int]int `json:"gamesWon"`
+
+	IsMatchEnded bool `json:"isMatchEnded"`
+
+	// WinnerTeamID is the team that won the match, -1 if none yet (or on a tie).
+	WinnerTeamID int `json:"winnerTeamID"`
+}
+
+// Match is a series of games, won by the first team to win most of them (e.g. 2 out of
+// 3). Each game is opened by the next player.
+type Match struct {
+	MatchState
+
+	// GameState is the game being played.
+	GameState *GameState
+
+	opts []func(*GameState)
+}
+
+// NewMatch starts a best-of-bestOf match, whose games are created with the given options.
+func NewMatch(bestOf int, opts ...func(*GameState)) *Match {
+	m := &Match{
+		MatchState: MatchState{BestOf: bestOf, GameNumber: 1, GamesWon: map[int]int{}, WinnerTeamID: -1},
+		opts:       opts,
+	}
+	m.startGame()
+	return m
+}
+
+// RunAction runs the action on the current game, and counts it for the match if it ends.
+func (m *Match) RunAction(action Action) error {
+	if err := m.GameState.RunAction(action); err != nil {
+		return err
+	}
+	if m.GameState.IsGameEnded {
+		m.recordGame()
+	}
+	return nil
+}
+
+// NextGame starts the match's next game, once the current one is over.
+func (m *Match) NextGame() error {
+	if !m.GameState.IsGameEnded || m.IsMatchEnded {
+		return errMatchNotReady
+	}
+	m.GameNumber++
+	m.startGame()
+	return nil
+}
+
+func (m *Match) startGame() {
+	opts := append(append([]func(*GameState){}, m.opts...), WithFirstPlayer(m.GameNumber-1))
+	m.GameState = New(opts...)
+	m.GameState.FirstPlayerID %= len(m.GameState.Players)
+	m.GameState.Match = &m.MatchState
+}
+
+// recordGame counts the current game's result, and ends the match once it's decided.
+func (m *Match) recordGame() {
+	m.GamesWon[m.GameState.WinnerTeamID]++
+
+	mostWins, leaders := 0, []int{}
+	for teamID, wins := range m.GamesWon {
+		switch {
+		case wins > mostWins:
+			mostWins, leaders = wins, []int{teamID}
+		case wins == mostWins:
+			leaders = append(leaders, teamID)
+		}
+	}
+	if mostWins > m.BestOf/2 || m.GameNumber == m.BestOf {
+		m.IsMatchEnded = true
+		if len(leaders) == 1 {
+			m.WinnerTeamID = leaders[0]
+		}
+	}
+}
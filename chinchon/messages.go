@@ -0,0 +1,370 @@
+package chinchon
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Locales with a message catalog
+const (
+	LOCALE_EN = "en"
+	LOCALE_ES = "es"
+)
+
+// DefaultLocale is used for unknown locales, missing messages, and the String methods.
+const DefaultLocale = LOCALE_EN
+
+// messages maps each locale to its message templates. Action messages are keyed by
+// "choice.<action name>" (e.g. for menus) and "log.<action name>" (after it's played).
+var messages = map[string]map[string]string{
+	LOCALE_EN: {
+		"card":   "%d of %s",
+		ORO:      "coins",
+		COPA:     "cups",
+		ESPADA:   "swords",
+		BASTO:    "clubs",
+		"you":    "You",
+		"them":   "Opponent",
+		"player": "Player %d",
+
+		"choice." + DRAW_FROM_DECK:         "Draw from deck",
+		"choice." + DRAW_FROM_DISCARD:      "Draw from discard pile",
+		"choice." + DISCARD_CARD:           "Discard %s",
+		"choice." + CLOSE_ROUND:            "Close the round discarding %s",
+		"choice." + CONFIRM_ROUND_FINISHED: "Continue",
+		"choice." + LAY_OFF_CARD:           "Lay off %s",
+		"choice." + FINISH_LAY_OFF:         "Done laying off",
+		"choice." + ACCEPT_REENTER:         "Re-enter the game",
+		"choice." + DECLINE_REENTER:        "Leave the game",
+		"choice." + RESIGN:                 "Resign the game",
+		"choice." + RESIGN_ROUND:           "Resign the round",
+		"choice." + REORDER_HAND:           "Reorder your hand",
+		"choice." + SET_HAND_SORT:          "Sort your hand",
+
+		"log." + DRAW_FROM_DECK:         "%s draws from deck",
+		"log." + DRAW_FROM_DISCARD:      "%s draws from discard pile",
+		"log." + DISCARD_CARD:           "%s discards %s",
+		"log." + CLOSE_ROUND:            "%s closes the round discarding %s",
+		"log." + CONFIRM_ROUND_FINISHED: "%s confirms round finished",
+		"log." + LAY_OFF_CARD:           "%s lays off %s",
+		"log." + FINISH_LAY_OFF:         "%s is done laying off",
+		"log." + ACCEPT_REENTER:         "%s re-enters the game",
+		"log." + DECLINE_REENTER:        "%s leaves the game",
+		"log." + RESIGN:                 "%s resigns the game",
+		"log." + RESIGN_ROUND:           "%s resigns the round",
+		"log." + REORDER_HAND:           "%s reorders their hand",
+		"log." + SET_HAND_SORT:          "%s sorts their hand",
+
+		"describe.round":         "Round %d.",
+		"describe.score":         "%s: %d points",
+		"describe.scores":        "Scores: %s.",
+		"describe.suit":          "%s of %s",
+		"describe.hand":          "You hold %s.",
+		"describe.discard":       "Top of the discard pile: %s.",
+		"describe.discardEmpty":  "The discard pile is empty.",
+		"describe.drawPile":      "%d cards left in the deck.",
+		"describe.lastAction":    "Last play: %s.",
+		"describe.yourTurn":      "It's your turn.",
+		"describe.theirTurn":     "Waiting for %s.",
+		"describe.options":       "You can: %s.",
+		"describe.roundFinished": "The round is finished.",
+		"describe.gameWon":       "The game is over. You won.",
+		"describe.gameLost":      "The game is over. You lost.",
+
+		"history.title":        "Chinchón hand history",
+		"history.players":      "Players: %s",
+		"history.playerInTeam": "%s (team %d)",
+		"history.round":        "Round %d",
+		"history.dealt":        "Dealt:",
+		"history.plays":        "Plays:",
+		"history.bot":          "(bot)",
+		"history.chinchon":     "Chinchón! The game ends.",
+		"history.chinchonBy":   "Chinchón by %s!",
+		"history.resigned":     "%s resigns. The game ends.",
+		"history.inProgress":   "The round is in progress.",
+		"history.table":        "| Player | Penalty | Points | Total |",
+		"history.result":       "Result",
+		"history.winner":       "%s wins.",
+		"history.teamWinner":   "Team %d wins.",
+
+		"reason." + string(REASON_GAME_ENDED):               "The game is over.",
+		"reason." + string(REASON_NOT_YOUR_TURN):            "It's not your turn.",
+		"reason." + string(REASON_MUST_DRAW_FIRST):          "You must draw a card first.",
+		"reason." + string(REASON_ALREADY_DREW):             "You already drew a card.",
+		"reason." + string(REASON_CARD_NOT_IN_HAND):         "You don't have that card.",
+		"reason." + string(REASON_CLOSE_THRESHOLD_EXCEEDED): "You can't close with those cards left ungrouped.",
+		"reason." + string(REASON_TOO_EARLY_TO_CLOSE):       "It's too early in the round to close.",
+		"reason." + string(REASON_ROUND_FINISHED):           "The round is over.",
+		"reason." + string(REASON_ROUND_NOT_FINISHED):       "The round isn't over yet.",
+		"reason." + string(REASON_NOT_POSSIBLE):             "You can't do that now.",
+	},
+	LOCALE_ES: {
+		"card":   "%d de %s",
+		ORO:      "oro",
+		COPA:     "copa",
+		ESPADA:   "espada",
+		BASTO:    "basto",
+		"you":    "Tú",
+		"them":   "Oponente",
+		"player": "Jugador %d",
+
+		"choice." + DRAW_FROM_DECK:         "Robar del mazo",
+		"choice." + DRAW_FROM_DISCARD:      "Robar de la pila de descarte",
+		"choice." + DISCARD_CARD:           "Descartar %s",
+		"choice." + CLOSE_ROUND:            "Cerrar la ronda descartando %s",
+		"choice." + CONFIRM_ROUND_FINISHED: "Continuar",
+		"choice." + LAY_OFF_CARD:           "Acomodar %s",
+		"choice." + FINISH_LAY_OFF:         "Terminar de acomodar",
+		"choice." + ACCEPT_REENTER:         "Reengancharse",
+		"choice." + DECLINE_REENTER:        "Abandonar el juego",
+		"choice." + RESIGN:                 "Rendirse",
+		"choice." + RESIGN_ROUND:           "Abandonar la ronda",
+		"choice." + REORDER_HAND:           "Reordenar tu mano",
+		"choice." + SET_HAND_SORT:          "Ordenar tu mano",
+
+		"log." + DRAW_FROM_DECK:         "%s robó del mazo",
+		"log." + DRAW_FROM_DISCARD:      "%s robó de la pila de descarte",
+		"log." + DISCARD_CARD:           "%s descartó %s",
+		"log." + CLOSE_ROUND:            "%s cerró la ronda descartando %s",
+		"log." + CONFIRM_ROUND_FINISHED: "%s confirmó el fin de la ronda",
+		"log." + LAY_OFF_CARD:           "%s acomodó %s",
+		"log." + FINISH_LAY_OFF:         "%s terminó de acomodar",
+		"log." + ACCEPT_REENTER:         "%s se reenganchó",
+		"log." + DECLINE_REENTER:        "%s abandonó el juego",
+		"log." + RESIGN:                 "%s se rindió",
+		"log." + RESIGN_ROUND:           "%s abandonó la ronda",
+		"log." + REORDER_HAND:           "%s reordenó su mano",
+		"log." + SET_HAND_SORT:          "%s ordenó su mano",
+
+		"describe.round":         "Ronda %d.",
+		"describe.score":         "%s: %d puntos",
+		"describe.scores":        "Puntajes: %s.",
+		"describe.suit":          "%s de %s",
+		"describe.hand":          "Tienes %s.",
+		"describe.discard":       "Arriba de la pila de descarte: %s.",
+		"describe.discardEmpty":  "La pila de descarte está vacía.",
+		"describe.drawPile":      "Quedan %d cartas en el mazo.",
+		"describe.lastAction":    "Última jugada: %s.",
+		"describe.yourTurn":      "Es tu turno.",
+		"describe.theirTurn":     "Esperando a %s.",
+		"describe.options":       "Puedes: %s.",
+		"describe.roundFinished": "La ronda terminó.",
+		"describe.gameWon":       "El juego terminó. Ganaste.",
+		"describe.gameLost":      "El juego terminó. Perdiste.",
+
+		"history.title":        "Historial de Chinchón",
+		"history.players":      "Jugadores: %s",
+		"history.playerInTeam": "%s (equipo %d)",
+		"history.round":        "Ronda %d",
+		"history.dealt":        "Reparto:",
+		"history.plays":        "Jugadas:",
+		"history.bot":          "(bot)",
+		"history.chinchon":     "¡Chinchón! Termina el juego.",
+		"history.chinchonBy":   "¡Chinchón de %s!",
+		"history.resigned":     "%s se rindió. Termina el juego.",
+		"history.inProgress":   "La ronda está en curso.",
+		"history.table":        "| Jugador | Penalización | Puntos | Total |",
+		"history.result":       "Resultado",
+		"history.winner":       "Gana %s.",
+		"history.teamWinner":   "Gana el equipo %d.",
+
+		"reason." + string(REASON_GAME_ENDED):               "El juego terminó.",
+		"reason." + string(REASON_NOT_YOUR_TURN):            "No es tu turno.",
+		"reason." + string(REASON_MUST_DRAW_FIRST):          "Primero tienes que robar una carta.",
+		"reason." + string(REASON_ALREADY_DREW):             "Ya robaste una carta.",
+		"reason." + string(REASON_CARD_NOT_IN_HAND):         "No tienes esa carta.",
+		"reason." + string(REASON_CLOSE_THRESHOLD_EXCEEDED): "No puedes cerrar con esas cartas sin agrupar.",
+		"reason." + string(REASON_TOO_EARLY_TO_CLOSE):       "Todavía es muy pronto en la ronda para cerrar.",
+		"reason." + string(REASON_ROUND_FINISHED):           "La ronda terminó.",
+		"reason." + string(REASON_ROUND_NOT_FINISHED):       "La ronda todavía no terminó.",
+		"reason." + string(REASON_NOT_POSSIBLE):             "No puedes hacer eso ahora.",
+	},
+}
+
+// Catalog provides the human-readable text for cards and actions in a locale, so that
+// every client shows the same text.
+type Catalog struct {
+	Locale string
+}
+
+// NewCatalog returns the catalog for the locale, or for DefaultLocale if it's unknown.
+func NewCatalog(locale string) Catalog {
+	if _, ok := messages[locale]; !ok {
+		locale = DefaultLocale
+	}
+	return Catalog{Locale: locale}
+}
+
+// Locales returns the locales with a message catalog.
+func Locales() []string {
+	return []string{LOCALE_EN, LOCALE_ES}
+}
+
+// Card returns the card's name, e.g. "4 of coins".
+func (c Catalog) Card(card Card) string {
+	return c.message("card", card.Number, c.message(card.Suit))
+}
+
+// Cards returns the names of the cards, separated by commas.
+func (c Catalog) Cards(cards []Card) string {
+	names := []string{}
+	for _, card := range cards {
+		names = append(names, c.Card(card))
+	}
+	return strings.Join(names, ", ")
+}
+
+// PlayerName returns how to call playerID to youPlayerID, e.g. "You" or "Opponent". In
+// games with more than 2 players, other players are numbered from 1.
+func (c Catalog) PlayerName(playerID, youPlayerID, playerCount int) string {
+	switch {
+	case playerID == youPlayerID:
+		return c.message("you")
+	case playerCount > 2:
+		return c.message("player", playerID+1)
+	default:
+		return c.message("them")
+	}
+}
+
+// PlayerNameIn returns how to call playerID to the player the state is for: "You", or their
+// display name if they have one, or else as PlayerName does.
+func (c Catalog) PlayerNameIn(state ClientGameState, playerID int) string {
+	if playerID != state.YouPlayerID && playerID >= 0 && playerID < len(state.Players) && state.Players[playerID].Name != "" {
+		return state.Players[playerID].Name
+	}
+	return c.PlayerName(playerID, state.YouPlayerID, len(state.Players))
+}
+
+// ActionChoice describes the action as an option to choose, e.g. "Draw from deck".
+func (c Catalog) ActionChoice(action Action) string {
+	if card, ok := actionCard(action); ok {
+		return c.message("choice."+action.GetName(), c.Card(card))
+	}
+	return c.message("choice." + action.GetName())
+}
+
+// ActionLog describes the action as played by who, e.g. "Opponent draws from deck".
+func (c Catalog) ActionLog(action Action, who string) string {
+	if card, ok := actionCard(action); ok {
+		return c.message("log."+action.GetName(), who, c.Card(card))
+	}
+	return c.message("log."+action.GetName(), who)
+}
+
+// RejectionReason explains why an action was rejected, e.g. "It's not your turn.".
+func (c Catalog) RejectionReason(reason RejectionReason) string {
+	return c.message("reason." + string(reason))
+}
+
+// DescribeState describes the whole situation in plain language, for screen readers and
+// text-to-speech clients, e.g. "Round 2. Scores: You: 12 points, Opponent: 30 points. You
+// hold 1, 3, 4 of coins; 7 of cups. ...".
+func (c Catalog) DescribeState(state ClientGameState) string {
+	sentences := []string{c.message("describe.round", state.RoundNumber)}
+
+	scores := []string{}
+	for _, player := range state.Players {
+		name := c.PlayerNameIn(state, player.PlayerID)
+		scores = append(scores, c.message("describe.score", name, player.Score))
+	}
+	sentences = append(sentences, c.message("describe.scores", strings.Join(scores, ", ")))
+
+	if state.IsGameEnded {
+		if state.YourTeamID == state.WinnerTeamID {
+			return strings.Join(append(sentences, c.message("describe.gameWon")), " ")
+		}
+		return strings.Join(append(sentences, c.message("describe.gameLost")), " ")
+	}
+
+	sentences = append(sentences, c.message("describe.hand", c.describeHand(state.YourHand)))
+	if state.TopDiscardCard != nil {
+		sentences = append(sentences, c.message("describe.discard", c.Card(*state.TopDiscardCard)))
+	} else {
+		sentences = append(sentences, c.message("describe.discardEmpty"))
+	}
+	sentences = append(sentences, c.message("describe.drawPile", state.DrawPileSize))
+
+	if log := state.LastActionLog; log != nil {
+		if action, err := DeserializeAction(log.Action); err == nil {
+			who := c.PlayerNameIn(state, log.PlayerID)
+			sentences = append(sentences, c.message("describe.lastAction", c.ActionLog(action, who)))
+		}
+	}
+
+	switch {
+	case state.IsRoundFinished:
+		sentences = append(sentences, c.message("describe.roundFinished"))
+	case state.TurnPlayerID == state.YouPlayerID:
+		sentences = append(sentences, c.message("describe.yourTurn"))
+	default:
+		who := c.PlayerNameIn(state, state.TurnPlayerID)
+		sentences = append(sentences, c.message("describe.theirTurn", who))
+	}
+
+	choices := []string{}
+	actions, _ := state.Actions()
+	for _, action := range actions {
+		choices = append(choices, c.ActionChoice(action))
+	}
+	if len(choices) > 0 {
+		sentences = append(sentences, c.message("describe.options", strings.Join(choices, "; ")))
+	}
+
+	return strings.Join(sentences, " ")
+}
+
+// describeHand lists the hand grouped by suit, in order, e.g. "1, 3, 4 of coins; 7 of cups".
+func (c Catalog) describeHand(hand []Card) string {
+	bySuit := []string{}
+	for _, suit := range []string{ORO, COPA, ESPADA, BASTO} {
+		numbers := []string{}
+		for _, card := range sortedCards(hand) {
+			if card.Suit == suit {
+				numbers = append(numbers, fmt.Sprint(card.Number))
+			}
+		}
+		if len(numbers) > 0 {
+			bySuit = append(bySuit, c.message("describe.suit", strings.Join(numbers, ", "), c.message(suit)))
+		}
+	}
+	return strings.Join(bySuit, "; ")
+}
+
+// message formats the message with the given key, falling back to DefaultLocale and
+// then to the key itself.
+func (c Catalog) message(key string, args ...any) string {
+	template, ok := messages[c.Locale][key]
+	if !ok {
+		template, ok = messages[DefaultLocale][key]
+	}
+	if !ok {
+		// E.g. "Player 0 some action" for an action without messages
+		words := []string{}
+		for _, arg := range args {
+			words = append(words, fmt.Sprint(arg))
+		}
+		name := key[strings.Index(key, ".")+1:]
+		return strings.Join(append(words, strings.ReplaceAll(name, "_", " ")), " ")
+	}
+	return fmt.Sprintf(template, args...)
+}
+
+// actionCard returns the card an action is about, if any.
+func actionCard(action Action) (Card, bool) {
+	switch a := action.(type) {
+	case ActionDiscardCard:
+		return a.Card, true
+	case *ActionDiscardCard:
+		return a.Card, true
+	case ActionClose:
+		return a.Card, true
+	case *ActionClose:
+		return a.Card, true
+	case ActionLayOffCard:
+		return a.Card, true
+	case *ActionLayOffCard:
+		return a.Card, true
+	}
+	return Card{}, false
+}
@@ -0,0 +1,7 @@
+//go:build !chinchondebug
+// +build !chinchondebug
+
+package chinchon
+
+// debugValidation is only true in builds with the chinchondebug tag (see WithValidation).
+const debugValidation = false
@@ -0,0 +1,421 @@
+package chinchon
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The notation is a compact, human-readable text format for whole games, similar to chess'
+// PGN, for sharing, archiving and re-importing them. It starts with the game's rules as tags,
+// one per line, e.g.
+//
+//	[Players "2"]
+//	[MaxPoints "100"]
+//
+// followed by the rounds, each one with the deck it was dealt from (from the top, and with
+// verifiable shuffles, the seed it was derived from and the commitment to it), the draw
+// piles made of the discard pile when it ran out, if any, and the moves, e.g.
+//
+//	1. deck 4c 12e 1o ... seed=9f2c... commitment=51ab...
+//	1. refill 7b 3c ...
+//	1. 0:D 0:x4c 1:P 1:x12e~ 0:C7e
+//
+// Cards are written as their number and the initial of their suit (o, c, e or b), and moves
+// as the player's ID, a colon, and the move: D draws from the deck, P picks the top discarded
+// card, x discards a card, C closes by discarding a card (followed by =, and the declared
+// groups, separated by / with their cards separated by dots, with declared melds), L lays a
+// card off (followed by @ and the group's index), F finishes laying off, R resigns, RR
+// resigns the round, A accepts to re-enter and N declines. Moves played by a bot standing in
+// for the player end with ~. Lines starting with ; are comments.
+
+// notationSuits are the suits' initials in the notation.
+var notationSuits = map[string]string{ORO: "o", COPA: "c", ESPADA: "e", BASTO: "b"}
+
+// notationTag is a rule written as a tag, and how to set it when importing a game.
+type notationTag struct {
+	name   string
+	value  func(g GameState) string
+	option func(value string) (func(*GameState), error)
+}
+
+func intTag(name string, get func(g GameState) int, set func(gs *GameState, n int)) notationTag {
+	return notationTag{
+		name:  name,
+		value: func(g GameState) string { return strconv.Itoa(get(g)) },
+		option: func(value string) (func(*GameState), error) {
+			n, err := strconv.Atoi(value)
+			return func(gs *GameState) { set(gs, n) }, err
+		},
+	}
+}
+
+func boolTag(name string, get func(g GameState) bool, set func(gs *GameState)) notationTag {
+	return notationTag{
+		name:  name,
+		value: func(g GameState) string { return strconv.FormatBool(get(g)) },
+		option: func(value string) (func(*GameState), error) {
+			b, err := strconv.ParseBool(value)
+			return func(gs *GameState) {
+				if b {
+					set(gs)
+				}
+			}, err
+		},
+	}
+}
+
+func stringTag(name string, get func(g GameState) string, set func(gs *GameState, s string)) notationTag {
+	return notationTag{
+		name:   name,
+		value:  get,
+		option: func(value string) (func(*GameState), error) { return func(gs *GameState) { set(gs, value) }, nil },
+	}
+}
+
+// notationTags are the rules written in the notation, in the order their options are applied.
+var notationTags = []notationTag{
+	intTag("Players", func(g GameState) int { return len(g.Players) }, func(gs *GameState, n int) { WithPlayers(n)(gs) }),
+	boolTag("Teams", func(g GameState) bool { return g.RuleTeams }, func(gs *GameState) { WithTeams()(gs) }),
+	intTag("FirstPlayer", func(g GameState) int { return g.FirstPlayerID }, func(gs *GameState, n int) { gs.FirstPlayerID = n }),
+	stringTag("DeckType", func(g GameState) string { return g.RuleDeckType }, func(gs *GameState, s string) { WithDeckType(s)(gs) }),
+	intTag("HandSize", func(g GameState) int { return g.RuleHandSize }, func(gs *GameState, n int) { WithHandSize(n)(gs) }),
+	intTag("MaxPoints", func(g GameState) int { return g.RuleMaxPoints }, func(gs *GameState, n int) { gs.RuleMaxPoints = n }),
+	intTag("MaxRounds", func(g GameState) int { return g.RuleMaxRounds }, func(gs *GameState, n int) { gs.RuleMaxRounds = n }),
+	intTag("CloseThreshold", func(g GameState) int { return g.RuleCloseThreshold }, func(gs *GameState, n int) { gs.RuleCloseThreshold = n }),
+	stringTag("CloseBonusMode", func(g GameState) string { return g.RuleCloseBonusMode }, func(gs *GameState, s string) { gs.RuleCloseBonusMode = s }),
+	stringTag("CloseTieMode", func(g GameState) string { return g.RuleCloseTieMode }, func(gs *GameState, s string) { gs.RuleCloseTieMode = s }),
+	stringTag("ChinchonMode", func(g GameState) string { return g.RuleChinchonMode }, func(gs *GameState, s string) { gs.RuleChinchonMode = s }),
+	boolTag("Reenter", func(g GameState) bool { return g.RuleReenter }, func(gs *GameState) { gs.RuleReenter = true }),
+	intTag("ResignRoundPenalty", func(g GameState) int { return g.RuleResignRoundPenalty }, func(gs *GameState, n int) { gs.RuleResignRoundPenalty = n }),
+	intTag("MinTurnsBeforeClose", func(g GameState) int { return g.RuleMinTurnsBeforeClose }, func(gs *GameState, n int) { gs.RuleMinTurnsBeforeClose = n }),
+	boolTag("AceWraparound", func(g GameState) bool { return g.RuleAceWraparound }, func(gs *GameState) { gs.RuleAceWraparound = true }),
+	boolTag("DeclaredMelds", func(g GameState) bool { return g.RuleDeclaredMelds }, func(gs *GameState) { gs.RuleDeclaredMelds = true }),
+	boolTag("LayOff", func(g GameState) bool { return g.RuleLayOff }, func(gs *GameState) { gs.RuleLayOff = true }),
+	boolTag("VerifiableShuffle", func(g GameState) bool { return g.RuleVerifiableShuffle }, func(gs *GameState) { WithVerifiableShuffle()(gs) }),
+}
+
+// ExportNotation writes the game in the notation. Games started from a scenario can't be
+// exported, since they weren't dealt from a deck.
+func ExportNotation(g *GameState) (string, error) {
+	var b strings.Builder
+	for _, tag := range notationTags {
+		fmt.Fprintf(&b, "[%v %q]\n", tag.name, tag.value(*g))
+	}
+	result := "*"
+	if g.IsGameEnded {
+		result = strconv.Itoa(g.WinnerTeamID)
+	}
+	fmt.Fprintf(&b, "[WinnerTeam %q]\n", result)
+
+	for number, round := range g.RoundsLog {
+		if number == 0 {
+			continue // RoundsLog is 1-indexed
+		}
+		if round.DeckDealt == nil {
+			return "", fmt.Errorf("round %d: %w", number, errMissingDeck)
+		}
+		fmt.Fprintf(&b, "\n%d. deck %v", number, formatNotationCards(round.DeckDealt, " "))
+		if round.ShuffleSeed != "" {
+			fmt.Fprintf(&b, " seed=%v commitment=%v", round.ShuffleSeed, round.ShuffleCommitment)
+		}
+		b.WriteString("\n")
+		for _, refill := range round.Refills {
+			fmt.Fprintf(&b, "%d. refill %v\n", number, formatNotationCards(refill, " "))
+		}
+
+		moves := []string{}
+		for i, log := range round.ActionsLog {
+			action, err := DeserializeAction(log.Action)
+			if err != nil {
+				return "", fmt.Errorf("round %d, action %d: %w", number, i+1, err)
+			}
+			move, err := formatNotationMove(action)
+			if err != nil {
+				return "", fmt.Errorf("round %d, action %d: %w", number, i+1, err)
+			}
+			if log.PlayedByBot {
+				move += "~"
+			}
+			moves = append(moves, move)
+		}
+		// Ten moves per line, to keep lines short
+		for start := 0; start < len(moves); start += 10 {
+			end := min(start+10, len(moves))
+			fmt.Fprintf(&b, "%d. %v\n", number, strings.Join(moves[start:end], " "))
+		}
+	}
+	return b.String(), nil
+}
+
+// ParseNotation reads a game written in the notation, and replays it.
+func ParseNotation(r io.Reader) (gs *GameState, err error) {
+	tagOpts := map[string]func(*GameState){}
+	rounds := []*RoundLog{{}}
+	tags := map[string]notationTag{}
+	for _, tag := range notationTags {
+		tags[tag.name] = tag
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, "["):
+			name, value, ok := parseNotationTag(line)
+			if !ok {
+				return nil, fmt.Errorf("line %d: invalid tag %v", lineNumber, line)
+			}
+			tag, ok := tags[name]
+			if !ok {
+				continue // e.g. the result, or tags from other tools
+			}
+			opt, err := tag.option(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid %v: %w", lineNumber, name, err)
+			}
+			tagOpts[name] = opt
+		default:
+			if err := parseNotationRoundLine(line, &rounds); err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	opts := []func(*GameState){}
+	for _, tag := range notationTags {
+		if opt, ok := tagOpts[tag.name]; ok {
+			opts = append(opts, opt)
+		}
+	}
+
+	// Options panic on invalid rules
+	defer func() {
+		if r := recover(); r != nil {
+			gs, err = nil, fmt.Errorf("invalid rules: %v", r)
+		}
+	}()
+	gs, err = Replay(rounds, opts...)
+	if err != nil {
+		return nil, err
+	}
+	// Replaying derives the commitments from the decks and seeds, so a tampered deck shows
+	for number, round := range rounds {
+		if round.ShuffleCommitment != "" && round.ShuffleCommitment != gs.RoundsLog[number].ShuffleCommitment {
+			return nil, fmt.Errorf("round %d: the deck doesn't match the commitment", number)
+		}
+	}
+	return gs, nil
+}
+
+func parseNotationTag(line string) (name, value string, ok bool) {
+	if !strings.HasSuffix(line, "]") {
+		return "", "", false
+	}
+	name, quoted, ok := strings.Cut(strings.TrimSpace(line[1:len(line)-1]), " ")
+	if !ok {
+		return "", "", false
+	}
+	value, err := strconv.Unquote(strings.TrimSpace(quoted))
+	return name, value, err == nil
+}
+
+// parseNotationRoundLine parses a line of a round, adding the round to rounds if it's new.
+func parseNotationRoundLine(line string, rounds *[]*RoundLog) error {
+	prefix, rest, _ := strings.Cut(line, " ")
+	number, err := strconv.Atoi(strings.TrimSuffix(prefix, "."))
+	if err != nil || !strings.HasSuffix(prefix, ".") {
+		return fmt.Errorf("expected a round number, got %v", prefix)
+	}
+	if number == len(*rounds) {
+		*rounds = append(*rounds, &RoundLog{})
+	}
+	if number != len(*rounds)-1 {
+		return fmt.Errorf("round %d is out of order", number)
+	}
+	round := (*rounds)[number]
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return nil
+	}
+	switch fields[0] {
+	case "deck":
+		cardFields := []string{}
+		for _, field := range fields[1:] {
+			if seed, ok := strings.CutPrefix(field, "seed="); ok {
+				round.ShuffleSeed = seed
+			} else if commitment, ok := strings.CutPrefix(field, "commitment="); ok {
+				round.ShuffleCommitment = commitment
+			} else {
+				cardFields = append(cardFields, field)
+			}
+		}
+		cards, err := parseNotationCards(cardFields)
+		round.DeckDealt = cards
+		return err
+	case "refill":
+		cards, err := parseNotationCards(fields[1:])
+		round.Refills = append(round.Refills, cards)
+		return err
+	}
+	for _, field := range fields {
+		move, playedByBot := strings.CutSuffix(field, "~")
+		action, err := parseNotationMove(move)
+		if err != nil {
+			return err
+		}
+		round.ActionsLog = append(round.ActionsLog, ActionLog{
+			PlayerID:    action.GetPlayerID(),
+			Action:      SerializeAction(action),
+			PlayedByBot: playedByBot,
+		})
+	}
+	return nil
+}
+
+func formatNotationCard(card Card) string {
+	return strconv.Itoa(card.Number) + notationSuits[card.Suit]
+}
+
+func formatNotationCards(cards []Card, separator string) string {
+	formatted := []string{}
+	for _, card := range cards {
+		formatted = append(formatted, formatNotationCard(card))
+	}
+	return strings.Join(formatted, separator)
+}
+
+func parseNotationCard(s string) (Card, error) {
+	if len(s) < 2 {
+		return Card{}, fmt.Errorf("invalid card %v", s)
+	}
+	number, err := strconv.Atoi(s[:len(s)-1])
+	for suit, initial := range notationSuits {
+		if initial == s[len(s)-1:] && err == nil {
+			return Card{Suit: suit, Number: number}, nil
+		}
+	}
+	return Card{}, fmt.Errorf("invalid card %v", s)
+}
+
+func parseNotationCards(fields []string) ([]Card, error) {
+	cards := []Card{}
+	for _, field := range fields {
+		card, err := parseNotationCard(field)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, card)
+	}
+	return cards, nil
+}
+
+// formatNotationMove writes a logged action as a move.
+func formatNotationMove(action Action) (string, error) {
+	move := ""
+	switch a := action.(type) {
+	case *ActionDrawFromDeck:
+		move = "D"
+	case *ActionDrawFromDiscard:
+		move = "P"
+	case *ActionDiscardCard:
+		move = "x" + formatNotationCard(a.Card)
+	case *ActionClose:
+		move = "C" + formatNotationCard(a.Card)
+		if len(a.Groups) > 0 {
+			groups := []string{}
+			for _, group := range a.Groups {
+				groups = append(groups, formatNotationCards(group, "."))
+			}
+			move += "=" + strings.Join(groups, "/")
+		}
+	case *ActionLayOffCard:
+		move = fmt.Sprintf("L%v@%d", formatNotationCard(a.Card), a.GroupIndex)
+	case *ActionFinishLayOff:
+		move = "F"
+	case *ActionResign:
+		move = "R"
+	case *ActionResignRound:
+		move = "RR"
+	case *ActionAcceptReenter:
+		move = "A"
+	case *ActionDeclineReenter:
+		move = "N"
+	default:
+		return "", fmt.Errorf("action %v has no notation", action.GetName())
+	}
+	return fmt.Sprintf("%d:%v", action.GetPlayerID(), move), nil
+}
+
+// parseNotationMove reads a move as an action.
+func parseNotationMove(s string) (Action, error) {
+	prefix, move, ok := strings.Cut(s, ":")
+	playerID, err := strconv.Atoi(prefix)
+	if !ok || err != nil || move == "" {
+		return nil, fmt.Errorf("invalid move %v", s)
+	}
+
+	switch move {
+	case "D":
+		return NewActionDrawFromDeck(playerID), nil
+	case "P":
+		return NewActionDrawFromDiscard(playerID), nil
+	case "F":
+		return NewActionFinishLayOff(playerID), nil
+	case "R":
+		return NewActionResign(playerID), nil
+	case "RR":
+		return NewActionResignRound(playerID), nil
+	case "A":
+		return NewActionAcceptReenter(playerID), nil
+	case "N":
+		return NewActionDeclineReenter(playerID), nil
+	}
+
+	switch move[0] {
+	case 'x':
+		card, err := parseNotationCard(move[1:])
+		if err != nil {
+			return nil, err
+		}
+		return NewActionDiscardCard(card, playerID), nil
+	case 'C':
+		cardPart, groupsPart, hasGroups := strings.Cut(move[1:], "=")
+		card, err := parseNotationCard(cardPart)
+		if err != nil {
+			return nil, err
+		}
+		action := NewActionClose(card, playerID)
+		if hasGroups {
+			for _, group := range strings.Split(groupsPart, "/") {
+				cards, err := parseNotationCards(strings.Split(group, "."))
+				if err != nil {
+					return nil, err
+				}
+				action.(*ActionClose).Groups = append(action.(*ActionClose).Groups, cards)
+			}
+		}
+		return action, nil
+	case 'L':
+		cardPart, indexPart, _ := strings.Cut(move[1:], "@")
+		card, err := parseNotationCard(cardPart)
+		if err != nil {
+			return nil, err
+		}
+		groupIndex, err := strconv.Atoi(indexPart)
+		if err != nil {
+			return nil, fmt.Errorf("invalid move %v", s)
+		}
+		return NewActionLayOffCard(card, groupIndex, playerID), nil
+	}
+	return nil, fmt.Errorf("invalid move %v", s)
+}
@@ -0,0 +1,39 @@
+package chinchon
+
+// Observer is notified as the game progresses, e.g. by a server embedding the engine that
+// needs to react to rounds ending without diffing states. Observers are called
+// synchronously, and must not run actions on the game.
+type Observer interface {
+	// OnAction is called after an action is run, and its consequences (e.g. a new round
+	// starting, or the turn changing) are applied.
+	OnAction(g *GameState, action Action)
+
+	// OnRoundStart is called after a round is dealt, including the first one.
+	OnRoundStart(g *GameState)
+
+	// OnRoundEnd is called once a round is scored, or ends with a Chinchón. Its score
+	// changes are in the round's log.
+	OnRoundEnd(g *GameState, round *RoundLog)
+
+	// OnGameEnd is called once the game has a winner.
+	OnGameEnd(g *GameState)
+}
+
+// WithObserver registers an observer for the game's progress. Observers aren't saved with
+// the game, so they need registering again after Load.
+func WithObserver(observer Observer) func(*GameState) {
+	return func(gs *GameState) {
+		gs.AddObserver(observer)
+	}
+}
+
+// AddObserver registers an observer for the game's progress, e.g. after Load.
+func (g *GameState) AddObserver(observer Observer) {
+	g.observers = append(g.observers, observer)
+}
+
+func (g *GameState) notify(call func(Observer)) {
+	for _, observer := range g.observers {
+		call(observer)
+	}
+}
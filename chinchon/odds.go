@@ -0,0 +1,72 @@
+package chinchon
+
+// Odds returns the probability that the next card drawn from the deck completes the target
+// group (a run or a set) with the cards in your hand, e.g. for hint UIs and bots. Every card
+// you haven't seen (in the deck or in other players' hands) is assumed equally likely to be
+// drawn. It's 1 if your hand already has the group, and 0 if the group isn't valid or needs
+// more than one card.
+func Odds(state ClientGameState, targetGroup []Card) float64 {
+	if !state.HandEvaluator().rules.isValidGroup(targetGroup) {
+		return 0
+	}
+
+	hand := Hand{Cards: state.YourHand}
+	missing := []Card{}
+	for _, card := range targetGroup {
+		if !hand.HasCard(card) {
+			missing = append(missing, card)
+		}
+	}
+	if len(missing) == 0 {
+		return 1
+	}
+	if len(missing) > 1 {
+		return 0
+	}
+
+	unseen := unseenCards(state)
+	for _, card := range unseen {
+		if card == missing[0] {
+			return 1 / float64(len(unseen))
+		}
+	}
+	return 0
+}
+
+// unseenCards returns the cards of the deck the player hasn't seen: the ones in the draw pile
+// or in other players' hidden hands.
+func unseenCards(state ClientGameState) []Card {
+	seen := map[Card]bool{}
+	see := func(cards []Card) {
+		for _, card := range cards {
+			seen[card] = true
+		}
+	}
+
+	see(state.YourHand)
+	see(state.TheirHand)
+	for _, player := range state.Players {
+		see(player.Hand)
+	}
+	for _, group := range append(append([][]Card{}, state.DeclaredGroups...), state.LayOffGroups...) {
+		see(group)
+	}
+	if state.TopDiscardCard != nil {
+		see([]Card{*state.TopDiscardCard})
+	}
+	// Discarded cards are still known, either in the discard pile or in the hand of whoever
+	// picked them, unless they were shuffled back into the draw pile
+	for _, entry := range state.DiscardHistory {
+		if !entry.Reshuffled {
+			see([]Card{entry.Card})
+		}
+	}
+
+	unseen := []Card{}
+	for _, card := range makeCards(state.RuleDeckType, nil) {
+		if !seen[card] {
+			unseen = append(unseen, card)
+		}
+	}
+	return unseen
+}
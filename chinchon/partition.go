@@ -0,0 +1,240 @@
+package chinchon
+
+import "sort"
+
+// handRules are the house rules that decide how a hand's cards can be grouped.
+type handRules struct {
+	// aceWraparound lets runs connect the 12 to the ace, e.g. 11-12-1 (see WithAceWraparound).
+	aceWraparound bool
+
+	// deckType decides which numbers are consecutive, e.g. 7 and 10 without 8s and 9s.
+	deckType string
+
+	// handSize is the number of cards dealt, which a Chinchón must have (see WithHandSize).
+	handSize int
+}
+
+// defaultHandRules are the rules of Hand's methods, which don't know the game's options.
+var defaultHandRules = handRules{deckType: DECK_SPANISH_48, handSize: DefaultHandSize}
+
+// BestPartition returns the non-overlapping runs and sets that leave the fewest penalty
+// points ungrouped, and those points. Unlike ValidGroups, which may return overlapping
+// groups, every card is in at most one group.
+func (h Hand) BestPartition() ([][]Card, int) {
+	return defaultHandRules.bestPartition(h.Cards)
+}
+
+// MinUngroupedCards returns the fewest cards that can't be grouped in the hand, which is
+// what closing the round depends on. It may differ from the ungrouped cards of the
+// BestPartition, e.g. if a single 12 is left ungrouped rather than two aces.
+func (h Hand) MinUngroupedCards() int {
+	_, ungrouped := defaultHandRules.minimumPartition(h.Cards, func(Card) int { return 1 })
+	return ungrouped
+}
+
+// closingDiscards returns the cards the hand may discard to close the round: those that
+// leave at most one ungrouped card, worth no more than threshold.
+func (r handRules) closingDiscards(hand []Card, threshold int) []Card {
+	discards := []Card{}
+	for i, card := range hand {
+		rest := append(append([]Card{}, hand[:i]...), hand[i+1:]...)
+		// Fewest ungrouped cards first, and then the least penalty points among them
+		_, cost := r.minimumPartition(rest, func(c Card) int { return 100 + c.PenaltyValue() })
+		ungrouped, deadwood := cost/100, cost%100
+		if ungrouped == 0 || (ungrouped == 1 && deadwood <= threshold) {
+			discards = append(discards, card)
+		}
+	}
+	return discards
+}
+
+// bestPartition finds the non-overlapping groups that leave the fewest penalty points
+// ungrouped.
+func (r handRules) bestPartition(cards []Card) ([][]Card, int) {
+	return r.minimumPartition(cards, Card.PenaltyValue)
+}
+
+// minimumPartition finds the non-overlapping groups that minimize the total cost of the
+// ungrouped cards, with an exact backtracking search.
+func (r handRules) minimumPartition(cards []Card, cost func(Card) int) ([][]Card, int) {
+	candidates := r.candidateGroups(cards)
+
+	var (
+		bestGroups   [][]Card
+		bestDeadwood = -1
+		used         = make(map[Card]bool)
+		chosen       [][]Card
+	)
+
+	var search func(i int, deadwood int)
+	search = func(i int, deadwood int) {
+		if bestDeadwood != -1 && deadwood >= bestDeadwood {
+			return
+		}
+		for i < len(cards) && used[cards[i]] {
+			i++
+		}
+		if i == len(cards) {
+			bestDeadwood = deadwood
+			bestGroups = append([][]Card{}, chosen...)
+			return
+		}
+
+		card := cards[i]
+		for _, group := range candidates[card] {
+			if !allAvailable(group, used) {
+				continue
+			}
+			for _, c := range group {
+				used[c] = true
+			}
+			chosen = append(chosen, group)
+			search(i+1, deadwood)
+			chosen = chosen[:len(chosen)-1]
+			for _, c := range group {
+				used[c] = false
+			}
+		}
+
+		// Leave the card ungrouped.
+		used[card] = true
+		search(i+1, deadwood+cost(card))
+		used[card] = false
+	}
+	search(0, 0)
+
+	return bestGroups, bestDeadwood
+}
+
+// candidateGroups returns, for each card, every valid run or set that contains it.
+func (r handRules) candidateGroups(cards []Card) map[Card][][]Card {
+	// Runs: every consecutive stretch of 3 or more cards of the same suit.
+	groups := r.runs(cards)
+
+	numberCards := make(map[int][]Card)
+	for _, card := range cards {
+		numberCards[card.Number] = append(numberCards[card.Number], card)
+	}
+
+	// Sets: every combination of 3 or 4 cards of the same number.
+	for _, cs := range numberCards {
+		for _, subset := range subsetsOfSize(cs, 3) {
+			groups = append(groups, subset)
+		}
+		if len(cs) >= 4 {
+			groups = append(groups, append([]Card{}, cs[:4]...))
+		}
+	}
+
+	byCard := make(map[Card][][]Card)
+	for _, group := range groups {
+		for _, card := range group {
+			byCard[card] = append(byCard[card], group)
+		}
+	}
+	return byCard
+}
+
+// runs returns every consecutive stretch of 3 or more cards of the same suit.
+func (r handRules) runs(cards []Card) [][]Card {
+	var runs [][]Card
+
+	suitCards := make(map[string]map[int]bool)
+	for _, card := range cards {
+		if suitCards[card.Suit] == nil {
+			suitCards[card.Suit] = map[int]bool{}
+		}
+		suitCards[card.Suit][card.Number] = true
+	}
+
+	sorted := append([]Card{}, cards...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Number < sorted[j].Number })
+	for _, start := range sorted {
+		numbers := suitCards[start.Suit]
+		run := []Card{start}
+		for len(run) < len(numbers) {
+			next, ok := r.nextInRun(run[len(run)-1].Number)
+			if !ok || !numbers[next] {
+				break
+			}
+			run = append(run, Card{Suit: start.Suit, Number: next})
+			if len(run) >= 3 {
+				runs = append(runs, append([]Card{}, run...))
+			}
+		}
+	}
+	return runs
+}
+
+// nextInRun returns the number that follows n in a run, if any.
+func (r handRules) nextInRun(n int) (int, bool) {
+	numbers := deckNumbers(r.deckType)
+	for i, number := range numbers {
+		if number != n {
+			continue
+		}
+		if i+1 < len(numbers) {
+			return numbers[i+1], true
+		}
+		return numbers[0], r.aceWraparound
+	}
+	return 0, false
+}
+
+// isRun returns true if the cards are a single run.
+func (r handRules) isRun(cards []Card) bool {
+	for _, run := range r.runs(cards) {
+		if len(run) == len(cards) {
+			return true
+		}
+	}
+	return false
+}
+
+// isChinchon returns true if the hand's cards (usually 7) are a single run.
+func (r handRules) isChinchon(cards []Card) bool {
+	return len(cards) == r.handSize && r.isRun(cards)
+}
+
+func subsetsOfSize(cards []Card, size int) [][]Card {
+	if size == 0 {
+		return [][]Card{{}}
+	}
+	var subsets [][]Card
+	for i := 0; i+size <= len(cards); i++ {
+		for _, rest := range subsetsOfSize(cards[i+1:], size-1) {
+			subsets = append(subsets, append([]Card{cards[i]}, rest...))
+		}
+	}
+	return subsets
+}
+
+func allAvailable(group []Card, used map[Card]bool) bool {
+	for _, c := range group {
+		if used[c] {
+			return false
+		}
+	}
+	return true
+}
+
+// isValidDeclaration returns true if, once the player discards the card, the declared groups
+// are valid, non-overlapping groups of their remaining cards that meet the close condition.
+func (g GameState) isValidDeclaration(playerID int, discard Card, groups [][]Card) bool {
+	rest := &Hand{Cards: append([]Card{}, g.Players[playerID].Hand.Cards...)}
+	if len(rest.Cards) != g.RuleHandSize+1 || rest.RemoveCard(discard) != nil {
+		return false
+	}
+
+	for _, group := range groups {
+		if !g.handRules().isValidGroup(group) {
+			return false
+		}
+		for _, card := range group {
+			if rest.RemoveCard(card) != nil {
+				return false // Not in the hand, or in another group
+			}
+		}
+	}
+	return len(rest.Cards) == 0 || (len(rest.Cards) == 1 && rest.Cards[0].PenaltyValue() <= g.RuleCloseThreshold)
+}
@@ -0,0 +1,87 @@
+package chinchon
+
+import (
+	"encoding/json"
+	"math/rand"
+)
+
+// savedGameState is the storage format of a GameState: unlike its regular JSON encoding,
+// which is meant for display, it includes the draw pile.
+type savedGameState struct {
+	SchemaVersion int `json:"schemaVersion"`
+	GameState
+	DrawPile []Card `json:"drawPile"`
+
+	// Seed and SeedDraws restore the random source of a game created with WithSeed.
+	Seed      *int64 `json:"seed,omitempty"`
+	SeedDraws uint64 `json:"seedDraws,omitempty"`
+}
+
+// Save serializes the whole game state, including the order of the draw pile, so that it
+// can be restored with Load.
+//
+// The random source set with WithSeed is saved too, so that rounds dealt after loading
+// are the same as if the game hadn't been saved. One set with WithRandSource can't be:
+// rounds dealt after loading are shuffled randomly.
+func (g GameState) Save() ([]byte, error) {
+	saved := savedGameState{SchemaVersion: SchemaVersion, GameState: g, DrawPile: g.DrawPile.cards}
+	if source := g.DrawPile.seeded; source != nil {
+		saved.Seed, saved.SeedDraws = &source.seed, source.draws
+	}
+	return json.Marshal(saved)
+}
+
+// Load restores a game state serialized with Save, migrating it from older schema versions.
+func Load(bs []byte) (*GameState, error) {
+	bs, err := migrate(bs, gameStateMigrations)
+	if err != nil {
+		return nil, err
+	}
+	var saved savedGameState
+	if err := json.Unmarshal(bs, &saved); err != nil {
+		return nil, err
+	}
+	gs := saved.GameState
+	gs.DrawPile = newDeck()
+	gs.DrawPile.cards = saved.DrawPile
+	gs.DrawPile.deckType = gs.RuleDeckType
+	if saved.Seed != nil {
+		source := newSeededSource(*saved.Seed, saved.SeedDraws)
+		gs.DrawPile.rng = rand.New(source)
+		gs.DrawPile.seeded = source
+	}
+	return &gs, nil
+}
+
+// seededSource is a math/rand source that counts the numbers drawn from it, so that it
+// can be saved as its seed and count, and restored by drawing as many again.
+type seededSource struct {
+	rand.Source64
+	seed  int64
+	draws uint64
+}
+
+// newSeededSource returns a source with the given seed, that already drew the given
+// count of numbers.
+func newSeededSource(seed int64, draws uint64) *seededSource {
+	s := &seededSource{Source64: rand.NewSource(seed).(rand.Source64), seed: seed}
+	for ; s.draws < draws; s.draws++ {
+		s.Source64.Int63()
+	}
+	return s
+}
+
+func (s *seededSource) Int63() int64 {
+	s.draws++
+	return s.Source64.Int63()
+}
+
+func (s *seededSource) Uint64() uint64 {
+	s.draws++
+	return s.Source64.Uint64()
+}
+
+func (s *seededSource) Seed(seed int64) {
+	s.Source64.Seed(seed)
+	s.seed, s.draws = seed, 0
+}
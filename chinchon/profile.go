@@ -0,0 +1,54 @@
+package chinchon
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxNameLength is the maximum length of a player's display name, in characters.
+const MaxNameLength = 24
+
+// maxAvatarLength is the maximum length of a player's avatar, in code points, enough for
+// emoji made of several (e.g. with skin tones, or joined with zero width joiners).
+const maxAvatarLength = 8
+
+var errInvalidProfile = errors.New("invalid profile")
+
+// SetPlayerProfile sets how the player is shown to everyone (see ClientPlayer): their
+// display name, e.g. "Juan", and an optional avatar emoji. Neither affects the game.
+func (g *GameState) SetPlayerProfile(playerID int, name, avatar string) error {
+	if playerID < 0 || playerID >= len(g.Players) {
+		return fmt.Errorf("%w: no player %d", errInvalidProfile, playerID)
+	}
+	if err := ValidateProfile(name, avatar); err != nil {
+		return err
+	}
+	g.Players[playerID].Name, g.Players[playerID].Avatar = name, avatar
+	return nil
+}
+
+// ValidateProfile checks a display name and avatar for SetPlayerProfile: names are up to
+// MaxNameLength printable characters, without surrounding spaces, and avatars are a single
+// emoji, if any.
+func ValidateProfile(name, avatar string) error {
+	if utf8.RuneCountInString(name) > MaxNameLength || strings.TrimSpace(name) != name {
+		return fmt.Errorf("%w: names must be up to %d characters, without surrounding spaces", errInvalidProfile, MaxNameLength)
+	}
+	for _, r := range name {
+		if !unicode.IsPrint(r) {
+			return fmt.Errorf("%w: names must be printable", errInvalidProfile)
+		}
+	}
+	if utf8.RuneCountInString(avatar) > maxAvatarLength {
+		return fmt.Errorf("%w: avatars must be an emoji", errInvalidProfile)
+	}
+	for _, r := range avatar {
+		if !unicode.In(r, unicode.So, unicode.Sk, unicode.Mn) && r != '‍' {
+			return fmt.Errorf("%w: avatars must be an emoji", errInvalidProfile)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package chinchon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Puzzle kinds
+const (
+	// PUZZLE_DRAW asks whether to draw from the deck or take the top discard card.
+	PUZZLE_DRAW = "draw"
+
+	// PUZZLE_DISCARD asks which card to discard (or whether to close) after drawing.
+	PUZZLE_DISCARD = "discard"
+)
+
+// Puzzle is a "find the best play" position. Puzzles are usually loaded from a scenario file
+// with LoadPuzzles.
+type Puzzle struct {
+	// Name is a short title for the puzzle.
+	Name string `json:"name"`
+
+	// Description explains the situation to the player.
+	Description string `json:"description"`
+
+	// Kind is either PUZZLE_DRAW (7 cards in hand) or PUZZLE_DISCARD (8 cards in hand).
+	Kind string `json:"kind"`
+
+	// Hand is the player's hand.
+	Hand []Card `json:"hand"`
+
+	// TopDiscardCard is the card on top of the discard pile.
+	TopDiscardCard Card `json:"topDiscardCard"`
+}
+
+// LoadPuzzles reads a JSON list of puzzles and validates each of them.
+func LoadPuzzles(r io.Reader) ([]Puzzle, error) {
+	var puzzles []Puzzle
+	if err := json.NewDecoder(r).Decode(&puzzles); err != nil {
+		return nil, err
+	}
+	for i, p := range puzzles {
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("puzzle %d (%v): %w", i+1, p.Name, err)
+		}
+	}
+	return puzzles, nil
+}
+
+func (p Puzzle) validate() error {
+	expectedHandSize := map[string]int{PUZZLE_DRAW: 7, PUZZLE_DISCARD: 8}[p.Kind]
+	if expectedHandSize == 0 {
+		return fmt.Errorf("unknown puzzle kind [%v]", p.Kind)
+	}
+	if len(p.Hand) != expectedHandSize {
+		return fmt.Errorf("%v puzzles need %d cards in hand, got %d", p.Kind, expectedHandSize, len(p.Hand))
+	}
+	seen := map[Card]bool{p.TopDiscardCard: true}
+	for _, card := range p.Hand {
+		if seen[card] {
+			return fmt.Errorf("card %v appears more than once", card)
+		}
+		seen[card] = true
+	}
+	return nil
+}
+
+// PossibleActions returns the plays the player may choose from, for player 0.
+func (p Puzzle) PossibleActions() []Action {
+	if p.Kind == PUZZLE_DRAW {
+		return []Action{NewActionDrawFromDeck(0), NewActionDrawFromDiscard(0)}
+	}
+	actions := []Action{}
+	for _, card := range p.Hand {
+		actions = append(actions, NewActionDiscardCard(card, 0))
+	}
+	return append(actions, p.closingActions()...)
+}
+
+// Solve returns every optimal play for the puzzle. There may be more than one when
+// several plays leave the same penalty points.
+func (p Puzzle) Solve() []Action {
+	if p.Kind == PUZZLE_DRAW {
+		_, current := defaultHandRules.bestPartition(p.Hand)
+		_, withDiscard := defaultHandRules.bestDiscard(append(append([]Card{}, p.Hand...), p.TopDiscardCard))
+		if withDiscard < current {
+			return []Action{NewActionDrawFromDiscard(0)}
+		}
+		return []Action{NewActionDrawFromDeck(0)}
+	}
+
+	if closes := p.closingActions(); len(closes) > 0 {
+		return closes
+	}
+	cards, _ := defaultHandRules.bestDiscard(p.Hand)
+	solutions := []Action{}
+	for _, card := range cards {
+		solutions = append(solutions, NewActionDiscardCard(card, 0))
+	}
+	return solutions
+}
+
+// Check returns true if the action is one of the optimal plays.
+func (p Puzzle) Check(action Action) bool {
+	answer := string(SerializeAction(action))
+	for _, solution := range p.Solve() {
+		if string(SerializeAction(solution)) == answer {
+			return true
+		}
+	}
+	return false
+}
+
+// closingActions returns a close for each card that, once discarded, lets the player close
+// the round under the default rules.
+func (p Puzzle) closingActions() []Action {
+	actions := []Action{}
+	for _, card := range defaultHandRules.closingDiscards(p.Hand, DefaultCloseThreshold) {
+		actions = append(actions, NewActionClose(card, 0))
+	}
+	return actions
+}
+
+// bestDiscard returns the cards whose discard leaves the fewest penalty points, and that amount.
+func (r handRules) bestDiscard(hand []Card) ([]Card, int) {
+	var (
+		best     []Card
+		deadwood = -1
+	)
+	for i, card := range hand {
+		rest := append(append([]Card{}, hand[:i]...), hand[i+1:]...)
+		_, d := r.bestPartition(rest)
+		switch {
+		case deadwood == -1 || d < deadwood:
+			best, deadwood = []Card{card}, d
+		case d == deadwood:
+			best = append(best, card)
+		}
+	}
+	return best, deadwood
+}
+
+// PuzzleStreak tracks consecutive correctly solved puzzles.
+type PuzzleStreak struct {
+	Current int `json:"current"`
+	Best    int `json:"best"`
+	Solved  int `json:"solved"`
+	Played  int `json:"played"`
+}
+
+// Record updates the streak with the result of a puzzle.
+func (s *PuzzleStreak) Record(correct bool) {
+	s.Played++
+	if !correct {
+		s.Current = 0
+		return
+	}
+	s.Solved++
+	s.Current++
+	if s.Current > s.Best {
+		s.Best = s.Current
+	}
+}
@@ -0,0 +1,61 @@
+package chinchon
+
+// RejectionReason explains why an action can't be run, e.g. for clients to tell players.
+type RejectionReason string
+
+// Rejection reasons, returned by ValidateAction
+const (
+	REASON_NONE                     RejectionReason = ""
+	REASON_GAME_ENDED               RejectionReason = "game_ended"
+	REASON_NOT_YOUR_TURN            RejectionReason = "not_your_turn"
+	REASON_MUST_DRAW_FIRST          RejectionReason = "must_draw_first"
+	REASON_ALREADY_DREW             RejectionReason = "already_drew"
+	REASON_CARD_NOT_IN_HAND         RejectionReason = "card_not_in_hand"
+	REASON_CLOSE_THRESHOLD_EXCEEDED RejectionReason = "close_threshold_exceeded"
+	REASON_TOO_EARLY_TO_CLOSE       RejectionReason = "too_early_to_close"
+	REASON_ROUND_FINISHED           RejectionReason = "round_finished"
+	REASON_ROUND_NOT_FINISHED       RejectionReason = "round_not_finished"
+	REASON_NOT_POSSIBLE             RejectionReason = "not_possible" // For any other reason
+)
+
+// ValidateAction returns why RunAction would reject the action, or REASON_NONE if it can
+// be run.
+func (g GameState) ValidateAction(action Action) RejectionReason {
+	player, ok := g.Players[action.GetPlayerID()]
+	if !ok {
+		return REASON_NOT_POSSIBLE
+	}
+	if g.IsGameEnded {
+		return REASON_GAME_ENDED
+	}
+	if !g.IsRoundFinished && action.GetPlayerID() != g.TurnPlayerID && !isOutOfTurn(action) {
+		return REASON_NOT_YOUR_TURN
+	}
+	if action.IsPossible(g) {
+		return REASON_NONE
+	}
+
+	if card, ok := actionCard(action); ok && player.Hand != nil && !player.Hand.HasCard(card) {
+		return REASON_CARD_NOT_IN_HAND
+	}
+	switch action.GetName() {
+	case DRAW_FROM_DECK, DRAW_FROM_DISCARD, DISCARD_CARD, CLOSE_ROUND:
+		switch {
+		case g.IsRoundFinished:
+			return REASON_ROUND_FINISHED
+		case g.HasDrawnCard && (action.GetName() == DRAW_FROM_DECK || action.GetName() == DRAW_FROM_DISCARD):
+			return REASON_ALREADY_DREW
+		case !g.HasDrawnCard && (action.GetName() == DISCARD_CARD || action.GetName() == CLOSE_ROUND):
+			return REASON_MUST_DRAW_FIRST
+		case action.GetName() == CLOSE_ROUND && g.turnsBeforeClose(action.GetPlayerID()) > 0:
+			return REASON_TOO_EARLY_TO_CLOSE
+		case action.GetName() == CLOSE_ROUND:
+			return REASON_CLOSE_THRESHOLD_EXCEEDED
+		}
+	case CONFIRM_ROUND_FINISHED:
+		if !g.IsRoundFinished {
+			return REASON_ROUND_NOT_FINISHED
+		}
+	}
+	return REASON_NOT_POSSIBLE
+}
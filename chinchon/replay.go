@@ -0,0 +1,92 @@
+package chinchon
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errMissingDeck = errors.New("round log has no recorded deck")
+
+// Replay rebuilds a game from its round logs, e.g. for replay viewers, resolving disputes
+// or debugging desyncs. The game must be created with the options it was played with, but
+// not its seed: rounds are dealt from their logged decks.
+//
+// To rebuild an intermediate state, pass the logs truncated to that point. Confirmations of
+// finished rounds aren't logged, so they're replayed when needed to go on.
+func Replay(rounds []*RoundLog, opts ...func(*GameState)) (*GameState, error) {
+	stacked := []Deal{}
+	for number, round := range rounds {
+		if number == 0 {
+			continue // RoundsLog is 1-indexed
+		}
+		if round.DeckDealt == nil {
+			return nil, fmt.Errorf("round %d: %w", number, errMissingDeck)
+		}
+		stacked = append(stacked, Deal{Cards: round.DeckDealt, ShuffleSeed: round.ShuffleSeed})
+		for _, refill := range round.Refills {
+			stacked = append(stacked, Deal{Cards: refill})
+		}
+	}
+	if len(stacked) == 0 {
+		return nil, fmt.Errorf("round 1: %w", errMissingDeck)
+	}
+
+	gs := New(append(opts, func(gs *GameState) { gs.DrawPile.stacked = stacked })...)
+	for number, round := range rounds {
+		if number == 0 {
+			continue
+		}
+		if gs.RoundNumber != number {
+			return nil, fmt.Errorf("round %d: expected to be in round %d", gs.RoundNumber, number)
+		}
+		for i, log := range round.ActionsLog {
+			action, err := DeserializeAction(log.Action)
+			if err != nil {
+				return nil, fmt.Errorf("round %d, action %d: %w", number, i+1, err)
+			}
+			if !action.IsPossible(*gs) {
+				// e.g. re-entering the game, once everyone confirmed the round
+				gs.confirmRoundFinished()
+			}
+			run := gs.RunAction
+			if log.PlayedByBot {
+				run = gs.RunTakeoverAction
+			}
+			if err := run(action); err != nil {
+				return nil, fmt.Errorf("round %d, action %d: %w", number, i+1, err)
+			}
+		}
+		if number < len(rounds)-1 {
+			gs.confirmRoundFinished()
+		}
+	}
+	return gs, nil
+}
+
+// confirmRoundFinished confirms the finished round for every player who hasn't yet.
+func (g *GameState) confirmRoundFinished() {
+	for playerID := 0; playerID < len(g.Players); playerID++ {
+		if action := NewActionConfirmRoundFinished(playerID); action.IsPossible(*g) {
+			_ = g.RunAction(action)
+		}
+	}
+}
+
+// ReplayFile is a self-contained recording of a game: the rules it was played with, and its
+// round logs, which include the deals. It's serialized as JSON.
+type ReplayFile struct {
+	SchemaVersion int         `json:"schemaVersion"`
+	Rules         Rules       `json:"rules"`
+	RoundsLog     []*RoundLog `json:"roundsLog"`
+}
+
+// ReplayFile records the game so far.
+func (g GameState) ReplayFile() ReplayFile {
+	return ReplayFile{SchemaVersion: SchemaVersion, Rules: g.Rules(), RoundsLog: g.RoundsLog}
+}
+
+// Replay rebuilds the recorded game (see Replay). Extra options, e.g. WithObserver, are
+// applied after the recorded rules.
+func (f ReplayFile) Replay(opts ...func(*GameState)) (*GameState, error) {
+	return Replay(f.RoundsLog, append(f.Rules.Options(), opts...)...)
+}
@@ -0,0 +1,131 @@
+package chinchon
+
+import (
+	"fmt"
+	"time"
+)
+
+// Rules are the rules a game is played with, as set by its options, in a form that can be
+// serialized, e.g. to record them alongside the game or to configure games from outside Go.
+type Rules struct {
+	Players             int           `json:"players"`
+	Teams               bool          `json:"teams,omitempty"`
+	FirstPlayerID       int           `json:"firstPlayerID,omitempty"`
+	MaxPoints           int           `json:"maxPoints"`
+	MaxRounds           int           `json:"maxRounds,omitempty"`
+	CloseThreshold      int           `json:"closeThreshold"`
+	CloseBonusMode      string        `json:"closeBonusMode"`
+	CloseTieMode        string        `json:"closeTieMode"`
+	ChinchonMode        string        `json:"chinchonMode"`
+	Reenter             bool          `json:"reenter,omitempty"`
+	ResignRoundPenalty  int           `json:"resignRoundPenalty,omitempty"`
+	MinTurnsBeforeClose int           `json:"minTurnsBeforeClose,omitempty"`
+	HandSize            int           `json:"handSize"`
+	DeckType            string        `json:"deckType"`
+	AceWraparound       bool          `json:"aceWraparound,omitempty"`
+	DeclaredMelds       bool          `json:"declaredMelds,omitempty"`
+	LayOff              bool          `json:"layOff,omitempty"`
+	TurnTimeout         time.Duration `json:"turnTimeout,omitempty"`
+	TurnTimeoutMode     string        `json:"turnTimeoutMode,omitempty"`
+	Undo                bool          `json:"undo,omitempty"`
+	VerifiableShuffle   bool          `json:"verifiableShuffle,omitempty"`
+}
+
+// Rules returns the rules the game is played with.
+func (g GameState) Rules() Rules {
+	return Rules{
+		Players:             len(g.Players),
+		Teams:               g.RuleTeams,
+		FirstPlayerID:       g.FirstPlayerID,
+		MaxPoints:           g.RuleMaxPoints,
+		MaxRounds:           g.RuleMaxRounds,
+		CloseThreshold:      g.RuleCloseThreshold,
+		CloseBonusMode:      g.RuleCloseBonusMode,
+		CloseTieMode:        g.RuleCloseTieMode,
+		ChinchonMode:        g.RuleChinchonMode,
+		Reenter:             g.RuleReenter,
+		ResignRoundPenalty:  g.RuleResignRoundPenalty,
+		MinTurnsBeforeClose: g.RuleMinTurnsBeforeClose,
+		HandSize:            g.RuleHandSize,
+		DeckType:            g.RuleDeckType,
+		AceWraparound:       g.RuleAceWraparound,
+		DeclaredMelds:       g.RuleDeclaredMelds,
+		LayOff:              g.RuleLayOff,
+		TurnTimeout:         g.RuleTurnTimeout,
+		TurnTimeoutMode:     g.RuleTurnTimeoutMode,
+		Undo:                g.RuleUndo,
+		VerifiableShuffle:   g.RuleVerifiableShuffle,
+	}
+}
+
+// Options returns the options that create games with the rules. Like the options
+// themselves, New panics if the rules are invalid.
+func (r Rules) Options() []func(*GameState) {
+	opts := []func(*GameState){
+		WithPlayers(r.Players),
+		WithFirstPlayer(r.FirstPlayerID),
+		WithMaxPoints(r.MaxPoints),
+		WithMaxRounds(r.MaxRounds),
+		WithCloseThreshold(r.CloseThreshold),
+		WithCloseBonusMode(r.CloseBonusMode),
+		WithCloseTieMode(r.CloseTieMode),
+		WithChinchonMode(r.ChinchonMode),
+		WithResignRound(r.ResignRoundPenalty),
+		WithMinTurnsBeforeClose(r.MinTurnsBeforeClose),
+		WithHandSize(r.HandSize),
+		WithDeckType(r.DeckType),
+		WithTurnTimeout(r.TurnTimeout, r.TurnTimeoutMode),
+	}
+	flags := []struct {
+		enabled bool
+		opt     func(*GameState)
+	}{
+		{r.Teams, WithTeams()},
+		{r.Reenter, WithReenter()},
+		{r.AceWraparound, WithAceWraparound()},
+		{r.DeclaredMelds, WithDeclaredMelds()},
+		{r.LayOff, WithLayOff()},
+		{r.Undo, WithUndo()},
+		{r.VerifiableShuffle, WithVerifiableShuffle()},
+	}
+	for _, flag := range flags {
+		if flag.enabled {
+			opts = append(opts, flag.opt)
+		}
+	}
+	return opts
+}
+
+// Validate returns an error if games can't be created with the rules, e.g. because they
+// came from outside Go, rather than panicking like New.
+func (r Rules) Validate() (err error) {
+	switch {
+	case r.MaxPoints <= 0:
+		return fmt.Errorf("invalid max points %d", r.MaxPoints)
+	case r.MaxRounds < 0:
+		return fmt.Errorf("invalid max rounds %d", r.MaxRounds)
+	case r.CloseThreshold < 0:
+		return fmt.Errorf("invalid close threshold %d", r.CloseThreshold)
+	case r.ResignRoundPenalty < 0:
+		return fmt.Errorf("invalid resign round penalty %d", r.ResignRoundPenalty)
+	case r.MinTurnsBeforeClose < 0:
+		return fmt.Errorf("invalid min turns before close %d", r.MinTurnsBeforeClose)
+	case r.FirstPlayerID < 0 || r.FirstPlayerID >= r.Players:
+		return fmt.Errorf("invalid first player ID %d", r.FirstPlayerID)
+	case r.CloseBonusMode != CLOSE_BONUS_OPPONENTS_PLUS_10 && r.CloseBonusMode != CLOSE_BONUS_CLOSER_MINUS_10:
+		return fmt.Errorf("invalid close bonus mode %v", r.CloseBonusMode)
+	case r.CloseTieMode != CLOSE_TIE_PENALTIES && r.CloseTieMode != CLOSE_TIE_CLOSER_WINS && r.CloseTieMode != CLOSE_TIE_REPLAY && r.CloseTieMode != CLOSE_TIE_SPLIT:
+		return fmt.Errorf("invalid close tie mode %v", r.CloseTieMode)
+	case r.TurnTimeout < 0 || r.TurnTimeout > 0 && r.TurnTimeoutMode != TIMEOUT_AUTO_PLAY && r.TurnTimeoutMode != TIMEOUT_FORFEIT:
+		return fmt.Errorf("invalid turn timeout %v with mode %v", r.TurnTimeout, r.TurnTimeoutMode)
+	}
+
+	// The options check the rest.
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("invalid rules: %v", p)
+		}
+	}()
+	New(r.Options()...)
+	return nil
+}
@@ -0,0 +1,132 @@
+package chinchon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Scenario is a custom starting position, for teaching content, puzzles and reproducing
+// bug reports. Scenarios are usually loaded from a JSON file with LoadScenario.
+type Scenario struct {
+	// Hands is a map from PlayerID to the player's hand. Every player needs one.
+	Hands map[int][]Card `json:"hands"`
+
+	// DiscardPile is the discard pile, from bottom to top.
+	DiscardPile []Card `json:"discardPile"`
+
+	// DrawPile is the draw pile, from top to bottom. If empty, the cards not used elsewhere
+	// in the scenario are shuffled into it.
+	DrawPile []Card `json:"drawPile"`
+
+	// Scores is a map from PlayerID to the player's score. Missing players start at 0.
+	Scores map[int]int `json:"scores"`
+
+	// TurnPlayerID is the player whose turn it is.
+	TurnPlayerID int `json:"turnPlayerID"`
+
+	// HasDrawnCard is true if the turn player has already drawn, so their hand has an extra card.
+	HasDrawnCard bool `json:"hasDrawnCard"`
+}
+
+// LoadScenario reads a JSON scenario.
+func LoadScenario(r io.Reader) (Scenario, error) {
+	var s Scenario
+	err := json.NewDecoder(r).Decode(&s)
+	return s, err
+}
+
+// NewFromScenario creates a game (configured with the usual options) that starts from the
+// scenario's position instead of a random deal. Later rounds are dealt normally.
+func NewFromScenario(s Scenario, opts ...func(*GameState)) (*GameState, error) {
+	gs := New(opts...)
+
+	if err := s.validate(*gs); err != nil {
+		return nil, err
+	}
+
+	used := map[Card]bool{}
+	for playerID, cards := range s.Hands {
+		gs.Players[playerID].Hand = &Hand{Cards: append([]Card{}, cards...)}
+		gs.Players[playerID].Score = s.Scores[playerID]
+		for _, card := range cards {
+			used[card] = true
+		}
+	}
+	for _, card := range append(append([]Card{}, s.DiscardPile...), s.DrawPile...) {
+		used[card] = true
+	}
+
+	gs.DiscardPile = append([]Card{}, s.DiscardPile...)
+	gs.DiscardHistory = []DiscardEntry{}
+	for _, card := range s.DiscardPile {
+		gs.recordDiscard(-1, card)
+	}
+	gs.DrawPile.cards = append([]Card{}, s.DrawPile...)
+	if len(s.DrawPile) == 0 {
+		for _, card := range makeCards(gs.RuleDeckType, gs.DrawPile.rng) {
+			if !used[card] {
+				gs.DrawPile.cards = append(gs.DrawPile.cards, card)
+			}
+		}
+	}
+
+	gs.TurnPlayerID = s.TurnPlayerID
+	gs.TurnOpponentPlayerID = gs.nextPlayer(s.TurnPlayerID)
+	gs.HasDrawnCard = s.HasDrawnCard
+	gs.Events = []Event{} // The random deal's events don't apply
+
+	for playerID, player := range gs.Players {
+		handCopy := player.Hand.DeepCopy()
+		gs.RoundsLog[gs.RoundNumber].HandsDealt[playerID] = &handCopy
+	}
+	gs.RoundsLog[gs.RoundNumber].DeckDealt = nil // The scenario wasn't dealt from a deck
+	gs.RoundsLog[gs.RoundNumber].ShuffleCommitment = ""
+	gs.RoundsLog[gs.RoundNumber].ShuffleSeed = ""
+	gs.PossibleActions = _serializeActions(gs.CalculatePossibleActions())
+
+	return gs, nil
+}
+
+func (s Scenario) validate(gs GameState) error {
+	if _, ok := gs.Players[s.TurnPlayerID]; !ok {
+		return fmt.Errorf("invalid turn player ID %d", s.TurnPlayerID)
+	}
+	if len(s.Hands) != len(gs.Players) {
+		return fmt.Errorf("expected %d hands, got %d", len(gs.Players), len(s.Hands))
+	}
+
+	seen := map[Card]bool{}
+	checkCards := func(where string, cards []Card) error {
+		for _, card := range cards {
+			if !isInDeck(gs.RuleDeckType, card) {
+				return fmt.Errorf("invalid card %v in %v", card, where)
+			}
+			if seen[card] {
+				return fmt.Errorf("card %v appears more than once", card)
+			}
+			seen[card] = true
+		}
+		return nil
+	}
+
+	for playerID, cards := range s.Hands {
+		if _, ok := gs.Players[playerID]; !ok {
+			return fmt.Errorf("invalid player ID %d", playerID)
+		}
+		expectedHandSize := gs.RuleHandSize
+		if s.HasDrawnCard && playerID == s.TurnPlayerID {
+			expectedHandSize++
+		}
+		if len(cards) != expectedHandSize {
+			return fmt.Errorf("player %d should have %d cards, got %d", playerID, expectedHandSize, len(cards))
+		}
+		if err := checkCards(fmt.Sprintf("player %d's hand", playerID), cards); err != nil {
+			return err
+		}
+	}
+	if err := checkCards("discard pile", s.DiscardPile); err != nil {
+		return err
+	}
+	return checkCards("draw pile", s.DrawPile)
+}
@@ -0,0 +1,102 @@
+package chinchon
+
+import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// WithVerifiableShuffle makes every round's shuffle auditable, for competitive play: each
+// round's deck is derived from a new random seed, players get a commitment to the deck when
+// the round starts, and the seed is revealed when it finishes, so that they can check their
+// deal with VerifyShuffle. Decks given with WithDeck or WithDealHands aren't committed to.
+func WithVerifiableShuffle() func(*GameState) {
+	return func(gs *GameState) {
+		gs.RuleVerifiableShuffle = true
+	}
+}
+
+// shuffleRound shuffles the deck for a new round, unless a deal is stacked. With
+// RuleVerifiableShuffle, it returns the seed the deck was derived from, and the commitment
+// to it.
+func (g *GameState) shuffleRound() (seed, commitment string) {
+	if deal, ok := g.DrawPile.popStacked(); ok {
+		if deal.ShuffleSeed == "" {
+			return "", ""
+		}
+		return deal.ShuffleSeed, shuffleCommitment(g.DrawPile.cards, deal.ShuffleSeed)
+	}
+	if !g.RuleVerifiableShuffle {
+		g.DrawPile.shuffle()
+		return "", ""
+	}
+
+	bs := make([]byte, 32)
+	if _, err := crand.Read(bs); err != nil {
+		panic(fmt.Sprintf("chinchon: can't generate a shuffle seed: %v", err))
+	}
+	seed = hex.EncodeToString(bs)
+	g.DrawPile.cards = seededDeck(seed, g.RuleDeckType)
+	return seed, shuffleCommitment(g.DrawPile.cards, seed)
+}
+
+// seededDeck returns the deck of the given type, shuffled as determined by the seed.
+func seededDeck(seed, deckType string) []Card {
+	sum := sha256.Sum256([]byte(seed))
+	return makeCards(deckType, rand.New(rand.NewSource(int64(binary.BigEndian.Uint64(sum[:])))))
+}
+
+// shuffleCommitment returns the hex-encoded SHA-256 hash of the deck, salted with the seed.
+func shuffleCommitment(cards []Card, seed string) string {
+	bs, _ := json.Marshal(cards)
+	sum := sha256.Sum256(append(bs, seed...))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyShuffle checks a round dealt with WithVerifiableShuffle: that the seed revealed when
+// the round finished matches the commitment given when it started, and that the observed
+// deals (a map from PlayerID to the hand they were dealt, usually just the player's own one)
+// are the ones the seed's deck deals. It returns nil if the round was dealt fairly.
+func VerifyShuffle(commitment, seed string, observedDeals map[int][]Card) error {
+	var cards []Card
+	for _, deckType := range []string{DECK_SPANISH_48, DECK_SPANISH_40, DECK_FRENCH_52} {
+		if deck := seededDeck(seed, deckType); shuffleCommitment(deck, seed) == commitment {
+			cards = deck
+		}
+	}
+	if cards == nil {
+		return errors.New("the seed doesn't match the commitment")
+	}
+
+	// Each player is dealt their hand from the top of the deck in turn, starting with player 0
+	for playerID, hand := range observedDeals {
+		start, end := playerID*len(hand), (playerID+1)*len(hand)
+		if playerID < 0 || end > len(cards) || !sameCards(hand, cards[start:end]) {
+			return fmt.Errorf("player %d wasn't dealt the cards of the committed deck", playerID)
+		}
+	}
+	return nil
+}
+
+// sameCards returns true if both lists have the same cards, in any order.
+func sameCards(a, b []Card) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := map[Card]int{}
+	for _, card := range a {
+		counts[card]++
+	}
+	for _, card := range b {
+		if counts[card] == 0 {
+			return false
+		}
+		counts[card]--
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+package chinchon
+
+// SpectatorGameState represents the state of a Chinchón game as available to spectators:
+// only public information, unless hands are revealed, e.g. for broadcasting a game with
+// a delay.
+type SpectatorGameState struct {
+	RoundNumber  int `json:"roundNumber"`
+	TurnPlayerID int `json:"turnPlayerID"`
+
+	TopDiscardCard *Card `json:"topDiscardCard"`
+	DrawPileSize   int   `json:"drawPileSize"`
+
+	// DiscardHistory is every card placed on the discard pile during the round, in order,
+	// with who discarded and who picked each one.
+	DiscardHistory []DiscardEntry `json:"discardHistory"`
+
+	IsGameEnded     bool `json:"isGameEnded"`
+	IsGameAborted   bool `json:"isGameAborted,omitempty"`
+	IsRoundFinished bool `json:"isRoundFinished"`
+
+	WinnerPlayerID int `json:"winnerPlayerID"`
+	LoserPlayerID  int `json:"loserPlayerID"`
+
+	LastActionLog *ActionLog `json:"lastActionLog"`
+
+	RuleMaxPoints           int    `json:"ruleMaxPoints"`
+	RuleMaxRounds           int    `json:"ruleMaxRounds"`
+	RuleCloseThreshold      int    `json:"ruleCloseThreshold"`
+	RuleCloseBonusMode      string `json:"ruleCloseBonusMode"`
+	RuleCloseTieMode        string `json:"ruleCloseTieMode"`
+	RuleChinchonMode        string `json:"ruleChinchonMode"`
+	RuleReenter             bool   `json:"ruleReenter"`
+	RuleLayOff              bool   `json:"ruleLayOff"`
+	RuleDeclaredMelds       bool   `json:"ruleDeclaredMelds"`
+	RuleAceWraparound       bool   `json:"ruleAceWraparound"`
+	RuleDeckType            string `json:"ruleDeckType"`
+	RuleResignRoundPenalty  int    `json:"ruleResignRoundPenalty"`
+	RuleHandSize            int    `json:"ruleHandSize"`
+	RuleMinTurnsBeforeClose int    `json:"ruleMinTurnsBeforeClose"`
+	RuleTeams               bool   `json:"ruleTeams"`
+	HasDrawnCard            bool   `json:"hasDrawnCard"`
+
+	// TurnTimeRemainingMs is the time left for the current turn, in milliseconds, with a
+	// turn timeout. It's 0 otherwise.
+	TurnTimeRemainingMs int64 `json:"turnTimeRemainingMs"`
+
+	// DeclaredGroups are the groups the closing player declared, with RuleDeclaredMelds.
+	DeclaredGroups [][]Card `json:"declaredGroups"`
+
+	// IsLayingOff is true while opponents lay off cards onto LayOffGroups, the closing
+	// player's groups, before the round's scores are calculated.
+	IsLayingOff  bool     `json:"isLayingOff"`
+	LayOffGroups [][]Card `json:"layOffGroups"`
+
+	// ReenterOfferedPlayerID is the busted player who's deciding whether to re-enter, -1 if none.
+	ReenterOfferedPlayerID int `json:"reenterOfferedPlayerID"`
+
+	// Players lists every player at the table, in turn order. Their hands are only shown
+	// once the round is finished, or all along if HandsRevealed.
+	Players       []ClientPlayer `json:"players"`
+	HandsRevealed bool           `json:"handsRevealed"`
+
+	// Match is the series score, if the game is part of a match.
+	Match *MatchState `json:"match,omitempty"`
+
+	// Stats are the game's statistics, once it's ended.
+	Stats *Stats `json:"stats,omitempty"`
+
+	TeamScores   map[int]int `json:"teamScores"`
+	WinnerTeamID int         `json:"winnerTeamID"`
+	LoserTeamID  int         `json:"loserTeamID"`
+
+	// Events are the changes to the table since the previous state, for animating them.
+	// Cards moved face down are hidden, unless HandsRevealed.
+	Events []Event `json:"events"`
+
+	// SchemaVersion is the version of this state's format, for clients to detect changes.
+	SchemaVersion int `json:"schemaVersion"`
+}
+
+// ToSpectatorGameState returns the state of the game as seen by spectators, who can't see
+// any hidden cards, so that it's safe to send to watchers. With revealHands, every hand is
+// shown (but not the draw pile), e.g. for a TV-style broadcast that's delayed so that it
+// can't help the players.
+func (g *GameState) ToSpectatorGameState(revealHands bool) SpectatorGameState {
+	var topDiscardCard *Card
+	if len(g.DiscardPile) > 0 {
+		card := g.DiscardPile[len(g.DiscardPile)-1]
+		topDiscardCard = &card
+	}
+
+	// As for players, hands are revealed once the round is scored
+	isScored := g.IsRoundFinished && !g.IsLayingOff
+	players := []ClientPlayer{}
+	for playerID := 0; playerID < len(g.Players); playerID++ {
+		player := ClientPlayer{
+			PlayerID: playerID,
+			Team:     g.Players[playerID].Team,
+			Score:    g.Players[playerID].Score,
+			HandSize: len(g.Players[playerID].Hand.Cards),
+			Name:     g.Players[playerID].Name,
+			Avatar:   g.Players[playerID].Avatar,
+		}
+		if isScored || revealHands {
+			player.Hand = g.Players[playerID].sortedHand()
+		}
+		if isScored {
+			player.Groups, player.PenaltyPoints = g.scoredPartition(playerID)
+		}
+		players = append(players, player)
+	}
+
+	events := g.Events
+	if !revealHands {
+		events = g.eventsFor(-1)
+	}
+
+	sgs := SpectatorGameState{
+		RoundNumber:             g.RoundNumber,
+		TurnPlayerID:            g.TurnPlayerID,
+		TopDiscardCard:          topDiscardCard,
+		DrawPileSize:            g.DrawPileSize(),
+		DiscardHistory:          g.DiscardHistory,
+		IsGameEnded:             g.IsGameEnded,
+		IsGameAborted:           g.IsGameAborted,
+		IsRoundFinished:         g.IsRoundFinished,
+		WinnerPlayerID:          g.WinnerPlayerID,
+		LoserPlayerID:           g.LoserPlayerID,
+		RuleMaxPoints:           g.RuleMaxPoints,
+		RuleMaxRounds:           g.RuleMaxRounds,
+		RuleCloseThreshold:      g.RuleCloseThreshold,
+		RuleCloseBonusMode:      g.RuleCloseBonusMode,
+		RuleCloseTieMode:        g.RuleCloseTieMode,
+		RuleChinchonMode:        g.RuleChinchonMode,
+		RuleReenter:             g.RuleReenter,
+		RuleLayOff:              g.RuleLayOff,
+		RuleDeclaredMelds:       g.RuleDeclaredMelds,
+		RuleAceWraparound:       g.RuleAceWraparound,
+		RuleDeckType:            g.RuleDeckType,
+		RuleResignRoundPenalty:  g.RuleResignRoundPenalty,
+		RuleHandSize:            g.RuleHandSize,
+		RuleMinTurnsBeforeClose: g.RuleMinTurnsBeforeClose,
+		RuleTeams:               g.RuleTeams,
+		HasDrawnCard:            g.HasDrawnCard,
+		TurnTimeRemainingMs:     g.turnTimeRemaining().Milliseconds(),
+		DeclaredGroups:          g.DeclaredGroups,
+		IsLayingOff:             g.IsLayingOff,
+		LayOffGroups:            g.LayOffGroups,
+		ReenterOfferedPlayerID:  g.ReenterOfferedPlayerID,
+		Players:                 players,
+		HandsRevealed:           revealHands,
+		TeamScores:              g.TeamScores(),
+		WinnerTeamID:            g.WinnerTeamID,
+		LoserTeamID:             g.LoserTeamID,
+		Events:                  events,
+		SchemaVersion:           SchemaVersion,
+	}
+	if g.IsGameEnded {
+		stats := g.Stats()
+		sgs.Stats = &stats
+	}
+	if g.Match != nil {
+		match := *g.Match
+		sgs.Match = &match
+	}
+	if len(g.RoundsLog[g.RoundNumber].ActionsLog) > 0 {
+		actionsLog := g.RoundsLog[g.RoundNumber].ActionsLog
+		sgs.LastActionLog = &actionsLog[len(actionsLog)-1]
+	}
+
+	return sgs
+}
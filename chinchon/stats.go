@@ -0,0 +1,69 @@
+package chinchon
+
+// Stats are a game's statistics, worked out from its round logs (see GameState.Stats).
+type Stats struct {
+	// Rounds is the number of rounds played, including the current one.
+	Rounds int `json:"rounds"`
+
+	// Players is a map from PlayerID to the player's statistics.
+	Players map[int]*PlayerStats `json:"players"`
+}
+
+// PlayerStats are a player's statistics in a game.
+type PlayerStats struct {
+	DrawsFromDeck    int `json:"drawsFromDeck"`
+	DrawsFromDiscard int `json:"drawsFromDiscard"`
+	RoundsClosed     int `json:"roundsClosed"`
+	Chinchones       int `json:"chinchones"`
+
+	// AverageDeadwoodAtClose is the average of the player's penalty points in the rounds
+	// that someone closed, 0 if there are none.
+	AverageDeadwoodAtClose float64 `json:"averageDeadwoodAtClose"`
+}
+
+// Stats returns the game's statistics so far.
+func (g GameState) Stats() Stats {
+	stats := Stats{Rounds: g.RoundNumber, Players: map[int]*PlayerStats{}}
+	deadwood, closedRounds := map[int]int{}, 0
+	for id := range g.Players {
+		stats.Players[id] = &PlayerStats{}
+	}
+
+	for number, round := range g.RoundsLog {
+		if number == 0 {
+			continue // RoundsLog is 1-indexed
+		}
+		for _, log := range round.ActionsLog {
+			action, err := DeserializeAction(log.Action)
+			if err != nil || stats.Players[log.PlayerID] == nil {
+				continue
+			}
+			switch action.GetName() {
+			case DRAW_FROM_DECK:
+				stats.Players[log.PlayerID].DrawsFromDeck++
+			case DRAW_FROM_DISCARD:
+				stats.Players[log.PlayerID].DrawsFromDiscard++
+			}
+		}
+
+		switch {
+		case round.WasChinchon:
+			if player := stats.Players[round.WinnerPlayerID]; player != nil {
+				player.Chinchones++
+			}
+		case round.ClosedByPlayerID != -1 && !round.WasResigned && len(round.PenaltyPoints) > 0:
+			stats.Players[round.ClosedByPlayerID].RoundsClosed++
+			closedRounds++
+			for id, points := range round.PenaltyPoints {
+				deadwood[id] += points
+			}
+		}
+	}
+
+	if closedRounds > 0 {
+		for id, player := range stats.Players {
+			player.AverageDeadwoodAtClose = float64(deadwood[id]) / float64(closedRounds)
+		}
+	}
+	return stats
+}
@@ -0,0 +1,134 @@
+package chinchon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Tutorial is a guided lesson: a sequence of scripted steps played through the normal
+// engine, where the student (always player 0) is asked for a specific action at each step.
+// Tutorials are usually loaded from a JSON file with LoadTutorial.
+type Tutorial struct {
+	// Name is a short title for the tutorial.
+	Name string `json:"name"`
+
+	// Steps are played in order. The first one must have a Scenario.
+	Steps []TutorialStep `json:"steps"`
+}
+
+// TutorialStep is a single step of a tutorial.
+type TutorialStep struct {
+	// Scenario, if set, restarts the game from this position. Otherwise, the step continues
+	// the game where the previous step left it.
+	Scenario *Scenario `json:"scenario,omitempty"`
+
+	// Text explains the situation and what the student should do.
+	Text string `json:"text"`
+
+	// ExpectedAction is the serialized action the student must play to move on.
+	ExpectedAction json.RawMessage `json:"expectedAction"`
+
+	// Hint is shown when the student plays something else.
+	Hint string `json:"hint"`
+
+	// Explanation is shown once the student plays the expected action.
+	Explanation string `json:"explanation"`
+
+	// OpponentActions are serialized actions run right after the expected action, e.g. the
+	// opponent's scripted turn.
+	OpponentActions []json.RawMessage `json:"opponentActions,omitempty"`
+}
+
+// LoadTutorial reads a JSON tutorial and checks that every step's actions are valid actions.
+// Whether they're possible is only known when playing it, e.g. with a TutorialSession.
+func LoadTutorial(r io.Reader) (Tutorial, error) {
+	var t Tutorial
+	if err := json.NewDecoder(r).Decode(&t); err != nil {
+		return t, err
+	}
+	if len(t.Steps) == 0 || t.Steps[0].Scenario == nil {
+		return t, errors.New("the first tutorial step needs a scenario")
+	}
+	for i, step := range t.Steps {
+		for _, bs := range append([]json.RawMessage{step.ExpectedAction}, step.OpponentActions...) {
+			if _, err := DeserializeAction(bs); err != nil {
+				return t, fmt.Errorf("step %d: %w", i+1, err)
+			}
+		}
+	}
+	return t, nil
+}
+
+// TutorialSession is a tutorial being played.
+type TutorialSession struct {
+	Tutorial  Tutorial
+	Step      int
+	GameState *GameState
+}
+
+// NewTutorialSession starts the tutorial from its first step.
+func NewTutorialSession(t Tutorial) (*TutorialSession, error) {
+	s := &TutorialSession{Tutorial: t}
+	return s, s.startStep()
+}
+
+// CurrentStep returns the step the student is at.
+func (s *TutorialSession) CurrentStep() TutorialStep {
+	return s.Tutorial.Steps[s.Step]
+}
+
+// IsFinished returns true once every step has been played.
+func (s *TutorialSession) IsFinished() bool {
+	return s.Step >= len(s.Tutorial.Steps)
+}
+
+// Play checks the student's action against the current step. If it's the expected one, it
+// runs it and the step's opponent actions, and moves on to the next step. It returns false
+// (and doesn't run anything) if the student should try again.
+func (s *TutorialSession) Play(action Action) (bool, error) {
+	if string(SerializeAction(action)) != string(normalizeAction(s.CurrentStep().ExpectedAction)) {
+		return false, nil
+	}
+
+	step := s.CurrentStep()
+	if err := s.GameState.RunAction(action); err != nil {
+		return false, fmt.Errorf("step %d: expected action can't be played: %w", s.Step+1, err)
+	}
+	for _, bs := range step.OpponentActions {
+		opponentAction, _ := DeserializeAction(bs)
+		if err := s.GameState.RunAction(opponentAction); err != nil {
+			return false, fmt.Errorf("step %d: opponent action %v can't be played: %w", s.Step+1, opponentAction, err)
+		}
+	}
+
+	s.Step++
+	if s.IsFinished() {
+		return true, nil
+	}
+	return true, s.startStep()
+}
+
+func (s *TutorialSession) startStep() error {
+	scenario := s.CurrentStep().Scenario
+	if scenario == nil {
+		return nil
+	}
+	gs, err := NewFromScenario(*scenario)
+	if err != nil {
+		return fmt.Errorf("step %d: %w", s.Step+1, err)
+	}
+	s.GameState = gs
+	return nil
+}
+
+// normalizeAction normalizes an action as written in a tutorial file, to compare it with a
+// serialized action, by deserializing and serializing it again.
+func normalizeAction(bs json.RawMessage) []byte {
+	action, err := DeserializeAction(bs)
+	if err != nil {
+		return nil
+	}
+	return SerializeAction(action)
+}
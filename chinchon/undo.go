@@ -0,0 +1,45 @@
+package chinchon
+
+// WithUndo lets actions be taken back with Undo, e.g. for coaching clients. Competitive
+// games should leave it off.
+func WithUndo() func(*GameState) {
+	return func(gs *GameState) {
+		gs.RuleUndo = true
+	}
+}
+
+// Undo reverts the last action of the current round, other than confirming it's finished
+// (which is reverted along with it) and arranging hands. It returns ErrNothingToUndo without WithUndo, or at
+// the start of a round.
+func (g *GameState) Undo() error {
+	if !g.RuleUndo || len(g.undoSnapshots) == 0 {
+		return ErrNothingToUndo
+	}
+	last := len(g.undoSnapshots) - 1
+	restored, err := Load(g.undoSnapshots[last])
+	if err != nil {
+		return err
+	}
+
+	// Keep what isn't saved
+	restored.DrawPile.rng = g.DrawPile.rng
+	restored.DrawPile.stacked = g.DrawPile.stacked
+	restored.observers = g.observers
+	restored.undoSnapshots = g.undoSnapshots[:last]
+	*g = *restored
+	return nil
+}
+
+// pushUndoSnapshot saves the state before running the action, with RuleUndo. It returns
+// true if it did.
+func (g *GameState) pushUndoSnapshot(action Action) bool {
+	if !g.RuleUndo || action.GetName() == CONFIRM_ROUND_FINISHED || isCosmetic(action) {
+		return false
+	}
+	bs, err := g.Save()
+	if err != nil {
+		return false
+	}
+	g.undoSnapshots = append(g.undoSnapshots, bs)
+	return true
+}
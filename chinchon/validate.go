@@ -0,0 +1,135 @@
+package chinchon
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidState is returned by Validate when the game breaks one of its invariants.
+var ErrInvalidState = errors.New("invalid game state")
+
+// WithValidation runs Validate after every action, and panics if the game is left in an
+// invalid state, e.g. for tests and bots looking for engine bugs. Builds with the
+// chinchondebug tag validate every game.
+func WithValidation() func(*GameState) {
+	return func(gs *GameState) {
+		gs.validate = true
+	}
+}
+
+// Validate checks the game's invariants: every card of the round's deck is in exactly one
+// place (a hand, a pile or a lay-off group), hand sizes are legal for the phase of the round,
+// and the turn fields are consistent. It returns an error wrapping ErrInvalidState for the
+// first one broken.
+func (g GameState) Validate() error {
+	if err := g.validateTurn(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidState, err)
+	}
+	if err := g.validateCards(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidState, err)
+	}
+	return nil
+}
+
+func (g GameState) validateTurn() error {
+	if g.RoundNumber != len(g.RoundsLog)-1 {
+		return fmt.Errorf("round %d, but %d rounds logged", g.RoundNumber, len(g.RoundsLog)-1)
+	}
+	if _, ok := g.Players[g.TurnPlayerID]; !ok {
+		return fmt.Errorf("invalid turn player ID %d", g.TurnPlayerID)
+	}
+	if g.TurnOpponentPlayerID != g.nextPlayer(g.TurnPlayerID) {
+		return fmt.Errorf("turn opponent is %d, but player %d plays after %d", g.TurnOpponentPlayerID, g.nextPlayer(g.TurnPlayerID), g.TurnPlayerID)
+	}
+	if _, ok := g.Players[g.CurrentRoundClosedByPlayerID]; !ok && g.CurrentRoundClosedByPlayerID != -1 {
+		return fmt.Errorf("invalid closing player ID %d", g.CurrentRoundClosedByPlayerID)
+	}
+	if g.IsLayingOff && g.CurrentRoundClosedByPlayerID == -1 {
+		return fmt.Errorf("laying off, but nobody closed the round")
+	}
+	if _, ok := g.Players[g.ReenterOfferedPlayerID]; !ok && g.ReenterOfferedPlayerID != -1 {
+		return fmt.Errorf("invalid re-enter offered player ID %d", g.ReenterOfferedPlayerID)
+	}
+	return nil
+}
+
+func (g GameState) validateCards() error {
+	seen := map[Card]bool{}
+	count := func(where string, cards []Card) error {
+		for _, card := range cards {
+			if !isInDeck(g.RuleDeckType, card) {
+				return fmt.Errorf("invalid card %v in %v", card, where)
+			}
+			if seen[card] {
+				return fmt.Errorf("card %v appears more than once", card)
+			}
+			seen[card] = true
+		}
+		return nil
+	}
+
+	for playerID := 0; playerID < len(g.Players); playerID++ {
+		player, ok := g.Players[playerID]
+		if !ok || player.Hand == nil {
+			return fmt.Errorf("player %d has no hand", playerID)
+		}
+		if err := g.validateHandSize(playerID); err != nil {
+			return err
+		}
+		if err := count(fmt.Sprintf("player %d's hand", playerID), player.Hand.Cards); err != nil {
+			return err
+		}
+	}
+	if err := count("discard pile", g.DiscardPile); err != nil {
+		return err
+	}
+	if err := count("draw pile", g.DrawPile.cards); err != nil {
+		return err
+	}
+
+	// Lay-off groups start as the closing player's groups, which are still in their hand, so
+	// only the cards laid off from other hands are counted.
+	for _, group := range g.LayOffGroups {
+		for _, card := range group {
+			if !g.Players[g.CurrentRoundClosedByPlayerID].Hand.HasCard(card) {
+				if err := count("lay-off groups", []Card{card}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// Scenarios aren't dealt from a deck, so there's no known card count to compare with.
+	if deck := g.RoundsLog[g.RoundNumber].DeckDealt; deck != nil && len(seen) != len(deck) {
+		return fmt.Errorf("%d cards on the table, but %d were dealt", len(seen), len(deck))
+	}
+	return nil
+}
+
+// validateHandSize checks the player's hand size: RuleHandSize during the round (plus the
+// card drawn by the turn player), and at most one more once the round is closing or finished,
+// since players may have laid off cards or resigned the round before discarding.
+func (g GameState) validateHandSize(playerID int) error {
+	size := len(g.Players[playerID].Hand.Cards)
+	expected := g.RuleHandSize
+	if g.HasDrawnCard && playerID == g.TurnPlayerID {
+		expected++
+	}
+	if g.IsRoundFinished || g.IsLayingOff {
+		if size > g.RuleHandSize+1 {
+			return fmt.Errorf("player %d has %d cards, at most %d expected", playerID, size, g.RuleHandSize+1)
+		}
+		return nil
+	}
+	if size != expected {
+		return fmt.Errorf("player %d has %d cards, %d expected", playerID, size, expected)
+	}
+	return nil
+}
+
+// mustValidate panics if the game is in an invalid state after running the action.
+func (g GameState) mustValidate(action Action) {
+	if err := g.Validate(); err != nil {
+		panic(fmt.Sprintf("chinchon: %v after running [%v]", err, action))
+	}
+}
@@ -0,0 +1,88 @@
+package chinchon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaVersion is the version of the JSON format of saved games, client game states and
+// actions, which they're serialized with. Games and actions from older versions (those
+// without a version are 0) are migrated when loaded, and newer ones are rejected.
+const SchemaVersion = 1
+
+// migration upgrades a serialized object from one schema version to the next.
+type migration func(object map[string]any) error
+
+// gameStateMigrations upgrade games saved with Save, from the version they're indexed by.
+var gameStateMigrations = map[int]migration{
+	0: func(state map[string]any) error {
+		// Unversioned games may predate rules and fields that need a non-zero default
+		setDefault(state, "reenterOfferedPlayerID", -1)
+		setDefault(state, "ruleDeckType", DECK_SPANISH_48)
+		setDefault(state, "ruleCloseTieMode", CLOSE_TIE_PENALTIES)
+		setDefault(state, "ruleHandSize", DefaultHandSize)
+		setDefault(state, "ruleCloseThreshold", DefaultCloseThreshold)
+		for _, key := range []string{"roundFinishedConfirmedPlayerIDs", "reenteredPlayerIDs", "layOffFinishedPlayerIDs"} {
+			setDefault(state, key, map[string]any{})
+		}
+		return nil
+	},
+}
+
+// actionMigrations upgrade serialized actions, from the version they're indexed by. Actions
+// haven't changed since they're versioned.
+var actionMigrations = map[int]migration{
+	0: func(action map[string]any) error { return nil },
+}
+
+// setDefault sets the object's key to the value, if it's missing or null.
+func setDefault(object map[string]any, key string, value any) {
+	if object[key] == nil {
+		object[key] = value
+	}
+}
+
+// migrate upgrades the serialized object to SchemaVersion with the migrations, returning
+// it as is if it's already up to date.
+func migrate(bs []byte, migrations map[int]migration) ([]byte, error) {
+	var versioned struct {
+		SchemaVersion int `json:"schemaVersion"`
+	}
+	if err := json.Unmarshal(bs, &versioned); err != nil {
+		return nil, err
+	}
+	version := versioned.SchemaVersion
+	if version > SchemaVersion {
+		return nil, fmt.Errorf("unsupported schema version %d, expected up to %d", version, SchemaVersion)
+	}
+	if version == SchemaVersion {
+		return bs, nil
+	}
+
+	var object map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(bs))
+	decoder.UseNumber() // Keep numbers as they are
+	if err := decoder.Decode(&object); err != nil {
+		return nil, err
+	}
+	for ; version < SchemaVersion; version++ {
+		if err := migrations[version](object); err != nil {
+			return nil, fmt.Errorf("failed to migrate from schema version %d: %w", version, err)
+		}
+	}
+	object["schemaVersion"] = SchemaVersion
+	return json.Marshal(object)
+}
+
+// withSchemaVersion adds the current schemaVersion field to a serialized object.
+func withSchemaVersion(bs []byte) []byte {
+	if len(bs) < 2 || bs[0] != '{' {
+		return bs
+	}
+	field := fmt.Sprintf(`{"schemaVersion":%d`, SchemaVersion)
+	if bs[1] != '}' {
+		field += ","
+	}
+	return append([]byte(field), bs[1:]...)
+}
@@ -0,0 +1,2128 @@
+// Protocol Buffers schema for the Chinchón engine's types, for clients that don't speak Go
+// (e.g. mobile, web or Python bots). It mirrors the JSON shapes of the chinchon package, and
+// the chinchonpb package converts between both.
+//
+// Regenerate chinchon.pb.go with `go generate ./chinchonpb` (needs protoc and protoc-gen-go).
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: chinchon.proto
+
+package chinchonpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Card struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Suit   string `protobuf:"bytes,1,opt,name=suit,proto3" json:"suit,omitempty"`
+	Number int32  `protobuf:"varint,2,opt,name=number,proto3" json:"number,omitempty"`
+}
+
+func (x *Card) Reset() {
+	*x = Card{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_chinchon_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Card) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Card) ProtoMessage() {}
+
+func (x *Card) ProtoReflect() protoreflect.Message {
+	mi := &file_chinchon_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Card.ProtoReflect.Descriptor instead.
+func (*Card) Descriptor() ([]byte, []int) {
+	return file_chinchon_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Card) GetSuit() string {
+	if x != nil {
+		return x.Suit
+	}
+	return ""
+}
+
+func (x *Card) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+// Group is a run or set of cards.
+type Group struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cards []*Card `protobuf:"bytes,1,rep,name=cards,proto3" json:"cards,omitempty"`
+}
+
+func (x *Group) Reset() {
+	*x = Group{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_chinchon_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Group) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Group) ProtoMessage() {}
+
+func (x *Group) ProtoReflect() protoreflect.Message {
+	mi := &file_chinchon_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Group.ProtoReflect.Descriptor instead.
+func (*Group) Descriptor() ([]byte, []int) {
+	return file_chinchon_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Group) GetCards() []*Card {
+	if x != nil {
+		return x.Cards
+	}
+	return nil
+}
+
+// Action is any action. Only the fields of its kind (by name) are set.
+type Action struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name     string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	PlayerId int32  `protobuf:"varint,2,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	// card is set for discard_card, close_round and lay_off_card.
+	Card *Card `protobuf:"bytes,3,opt,name=card,proto3" json:"card,omitempty"`
+	// groups are the declared groups of close_round, with declared melds.
+	Groups []*Group `protobuf:"bytes,4,rep,name=groups,proto3" json:"groups,omitempty"`
+	// group_index is the group of lay_off_card.
+	GroupIndex int32 `protobuf:"varint,5,opt,name=group_index,json=groupIndex,proto3" json:"group_index,omitempty"`
+	// cards is the new order of reorder_hand.
+	Cards []*Card `protobuf:"bytes,6,rep,name=cards,proto3" json:"cards,omitempty"`
+	// sort is the preference of set_hand_sort.
+	Sort string `protobuf:"bytes,7,opt,name=sort,proto3" json:"sort,omitempty"`
+}
+
+func (x *Action) Reset() {
+	*x = Action{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_chinchon_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Action) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Action) ProtoMessage() {}
+
+func (x *Action) ProtoReflect() protoreflect.Message {
+	mi := &file_chinchon_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Action.ProtoReflect.Descriptor instead.
+func (*Action) Descriptor() ([]byte, []int) {
+	return file_chinchon_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Action) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Action) GetPlayerId() int32 {
+	if x != nil {
+		return x.PlayerId
+	}
+	return 0
+}
+
+func (x *Action) GetCard() *Card {
+	if x != nil {
+		return x.Card
+	}
+	return nil
+}
+
+func (x *Action) GetGroups() []*Group {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+func (x *Action) GetGroupIndex() int32 {
+	if x != nil {
+		return x.GroupIndex
+	}
+	return 0
+}
+
+func (x *Action) GetCards() []*Card {
+	if x != nil {
+		return x.Cards
+	}
+	return nil
+}
+
+func (x *Action) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+type ActionLog struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PlayerId    int32   `protobuf:"varint,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	Action      *Action `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	PlayedByBot bool    `protobuf:"varint,3,opt,name=played_by_bot,json=playedByBot,proto3" json:"played_by_bot,omitempty"`
+}
+
+func (x *ActionLog) Reset() {
+	*x = ActionLog{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_chinchon_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ActionLog) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActionLog) ProtoMessage() {}
+
+func (x *ActionLog) ProtoReflect() protoreflect.Message {
+	mi := &file_chinchon_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActionLog.ProtoReflect.Descriptor instead.
+func (*ActionLog) Descriptor() ([]byte, []int) {
+	return file_chinchon_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ActionLog) GetPlayerId() int32 {
+	if x != nil {
+		return x.PlayerId
+	}
+	return 0
+}
+
+func (x *ActionLog) GetAction() *Action {
+	if x != nil {
+		return x.Action
+	}
+	return nil
+}
+
+func (x *ActionLog) GetPlayedByBot() bool {
+	if x != nil {
+		return x.PlayedByBot
+	}
+	return false
+}
+
+// Rules are the game's options.
+type Rules struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MaxPoints           int32  `protobuf:"varint,1,opt,name=max_points,json=maxPoints,proto3" json:"max_points,omitempty"`
+	MaxRounds           int32  `protobuf:"varint,2,opt,name=max_rounds,json=maxRounds,proto3" json:"max_rounds,omitempty"`
+	CloseThreshold      int32  `protobuf:"varint,3,opt,name=close_threshold,json=closeThreshold,proto3" json:"close_threshold,omitempty"`
+	CloseBonusMode      string `protobuf:"bytes,4,opt,name=close_bonus_mode,json=closeBonusMode,proto3" json:"close_bonus_mode,omitempty"`
+	CloseTieMode        string `protobuf:"bytes,5,opt,name=close_tie_mode,json=closeTieMode,proto3" json:"close_tie_mode,omitempty"`
+	Reenter             bool   `protobuf:"varint,6,opt,name=reenter,proto3" json:"reenter,omitempty"`
+	LayOff              bool   `protobuf:"varint,7,opt,name=lay_off,json=layOff,proto3" json:"lay_off,omitempty"`
+	DeclaredMelds       bool   `protobuf:"varint,8,opt,name=declared_melds,json=declaredMelds,proto3" json:"declared_melds,omitempty"`
+	AceWraparound       bool   `protobuf:"varint,9,opt,name=ace_wraparound,json=aceWraparound,proto3" json:"ace_wraparound,omitempty"`
+	DeckType            string `protobuf:"bytes,10,opt,name=deck_type,json=deckType,proto3" json:"deck_type,omitempty"`
+	ResignRoundPenalty  int32  `protobuf:"varint,11,opt,name=resign_round_penalty,json=resignRoundPenalty,proto3" json:"resign_round_penalty,omitempty"`
+	HandSize            int32  `protobuf:"varint,12,opt,name=hand_size,json=handSize,proto3" json:"hand_size,omitempty"`
+	MinTurnsBeforeClose int32  `protobuf:"varint,13,opt,name=min_turns_before_close,json=minTurnsBeforeClose,proto3" json:"min_turns_before_close,omitempty"`
+	Teams               bool   `protobuf:"varint,14,opt,name=teams,proto3" json:"teams,omitempty"`
+	ChinchonMode        string `protobuf:"bytes,15,opt,name=chinchon_mode,json=chinchonMode,proto3" json:"chinchon_mode,omitempty"`
+	VerifiableShuffle   bool   `protobuf:"varint,16,opt,name=verifiable_shuffle,json=verifiableShuffle,proto3" json:"verifiable_shuffle,omitempty"`
+}
+
+func (x *Rules) Reset() {
+	*x = Rules{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_chinchon_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Rules) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rules) ProtoMessage() {}
+
+func (x *Rules) ProtoReflect() protoreflect.Message {
+	mi := &file_chinchon_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rules.ProtoReflect.Descriptor instead.
+func (*Rules) Descriptor() ([]byte, []int) {
+	return file_chinchon_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Rules) GetMaxPoints() int32 {
+	if x != nil {
+		return x.MaxPoints
+	}
+	return 0
+}
+
+func (x *Rules) GetMaxRounds() int32 {
+	if x != nil {
+		return x.MaxRounds
+	}
+	return 0
+}
+
+func (x *Rules) GetCloseThreshold() int32 {
+	if x != nil {
+		return x.CloseThreshold
+	}
+	return 0
+}
+
+func (x *Rules) GetCloseBonusMode() string {
+	if x != nil {
+		return x.CloseBonusMode
+	}
+	return ""
+}
+
+func (x *Rules) GetCloseTieMode() string {
+	if x != nil {
+		return x.CloseTieMode
+	}
+	return ""
+}
+
+func (x *Rules) GetReenter() bool {
+	if x != nil {
+		return x.Reenter
+	}
+	return false
+}
+
+func (x *Rules) GetLayOff() bool {
+	if x != nil {
+		return x.LayOff
+	}
+	return false
+}
+
+func (x *Rules) GetDeclaredMelds() bool {
+	if x != nil {
+		return x.DeclaredMelds
+	}
+	return false
+}
+
+func (x *Rules) GetAceWraparound() bool {
+	if x != nil {
+		return x.AceWraparound
+	}
+	return false
+}
+
+func (x *Rules) GetDeckType() string {
+	if x != nil {
+		return x.DeckType
+	}
+	return ""
+}
+
+func (x *Rules) GetResignRoundPenalty() int32 {
+	if x != nil {
+		return x.ResignRoundPenalty
+	}
+	return 0
+}
+
+func (x *Rules) GetHandSize() int32 {
+	if x != nil {
+		return x.HandSize
+	}
+	return 0
+}
+
+func (x *Rules) GetMinTurnsBeforeClose() int32 {
+	if x != nil {
+		return x.MinTurnsBeforeClose
+	}
+	return 0
+}
+
+func (x *Rules) GetTeams() bool {
+	if x != nil {
+		return x.Teams
+	}
+	return false
+}
+
+func (x *Rules) GetChinchonMode() string {
+	if x != nil {
+		return x.ChinchonMode
+	}
+	return ""
+}
+
+func (x *Rules) GetVerifiableShuffle() bool {
+	if x != nil {
+		return x.VerifiableShuffle
+	}
+	return false
+}
+
+type ClientPlayer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PlayerId      int32    `protobuf:"varint,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	Team          int32    `protobuf:"varint,2,opt,name=team,proto3" json:"team,omitempty"`
+	Score         int32    `protobuf:"varint,3,opt,name=score,proto3" json:"score,omitempty"`
+	HandSize      int32    `protobuf:"varint,4,opt,name=hand_size,json=handSize,proto3" json:"hand_size,omitempty"`
+	Hand          []*Card  `protobuf:"bytes,5,rep,name=hand,proto3" json:"hand,omitempty"`
+	Groups        []*Group `protobuf:"bytes,6,rep,name=groups,proto3" json:"groups,omitempty"`
+	PenaltyPoints int32    `protobuf:"varint,7,opt,name=penalty_points,json=penaltyPoints,proto3" json:"penalty_points,omitempty"`
+	// name and avatar are the player's display name and avatar emoji, if they have any.
+	Name   string `protobuf:"bytes,8,opt,name=name,proto3" json:"name,omitempty"`
+	Avatar string `protobuf:"bytes,9,opt,name=avatar,proto3" json:"avatar,omitempty"`
+}
+
+func (x *ClientPlayer) Reset() {
+	*x = ClientPlayer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_chinchon_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientPlayer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientPlayer) ProtoMessage() {}
+
+func (x *ClientPlayer) ProtoReflect() protoreflect.Message {
+	mi := &file_chinchon_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientPlayer.ProtoReflect.Descriptor instead.
+func (*ClientPlayer) Descriptor() ([]byte, []int) {
+	return file_chinchon_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ClientPlayer) GetPlayerId() int32 {
+	if x != nil {
+		return x.PlayerId
+	}
+	return 0
+}
+
+func (x *ClientPlayer) GetTeam() int32 {
+	if x != nil {
+		return x.Team
+	}
+	return 0
+}
+
+func (x *ClientPlayer) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *ClientPlayer) GetHandSize() int32 {
+	if x != nil {
+		return x.HandSize
+	}
+	return 0
+}
+
+func (x *ClientPlayer) GetHand() []*Card {
+	if x != nil {
+		return x.Hand
+	}
+	return nil
+}
+
+func (x *ClientPlayer) GetGroups() []*Group {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+func (x *ClientPlayer) GetPenaltyPoints() int32 {
+	if x != nil {
+		return x.PenaltyPoints
+	}
+	return 0
+}
+
+func (x *ClientPlayer) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ClientPlayer) GetAvatar() string {
+	if x != nil {
+		return x.Avatar
+	}
+	return ""
+}
+
+type Event struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type     string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	PlayerId int32  `protobuf:"varint,2,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	Card     *Card  `protobuf:"bytes,3,opt,name=card,proto3" json:"card,omitempty"`
+	From     string `protobuf:"bytes,4,opt,name=from,proto3" json:"from,omitempty"`
+	To       string `protobuf:"bytes,5,opt,name=to,proto3" json:"to,omitempty"`
+	FaceUp   bool   `protobuf:"varint,6,opt,name=face_up,json=faceUp,proto3" json:"face_up,omitempty"`
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_chinchon_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_chinchon_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_chinchon_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetPlayerId() int32 {
+	if x != nil {
+		return x.PlayerId
+	}
+	return 0
+}
+
+func (x *Event) GetCard() *Card {
+	if x != nil {
+		return x.Card
+	}
+	return nil
+}
+
+func (x *Event) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *Event) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *Event) GetFaceUp() bool {
+	if x != nil {
+		return x.FaceUp
+	}
+	return false
+}
+
+type DiscardEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Card             *Card `protobuf:"bytes,1,opt,name=card,proto3" json:"card,omitempty"`
+	PlayerId         int32 `protobuf:"varint,2,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	PickedByPlayerId int32 `protobuf:"varint,3,opt,name=picked_by_player_id,json=pickedByPlayerId,proto3" json:"picked_by_player_id,omitempty"`
+	Reshuffled       bool  `protobuf:"varint,4,opt,name=reshuffled,proto3" json:"reshuffled,omitempty"`
+}
+
+func (x *DiscardEntry) Reset() {
+	*x = DiscardEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_chinchon_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DiscardEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiscardEntry) ProtoMessage() {}
+
+func (x *DiscardEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_chinchon_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiscardEntry.ProtoReflect.Descriptor instead.
+func (*DiscardEntry) Descriptor() ([]byte, []int) {
+	return file_chinchon_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DiscardEntry) GetCard() *Card {
+	if x != nil {
+		return x.Card
+	}
+	return nil
+}
+
+func (x *DiscardEntry) GetPlayerId() int32 {
+	if x != nil {
+		return x.PlayerId
+	}
+	return 0
+}
+
+func (x *DiscardEntry) GetPickedByPlayerId() int32 {
+	if x != nil {
+		return x.PickedByPlayerId
+	}
+	return 0
+}
+
+func (x *DiscardEntry) GetReshuffled() bool {
+	if x != nil {
+		return x.Reshuffled
+	}
+	return false
+}
+
+type MatchState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BestOf       int32           `protobuf:"varint,1,opt,name=best_of,json=bestOf,proto3" json:"best_of,omitempty"`
+	GameNumber   int32           `protobuf:"varint,2,opt,name=game_number,json=gameNumber,proto3" json:"game_number,omitempty"`
+	GamesWon     map[int32]int32 `protobuf:"bytes,3,rep,name=games_won,json=gamesWon,proto3" json:"games_won,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	IsMatchEnded bool            `protobuf:"varint,4,opt,name=is_match_ended,json=isMatchEnded,proto3" json:"is_match_ended,omitempty"`
+	WinnerTeamId int32           `protobuf:"varint,5,opt,name=winner_team_id,json=winnerTeamId,proto3" json:"winner_team_id,omitempty"`
+}
+
+func (x *MatchState) Reset() {
+	*x = MatchState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_chinchon_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MatchState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatchState) ProtoMessage() {}
+
+func (x *MatchState) ProtoReflect() protoreflect.Message {
+	mi := &file_chinchon_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatchState.ProtoReflect.Descriptor instead.
+func (*MatchState) Descriptor() ([]byte, []int) {
+	return file_chinchon_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *MatchState) GetBestOf() int32 {
+	if x != nil {
+		return x.BestOf
+	}
+	return 0
+}
+
+func (x *MatchState) GetGameNumber() int32 {
+	if x != nil {
+		return x.GameNumber
+	}
+	return 0
+}
+
+func (x *MatchState) GetGamesWon() map[int32]int32 {
+	if x != nil {
+		return x.GamesWon
+	}
+	return nil
+}
+
+func (x *MatchState) GetIsMatchEnded() bool {
+	if x != nil {
+		return x.IsMatchEnded
+	}
+	return false
+}
+
+func (x *MatchState) GetWinnerTeamId() int32 {
+	if x != nil {
+		return x.WinnerTeamId
+	}
+	return 0
+}
+
+type PlayerStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DrawsFromDeck          int32   `protobuf:"varint,1,opt,name=draws_from_deck,json=drawsFromDeck,proto3" json:"draws_from_deck,omitempty"`
+	DrawsFromDiscard       int32   `protobuf:"varint,2,opt,name=draws_from_discard,json=drawsFromDiscard,proto3" json:"draws_from_discard,omitempty"`
+	RoundsClosed           int32   `protobuf:"varint,3,opt,name=rounds_closed,json=roundsClosed,proto3" json:"rounds_closed,omitempty"`
+	Chinchones             int32   `protobuf:"varint,4,opt,name=chinchones,proto3" json:"chinchones,omitempty"`
+	AverageDeadwoodAtClose float64 `protobuf:"fixed64,5,opt,name=average_deadwood_at_close,json=averageDeadwoodAtClose,proto3" json:"average_deadwood_at_close,omitempty"`
+}
+
+func (x *PlayerStats) Reset() {
+	*x = PlayerStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_chinchon_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PlayerStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerStats) ProtoMessage() {}
+
+func (x *PlayerStats) ProtoReflect() protoreflect.Message {
+	mi := &file_chinchon_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerStats.ProtoReflect.Descriptor instead.
+func (*PlayerStats) Descriptor() ([]byte, []int) {
+	return file_chinchon_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PlayerStats) GetDrawsFromDeck() int32 {
+	if x != nil {
+		return x.DrawsFromDeck
+	}
+	return 0
+}
+
+func (x *PlayerStats) GetDrawsFromDiscard() int32 {
+	if x != nil {
+		return x.DrawsFromDiscard
+	}
+	return 0
+}
+
+func (x *PlayerStats) GetRoundsClosed() int32 {
+	if x != nil {
+		return x.RoundsClosed
+	}
+	return 0
+}
+
+func (x *PlayerStats) GetChinchones() int32 {
+	if x != nil {
+		return x.Chinchones
+	}
+	return 0
+}
+
+func (x *PlayerStats) GetAverageDeadwoodAtClose() float64 {
+	if x != nil {
+		return x.AverageDeadwoodAtClose
+	}
+	return 0
+}
+
+type Stats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rounds  int32                  `protobuf:"varint,1,opt,name=rounds,proto3" json:"rounds,omitempty"`
+	Players map[int32]*PlayerStats `protobuf:"bytes,2,rep,name=players,proto3" json:"players,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *Stats) Reset() {
+	*x = Stats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_chinchon_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Stats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Stats) ProtoMessage() {}
+
+func (x *Stats) ProtoReflect() protoreflect.Message {
+	mi := &file_chinchon_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Stats.ProtoReflect.Descriptor instead.
+func (*Stats) Descriptor() ([]byte, []int) {
+	return file_chinchon_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Stats) GetRounds() int32 {
+	if x != nil {
+		return x.Rounds
+	}
+	return 0
+}
+
+func (x *Stats) GetPlayers() map[int32]*PlayerStats {
+	if x != nil {
+		return x.Players
+	}
+	return nil
+}
+
+// ClientGameState is the state of a game as available to a player.
+type ClientGameState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RoundNumber            int32           `protobuf:"varint,1,opt,name=round_number,json=roundNumber,proto3" json:"round_number,omitempty"`
+	TurnPlayerId           int32           `protobuf:"varint,2,opt,name=turn_player_id,json=turnPlayerId,proto3" json:"turn_player_id,omitempty"`
+	YouPlayerId            int32           `protobuf:"varint,3,opt,name=you_player_id,json=youPlayerId,proto3" json:"you_player_id,omitempty"`
+	ThemPlayerId           int32           `protobuf:"varint,4,opt,name=them_player_id,json=themPlayerId,proto3" json:"them_player_id,omitempty"`
+	YourScore              int32           `protobuf:"varint,5,opt,name=your_score,json=yourScore,proto3" json:"your_score,omitempty"`
+	TheirScore             int32           `protobuf:"varint,6,opt,name=their_score,json=theirScore,proto3" json:"their_score,omitempty"`
+	YourHand               []*Card         `protobuf:"bytes,7,rep,name=your_hand,json=yourHand,proto3" json:"your_hand,omitempty"`
+	TheirHandSize          int32           `protobuf:"varint,8,opt,name=their_hand_size,json=theirHandSize,proto3" json:"their_hand_size,omitempty"`
+	TheirHand              []*Card         `protobuf:"bytes,9,rep,name=their_hand,json=theirHand,proto3" json:"their_hand,omitempty"`
+	TopDiscardCard         *Card           `protobuf:"bytes,10,opt,name=top_discard_card,json=topDiscardCard,proto3" json:"top_discard_card,omitempty"`
+	DrawPileSize           int32           `protobuf:"varint,11,opt,name=draw_pile_size,json=drawPileSize,proto3" json:"draw_pile_size,omitempty"`
+	PossibleActions        []*Action       `protobuf:"bytes,12,rep,name=possible_actions,json=possibleActions,proto3" json:"possible_actions,omitempty"`
+	IsGameEnded            bool            `protobuf:"varint,13,opt,name=is_game_ended,json=isGameEnded,proto3" json:"is_game_ended,omitempty"`
+	IsRoundFinished        bool            `protobuf:"varint,14,opt,name=is_round_finished,json=isRoundFinished,proto3" json:"is_round_finished,omitempty"`
+	WinnerPlayerId         int32           `protobuf:"varint,15,opt,name=winner_player_id,json=winnerPlayerId,proto3" json:"winner_player_id,omitempty"`
+	LoserPlayerId          int32           `protobuf:"varint,16,opt,name=loser_player_id,json=loserPlayerId,proto3" json:"loser_player_id,omitempty"`
+	LastActionLog          *ActionLog      `protobuf:"bytes,17,opt,name=last_action_log,json=lastActionLog,proto3" json:"last_action_log,omitempty"`
+	Rules                  *Rules          `protobuf:"bytes,18,opt,name=rules,proto3" json:"rules,omitempty"`
+	HasDrawnCard           bool            `protobuf:"varint,19,opt,name=has_drawn_card,json=hasDrawnCard,proto3" json:"has_drawn_card,omitempty"`
+	TurnsBeforeYouCanClose int32           `protobuf:"varint,20,opt,name=turns_before_you_can_close,json=turnsBeforeYouCanClose,proto3" json:"turns_before_you_can_close,omitempty"`
+	TurnTimeRemainingMs    int64           `protobuf:"varint,21,opt,name=turn_time_remaining_ms,json=turnTimeRemainingMs,proto3" json:"turn_time_remaining_ms,omitempty"`
+	DeclaredGroups         []*Group        `protobuf:"bytes,22,rep,name=declared_groups,json=declaredGroups,proto3" json:"declared_groups,omitempty"`
+	IsLayingOff            bool            `protobuf:"varint,23,opt,name=is_laying_off,json=isLayingOff,proto3" json:"is_laying_off,omitempty"`
+	LayOffGroups           []*Group        `protobuf:"bytes,24,rep,name=lay_off_groups,json=layOffGroups,proto3" json:"lay_off_groups,omitempty"`
+	ReenterOfferedPlayerId int32           `protobuf:"varint,25,opt,name=reenter_offered_player_id,json=reenterOfferedPlayerId,proto3" json:"reenter_offered_player_id,omitempty"`
+	Players                []*ClientPlayer `protobuf:"bytes,26,rep,name=players,proto3" json:"players,omitempty"`
+	Match                  *MatchState     `protobuf:"bytes,27,opt,name=match,proto3" json:"match,omitempty"`
+	Stats                  *Stats          `protobuf:"bytes,28,opt,name=stats,proto3" json:"stats,omitempty"`
+	YourTeamId             int32           `protobuf:"varint,29,opt,name=your_team_id,json=yourTeamId,proto3" json:"your_team_id,omitempty"`
+	TeamScores             map[int32]int32 `protobuf:"bytes,30,rep,name=team_scores,json=teamScores,proto3" json:"team_scores,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	WinnerTeamId           int32           `protobuf:"varint,31,opt,name=winner_team_id,json=winnerTeamId,proto3" json:"winner_team_id,omitempty"`
+	LoserTeamId            int32           `protobuf:"varint,32,opt,name=loser_team_id,json=loserTeamId,proto3" json:"loser_team_id,omitempty"`
+	Events                 []*Event        `protobuf:"bytes,33,rep,name=events,proto3" json:"events,omitempty"`
+	StateHash              string          `protobuf:"bytes,34,opt,name=state_hash,json=stateHash,proto3" json:"state_hash,omitempty"`
+	ShuffleCommitment      string          `protobuf:"bytes,35,opt,name=shuffle_commitment,json=shuffleCommitment,proto3" json:"shuffle_commitment,omitempty"`
+	ShuffleSeed            string          `protobuf:"bytes,36,opt,name=shuffle_seed,json=shuffleSeed,proto3" json:"shuffle_seed,omitempty"`
+	DiscardHistory         []*DiscardEntry `protobuf:"bytes,37,rep,name=discard_history,json=discardHistory,proto3" json:"discard_history,omitempty"`
+	IsGameAborted          bool            `protobuf:"varint,38,opt,name=is_game_aborted,json=isGameAborted,proto3" json:"is_game_aborted,omitempty"`
+	// schema_version is the version of the chinchon package's JSON format this state mirrors.
+	SchemaVersion int32 `protobuf:"varint,39,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+}
+
+func (x *ClientGameState) Reset() {
+	*x = ClientGameState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_chinchon_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientGameState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientGameState) ProtoMessage() {}
+
+func (x *ClientGameState) ProtoReflect() protoreflect.Message {
+	mi := &file_chinchon_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientGameState.ProtoReflect.Descriptor instead.
+func (*ClientGameState) Descriptor() ([]byte, []int) {
+	return file_chinchon_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ClientGameState) GetRoundNumber() int32 {
+	if x != nil {
+		return x.RoundNumber
+	}
+	return 0
+}
+
+func (x *ClientGameState) GetTurnPlayerId() int32 {
+	if x != nil {
+		return x.TurnPlayerId
+	}
+	return 0
+}
+
+func (x *ClientGameState) GetYouPlayerId() int32 {
+	if x != nil {
+		return x.YouPlayerId
+	}
+	return 0
+}
+
+func (x *ClientGameState) GetThemPlayerId() int32 {
+	if x != nil {
+		return x.ThemPlayerId
+	}
+	return 0
+}
+
+func (x *ClientGameState) GetYourScore() int32 {
+	if x != nil {
+		return x.YourScore
+	}
+	return 0
+}
+
+func (x *ClientGameState) GetTheirScore() int32 {
+	if x != nil {
+		return x.TheirScore
+	}
+	return 0
+}
+
+func (x *ClientGameState) GetYourHand() []*Card {
+	if x != nil {
+		return x.YourHand
+	}
+	return nil
+}
+
+func (x *ClientGameState) GetTheirHandSize() int32 {
+	if x != nil {
+		return x.TheirHandSize
+	}
+	return 0
+}
+
+func (x *ClientGameState) GetTheirHand() []*Card {
+	if x != nil {
+		return x.TheirHand
+	}
+	return nil
+}
+
+func (x *ClientGameState) GetTopDiscardCard() *Card {
+	if x != nil {
+		return x.TopDiscardCard
+	}
+	return nil
+}
+
+func (x *ClientGameState) GetDrawPileSize() int32 {
+	if x != nil {
+		return x.DrawPileSize
+	}
+	return 0
+}
+
+func (x *ClientGameState) GetPossibleActions() []*Action {
+	if x != nil {
+		return x.PossibleActions
+	}
+	return nil
+}
+
+func (x *ClientGameState) GetIsGameEnded() bool {
+	if x != nil {
+		return x.IsGameEnded
+	}
+	return false
+}
+
+func (x *ClientGameState) GetIsRoundFinished() bool {
+	if x != nil {
+		return x.IsRoundFinished
+	}
+	return false
+}
+
+func (x *ClientGameState) GetWinnerPlayerId() int32 {
+	if x != nil {
+		return x.WinnerPlayerId
+	}
+	return 0
+}
+
+func (x *ClientGameState) GetLoserPlayerId() int32 {
+	if x != nil {
+		return x.LoserPlayerId
+	}
+	return 0
+}
+
+func (x *ClientGameState) GetLastActionLog() *ActionLog {
+	if x != nil {
+		return x.LastActionLog
+	}
+	return nil
+}
+
+func (x *ClientGameState) GetRules() *Rules {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *ClientGameState) GetHasDrawnCard() bool {
+	if x != nil {
+		return x.HasDrawnCard
+	}
+	return false
+}
+
+func (x *ClientGameState) GetTurnsBeforeYouCanClose() int32 {
+	if x != nil {
+		return x.TurnsBeforeYouCanClose
+	}
+	return 0
+}
+
+func (x *ClientGameState) GetTurnTimeRemainingMs() int64 {
+	if x != nil {
+		return x.TurnTimeRemainingMs
+	}
+	return 0
+}
+
+func (x *ClientGameState) GetDeclaredGroups() []*Group {
+	if x != nil {
+		return x.DeclaredGroups
+	}
+	return nil
+}
+
+func (x *ClientGameState) GetIsLayingOff() bool {
+	if x != nil {
+		return x.IsLayingOff
+	}
+	return false
+}
+
+func (x *ClientGameState) GetLayOffGroups() []*Group {
+	if x != nil {
+		return x.LayOffGroups
+	}
+	return nil
+}
+
+func (x *ClientGameState) GetReenterOfferedPlayerId() int32 {
+	if x != nil {
+		return x.ReenterOfferedPlayerId
+	}
+	return 0
+}
+
+func (x *ClientGameState) GetPlayers() []*ClientPlayer {
+	if x != nil {
+		return x.Players
+	}
+	return nil
+}
+
+func (x *ClientGameState) GetMatch() *MatchState {
+	if x != nil {
+		return x.Match
+	}
+	return nil
+}
+
+func (x *ClientGameState) GetStats() *Stats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *ClientGameState) GetYourTeamId() int32 {
+	if x != nil {
+		return x.YourTeamId
+	}
+	return 0
+}
+
+func (x *ClientGameState) GetTeamScores() map[int32]int32 {
+	if x != nil {
+		return x.TeamScores
+	}
+	return nil
+}
+
+func (x *ClientGameState) GetWinnerTeamId() int32 {
+	if x != nil {
+		return x.WinnerTeamId
+	}
+	return 0
+}
+
+func (x *ClientGameState) GetLoserTeamId() int32 {
+	if x != nil {
+		return x.LoserTeamId
+	}
+	return 0
+}
+
+func (x *ClientGameState) GetEvents() []*Event {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *ClientGameState) GetStateHash() string {
+	if x != nil {
+		return x.StateHash
+	}
+	return ""
+}
+
+func (x *ClientGameState) GetShuffleCommitment() string {
+	if x != nil {
+		return x.ShuffleCommitment
+	}
+	return ""
+}
+
+func (x *ClientGameState) GetShuffleSeed() string {
+	if x != nil {
+		return x.ShuffleSeed
+	}
+	return ""
+}
+
+func (x *ClientGameState) GetDiscardHistory() []*DiscardEntry {
+	if x != nil {
+		return x.DiscardHistory
+	}
+	return nil
+}
+
+func (x *ClientGameState) GetIsGameAborted() bool {
+	if x != nil {
+		return x.IsGameAborted
+	}
+	return false
+}
+
+func (x *ClientGameState) GetSchemaVersion() int32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+type Player struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PlayerId int32   `protobuf:"varint,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	Hand     []*Card `protobuf:"bytes,2,rep,name=hand,proto3" json:"hand,omitempty"`
+	Score    int32   `protobuf:"varint,3,opt,name=score,proto3" json:"score,omitempty"`
+	Team     int32   `protobuf:"varint,4,opt,name=team,proto3" json:"team,omitempty"`
+	HandSort string  `protobuf:"bytes,5,opt,name=hand_sort,json=handSort,proto3" json:"hand_sort,omitempty"`
+}
+
+func (x *Player) Reset() {
+	*x = Player{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_chinchon_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Player) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Player) ProtoMessage() {}
+
+func (x *Player) ProtoReflect() protoreflect.Message {
+	mi := &file_chinchon_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Player.ProtoReflect.Descriptor instead.
+func (*Player) Descriptor() ([]byte, []int) {
+	return file_chinchon_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Player) GetPlayerId() int32 {
+	if x != nil {
+		return x.PlayerId
+	}
+	return 0
+}
+
+func (x *Player) GetHand() []*Card {
+	if x != nil {
+		return x.Hand
+	}
+	return nil
+}
+
+func (x *Player) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *Player) GetTeam() int32 {
+	if x != nil {
+		return x.Team
+	}
+	return 0
+}
+
+func (x *Player) GetHandSort() string {
+	if x != nil {
+		return x.HandSort
+	}
+	return ""
+}
+
+// GameState is the whole state of a game, e.g. for tools and spectators with full access.
+// Unlike the chinchon package's Save, it doesn't include the draw pile's order, so it can't
+// be used to restore games.
+type GameState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RoundNumber     int32     `protobuf:"varint,1,opt,name=round_number,json=roundNumber,proto3" json:"round_number,omitempty"`
+	TurnPlayerId    int32     `protobuf:"varint,2,opt,name=turn_player_id,json=turnPlayerId,proto3" json:"turn_player_id,omitempty"`
+	Players         []*Player `protobuf:"bytes,3,rep,name=players,proto3" json:"players,omitempty"`
+	DiscardPile     []*Card   `protobuf:"bytes,4,rep,name=discard_pile,json=discardPile,proto3" json:"discard_pile,omitempty"`
+	DrawPileSize    int32     `protobuf:"varint,5,opt,name=draw_pile_size,json=drawPileSize,proto3" json:"draw_pile_size,omitempty"`
+	HasDrawnCard    bool      `protobuf:"varint,6,opt,name=has_drawn_card,json=hasDrawnCard,proto3" json:"has_drawn_card,omitempty"`
+	IsRoundFinished bool      `protobuf:"varint,7,opt,name=is_round_finished,json=isRoundFinished,proto3" json:"is_round_finished,omitempty"`
+	IsGameEnded     bool      `protobuf:"varint,8,opt,name=is_game_ended,json=isGameEnded,proto3" json:"is_game_ended,omitempty"`
+	WinnerPlayerId  int32     `protobuf:"varint,9,opt,name=winner_player_id,json=winnerPlayerId,proto3" json:"winner_player_id,omitempty"`
+	LoserPlayerId   int32     `protobuf:"varint,10,opt,name=loser_player_id,json=loserPlayerId,proto3" json:"loser_player_id,omitempty"`
+	WinnerTeamId    int32     `protobuf:"varint,11,opt,name=winner_team_id,json=winnerTeamId,proto3" json:"winner_team_id,omitempty"`
+	LoserTeamId     int32     `protobuf:"varint,12,opt,name=loser_team_id,json=loserTeamId,proto3" json:"loser_team_id,omitempty"`
+	Rules           *Rules    `protobuf:"bytes,13,opt,name=rules,proto3" json:"rules,omitempty"`
+	PossibleActions []*Action `protobuf:"bytes,14,rep,name=possible_actions,json=possibleActions,proto3" json:"possible_actions,omitempty"`
+	IsGameAborted   bool      `protobuf:"varint,15,opt,name=is_game_aborted,json=isGameAborted,proto3" json:"is_game_aborted,omitempty"`
+}
+
+func (x *GameState) Reset() {
+	*x = GameState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_chinchon_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GameState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameState) ProtoMessage() {}
+
+func (x *GameState) ProtoReflect() protoreflect.Message {
+	mi := &file_chinchon_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameState.ProtoReflect.Descriptor instead.
+func (*GameState) Descriptor() ([]byte, []int) {
+	return file_chinchon_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GameState) GetRoundNumber() int32 {
+	if x != nil {
+		return x.RoundNumber
+	}
+	return 0
+}
+
+func (x *GameState) GetTurnPlayerId() int32 {
+	if x != nil {
+		return x.TurnPlayerId
+	}
+	return 0
+}
+
+func (x *GameState) GetPlayers() []*Player {
+	if x != nil {
+		return x.Players
+	}
+	return nil
+}
+
+func (x *GameState) GetDiscardPile() []*Card {
+	if x != nil {
+		return x.DiscardPile
+	}
+	return nil
+}
+
+func (x *GameState) GetDrawPileSize() int32 {
+	if x != nil {
+		return x.DrawPileSize
+	}
+	return 0
+}
+
+func (x *GameState) GetHasDrawnCard() bool {
+	if x != nil {
+		return x.HasDrawnCard
+	}
+	return false
+}
+
+func (x *GameState) GetIsRoundFinished() bool {
+	if x != nil {
+		return x.IsRoundFinished
+	}
+	return false
+}
+
+func (x *GameState) GetIsGameEnded() bool {
+	if x != nil {
+		return x.IsGameEnded
+	}
+	return false
+}
+
+func (x *GameState) GetWinnerPlayerId() int32 {
+	if x != nil {
+		return x.WinnerPlayerId
+	}
+	return 0
+}
+
+func (x *GameState) GetLoserPlayerId() int32 {
+	if x != nil {
+		return x.LoserPlayerId
+	}
+	return 0
+}
+
+func (x *GameState) GetWinnerTeamId() int32 {
+	if x != nil {
+		return x.WinnerTeamId
+	}
+	return 0
+}
+
+func (x *GameState) GetLoserTeamId() int32 {
+	if x != nil {
+		return x.LoserTeamId
+	}
+	return 0
+}
+
+func (x *GameState) GetRules() *Rules {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *GameState) GetPossibleActions() []*Action {
+	if x != nil {
+		return x.PossibleActions
+	}
+	return nil
+}
+
+func (x *GameState) GetIsGameAborted() bool {
+	if x != nil {
+		return x.IsGameAborted
+	}
+	return false
+}
+
+var File_chinchon_proto protoreflect.FileDescriptor
+
+var file_chinchon_proto_rawDesc = []byte{
+	0x0a, 0x0e, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x0b, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x22, 0x32, 0x0a,
+	0x04, 0x43, 0x61, 0x72, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x75, 0x69, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x75, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6e, 0x75, 0x6d,
+	0x62, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65,
+	0x72, 0x22, 0x30, 0x0a, 0x05, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x27, 0x0a, 0x05, 0x63, 0x61,
+	0x72, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63, 0x68, 0x69, 0x6e,
+	0x63, 0x68, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x72, 0x64, 0x52, 0x05, 0x63, 0x61,
+	0x72, 0x64, 0x73, 0x22, 0xea, 0x01, 0x0a, 0x06, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12,
+	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x49, 0x64, 0x12,
+	0x25, 0x0a, 0x04, 0x63, 0x61, 0x72, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e,
+	0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x72, 0x64,
+	0x52, 0x04, 0x63, 0x61, 0x72, 0x64, 0x12, 0x2a, 0x0a, 0x06, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73,
+	0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f,
+	0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x06, 0x67, 0x72, 0x6f, 0x75,
+	0x70, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f, 0x69, 0x6e, 0x64, 0x65,
+	0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x49, 0x6e,
+	0x64, 0x65, 0x78, 0x12, 0x27, 0x0a, 0x05, 0x63, 0x61, 0x72, 0x64, 0x73, 0x18, 0x06, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x61, 0x72, 0x64, 0x52, 0x05, 0x63, 0x61, 0x72, 0x64, 0x73, 0x12, 0x12, 0x0a, 0x04,
+	0x73, 0x6f, 0x72, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6f, 0x72, 0x74,
+	0x22, 0x79, 0x0a, 0x09, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4c, 0x6f, 0x67, 0x12, 0x1b, 0x0a,
+	0x09, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x08, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x49, 0x64, 0x12, 0x2b, 0x0a, 0x06, 0x61, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x63, 0x68, 0x69,
+	0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x22, 0x0a, 0x0d, 0x70, 0x6c, 0x61, 0x79, 0x65,
+	0x64, 0x5f, 0x62, 0x79, 0x5f, 0x62, 0x6f, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b,
+	0x70, 0x6c, 0x61, 0x79, 0x65, 0x64, 0x42, 0x79, 0x42, 0x6f, 0x74, 0x22, 0xca, 0x04, 0x0a, 0x05,
+	0x52, 0x75, 0x6c, 0x65, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x61, 0x78, 0x5f, 0x70, 0x6f, 0x69,
+	0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x6d, 0x61, 0x78, 0x50, 0x6f,
+	0x69, 0x6e, 0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x61, 0x78, 0x5f, 0x72, 0x6f, 0x75, 0x6e,
+	0x64, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x6d, 0x61, 0x78, 0x52, 0x6f, 0x75,
+	0x6e, 0x64, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x5f, 0x74, 0x68, 0x72,
+	0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x63, 0x6c,
+	0x6f, 0x73, 0x65, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x28, 0x0a, 0x10,
+	0x63, 0x6c, 0x6f, 0x73, 0x65, 0x5f, 0x62, 0x6f, 0x6e, 0x75, 0x73, 0x5f, 0x6d, 0x6f, 0x64, 0x65,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x42, 0x6f, 0x6e,
+	0x75, 0x73, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x24, 0x0a, 0x0e, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x5f,
+	0x74, 0x69, 0x65, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c,
+	0x63, 0x6c, 0x6f, 0x73, 0x65, 0x54, 0x69, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07,
+	0x72, 0x65, 0x65, 0x6e, 0x74, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x72,
+	0x65, 0x65, 0x6e, 0x74, 0x65, 0x72, 0x12, 0x17, 0x0a, 0x07, 0x6c, 0x61, 0x79, 0x5f, 0x6f, 0x66,
+	0x66, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x6c, 0x61, 0x79, 0x4f, 0x66, 0x66, 0x12,
+	0x25, 0x0a, 0x0e, 0x64, 0x65, 0x63, 0x6c, 0x61, 0x72, 0x65, 0x64, 0x5f, 0x6d, 0x65, 0x6c, 0x64,
+	0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x64, 0x65, 0x63, 0x6c, 0x61, 0x72, 0x65,
+	0x64, 0x4d, 0x65, 0x6c, 0x64, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x61, 0x63, 0x65, 0x5f, 0x77, 0x72,
+	0x61, 0x70, 0x61, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d,
+	0x61, 0x63, 0x65, 0x57, 0x72, 0x61, 0x70, 0x61, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x1b, 0x0a,
+	0x09, 0x64, 0x65, 0x63, 0x6b, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x64, 0x65, 0x63, 0x6b, 0x54, 0x79, 0x70, 0x65, 0x12, 0x30, 0x0a, 0x14, 0x72, 0x65,
+	0x73, 0x69, 0x67, 0x6e, 0x5f, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x5f, 0x70, 0x65, 0x6e, 0x61, 0x6c,
+	0x74, 0x79, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x12, 0x72, 0x65, 0x73, 0x69, 0x67, 0x6e,
+	0x52, 0x6f, 0x75, 0x6e, 0x64, 0x50, 0x65, 0x6e, 0x61, 0x6c, 0x74, 0x79, 0x12, 0x1b, 0x0a, 0x09,
+	0x68, 0x61, 0x6e, 0x64, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x08, 0x68, 0x61, 0x6e, 0x64, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x33, 0x0a, 0x16, 0x6d, 0x69, 0x6e,
+	0x5f, 0x74, 0x75, 0x72, 0x6e, 0x73, 0x5f, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x5f, 0x63, 0x6c,
+	0x6f, 0x73, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x05, 0x52, 0x13, 0x6d, 0x69, 0x6e, 0x54, 0x75,
+	0x72, 0x6e, 0x73, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x12, 0x14,
+	0x0a, 0x05, 0x74, 0x65, 0x61, 0x6d, 0x73, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x74,
+	0x65, 0x61, 0x6d, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e,
+	0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x68, 0x69,
+	0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x2d, 0x0a, 0x12, 0x76, 0x65, 0x72,
+	0x69, 0x66, 0x69, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x73, 0x68, 0x75, 0x66, 0x66, 0x6c, 0x65, 0x18,
+	0x10, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x61, 0x62, 0x6c,
+	0x65, 0x53, 0x68, 0x75, 0x66, 0x66, 0x6c, 0x65, 0x22, 0x98, 0x02, 0x0a, 0x0c, 0x43, 0x6c, 0x69,
+	0x65, 0x6e, 0x74, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x6c, 0x61,
+	0x79, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x6c,
+	0x61, 0x79, 0x65, 0x72, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x61, 0x6d, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x74, 0x65, 0x61, 0x6d, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63,
+	0x6f, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65,
+	0x12, 0x1b, 0x0a, 0x09, 0x68, 0x61, 0x6e, 0x64, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x08, 0x68, 0x61, 0x6e, 0x64, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x25, 0x0a,
+	0x04, 0x68, 0x61, 0x6e, 0x64, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63, 0x68,
+	0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x72, 0x64, 0x52, 0x04,
+	0x68, 0x61, 0x6e, 0x64, 0x12, 0x2a, 0x0a, 0x06, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x18, 0x06,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2e,
+	0x76, 0x31, 0x2e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x06, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73,
+	0x12, 0x25, 0x0a, 0x0e, 0x70, 0x65, 0x6e, 0x61, 0x6c, 0x74, 0x79, 0x5f, 0x70, 0x6f, 0x69, 0x6e,
+	0x74, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x70, 0x65, 0x6e, 0x61, 0x6c, 0x74,
+	0x79, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x61,
+	0x76, 0x61, 0x74, 0x61, 0x72, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x76, 0x61,
+	0x74, 0x61, 0x72, 0x22, 0x9c, 0x01, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70,
+	0x65, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x49, 0x64, 0x12, 0x25,
+	0x0a, 0x04, 0x63, 0x61, 0x72, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63,
+	0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x72, 0x64, 0x52,
+	0x04, 0x63, 0x61, 0x72, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x74, 0x6f, 0x12, 0x17, 0x0a, 0x07, 0x66, 0x61, 0x63,
+	0x65, 0x5f, 0x75, 0x70, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x66, 0x61, 0x63, 0x65,
+	0x55, 0x70, 0x22, 0xa1, 0x01, 0x0a, 0x0c, 0x44, 0x69, 0x73, 0x63, 0x61, 0x72, 0x64, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x12, 0x25, 0x0a, 0x04, 0x63, 0x61, 0x72, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x11, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e,
+	0x43, 0x61, 0x72, 0x64, 0x52, 0x04, 0x63, 0x61, 0x72, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x6c,
+	0x61, 0x79, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70,
+	0x6c, 0x61, 0x79, 0x65, 0x72, 0x49, 0x64, 0x12, 0x2d, 0x0a, 0x13, 0x70, 0x69, 0x63, 0x6b, 0x65,
+	0x64, 0x5f, 0x62, 0x79, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x10, 0x70, 0x69, 0x63, 0x6b, 0x65, 0x64, 0x42, 0x79, 0x50, 0x6c,
+	0x61, 0x79, 0x65, 0x72, 0x49, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x72, 0x65, 0x73, 0x68, 0x75, 0x66,
+	0x66, 0x6c, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x72, 0x65, 0x73, 0x68,
+	0x75, 0x66, 0x66, 0x6c, 0x65, 0x64, 0x22, 0x93, 0x02, 0x0a, 0x0a, 0x4d, 0x61, 0x74, 0x63, 0x68,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x62, 0x65, 0x73, 0x74, 0x5f, 0x6f, 0x66,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x62, 0x65, 0x73, 0x74, 0x4f, 0x66, 0x12, 0x1f,
+	0x0a, 0x0b, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x0a, 0x67, 0x61, 0x6d, 0x65, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12,
+	0x42, 0x0a, 0x09, 0x67, 0x61, 0x6d, 0x65, 0x73, 0x5f, 0x77, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x25, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2e, 0x76, 0x31,
+	0x2e, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x53, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x47, 0x61, 0x6d, 0x65,
+	0x73, 0x57, 0x6f, 0x6e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x67, 0x61, 0x6d, 0x65, 0x73,
+	0x57, 0x6f, 0x6e, 0x12, 0x24, 0x0a, 0x0e, 0x69, 0x73, 0x5f, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x5f,
+	0x65, 0x6e, 0x64, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x69, 0x73, 0x4d,
+	0x61, 0x74, 0x63, 0x68, 0x45, 0x6e, 0x64, 0x65, 0x64, 0x12, 0x24, 0x0a, 0x0e, 0x77, 0x69, 0x6e,
+	0x6e, 0x65, 0x72, 0x5f, 0x74, 0x65, 0x61, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0c, 0x77, 0x69, 0x6e, 0x6e, 0x65, 0x72, 0x54, 0x65, 0x61, 0x6d, 0x49, 0x64, 0x1a,
+	0x3b, 0x0a, 0x0d, 0x47, 0x61, 0x6d, 0x65, 0x73, 0x57, 0x6f, 0x6e, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xe3, 0x01, 0x0a,
+	0x0b, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x26, 0x0a, 0x0f,
+	0x64, 0x72, 0x61, 0x77, 0x73, 0x5f, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x64, 0x65, 0x63, 0x6b, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x64, 0x72, 0x61, 0x77, 0x73, 0x46, 0x72, 0x6f, 0x6d,
+	0x44, 0x65, 0x63, 0x6b, 0x12, 0x2c, 0x0a, 0x12, 0x64, 0x72, 0x61, 0x77, 0x73, 0x5f, 0x66, 0x72,
+	0x6f, 0x6d, 0x5f, 0x64, 0x69, 0x73, 0x63, 0x61, 0x72, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x10, 0x64, 0x72, 0x61, 0x77, 0x73, 0x46, 0x72, 0x6f, 0x6d, 0x44, 0x69, 0x73, 0x63, 0x61,
+	0x72, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x5f, 0x63, 0x6c, 0x6f,
+	0x73, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x72, 0x6f, 0x75, 0x6e, 0x64,
+	0x73, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x68, 0x69, 0x6e, 0x63,
+	0x68, 0x6f, 0x6e, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x63, 0x68, 0x69,
+	0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x65, 0x73, 0x12, 0x39, 0x0a, 0x19, 0x61, 0x76, 0x65, 0x72, 0x61,
+	0x67, 0x65, 0x5f, 0x64, 0x65, 0x61, 0x64, 0x77, 0x6f, 0x6f, 0x64, 0x5f, 0x61, 0x74, 0x5f, 0x63,
+	0x6c, 0x6f, 0x73, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x16, 0x61, 0x76, 0x65, 0x72,
+	0x61, 0x67, 0x65, 0x44, 0x65, 0x61, 0x64, 0x77, 0x6f, 0x6f, 0x64, 0x41, 0x74, 0x43, 0x6c, 0x6f,
+	0x73, 0x65, 0x22, 0xb0, 0x01, 0x0a, 0x05, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x16, 0x0a, 0x06,
+	0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x72, 0x6f,
+	0x75, 0x6e, 0x64, 0x73, 0x12, 0x39, 0x0a, 0x07, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x2e, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x1a,
+	0x54, 0x0a, 0x0c, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x2e, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x18, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x50,
+	0x6c, 0x61, 0x79, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xc2, 0x0e, 0x0a, 0x0f, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x47, 0x61, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x6f, 0x75,
+	0x6e, 0x64, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0b, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x24, 0x0a, 0x0e,
+	0x74, 0x75, 0x72, 0x6e, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x74, 0x75, 0x72, 0x6e, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72,
+	0x49, 0x64, 0x12, 0x22, 0x0a, 0x0d, 0x79, 0x6f, 0x75, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72,
+	0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x79, 0x6f, 0x75, 0x50, 0x6c,
+	0x61, 0x79, 0x65, 0x72, 0x49, 0x64, 0x12, 0x24, 0x0a, 0x0e, 0x74, 0x68, 0x65, 0x6d, 0x5f, 0x70,
+	0x6c, 0x61, 0x79, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c,
+	0x74, 0x68, 0x65, 0x6d, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a,
+	0x79, 0x6f, 0x75, 0x72, 0x5f, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x09, 0x79, 0x6f, 0x75, 0x72, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x74,
+	0x68, 0x65, 0x69, 0x72, 0x5f, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0a, 0x74, 0x68, 0x65, 0x69, 0x72, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x2e, 0x0a, 0x09,
+	0x79, 0x6f, 0x75, 0x72, 0x5f, 0x68, 0x61, 0x6e, 0x64, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x11, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61,
+	0x72, 0x64, 0x52, 0x08, 0x79, 0x6f, 0x75, 0x72, 0x48, 0x61, 0x6e, 0x64, 0x12, 0x26, 0x0a, 0x0f,
+	0x74, 0x68, 0x65, 0x69, 0x72, 0x5f, 0x68, 0x61, 0x6e, 0x64, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x74, 0x68, 0x65, 0x69, 0x72, 0x48, 0x61, 0x6e, 0x64,
+	0x53, 0x69, 0x7a, 0x65, 0x12, 0x30, 0x0a, 0x0a, 0x74, 0x68, 0x65, 0x69, 0x72, 0x5f, 0x68, 0x61,
+	0x6e, 0x64, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63,
+	0x68, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x72, 0x64, 0x52, 0x09, 0x74, 0x68, 0x65,
+	0x69, 0x72, 0x48, 0x61, 0x6e, 0x64, 0x12, 0x3b, 0x0a, 0x10, 0x74, 0x6f, 0x70, 0x5f, 0x64, 0x69,
+	0x73, 0x63, 0x61, 0x72, 0x64, 0x5f, 0x63, 0x61, 0x72, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x11, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43,
+	0x61, 0x72, 0x64, 0x52, 0x0e, 0x74, 0x6f, 0x70, 0x44, 0x69, 0x73, 0x63, 0x61, 0x72, 0x64, 0x43,
+	0x61, 0x72, 0x64, 0x12, 0x24, 0x0a, 0x0e, 0x64, 0x72, 0x61, 0x77, 0x5f, 0x70, 0x69, 0x6c, 0x65,
+	0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x64, 0x72, 0x61,
+	0x77, 0x50, 0x69, 0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x3e, 0x0a, 0x10, 0x70, 0x6f, 0x73,
+	0x73, 0x69, 0x62, 0x6c, 0x65, 0x5f, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x0c, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2e, 0x76,
+	0x31, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0f, 0x70, 0x6f, 0x73, 0x73, 0x69, 0x62,
+	0x6c, 0x65, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x22, 0x0a, 0x0d, 0x69, 0x73, 0x5f,
+	0x67, 0x61, 0x6d, 0x65, 0x5f, 0x65, 0x6e, 0x64, 0x65, 0x64, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0b, 0x69, 0x73, 0x47, 0x61, 0x6d, 0x65, 0x45, 0x6e, 0x64, 0x65, 0x64, 0x12, 0x2a, 0x0a,
+	0x11, 0x69, 0x73, 0x5f, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x5f, 0x66, 0x69, 0x6e, 0x69, 0x73, 0x68,
+	0x65, 0x64, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x69, 0x73, 0x52, 0x6f, 0x75, 0x6e,
+	0x64, 0x46, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x65, 0x64, 0x12, 0x28, 0x0a, 0x10, 0x77, 0x69, 0x6e,
+	0x6e, 0x65, 0x72, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x0f, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x0e, 0x77, 0x69, 0x6e, 0x6e, 0x65, 0x72, 0x50, 0x6c, 0x61, 0x79, 0x65,
+	0x72, 0x49, 0x64, 0x12, 0x26, 0x0a, 0x0f, 0x6c, 0x6f, 0x73, 0x65, 0x72, 0x5f, 0x70, 0x6c, 0x61,
+	0x79, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x10, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x6c, 0x6f,
+	0x73, 0x65, 0x72, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x49, 0x64, 0x12, 0x3e, 0x0a, 0x0f, 0x6c,
+	0x61, 0x73, 0x74, 0x5f, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6c, 0x6f, 0x67, 0x18, 0x11,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2e,
+	0x76, 0x31, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4c, 0x6f, 0x67, 0x52, 0x0d, 0x6c, 0x61,
+	0x73, 0x74, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4c, 0x6f, 0x67, 0x12, 0x28, 0x0a, 0x05, 0x72,
+	0x75, 0x6c, 0x65, 0x73, 0x18, 0x12, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x68, 0x69,
+	0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x52, 0x05,
+	0x72, 0x75, 0x6c, 0x65, 0x73, 0x12, 0x24, 0x0a, 0x0e, 0x68, 0x61, 0x73, 0x5f, 0x64, 0x72, 0x61,
+	0x77, 0x6e, 0x5f, 0x63, 0x61, 0x72, 0x64, 0x18, 0x13, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x68,
+	0x61, 0x73, 0x44, 0x72, 0x61, 0x77, 0x6e, 0x43, 0x61, 0x72, 0x64, 0x12, 0x3a, 0x0a, 0x1a, 0x74,
+	0x75, 0x72, 0x6e, 0x73, 0x5f, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x5f, 0x79, 0x6f, 0x75, 0x5f,
+	0x63, 0x61, 0x6e, 0x5f, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x18, 0x14, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x16, 0x74, 0x75, 0x72, 0x6e, 0x73, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x59, 0x6f, 0x75, 0x43,
+	0x61, 0x6e, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x12, 0x33, 0x0a, 0x16, 0x74, 0x75, 0x72, 0x6e, 0x5f,
+	0x74, 0x69, 0x6d, 0x65, 0x5f, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x6d,
+	0x73, 0x18, 0x15, 0x20, 0x01, 0x28, 0x03, 0x52, 0x13, 0x74, 0x75, 0x72, 0x6e, 0x54, 0x69, 0x6d,
+	0x65, 0x52, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x4d, 0x73, 0x12, 0x3b, 0x0a, 0x0f,
+	0x64, 0x65, 0x63, 0x6c, 0x61, 0x72, 0x65, 0x64, 0x5f, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x18,
+	0x16, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x0e, 0x64, 0x65, 0x63, 0x6c, 0x61,
+	0x72, 0x65, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x12, 0x22, 0x0a, 0x0d, 0x69, 0x73, 0x5f,
+	0x6c, 0x61, 0x79, 0x69, 0x6e, 0x67, 0x5f, 0x6f, 0x66, 0x66, 0x18, 0x17, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0b, 0x69, 0x73, 0x4c, 0x61, 0x79, 0x69, 0x6e, 0x67, 0x4f, 0x66, 0x66, 0x12, 0x38, 0x0a,
+	0x0e, 0x6c, 0x61, 0x79, 0x5f, 0x6f, 0x66, 0x66, 0x5f, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x18,
+	0x18, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x0c, 0x6c, 0x61, 0x79, 0x4f, 0x66,
+	0x66, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x12, 0x39, 0x0a, 0x19, 0x72, 0x65, 0x65, 0x6e, 0x74,
+	0x65, 0x72, 0x5f, 0x6f, 0x66, 0x66, 0x65, 0x72, 0x65, 0x64, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x65,
+	0x72, 0x5f, 0x69, 0x64, 0x18, 0x19, 0x20, 0x01, 0x28, 0x05, 0x52, 0x16, 0x72, 0x65, 0x65, 0x6e,
+	0x74, 0x65, 0x72, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x65, 0x64, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72,
+	0x49, 0x64, 0x12, 0x33, 0x0a, 0x07, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x18, 0x1a, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2e, 0x76,
+	0x31, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x52, 0x07,
+	0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x12, 0x2d, 0x0a, 0x05, 0x6d, 0x61, 0x74, 0x63, 0x68,
+	0x18, 0x1b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f,
+	0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52,
+	0x05, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x12, 0x28, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x73, 0x18,
+	0x1c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x73,
+	0x12, 0x20, 0x0a, 0x0c, 0x79, 0x6f, 0x75, 0x72, 0x5f, 0x74, 0x65, 0x61, 0x6d, 0x5f, 0x69, 0x64,
+	0x18, 0x1d, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x79, 0x6f, 0x75, 0x72, 0x54, 0x65, 0x61, 0x6d,
+	0x49, 0x64, 0x12, 0x4d, 0x0a, 0x0b, 0x74, 0x65, 0x61, 0x6d, 0x5f, 0x73, 0x63, 0x6f, 0x72, 0x65,
+	0x73, 0x18, 0x1e, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68,
+	0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x47, 0x61, 0x6d, 0x65,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x54, 0x65, 0x61, 0x6d, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0a, 0x74, 0x65, 0x61, 0x6d, 0x53, 0x63, 0x6f, 0x72, 0x65,
+	0x73, 0x12, 0x24, 0x0a, 0x0e, 0x77, 0x69, 0x6e, 0x6e, 0x65, 0x72, 0x5f, 0x74, 0x65, 0x61, 0x6d,
+	0x5f, 0x69, 0x64, 0x18, 0x1f, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x77, 0x69, 0x6e, 0x6e, 0x65,
+	0x72, 0x54, 0x65, 0x61, 0x6d, 0x49, 0x64, 0x12, 0x22, 0x0a, 0x0d, 0x6c, 0x6f, 0x73, 0x65, 0x72,
+	0x5f, 0x74, 0x65, 0x61, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x20, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b,
+	0x6c, 0x6f, 0x73, 0x65, 0x72, 0x54, 0x65, 0x61, 0x6d, 0x49, 0x64, 0x12, 0x2a, 0x0a, 0x06, 0x65,
+	0x76, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x21, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x68,
+	0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52,
+	0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x65,
+	0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x22, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61,
+	0x74, 0x65, 0x48, 0x61, 0x73, 0x68, 0x12, 0x2d, 0x0a, 0x12, 0x73, 0x68, 0x75, 0x66, 0x66, 0x6c,
+	0x65, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x23, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x11, 0x73, 0x68, 0x75, 0x66, 0x66, 0x6c, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x69,
+	0x74, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x68, 0x75, 0x66, 0x66, 0x6c, 0x65,
+	0x5f, 0x73, 0x65, 0x65, 0x64, 0x18, 0x24, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x73, 0x68, 0x75,
+	0x66, 0x66, 0x6c, 0x65, 0x53, 0x65, 0x65, 0x64, 0x12, 0x42, 0x0a, 0x0f, 0x64, 0x69, 0x73, 0x63,
+	0x61, 0x72, 0x64, 0x5f, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x18, 0x25, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e,
+	0x44, 0x69, 0x73, 0x63, 0x61, 0x72, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0e, 0x64, 0x69,
+	0x73, 0x63, 0x61, 0x72, 0x64, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x26, 0x0a, 0x0f,
+	0x69, 0x73, 0x5f, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x61, 0x62, 0x6f, 0x72, 0x74, 0x65, 0x64, 0x18,
+	0x26, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x69, 0x73, 0x47, 0x61, 0x6d, 0x65, 0x41, 0x62, 0x6f,
+	0x72, 0x74, 0x65, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x5f, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x27, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x73, 0x63,
+	0x68, 0x65, 0x6d, 0x61, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x1a, 0x3d, 0x0a, 0x0f, 0x54,
+	0x65, 0x61, 0x6d, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x93, 0x01, 0x0a, 0x06, 0x50,
+	0x6c, 0x61, 0x79, 0x65, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72,
+	0x49, 0x64, 0x12, 0x25, 0x0a, 0x04, 0x68, 0x61, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x11, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43,
+	0x61, 0x72, 0x64, 0x52, 0x04, 0x68, 0x61, 0x6e, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f,
+	0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x74, 0x65, 0x61, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x74,
+	0x65, 0x61, 0x6d, 0x12, 0x1b, 0x0a, 0x09, 0x68, 0x61, 0x6e, 0x64, 0x5f, 0x73, 0x6f, 0x72, 0x74,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x68, 0x61, 0x6e, 0x64, 0x53, 0x6f, 0x72, 0x74,
+	0x22, 0x83, 0x05, 0x0a, 0x09, 0x47, 0x61, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x21,
+	0x0a, 0x0c, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x4e, 0x75, 0x6d, 0x62, 0x65,
+	0x72, 0x12, 0x24, 0x0a, 0x0e, 0x74, 0x75, 0x72, 0x6e, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72,
+	0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x74, 0x75, 0x72, 0x6e, 0x50,
+	0x6c, 0x61, 0x79, 0x65, 0x72, 0x49, 0x64, 0x12, 0x2d, 0x0a, 0x07, 0x70, 0x6c, 0x61, 0x79, 0x65,
+	0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63,
+	0x68, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x52, 0x07, 0x70,
+	0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x12, 0x34, 0x0a, 0x0c, 0x64, 0x69, 0x73, 0x63, 0x61, 0x72,
+	0x64, 0x5f, 0x70, 0x69, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63,
+	0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x72, 0x64, 0x52,
+	0x0b, 0x64, 0x69, 0x73, 0x63, 0x61, 0x72, 0x64, 0x50, 0x69, 0x6c, 0x65, 0x12, 0x24, 0x0a, 0x0e,
+	0x64, 0x72, 0x61, 0x77, 0x5f, 0x70, 0x69, 0x6c, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x64, 0x72, 0x61, 0x77, 0x50, 0x69, 0x6c, 0x65, 0x53, 0x69,
+	0x7a, 0x65, 0x12, 0x24, 0x0a, 0x0e, 0x68, 0x61, 0x73, 0x5f, 0x64, 0x72, 0x61, 0x77, 0x6e, 0x5f,
+	0x63, 0x61, 0x72, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x68, 0x61, 0x73, 0x44,
+	0x72, 0x61, 0x77, 0x6e, 0x43, 0x61, 0x72, 0x64, 0x12, 0x2a, 0x0a, 0x11, 0x69, 0x73, 0x5f, 0x72,
+	0x6f, 0x75, 0x6e, 0x64, 0x5f, 0x66, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x65, 0x64, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0f, 0x69, 0x73, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x46, 0x69, 0x6e, 0x69,
+	0x73, 0x68, 0x65, 0x64, 0x12, 0x22, 0x0a, 0x0d, 0x69, 0x73, 0x5f, 0x67, 0x61, 0x6d, 0x65, 0x5f,
+	0x65, 0x6e, 0x64, 0x65, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x69, 0x73, 0x47,
+	0x61, 0x6d, 0x65, 0x45, 0x6e, 0x64, 0x65, 0x64, 0x12, 0x28, 0x0a, 0x10, 0x77, 0x69, 0x6e, 0x6e,
+	0x65, 0x72, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0e, 0x77, 0x69, 0x6e, 0x6e, 0x65, 0x72, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72,
+	0x49, 0x64, 0x12, 0x26, 0x0a, 0x0f, 0x6c, 0x6f, 0x73, 0x65, 0x72, 0x5f, 0x70, 0x6c, 0x61, 0x79,
+	0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x6c, 0x6f, 0x73,
+	0x65, 0x72, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x49, 0x64, 0x12, 0x24, 0x0a, 0x0e, 0x77, 0x69,
+	0x6e, 0x6e, 0x65, 0x72, 0x5f, 0x74, 0x65, 0x61, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x0b, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0c, 0x77, 0x69, 0x6e, 0x6e, 0x65, 0x72, 0x54, 0x65, 0x61, 0x6d, 0x49, 0x64,
+	0x12, 0x22, 0x0a, 0x0d, 0x6c, 0x6f, 0x73, 0x65, 0x72, 0x5f, 0x74, 0x65, 0x61, 0x6d, 0x5f, 0x69,
+	0x64, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x6c, 0x6f, 0x73, 0x65, 0x72, 0x54, 0x65,
+	0x61, 0x6d, 0x49, 0x64, 0x12, 0x28, 0x0a, 0x05, 0x72, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x0d, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2e, 0x76,
+	0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x52, 0x05, 0x72, 0x75, 0x6c, 0x65, 0x73, 0x12, 0x3e,
+	0x0a, 0x10, 0x70, 0x6f, 0x73, 0x73, 0x69, 0x62, 0x6c, 0x65, 0x5f, 0x61, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x18, 0x0e, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x63, 0x68, 0x69, 0x6e, 0x63,
+	0x68, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0f, 0x70,
+	0x6f, 0x73, 0x73, 0x69, 0x62, 0x6c, 0x65, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x26,
+	0x0a, 0x0f, 0x69, 0x73, 0x5f, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x61, 0x62, 0x6f, 0x72, 0x74, 0x65,
+	0x64, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x69, 0x73, 0x47, 0x61, 0x6d, 0x65, 0x41,
+	0x62, 0x6f, 0x72, 0x74, 0x65, 0x64, 0x42, 0x28, 0x5a, 0x26, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x64, 0x65, 0x76, 0x62, 0x6c, 0x61, 0x63, 0x2f, 0x63, 0x68, 0x69,
+	0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x2f, 0x63, 0x68, 0x69, 0x6e, 0x63, 0x68, 0x6f, 0x6e, 0x70, 0x62,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_chinchon_proto_rawDescOnce sync.Once
+	file_chinchon_proto_rawDescData = file_chinchon_proto_rawDesc
+)
+
+func file_chinchon_proto_rawDescGZIP() []byte {
+	file_chinchon_proto_rawDescOnce.Do(func() {
+		file_chinchon_proto_rawDescData = protoimpl.X.CompressGZIP(file_chinchon_proto_rawDescData)
+	})
+	return file_chinchon_proto_rawDescData
+}
+
+var file_chinchon_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_chinchon_proto_goTypes = []any{
+	(*Card)(nil),            // 0: chinchon.v1.Card
+	(*Group)(nil),           // 1: chinchon.v1.Group
+	(*Action)(nil),          // 2: chinchon.v1.Action
+	(*ActionLog)(nil),       // 3: chinchon.v1.ActionLog
+	(*Rules)(nil),           // 4: chinchon.v1.Rules
+	(*ClientPlayer)(nil),    // 5: chinchon.v1.ClientPlayer
+	(*Event)(nil),           // 6: chinchon.v1.Event
+	(*DiscardEntry)(nil),    // 7: chinchon.v1.DiscardEntry
+	(*MatchState)(nil),      // 8: chinchon.v1.MatchState
+	(*PlayerStats)(nil),     // 9: chinchon.v1.PlayerStats
+	(*Stats)(nil),           // 10: chinchon.v1.Stats
+	(*ClientGameState)(nil), // 11: chinchon.v1.ClientGameState
+	(*Player)(nil),          // 12: chinchon.v1.Player
+	(*GameState)(nil),       // 13: chinchon.v1.GameState
+	nil,                     // 14: chinchon.v1.MatchState.GamesWonEntry
+	nil,                     // 15: chinchon.v1.Stats.PlayersEntry
+	nil,                     // 16: chinchon.v1.ClientGameState.TeamScoresEntry
+}
+var file_chinchon_proto_depIdxs = []int32{
+	0,  // 0: chinchon.v1.Group.cards:type_name -> chinchon.v1.Card
+	0,  // 1: chinchon.v1.Action.card:type_name -> chinchon.v1.Card
+	1,  // 2: chinchon.v1.Action.groups:type_name -> chinchon.v1.Group
+	0,  // 3: chinchon.v1.Action.cards:type_name -> chinchon.v1.Card
+	2,  // 4: chinchon.v1.ActionLog.action:type_name -> chinchon.v1.Action
+	0,  // 5: chinchon.v1.ClientPlayer.hand:type_name -> chinchon.v1.Card
+	1,  // 6: chinchon.v1.ClientPlayer.groups:type_name -> chinchon.v1.Group
+	0,  // 7: chinchon.v1.Event.card:type_name -> chinchon.v1.Card
+	0,  // 8: chinchon.v1.DiscardEntry.card:type_name -> chinchon.v1.Card
+	14, // 9: chinchon.v1.MatchState.games_won:type_name -> chinchon.v1.MatchState.GamesWonEntry
+	15, // 10: chinchon.v1.Stats.players:type_name -> chinchon.v1.Stats.PlayersEntry
+	0,  // 11: chinchon.v1.ClientGameState.your_hand:type_name -> chinchon.v1.Card
+	0,  // 12: chinchon.v1.ClientGameState.their_hand:type_name -> chinchon.v1.Card
+	0,  // 13: chinchon.v1.ClientGameState.top_discard_card:type_name -> chinchon.v1.Card
+	2,  // 14: chinchon.v1.ClientGameState.possible_actions:type_name -> chinchon.v1.Action
+	3,  // 15: chinchon.v1.ClientGameState.last_action_log:type_name -> chinchon.v1.ActionLog
+	4,  // 16: chinchon.v1.ClientGameState.rules:type_name -> chinchon.v1.Rules
+	1,  // 17: chinchon.v1.ClientGameState.declared_groups:type_name -> chinchon.v1.Group
+	1,  // 18: chinchon.v1.ClientGameState.lay_off_groups:type_name -> chinchon.v1.Group
+	5,  // 19: chinchon.v1.ClientGameState.players:type_name -> chinchon.v1.ClientPlayer
+	8,  // 20: chinchon.v1.ClientGameState.match:type_name -> chinchon.v1.MatchState
+	10, // 21: chinchon.v1.ClientGameState.stats:type_name -> chinchon.v1.Stats
+	16, // 22: chinchon.v1.ClientGameState.team_scores:type_name -> chinchon.v1.ClientGameState.TeamScoresEntry
+	6,  // 23: chinchon.v1.ClientGameState.events:type_name -> chinchon.v1.Event
+	7,  // 24: chinchon.v1.ClientGameState.discard_history:type_name -> chinchon.v1.DiscardEntry
+	0,  // 25: chinchon.v1.Player.hand:type_name -> chinchon.v1.Card
+	12, // 26: chinchon.v1.GameState.players:type_name -> chinchon.v1.Player
+	0,  // 27: chinchon.v1.GameState.discard_pile:type_name -> chinchon.v1.Card
+	4,  // 28: chinchon.v1.GameState.rules:type_name -> chinchon.v1.Rules
+	2,  // 29: chinchon.v1.GameState.possible_actions:type_name -> chinchon.v1.Action
+	9,  // 30: chinchon.v1.Stats.PlayersEntry.value:type_name -> chinchon.v1.PlayerStats
+	31, // [31:31] is the sub-list for method output_type
+	31, // [31:31] is the sub-list for method input_type
+	31, // [31:31] is the sub-list for extension type_name
+	31, // [31:31] is the sub-list for extension extendee
+	0,  // [0:31] is the sub-list for field type_name
+}
+
+func init() { file_chinchon_proto_init() }
+func file_chinchon_proto_init() {
+	if File_chinchon_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_chinchon_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*Card); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_chinchon_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*Group); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_chinchon_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*Action); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_chinchon_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*ActionLog); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_chinchon_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*Rules); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_chinchon_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*ClientPlayer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_chinchon_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*Event); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_chinchon_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*DiscardEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_chinchon_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*MatchState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_chinchon_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*PlayerStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_chinchon_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*Stats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_chinchon_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*ClientGameState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_chinchon_proto_msgTypes[12].Exporter = func(v any, i int) any {
+			switch v := v.(*Player); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_chinchon_proto_msgTypes[13].Exporter = func(v any, i int) any {
+			switch v := v.(*GameState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_chinchon_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_chinchon_proto_goTypes,
+		DependencyIndexes: file_chinchon_proto_depIdxs,
+		MessageInfos:      file_chinchon_proto_msgTypes,
+	}.Build()
+	File_chinchon_proto = out.File
+	file_chinchon_proto_rawDesc = nil
+	file_chinchon_proto_goTypes = nil
+	file_chinchon_proto_depIdxs = nil
+}
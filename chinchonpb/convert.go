@@ -0,0 +1,328 @@
+package chinchonpb
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/devblac/chinchon/chinchon"
+)
+
+// FromCard converts a card.
+func FromCard(card chinchon.Card) *Card {
+	return &Card{Suit: card.Suit, Number: int32(card.Number)}
+}
+
+// ToCard converts a card. A nil card is the zero Card.
+func ToCard(card *Card) chinchon.Card {
+	return chinchon.Card{Suit: card.GetSuit(), Number: int(card.GetNumber())}
+}
+
+func fromCards(cards []chinchon.Card) []*Card {
+	pbCards := []*Card{}
+	for _, card := range cards {
+		pbCards = append(pbCards, FromCard(card))
+	}
+	return pbCards
+}
+
+func toCards(pbCards []*Card) []chinchon.Card {
+	cards := []chinchon.Card{}
+	for _, card := range pbCards {
+		cards = append(cards, ToCard(card))
+	}
+	return cards
+}
+
+func fromGroups(groups [][]chinchon.Card) []*Group {
+	pbGroups := []*Group{}
+	for _, group := range groups {
+		pbGroups = append(pbGroups, &Group{Cards: fromCards(group)})
+	}
+	return pbGroups
+}
+
+func toGroups(pbGroups []*Group) [][]chinchon.Card {
+	groups := [][]chinchon.Card{}
+	for _, group := range pbGroups {
+		groups = append(groups, toCards(group.GetCards()))
+	}
+	return groups
+}
+
+func fromDiscardHistory(history []chinchon.DiscardEntry) []*DiscardEntry {
+	pbHistory := []*DiscardEntry{}
+	for _, entry := range history {
+		pbHistory = append(pbHistory, &DiscardEntry{
+			Card:             FromCard(entry.Card),
+			PlayerId:         int32(entry.PlayerID),
+			PickedByPlayerId: int32(entry.PickedByPlayerID),
+			Reshuffled:       entry.Reshuffled,
+		})
+	}
+	return pbHistory
+}
+
+func fromInt32Map(m map[int]int) map[int32]int32 {
+	pbMap := map[int32]int32{}
+	for k, v := range m {
+		pbMap[int32(k)] = int32(v)
+	}
+	return pbMap
+}
+
+func toIntMap(pbMap map[int32]int32) map[int]int {
+	m := map[int]int{}
+	for k, v := range pbMap {
+		m[int(k)] = int(v)
+	}
+	return m
+}
+
+// jsonAction has the JSON fields of every action, to convert them all the same way.
+type jsonAction struct {
+	Name       string            `json:"name"`
+	PlayerID   int               `json:"playerID"`
+	Card       *chinchon.Card    `json:"card,omitempty"`
+	Groups     [][]chinchon.Card `json:"groups,omitempty"`
+	GroupIndex int               `json:"groupIndex"`
+	Cards      []chinchon.Card   `json:"cards,omitempty"`
+	Sort       string            `json:"sort"`
+}
+
+// FromAction converts an action.
+func FromAction(action chinchon.Action) (*Action, error) {
+	return fromSerializedAction(chinchon.SerializeAction(action))
+}
+
+func fromSerializedAction(bs []byte) (*Action, error) {
+	var a jsonAction
+	if err := json.Unmarshal(bs, &a); err != nil {
+		return nil, err
+	}
+	pbAction := &Action{
+		Name:       a.Name,
+		PlayerId:   int32(a.PlayerID),
+		Groups:     fromGroups(a.Groups),
+		GroupIndex: int32(a.GroupIndex),
+		Cards:      fromCards(a.Cards),
+		Sort:       a.Sort,
+	}
+	if a.Card != nil {
+		pbAction.Card = FromCard(*a.Card)
+	}
+	return pbAction, nil
+}
+
+// ToAction converts an action, failing if it's unknown.
+func ToAction(pbAction *Action) (chinchon.Action, error) {
+	a := jsonAction{
+		Name:       pbAction.GetName(),
+		PlayerID:   int(pbAction.GetPlayerId()),
+		GroupIndex: int(pbAction.GetGroupIndex()),
+		Sort:       pbAction.GetSort(),
+	}
+	if pbAction.GetCard() != nil {
+		card := ToCard(pbAction.GetCard())
+		a.Card = &card
+	}
+	if len(pbAction.GetGroups()) > 0 {
+		a.Groups = toGroups(pbAction.GetGroups())
+	}
+	if len(pbAction.GetCards()) > 0 {
+		a.Cards = toCards(pbAction.GetCards())
+	}
+	bs, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return chinchon.DeserializeAction(bs)
+}
+
+func fromActions(actions []chinchon.Action) ([]*Action, error) {
+	pbActions := []*Action{}
+	for _, action := range actions {
+		pbAction, err := FromAction(action)
+		if err != nil {
+			return nil, err
+		}
+		pbActions = append(pbActions, pbAction)
+	}
+	return pbActions, nil
+}
+
+func fromRules(gs chinchon.GameState) *Rules {
+	return &Rules{
+		MaxPoints:           int32(gs.RuleMaxPoints),
+		MaxRounds:           int32(gs.RuleMaxRounds),
+		CloseThreshold:      int32(gs.RuleCloseThreshold),
+		CloseBonusMode:      gs.RuleCloseBonusMode,
+		CloseTieMode:        gs.RuleCloseTieMode,
+		ChinchonMode:        gs.RuleChinchonMode,
+		VerifiableShuffle:   gs.RuleVerifiableShuffle,
+		Reenter:             gs.RuleReenter,
+		LayOff:              gs.RuleLayOff,
+		DeclaredMelds:       gs.RuleDeclaredMelds,
+		AceWraparound:       gs.RuleAceWraparound,
+		DeckType:            gs.RuleDeckType,
+		ResignRoundPenalty:  int32(gs.RuleResignRoundPenalty),
+		HandSize:            int32(gs.RuleHandSize),
+		MinTurnsBeforeClose: int32(gs.RuleMinTurnsBeforeClose),
+		Teams:               gs.RuleTeams,
+	}
+}
+
+// FromClientGameState converts a player's game state.
+func FromClientGameState(cgs chinchon.ClientGameState) (*ClientGameState, error) {
+	actions, err := cgs.Actions()
+	if err != nil {
+		return nil, err
+	}
+	possibleActions, err := fromActions(actions)
+	if err != nil {
+		return nil, err
+	}
+
+	pbState := &ClientGameState{
+		RoundNumber:     int32(cgs.RoundNumber),
+		TurnPlayerId:    int32(cgs.TurnPlayerID),
+		YouPlayerId:     int32(cgs.YouPlayerID),
+		ThemPlayerId:    int32(cgs.ThemPlayerID),
+		YourScore:       int32(cgs.YourScore),
+		TheirScore:      int32(cgs.TheirScore),
+		YourHand:        fromCards(cgs.YourHand),
+		TheirHandSize:   int32(cgs.TheirHandSize),
+		TheirHand:       fromCards(cgs.TheirHand),
+		DrawPileSize:    int32(cgs.DrawPileSize),
+		PossibleActions: possibleActions,
+		IsGameEnded:     cgs.IsGameEnded,
+		IsGameAborted:   cgs.IsGameAborted,
+		IsRoundFinished: cgs.IsRoundFinished,
+		WinnerPlayerId:  int32(cgs.WinnerPlayerID),
+		LoserPlayerId:   int32(cgs.LoserPlayerID),
+		Rules: &Rules{
+			MaxPoints:           int32(cgs.RuleMaxPoints),
+			MaxRounds:           int32(cgs.RuleMaxRounds),
+			CloseThreshold:      int32(cgs.RuleCloseThreshold),
+			CloseBonusMode:      cgs.RuleCloseBonusMode,
+			CloseTieMode:        cgs.RuleCloseTieMode,
+			ChinchonMode:        cgs.RuleChinchonMode,
+			VerifiableShuffle:   cgs.RuleVerifiableShuffle,
+			Reenter:             cgs.RuleReenter,
+			LayOff:              cgs.RuleLayOff,
+			DeclaredMelds:       cgs.RuleDeclaredMelds,
+			AceWraparound:       cgs.RuleAceWraparound,
+			DeckType:            cgs.RuleDeckType,
+			ResignRoundPenalty:  int32(cgs.RuleResignRoundPenalty),
+			HandSize:            int32(cgs.RuleHandSize),
+			MinTurnsBeforeClose: int32(cgs.RuleMinTurnsBeforeClose),
+			Teams:               cgs.RuleTeams,
+		},
+		HasDrawnCard:           cgs.HasDrawnCard,
+		TurnsBeforeYouCanClose: int32(cgs.TurnsBeforeYouCanClose),
+		TurnTimeRemainingMs:    cgs.TurnTimeRemainingMs,
+		DeclaredGroups:         fromGroups(cgs.DeclaredGroups),
+		IsLayingOff:            cgs.IsLayingOff,
+		LayOffGroups:           fromGroups(cgs.LayOffGroups),
+		ReenterOfferedPlayerId: int32(cgs.ReenterOfferedPlayerID),
+		YourTeamId:             int32(cgs.YourTeamID),
+		TeamScores:             fromInt32Map(cgs.TeamScores),
+		WinnerTeamId:           int32(cgs.WinnerTeamID),
+		LoserTeamId:            int32(cgs.LoserTeamID),
+		StateHash:              cgs.StateHash,
+		ShuffleCommitment:      cgs.ShuffleCommitment,
+		ShuffleSeed:            cgs.ShuffleSeed,
+		DiscardHistory:         fromDiscardHistory(cgs.DiscardHistory),
+		SchemaVersion:          int32(cgs.SchemaVersion),
+	}
+	if cgs.TopDiscardCard != nil {
+		pbState.TopDiscardCard = FromCard(*cgs.TopDiscardCard)
+	}
+	if cgs.LastActionLog != nil {
+		action, err := fromSerializedAction(cgs.LastActionLog.Action)
+		if err != nil {
+			return nil, fmt.Errorf("last action: %w", err)
+		}
+		pbState.LastActionLog = &ActionLog{
+			PlayerId:    int32(cgs.LastActionLog.PlayerID),
+			Action:      action,
+			PlayedByBot: cgs.LastActionLog.PlayedByBot,
+		}
+	}
+	for _, player := range cgs.Players {
+		pbState.Players = append(pbState.Players, &ClientPlayer{
+			PlayerId:      int32(player.PlayerID),
+			Team:          int32(player.Team),
+			Score:         int32(player.Score),
+			HandSize:      int32(player.HandSize),
+			Hand:          fromCards(player.Hand),
+			Groups:        fromGroups(player.Groups),
+			PenaltyPoints: int32(player.PenaltyPoints),
+			Name:          player.Name,
+			Avatar:        player.Avatar,
+		})
+	}
+	if cgs.Match != nil {
+		pbState.Match = &MatchState{
+			BestOf:       int32(cgs.Match.BestOf),
+			GameNumber:   int32(cgs.Match.GameNumber),
+			GamesWon:     fromInt32Map(cgs.Match.GamesWon),
+			IsMatchEnded: cgs.Match.IsMatchEnded,
+			WinnerTeamId: int32(cgs.Match.WinnerTeamID),
+		}
+	}
+	if cgs.Stats != nil {
+		pbState.Stats = &Stats{Rounds: int32(cgs.Stats.Rounds), Players: map[int32]*PlayerStats{}}
+		for id, stats := range cgs.Stats.Players {
+			pbState.Stats.Players[int32(id)] = &PlayerStats{
+				DrawsFromDeck:          int32(stats.DrawsFromDeck),
+				DrawsFromDiscard:       int32(stats.DrawsFromDiscard),
+				RoundsClosed:           int32(stats.RoundsClosed),
+				Chinchones:             int32(stats.Chinchones),
+				AverageDeadwoodAtClose: stats.AverageDeadwoodAtClose,
+			}
+		}
+	}
+	for _, event := range cgs.Events {
+		pbEvent := &Event{Type: event.Type, PlayerId: int32(event.PlayerID), From: event.From, To: event.To, FaceUp: event.FaceUp}
+		if event.Card != nil {
+			pbEvent.Card = FromCard(*event.Card)
+		}
+		pbState.Events = append(pbState.Events, pbEvent)
+	}
+	return pbState, nil
+}
+
+// FromGameState converts the whole state of a game. It doesn't include the draw pile's
+// order, so it can't be converted back: use the chinchon package's Save and Load for that.
+func FromGameState(gs *chinchon.GameState) (*GameState, error) {
+	possibleActions, err := fromActions(gs.CalculatePossibleActions())
+	if err != nil {
+		return nil, err
+	}
+
+	pbState := &GameState{
+		RoundNumber:     int32(gs.RoundNumber),
+		TurnPlayerId:    int32(gs.TurnPlayerID),
+		DiscardPile:     fromCards(gs.DiscardPile),
+		DrawPileSize:    int32(gs.DrawPileSize()),
+		HasDrawnCard:    gs.HasDrawnCard,
+		IsRoundFinished: gs.IsRoundFinished,
+		IsGameEnded:     gs.IsGameEnded,
+		IsGameAborted:   gs.IsGameAborted,
+		WinnerPlayerId:  int32(gs.WinnerPlayerID),
+		LoserPlayerId:   int32(gs.LoserPlayerID),
+		WinnerTeamId:    int32(gs.WinnerTeamID),
+		LoserTeamId:     int32(gs.LoserTeamID),
+		Rules:           fromRules(*gs),
+		PossibleActions: possibleActions,
+	}
+	for id := 0; id < len(gs.Players); id++ {
+		player := gs.Players[id]
+		pbPlayer := &Player{PlayerId: int32(id), Score: int32(player.Score), Team: int32(player.Team), HandSort: player.HandSort}
+		if player.Hand != nil {
+			pbPlayer.Hand = fromCards(player.Hand.Cards)
+		}
+		pbState.Players = append(pbState.Players, pbPlayer)
+	}
+	return pbState, nil
+}
@@ -0,0 +1,7 @@
+// Package chinchonpb has the Protocol Buffers version of the chinchon package's types (see
+// chinchon.proto), for clients that don't speak Go, and converts between both. It also has
+// the server's gRPC service (see game_service.proto).
+package chinchonpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative chinchon.proto
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative game_service.proto
@@ -33,16 +33,21 @@ func New(opts ...func(*Bot)) *Bot {
 }
 
 func (m Bot) ChooseAction(gs chinchon.ClientGameState) chinchon.Action {
+	actions, err := gs.Actions()
+	if err != nil {
+		m.logger.Printf("Failed to read possible actions: %v", err)
+		return nil
+	}
+
 	// Trivial cases
-	if len(gs.PossibleActions) == 0 {
+	if len(actions) == 0 {
 		return nil
 	}
-	if len(gs.PossibleActions) == 1 {
-		return _deserializeActions(gs.PossibleActions)[0]
+	if len(actions) == 1 {
+		return actions[0]
 	}
 
 	// Simple Chinchón bot strategy
-	actions := _deserializeActions(gs.PossibleActions)
 
 	// Always confirm round finished if possible
 	for _, action := range actions {
@@ -51,9 +56,25 @@ func (m Bot) ChooseAction(gs chinchon.ClientGameState) chinchon.Action {
 		}
 	}
 
+	// Always re-enter the game if possible
+	for _, action := range actions {
+		if action.GetName() == chinchon.ACCEPT_REENTER {
+			return action
+		}
+	}
+
+	// Lay off every card possible, then finish
+	for _, name := range []string{chinchon.LAY_OFF_CARD, chinchon.FINISH_LAY_OFF} {
+		for _, action := range actions {
+			if action.GetName() == name {
+				return action
+			}
+		}
+	}
+
 	// Prefer drawing from discard pile if the card helps form groups
 	for _, action := range actions {
-		if action.GetName() == chinchon.DRAW_FROM_DISCARD {
+		if action.GetName() == chinchon.DRAW_FROM_DISCARD && m.discardHelps(gs) {
 			return action
 		}
 	}
@@ -72,23 +93,36 @@ func (m Bot) ChooseAction(gs chinchon.ClientGameState) chinchon.Action {
 		}
 	}
 
-	// Discard the highest value card to minimize penalty
-	var bestDiscard chinchon.Action
-	highestValue := -1
+	// Discard the card that leaves the fewest penalty points
+	suggestion := gs.HandEvaluator().SuggestDiscard(gs.YourHand)
 	for _, action := range actions {
-		if action.GetName() == chinchon.DISCARD_CARD {
-			discardAction := action.(*chinchon.ActionDiscardCard)
-			value := discardAction.Card.PenaltyValue()
-			if value > highestValue {
-				highestValue = value
-				bestDiscard = action
-			}
+		if action.GetName() == chinchon.DISCARD_CARD && action.(*chinchon.ActionDiscardCard).Card == suggestion {
+			return action
 		}
 	}
-	if bestDiscard != nil {
-		return bestDiscard
-	}
 
 	// Fallback to first action
 	return actions[0]
 }
+
+// discardHelps returns true if taking the top discard, and then discarding the best card,
+// leaves fewer penalty points than the hand has now. Otherwise, the card would be discarded
+// right back.
+func (m Bot) discardHelps(gs chinchon.ClientGameState) bool {
+	if gs.TopDiscardCard == nil {
+		return false
+	}
+	evaluator := gs.HandEvaluator()
+	_, now := evaluator.BestPartition(gs.YourHand)
+
+	hand := append(append([]chinchon.Card{}, gs.YourHand...), *gs.TopDiscardCard)
+	discard := evaluator.SuggestDiscard(hand)
+	for i, card := range hand {
+		if card == discard {
+			hand = append(hand[:i], hand[i+1:]...)
+			break
+		}
+	}
+	_, after := evaluator.BestPartition(hand)
+	return after < now
+}
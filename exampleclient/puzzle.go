@@ -0,0 +1,84 @@
+//go:build !tinygo
+// +build !tinygo
+
+package exampleclient
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/devblac/chinchon/chinchon"
+	"github.com/nsf/termbox-go"
+)
+
+// Puzzles runs the puzzles in the given scenario file, one after the other.
+func Puzzles(path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Failed to open puzzle file: %v", err)
+	}
+	puzzles, err := chinchon.LoadPuzzles(f)
+	f.Close()
+	if err != nil {
+		log.Fatalf("Failed to load puzzles: %v", err)
+	}
+
+	ui := NewUI()
+	defer ui.Close()
+
+	streak := chinchon.PuzzleStreak{}
+	for i, puzzle := range puzzles {
+		actions := puzzle.PossibleActions()
+		renderPuzzle(i+1, len(puzzles), puzzle, actions, streak, "")
+
+		var answer chinchon.Action
+		for answer == nil {
+			num, err := strconv.Atoi(string(<-ui.keyCh))
+			if err != nil || num <= 0 || num > len(actions) {
+				continue
+			}
+			answer = actions[num-1]
+		}
+
+		correct := puzzle.Check(answer)
+		streak.Record(correct)
+
+		result := "¡Correcto!"
+		if !correct {
+			var solutions []string
+			for _, s := range puzzle.Solve() {
+				solutions = append(solutions, texts.ActionChoice(s))
+			}
+			result = "Incorrecto. La mejor jugada era: " + strings.Join(solutions, " o ")
+		}
+		renderPuzzle(i+1, len(puzzles), puzzle, actions, streak, result+" Presiona cualquier tecla para continuar.")
+		<-ui.keyCh
+	}
+}
+
+func renderPuzzle(number, total int, puzzle chinchon.Puzzle, actions []chinchon.Action, streak chinchon.PuzzleStreak, result string) {
+	if err := termbox.Clear(termbox.ColorWhite, termbox.ColorBlack); err != nil {
+		log.Fatal(err)
+	}
+	width, height := termbox.Size()
+
+	renderAt(0, 0, fmt.Sprintf("Problema %d de %d: %v", number, total, puzzle.Name))
+	renderAt(0, 1, puzzle.Description)
+	renderUpToAt(width-1, 0, fmt.Sprintf("Racha: %d (mejor: %d)", streak.Current, streak.Best))
+	renderUpToAt(width-1, 1, fmt.Sprintf("Resueltos: %d de %d", streak.Solved, streak.Played))
+
+	renderAt(0, height/2-1, "Pila de descarte: "+getCardString(puzzle.TopDiscardCard))
+	renderAt(0, height/2, result)
+	renderAt(0, height-4, "Tus cartas: "+getCardsString(puzzle.Hand))
+
+	actionsString := ""
+	for i, action := range actions {
+		actionsString += fmt.Sprintf("%d. %s   ", i+1, texts.ActionChoice(action))
+	}
+	renderAt(0, height-2, actionsString)
+
+	termbox.Flush()
+}
@@ -0,0 +1,105 @@
+//go:build !tinygo
+// +build !tinygo
+
+package exampleclient
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/devblac/chinchon/chinchon"
+	"github.com/nsf/termbox-go"
+)
+
+// Tutorial plays the guided tutorial in the given file, step by step.
+func Tutorial(path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Failed to open tutorial file: %v", err)
+	}
+	tutorial, err := chinchon.LoadTutorial(f)
+	f.Close()
+	if err != nil {
+		log.Fatalf("Failed to load tutorial: %v", err)
+	}
+	session, err := chinchon.NewTutorialSession(tutorial)
+	if err != nil {
+		log.Fatalf("Failed to start tutorial: %v", err)
+	}
+
+	ui := NewUI()
+	defer ui.Close()
+
+	notice := ""
+	for !session.IsFinished() {
+		actions := studentActions(*session.GameState)
+		renderTutorial(session, actions, notice)
+
+		num, err := strconv.Atoi(string(<-ui.keyCh))
+		if err != nil || num <= 0 || num > len(actions) {
+			continue
+		}
+
+		step := session.CurrentStep()
+		ok, err := session.Play(actions[num-1])
+		if err != nil {
+			log.Fatalf("Broken tutorial: %v", err)
+		}
+		if !ok {
+			notice = "Intenta de nuevo. " + step.Hint
+			continue
+		}
+		notice = ""
+		renderTutorialMessage(step.Explanation + " Presiona cualquier tecla para continuar.")
+		<-ui.keyCh
+	}
+	renderTutorialMessage("¡Terminaste el tutorial! Presiona cualquier tecla para salir.")
+	<-ui.keyCh
+}
+
+func studentActions(gs chinchon.GameState) []chinchon.Action {
+	actions := []chinchon.Action{}
+	for _, action := range gs.CalculatePossibleActions() {
+		if action.GetPlayerID() == 0 {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+func renderTutorial(session *chinchon.TutorialSession, actions []chinchon.Action, notice string) {
+	if err := termbox.Clear(termbox.ColorWhite, termbox.ColorBlack); err != nil {
+		log.Fatal(err)
+	}
+	width, height := termbox.Size()
+	gs := session.GameState
+
+	renderAt(0, 0, session.Tutorial.Name)
+	renderUpToAt(width-1, 0, fmt.Sprintf("Paso %d de %d", session.Step+1, len(session.Tutorial.Steps)))
+	renderAt(0, 2, session.CurrentStep().Text)
+
+	if topCard, err := gs.GetTopDiscardCard(); err == nil {
+		renderAt(0, height/2-1, "Pila de descarte: "+getCardString(topCard))
+	}
+	renderAt(0, height/2, notice)
+	renderAt(0, height-4, "Tus cartas: "+getCardsString(gs.Players[0].Hand.Cards))
+
+	actionsString := ""
+	for i, action := range actions {
+		actionsString += fmt.Sprintf("%d. %s   ", i+1, texts.ActionChoice(action))
+	}
+	renderAt(0, height-2, actionsString)
+
+	termbox.Flush()
+}
+
+func renderTutorialMessage(message string) {
+	if err := termbox.Clear(termbox.ColorWhite, termbox.ColorBlack); err != nil {
+		log.Fatal(err)
+	}
+	_, height := termbox.Size()
+	renderAt(0, height/2, message)
+	termbox.Flush()
+}
@@ -4,19 +4,24 @@
 package exampleclient
 
 import (
-	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/devblac/chinchon/chinchon"
+	"github.com/devblac/chinchon/server"
 	"github.com/nsf/termbox-go"
 )
 
 type ui struct {
-	keyCh chan rune
+	keyCh   chan rune
+	notice  string
+	latency string
 }
 
+// emoteKeys maps keys to server.Emotes by position.
+const emoteKeys = "zxcvb"
+
 func NewUI() *ui {
 	ui := &ui{}
 	ui.keyCh = ui.startKeyEventLoop()
@@ -50,7 +55,7 @@ type renderState struct {
 func calculateRenderState(state chinchon.ClientGameState) renderState {
 	var (
 		viewportWidth, viewportHeight = termbox.Size()
-		possibleActions               = _deserializeActions(state.PossibleActions)
+		possibleActions, _            = state.Actions() // Errors are logged when choosing an action
 		gs                            = state
 		mode                          = PRINT_MODE_NORMAL
 	)
@@ -85,6 +90,8 @@ func (u *ui) render(state chinchon.ClientGameState) error {
 	renderEndSummary(rs)
 	renderYourHand(rs)
 	renderActions(rs)
+	renderEmotes(rs, u.notice)
+	renderUpToAt(rs.viewportWidth-1, rs.viewportHeight-1, u.latency)
 
 	termbox.Flush()
 	// This is an artificial delay to make the game more human-like.
@@ -96,13 +103,48 @@ func (u *ui) render(state chinchon.ClientGameState) error {
 func renderScores(rs renderState) {
 	renderUpToAt(rs.viewportWidth-1, 0, fmt.Sprintf("Ronda número %d", rs.gs.RoundNumber))
 
+	if rs.gs.RuleTeams {
+		theirTeamID := 1 - rs.gs.YourTeamID
+		renderUpToAt(rs.viewportWidth-1, 1, fmt.Sprintf("Puntos de tu equipo: %d", rs.gs.TeamScores[rs.gs.YourTeamID]))
+		renderUpToAt(rs.viewportWidth-1, 2, fmt.Sprintf("Puntos del otro equipo: %d", rs.gs.TeamScores[theirTeamID]))
+		return
+	}
+	if len(rs.gs.Players) > 2 {
+		for i, player := range rs.gs.Players {
+			who := texts.PlayerNameIn(rs.gs, player.PlayerID)
+			if player.Avatar != "" {
+				who = player.Avatar + " " + who
+			}
+			renderUpToAt(rs.viewportWidth-1, i+1, fmt.Sprintf("%v: %d puntos", who, player.Score))
+		}
+		return
+	}
 	renderUpToAt(rs.viewportWidth-1, 1, fmt.Sprintf("Tus puntos: %d", rs.gs.YourScore))
 	renderUpToAt(rs.viewportWidth-1, 2, fmt.Sprintf("Sus puntos: %d", rs.gs.TheirScore))
 }
 
 func renderTheirHand(rs renderState) {
-	displayText := fmt.Sprintf("Cartas del oponente: %d cartas", rs.gs.TheirHandSize)
-	renderAt(0, 4, displayText)
+	if len(rs.gs.Players) <= 2 {
+		displayText := fmt.Sprintf("Cartas del oponente: %d cartas", rs.gs.TheirHandSize)
+		if len(rs.gs.TheirHand) > 0 {
+			displayText = fmt.Sprintf("Cartas del oponente: %v", texts.Cards(rs.gs.TheirHand))
+		}
+		renderAt(0, 4, displayText)
+		return
+	}
+
+	var hands []string
+	for _, player := range rs.gs.Players {
+		if player.PlayerID == rs.gs.YouPlayerID {
+			continue
+		}
+		who := fmt.Sprintf("Jugador %d", player.PlayerID+1)
+		if player.Team == rs.gs.YourTeamID {
+			who += " (compañero)"
+		}
+		hands = append(hands, fmt.Sprintf("%v: %d cartas", who, player.HandSize))
+	}
+	renderAt(0, 4, strings.Join(hands, "   "))
 }
 
 func renderDiscardPile(rs renderState) {
@@ -132,7 +174,9 @@ func renderEndSummary(rs renderState) {
 		renderText = "Ronda terminada. Presiona cualquier tecla para continuar."
 	case PRINT_MODE_END:
 		var resultText string
-		if rs.gs.YouPlayerID == rs.gs.WinnerPlayerID {
+		if rs.gs.IsGameAborted {
+			resultText = "El servidor terminó la partida, sin ganador."
+		} else if rs.gs.YourTeamID == rs.gs.WinnerTeamID {
 			resultText = "¡Ganaste! 🥰"
 		} else {
 			resultText = "Perdiste 😭"
@@ -153,7 +197,7 @@ func renderActions(rs renderState) {
 
 	actionsString := ""
 	for i, action := range rs.possibleActions {
-		actionsString += fmt.Sprintf("%d. %s   ", i+1, action.String())
+		actionsString += fmt.Sprintf("%d. %s   ", i+1, texts.ActionChoice(action))
 	}
 	renderText = actionsString
 
@@ -168,6 +212,18 @@ func renderActions(rs renderState) {
 	renderAt(0, rs.viewportHeight-2, renderText)
 }
 
+func renderEmotes(rs renderState, notice string) {
+	renderAt(0, 6, notice)
+
+	var hints []string
+	for i, emote := range server.Emotes {
+		if i < len(emoteKeys) {
+			hints = append(hints, fmt.Sprintf("%c: %v", emoteKeys[i], emote))
+		}
+	}
+	renderAt(0, rs.viewportHeight-1, "Emotes  "+strings.Join(hints, "   "))
+}
+
 func renderAt(x, y int, s string) {
 	_s := []rune(s)
 	for i, r := range _s {
@@ -209,12 +265,3 @@ func suitEmoji(suit string) string {
 		return "❓"
 	}
 }
-
-func _deserializeActions(as []json.RawMessage) []chinchon.Action {
-	_as := []chinchon.Action{}
-	for _, a := range as {
-		_a, _ := chinchon.DeserializeAction(a)
-		_as = append(_as, _a)
-	}
-	return _as
-}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/devblac/chinchon/chinchon"
+	"github.com/devblac/chinchon/server"
 )
 
 func getLastActionString(rs renderState) string {
@@ -17,33 +18,79 @@ func getLastActionString(rs renderState) string {
 		return "¡Empezó la ronda!"
 	}
 
-	return getActionString(*rs.gs.LastActionLog, rs.gs.YouPlayerID)
+	return getActionString(*rs.gs.LastActionLog, rs.gs)
 }
 
-func getActionString(log chinchon.ActionLog, playerID int) string {
-	lastAction, _ := chinchon.DeserializeAction(log.Action)
+// texts is the engine's catalog for the client's language.
+var texts = chinchon.NewCatalog(chinchon.LOCALE_ES)
 
-	who := "Tú"
-	if playerID != log.PlayerID {
-		who = "Oponente"
+func getActionString(log chinchon.ActionLog, gs chinchon.ClientGameState) string {
+	lastAction, err := chinchon.DeserializeAction(log.Action)
+	if err != nil {
+		return "???"
 	}
+	return texts.ActionLog(lastAction, texts.PlayerNameIn(gs, log.PlayerID))
+}
+
+func getEmoteString(emote server.MessageEmote, playerID int) string {
+	who := texts.PlayerName(emote.PlayerID, playerID, 2)
+	return fmt.Sprintf("%v: %v", who, emote.Emote)
+}
 
-	var what string
-	switch lastAction.GetName() {
-	case chinchon.DRAW_FROM_DECK:
-		what = "robó del mazo"
-	case chinchon.DRAW_FROM_DISCARD:
-		what = "robó de la pila de descarte"
-	case chinchon.DISCARD_CARD:
-		action := lastAction.(*chinchon.ActionDiscardCard)
-		what = fmt.Sprintf("descartó %v", getCardString(action.Card))
-	case chinchon.CLOSE_ROUND:
-		what = "cerró la ronda"
-	case chinchon.CONFIRM_ROUND_FINISHED:
-		what = ""
-	default:
-		what = "???"
+func getPlayerStatusString(status server.MessagePlayerStatus, playerID int) string {
+	if status.PlayerID == playerID {
+		return ""
 	}
+	if status.Connected {
+		return "El oponente volvió a la partida"
+	}
+	return "El oponente se desconectó"
+}
+
+func getSessionString(session server.MessageSession) string {
+	return fmt.Sprintf("Para volver a esta partida: TOKEN=%v", session.Token)
+}
+
+func getAnnouncementString(announcement server.MessageAnnouncement) string {
+	return fmt.Sprintf("Aviso del servidor: %v", announcement.Text)
+}
+
+func getErrorString(msgError server.MessageError) string {
+	switch msgError.Code {
+	case server.ErrorCodeNotYourTurn:
+		return "No es tu turno."
+	case server.ErrorCodeActionNotPossible:
+		return "No podés hacer eso ahora."
+	case server.ErrorCodeGameEnded:
+		return "La partida ya terminó."
+	case server.ErrorCodeNothingToUndo:
+		return "No hay nada para deshacer."
+	case server.ErrorCodeInvalidEmote:
+		return "Ese emote no existe."
+	case server.ErrorCodeWrongPassword:
+		return "La contraseña de la partida no es correcta."
+	case server.ErrorCodeServerFull:
+		return fmt.Sprintf("El servidor está lleno, probá de nuevo en %d segundos.", msgError.RetryAfter)
+	}
+	return fmt.Sprintf("Error del servidor: %v", msgError.Message)
+}
+
+func getSeatTakenString(seatTaken server.MessageSeatTaken) string {
+	if seatTaken.Reason == server.SeatTakenReasonReplaced {
+		return fmt.Sprintf("Te conectaste como jugador %d desde otro lado, así que se cerró esta conexión.", seatTaken.PlayerID+1)
+	}
+	return fmt.Sprintf("El jugador %d ya está conectado.", seatTaken.PlayerID+1)
+}
+
+func getLatencyString(latency server.MessagePlayerLatency) string {
+	quality := map[string]string{
+		server.ConnectionGood: "buena",
+		server.ConnectionFair: "regular",
+		server.ConnectionPoor: "mala",
+	}[latency.Quality]
+	return fmt.Sprintf("Conexión %v (%d ms)", quality, latency.RTTMillis)
+}
 
-	return fmt.Sprintf("%v %v", who, what)
+func getMaintenanceString() string {
+	return "El servidor se reinicia por mantenimiento. La partida sigue cuando vuelva."
 }
@@ -4,20 +4,254 @@
 package exampleclient
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/devblac/chinchon/chinchon"
 	"github.com/devblac/chinchon/server"
 	"github.com/gorilla/websocket"
 )
 
-func Player(playerID int, address string) {
+// Player plays as the player in the server's game with the given ID (the default game if
+// empty), with the session token for the seat if the server requires one to rejoin it. The
+// name, if any, shows to those browsing open games if they start the game, and the
+// password is the game's, if it was started with one.
+func Player(gameID string, playerID int, token string, name string, password string, address string) {
+	play(gameID, playerID, token, name, password, server.ServerURL(address, "/ws", true))
+}
+
+// OpenGames prints the server's games with free seats, and how to join them.
+func OpenGames(address string) {
+	conn, err := server.WsDial(server.ServerURL(address, "/lobby/ws", true))
+	if err != nil {
+		log.Fatalf("Failed to connect to WebSocket server: %v", err)
+	}
+	defer conn.Close()
+	if err := server.WsSend(conn, server.NewMessageListGames()); err != nil {
+		log.Fatal(err)
+	}
+	openGames, err := server.WsReadMessage[server.MessageOpenGames, server.MessageOpenGames](conn, server.MessageTypeOpenGames)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(openGames.Games) == 0 {
+		fmt.Println("No hay partidas con lugares libres.")
+		return
+	}
+	for _, g := range openGames.Games {
+		fmt.Print(g.ID)
+		if g.Creator != "" {
+			fmt.Printf(" (de %v)", g.Creator)
+		}
+		password := ""
+		if g.Password {
+			fmt.Print(" (con contraseña)")
+			password = "PASSWORD=... "
+		}
+		fmt.Printf(": %d jugadores, a %d puntos", g.Rules.Players, g.Rules.MaxPoints)
+		for _, playerID := range g.FreeSeats {
+			fmt.Printf("\n  GAME_ID=%v %vchinchon player %d", g.ID, password, playerID+1)
+		}
+		fmt.Println()
+	}
+}
+
+// MyGames prints the player's unfinished games, which can be resumed by playing them again.
+// The server tells who the player is by their session token, e.g. from a daily challenge.
+func MyGames(name string, token string, address string) {
+	req, err := http.NewRequest(http.MethodGet, server.ServerURL(address, "/games/mine", false), nil)
+	if err != nil {
+		log.Fatalf("Failed to list games: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := server.HTTPClient().Do(req)
+	if err != nil {
+		log.Fatalf("Failed to list games: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("Failed to list games: %v", resp.Status)
+	}
+
+	var games []server.MyGame
+	if err := json.NewDecoder(resp.Body).Decode(&games); err != nil {
+		log.Fatalf("Failed to list games: %v", err)
+	}
+	if len(games) == 0 {
+		fmt.Println("No tenés partidas sin terminar.")
+		return
+	}
+	for _, g := range games {
+		fmt.Printf("%v (%v): ronda %d, tus puntos %d, sus puntos %d\n", g.ID, g.Mode, g.RoundNumber, g.YourScore, g.TheirScore)
+		if g.Mode == server.MyGameModeDaily {
+			fmt.Printf("  TOKEN=%v chinchon daily %v\n", token, name)
+		} else {
+			fmt.Printf("  GAME_ID=%v TOKEN=... chinchon player %d\n", g.ID, g.PlayerID+1)
+		}
+	}
+}
+
+// Leaderboard prints the server's leaderboard, as the lobby sends it.
+func Leaderboard(address string) {
+	conn, err := server.WsDial(server.ServerURL(address, "/lobby/ws", true))
+	if err != nil {
+		log.Fatalf("Failed to connect to WebSocket server: %v", err)
+	}
+	defer conn.Close()
+	if err := server.WsSend(conn, server.NewMessageGimmeLeaderboard()); err != nil {
+		log.Fatal(err)
+	}
+	leaderboard, err := server.WsReadMessage[server.MessageHeresLeaderboard, server.MessageHeresLeaderboard](conn, server.MessageTypeHeresLeaderboard)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(leaderboard.Players) == 0 {
+		fmt.Println("Todavía nadie terminó una partida.")
+		return
+	}
+	for i, p := range leaderboard.Players {
+		fmt.Printf("%d. %v: %d ganadas, %d perdidas, %d chinchones, %.1f puntos por ronda", i+1, p.Name, p.Wins, p.Losses, p.Chinchones, p.AveragePointsConceded)
+		if p.Rating != 0 {
+			fmt.Printf(", rating %.0f", p.Rating)
+		}
+		fmt.Println()
+	}
+}
+
+// Daily plays today's daily challenge against the server's bot, with the session token for
+// the name if the server requires one to use it again.
+func Daily(name string, token string, address string) {
+	play("", 0, token, name, "", server.ServerURL(address, "/daily/ws?name="+url.QueryEscape(name), true))
+}
+
+// Match waits in the server's lobby until there's a match for the player, and plays it.
+func Match(name string, address string) {
+	conn, err := server.WsDial(server.ServerURL(address, "/lobby/ws", true))
+	if err != nil {
+		log.Fatalf("Failed to connect to WebSocket server: %v", err)
+	}
+	if err := server.WsSend(conn, server.NewMessageFindMatch(name)); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Buscando rival...")
+	matchFound, err := server.WsReadMessage[server.MessageMatchFound, server.MessageMatchFound](conn, server.MessageTypeMatchFound)
+	conn.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if matchFound.Token != "" {
+		fmt.Printf("Para volver a esta partida: GAME_ID=%v TOKEN=%v chinchon player %d\n", matchFound.GameID, matchFound.Token, matchFound.PlayerID+1)
+	}
+	play(matchFound.GameID, matchFound.PlayerID, matchFound.Token, name, "", server.ServerURL(address, "/ws", true))
+}
+
+// Tournament registers the player in the server's tournament with the given ID, and plays
+// their matches as they start, until the tournament is over. Players coming back to a
+// tournament they registered in give the secret they registered with.
+func Tournament(tournamentID string, name string, secret string, address string) {
+	body, _ := json.Marshal(server.TournamentPlayer{Name: name})
+	resp, err := server.HTTPClient().Post(server.ServerURL(address, "/api/tournaments/"+url.PathEscape(tournamentID)+"/players", false), "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Fatalf("Failed to register in the tournament: %v", err)
+	}
+	reason, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusCreated:
+		var registration server.TournamentRegistration
+		if err := json.Unmarshal(reason, &registration); err != nil {
+			log.Fatalf("Failed to register in the tournament: %v", err)
+		}
+		secret = registration.Secret
+		fmt.Printf("Para volver al torneo: TOURNAMENT_SECRET=%v chinchon tournament %v %v\n", secret, tournamentID, name)
+	case http.StatusConflict:
+		// E.g. if already registered, to come back to the tournament.
+		fmt.Printf("No te pudimos anotar: %v", reason)
+	default:
+		log.Fatalf("Failed to register in the tournament: %v %s", resp.Status, reason)
+	}
+
+	wsURL := server.ServerURL(address, "/tournaments/"+url.PathEscape(tournamentID)+"/ws?name="+url.QueryEscape(name)+"&secret="+url.QueryEscape(secret), true)
+	for {
+		// The connection is closed while playing, as nobody would answer the server's pings.
+		conn, err := server.WsDial(wsURL)
+		if err != nil {
+			log.Fatalf("Failed to connect to WebSocket server: %v", err)
+		}
+		matchFound := waitForTournamentMatch(conn)
+		conn.Close()
+		if matchFound == nil {
+			return
+		}
+		play(matchFound.GameID, matchFound.PlayerID, matchFound.Token, name, "", server.ServerURL(address, "/ws", true))
+	}
+}
+
+// waitForTournamentMatch prints the tournament's bracket as it changes, until the player's
+// next match starts, or the tournament is over (returning nil).
+func waitForTournamentMatch(conn *websocket.Conn) *server.MessageMatchFound {
+	for {
+		messageType, message, err := server.WsReadAnyMessage(conn)
+		if err != nil {
+			log.Fatal(err)
+		}
+		switch messageType {
+		case server.MessageTypeMatchFound:
+			matchFound, err := server.WsDeserializeMessage[server.MessageMatchFound, server.MessageMatchFound](message, server.MessageTypeMatchFound)
+			if err != nil {
+				log.Fatal(err)
+			}
+			return matchFound
+		case server.MessageTypeTournamentUpdate:
+			update, err := server.WsDeserializeMessage[server.MessageTournamentUpdate, server.MessageTournamentUpdate](message, server.MessageTypeTournamentUpdate)
+			if err != nil {
+				log.Fatal(err)
+			}
+			printTournament(update.Tournament)
+			if update.Tournament.Status == server.TournamentFinished {
+				return nil
+			}
+		}
+	}
+}
+
+func printTournament(t server.Tournament) {
+	fmt.Printf("\n%v (%d anotados)\n", t.Name, len(t.Players))
+	for i, round := range t.Rounds {
+		fmt.Printf("Ronda %d:\n", i+1)
+		for _, match := range round {
+			fmt.Printf("  %v", strings.Join(match.Players, " vs "))
+			switch {
+			case len(match.Players) == 1:
+				fmt.Print(" (pasa de ronda)")
+			case match.Winner != "":
+				fmt.Printf(" (ganó %v)", match.Winner)
+			case match.GameID != "":
+				fmt.Print(" (jugando)")
+			}
+			fmt.Println()
+		}
+	}
+	switch t.Status {
+	case server.TournamentRegistering:
+		fmt.Println("Esperando que empiece el torneo...")
+	case server.TournamentFinished:
+		fmt.Printf("¡%v ganó el torneo!\n", t.Winner)
+	}
+}
+
+func play(gameID string, playerID int, token string, name string, password string, wsURL string) {
 	var (
-		ui          = NewUI()
-		conn        = handshakeWithServer(playerID, address)
-		gameStateCh = recvGameState(conn)
+		ui                               = NewUI()
+		conn, firstType, first           = handshakeWithServer(gameID, playerID, token, name, password, wsURL)
+		gameStateCh, noticeCh, latencyCh = recvMessages(conn, firstType, first)
 
 		clientGameState chinchon.ClientGameState
 		possibleActions []chinchon.Action
@@ -31,14 +265,36 @@ func Player(playerID int, address string) {
 			if err := ui.render(clientGameState); err != nil {
 				log.Fatal(err)
 			}
+		case notice := <-noticeCh:
+			ui.notice = notice(clientGameState.YouPlayerID)
+			if err := ui.render(clientGameState); err != nil {
+				log.Fatal(err)
+			}
+		case latency := <-latencyCh:
+			// Shown from the next render on, as rendering pauses the game.
+			if latency.PlayerID == clientGameState.YouPlayerID {
+				ui.latency = getLatencyString(latency)
+			}
 		case key := <-ui.keyCh:
 			// If game is over, finish after any key press.
 			if clientGameState.IsGameEnded {
 				return
 			}
 
+			// Emote keys work regardless of whose turn it is.
+			if i := strings.IndexRune(emoteKeys, key); i >= 0 && i < len(server.Emotes) {
+				if err := server.WsSend(conn, server.NewMessageEmote(playerID, server.Emotes[i])); err != nil {
+					log.Fatal(err)
+				}
+				continue
+			}
+
 			// If there are no possible actions, ignore key presses.
-			possibleActions = _deserializeActions(clientGameState.PossibleActions)
+			actions, err := clientGameState.Actions()
+			if err != nil {
+				log.Println("Failed to read possible actions:", err)
+			}
+			possibleActions = actions
 			if len(possibleActions) == 0 {
 				continue
 			}
@@ -59,31 +315,103 @@ func Player(playerID int, address string) {
 	}
 }
 
-func handshakeWithServer(playerID int, address string) *websocket.Conn {
-	conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%v/ws", address), nil)
-	if err != nil {
-		log.Fatalf("Failed to connect to WebSocket server: %v", err)
-	}
-
+func handshakeWithServer(gameID string, playerID int, token string, name string, password string, wsURL string) (*websocket.Conn, int, []byte) {
 	// Hello message is meant to tell the server who we are, and request game state.
 	// Game could be in progress (this could be a reconnection).
-	if err := server.WsSend(conn, server.NewMessageHello(playerID)); err != nil {
-		log.Fatal(err)
+	hello := server.NewMessageHelloForGame(gameID, playerID, token)
+	hello.Name = name
+	hello.Password = password
+	hello.Deltas = true
+	conn, messageType, message, err := server.WsJoin(wsURL, hello)
+	if err != nil {
+		log.Fatalf("Failed to connect to WebSocket server: %v", err)
 	}
-
-	return conn
+	return conn, messageType, message
 }
 
-func recvGameState(conn *websocket.Conn) chan chinchon.ClientGameState {
+// recvMessages dispatches incoming messages, starting with the first one the server sent:
+// game states go to the first channel, while emotes, player status changes, session tokens,
+// maintenance restarts, announcements and errors go to the second one, as notices to render
+// for the player, and connection latencies to the third one.
+func recvMessages(conn *websocket.Conn, firstType int, first []byte) (chan chinchon.ClientGameState, chan func(youPlayerID int) string, chan server.MessagePlayerLatency) {
 	gameStateCh := make(chan chinchon.ClientGameState)
+	noticeCh := make(chan func(youPlayerID int) string)
+	latencyCh := make(chan server.MessagePlayerLatency)
 	go func() {
-		for {
-			clientGameState, err := server.WsReadMessage[chinchon.ClientGameState, server.MessageHeresGameState](conn, server.MessageTypeHeresGameState)
-			if err != nil {
-				log.Fatal(err)
+		var states server.StateAssembler
+		for messageType, message := firstType, first; ; messageType, message = readMessage(conn) {
+			switch messageType {
+			case server.MessageTypeHeresGameState, server.MessageTypeGameStateDelta:
+				clientGameState, err := states.ClientGameState(messageType, message)
+				if err != nil {
+					log.Fatal(err)
+				}
+				if !clientGameState.IsInSync() {
+					if err := server.WsSend(conn, server.NewMessageGimmeGameState()); err != nil {
+						log.Fatal(err)
+					}
+					continue
+				}
+				gameStateCh <- *clientGameState
+			case server.MessageTypeEmote:
+				emote, err := server.WsDeserializeMessage[server.MessageEmote, server.MessageEmote](message, messageType)
+				if err != nil {
+					continue
+				}
+				noticeCh <- func(youPlayerID int) string { return getEmoteString(*emote, youPlayerID) }
+			case server.MessageTypePlayerStatus:
+				status, err := server.WsDeserializeMessage[server.MessagePlayerStatus, server.MessagePlayerStatus](message, messageType)
+				if err != nil {
+					continue
+				}
+				noticeCh <- func(youPlayerID int) string { return getPlayerStatusString(*status, youPlayerID) }
+			case server.MessageTypeSession:
+				session, err := server.WsDeserializeMessage[server.MessageSession, server.MessageSession](message, messageType)
+				if err != nil {
+					continue
+				}
+				noticeCh <- func(youPlayerID int) string { return getSessionString(*session) }
+			case server.MessageTypeMaintenance:
+				noticeCh <- func(youPlayerID int) string { return getMaintenanceString() }
+			case server.MessageTypeAnnouncement:
+				announcement, err := server.WsDeserializeMessage[server.MessageAnnouncement, server.MessageAnnouncement](message, messageType)
+				if err != nil {
+					continue
+				}
+				noticeCh <- func(youPlayerID int) string { return getAnnouncementString(*announcement) }
+			case server.MessageTypeError:
+				msgError, err := server.WsDeserializeMessage[server.MessageError, server.MessageError](message, messageType)
+				if err != nil {
+					continue
+				}
+				if msgError.Code == server.ErrorCodeServerFull || msgError.Code == server.ErrorCodeWrongPassword {
+					// The server closes the connection right after.
+					log.Fatal(getErrorString(*msgError))
+				}
+				noticeCh <- func(youPlayerID int) string { return getErrorString(*msgError) }
+			case server.MessageTypeSeatTaken:
+				seatTaken, err := server.WsDeserializeMessage[server.MessageSeatTaken, server.MessageSeatTaken](message, messageType)
+				if err != nil {
+					continue
+				}
+				// The server closes the connection right after.
+				log.Fatal(getSeatTakenString(*seatTaken))
+			case server.MessageTypePlayerLatency:
+				latency, err := server.WsDeserializeMessage[server.MessagePlayerLatency, server.MessagePlayerLatency](message, messageType)
+				if err != nil {
+					continue
+				}
+				latencyCh <- *latency
 			}
-			gameStateCh <- *clientGameState
 		}
 	}()
-	return gameStateCh
+	return gameStateCh, noticeCh, latencyCh
+}
+
+func readMessage(conn *websocket.Conn) (int, []byte) {
+	messageType, message, err := server.WsReadAnyMessage(conn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return messageType, message
 }
@@ -4,14 +4,33 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/devblac/chinchon/botclient"
+	"github.com/devblac/chinchon/chinchon"
 	"github.com/devblac/chinchon/examplebot/newbot"
 	"github.com/devblac/chinchon/exampleclient"
+	"github.com/devblac/chinchon/rating"
+	"github.com/devblac/chinchon/redisstore"
 	"github.com/devblac/chinchon/server"
+	_ "github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	_ "modernc.org/sqlite"
 )
 
 func main() {
@@ -30,6 +49,17 @@ func main() {
 		address = os.Args[3]
 	}
 
+	if caFile := os.Getenv("TLS_CA_FILE"); caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		roots := x509.NewCertPool()
+		if err != nil || !roots.AppendCertsFromPEM(pem) {
+			fmt.Println("Invalid TLS_CA_FILE. Please provide a file with PEM certificates.")
+			os.Exit(1)
+		}
+		server.ClientTLSConfig = &tls.Config{RootCAs: roots}
+	}
+	server.ForceLongPolling = os.Getenv("LONG_POLLING") != ""
+
 	var (
 		playerNum int
 		err       error
@@ -44,25 +74,477 @@ func main() {
 
 	switch cmd {
 	case "server":
-		server.New(port).Start()
+		setupLogging()
+		var opts []server.Option
+		if delay, ok := durationEnv("SPECTATOR_DELAY"); ok {
+			opts = append(opts, server.WithSpectatorDelay(delay))
+		}
+		if grace, ok := durationEnv("RECONNECT_GRACE"); ok {
+			opts = append(opts, server.WithReconnectGrace(grace))
+		}
+		switch policy := server.SeatPolicy(os.Getenv("SEAT_POLICY")); policy {
+		case "":
+		case server.SeatPolicyReject, server.SeatPolicyKickOlder:
+			opts = append(opts, server.WithSeatPolicy(policy))
+		default:
+			fmt.Println("Invalid SEAT_POLICY. Please provide reject or kick_older.")
+			os.Exit(1)
+		}
+		if os.Getenv("SEND_QUEUE_SIZE") != "" || os.Getenv("SEND_QUEUE_POLICY") != "" {
+			size := server.DefaultSendQueueSize
+			if value := os.Getenv("SEND_QUEUE_SIZE"); value != "" {
+				if size, err = strconv.Atoi(value); err != nil || size < 1 {
+					fmt.Println("Invalid SEND_QUEUE_SIZE. Please provide a number of messages.")
+					os.Exit(1)
+				}
+			}
+			policy := server.SendQueuePolicy(os.Getenv("SEND_QUEUE_POLICY"))
+			switch policy {
+			case "":
+				policy = server.SendQueueDropOldest
+			case server.SendQueueDropOldest, server.SendQueueDisconnect:
+			default:
+				fmt.Println("Invalid SEND_QUEUE_POLICY. Please provide drop_oldest or disconnect.")
+				os.Exit(1)
+			}
+			opts = append(opts, server.WithSendQueue(size, policy))
+		}
+		if timeout, ok := durationEnv("BOT_TAKEOVER_TIMEOUT"); ok {
+			opts = append(opts, server.WithBotTakeover(timeout, server.DefaultMaxIdleTimeouts))
+		}
+		if count := os.Getenv("PLAYERS"); count != "" {
+			n, err := strconv.Atoi(count)
+			if err != nil || n < chinchon.MinPlayers || n > chinchon.MaxPlayers {
+				fmt.Printf("Invalid PLAYERS. Please provide a number from %d to %d.\n", chinchon.MinPlayers, chinchon.MaxPlayers)
+				os.Exit(1)
+			}
+			if os.Getenv("TEAMS") != "" && n%2 != 0 {
+				fmt.Println("Invalid PLAYERS. Team games need an even number of players.")
+				os.Exit(1)
+			}
+			opts = append(opts, server.WithGameOptions(chinchon.WithPlayers(n)))
+		}
+		if os.Getenv("TEAMS") != "" {
+			opts = append(opts, server.WithGameOptions(chinchon.WithTeams()))
+		}
+		if bots := os.Getenv("BOT_PLAYERS"); bots != "" {
+			playerIDs := []int{}
+			for _, bot := range strings.Split(bots, ",") {
+				n, err := strconv.Atoi(strings.TrimSpace(bot))
+				if err != nil || n < 1 || n > chinchon.MaxPlayers {
+					fmt.Println("Invalid BOT_PLAYERS. Please provide player numbers separated by commas, e.g. 2 or 2,3.")
+					os.Exit(1)
+				}
+				playerIDs = append(playerIDs, n-1)
+			}
+			opts = append(opts, server.WithHostedBots(playerIDs...))
+		}
+		if os.Getenv("REENTER") != "" {
+			opts = append(opts, server.WithGameOptions(chinchon.WithReenter()))
+		}
+		if penalty := os.Getenv("RESIGN_ROUND_PENALTY"); penalty != "" {
+			n, err := strconv.Atoi(penalty)
+			if err != nil || n <= 0 {
+				fmt.Println("Invalid RESIGN_ROUND_PENALTY. Please provide a positive number of points.")
+				os.Exit(1)
+			}
+			opts = append(opts, server.WithGameOptions(chinchon.WithResignRound(n)))
+		}
+		if rounds := os.Getenv("MAX_ROUNDS"); rounds != "" {
+			n, err := strconv.Atoi(rounds)
+			if err != nil || n <= 0 {
+				fmt.Println("Invalid MAX_ROUNDS. Please provide a positive number of rounds.")
+				os.Exit(1)
+			}
+			opts = append(opts, server.WithGameOptions(chinchon.WithMaxRounds(n)))
+		}
+		if turns := os.Getenv("MIN_TURNS_BEFORE_CLOSE"); turns != "" {
+			n, err := strconv.Atoi(turns)
+			if err != nil || n < 0 {
+				fmt.Println("Invalid MIN_TURNS_BEFORE_CLOSE. Please provide a non-negative number of turns.")
+				os.Exit(1)
+			}
+			opts = append(opts, server.WithGameOptions(chinchon.WithMinTurnsBeforeClose(n)))
+		}
+		if size := os.Getenv("HAND_SIZE"); size != "" {
+			n, err := strconv.Atoi(size)
+			if err != nil || n < chinchon.MinHandSize {
+				fmt.Printf("Invalid HAND_SIZE. Please provide a number of cards from %d.\n", chinchon.MinHandSize)
+				os.Exit(1)
+			}
+			opts = append(opts, server.WithGameOptions(chinchon.WithHandSize(n)))
+		}
+		if deckType := os.Getenv("DECK"); deckType != "" {
+			opts = append(opts, server.WithGameOptions(chinchon.WithDeckType(deckType)))
+		}
+		if mode := os.Getenv("CHINCHON_MODE"); mode != "" {
+			switch mode {
+			case chinchon.CHINCHON_WINS_GAME, chinchon.CHINCHON_WINS_ROUND, chinchon.CHINCHON_MINUS_25:
+			default:
+				fmt.Println("Invalid CHINCHON_MODE. Please provide wins_game, wins_round or minus_25.")
+				os.Exit(1)
+			}
+			opts = append(opts, server.WithGameOptions(chinchon.WithChinchonMode(mode)))
+		}
+		if os.Getenv("VERIFIABLE_SHUFFLE") != "" {
+			opts = append(opts, server.WithGameOptions(chinchon.WithVerifiableShuffle()))
+		}
+		if os.Getenv("ACE_WRAPAROUND") != "" {
+			opts = append(opts, server.WithGameOptions(chinchon.WithAceWraparound()))
+		}
+		if os.Getenv("DECLARED_MELDS") != "" {
+			opts = append(opts, server.WithGameOptions(chinchon.WithDeclaredMelds()))
+		}
+		if os.Getenv("LAY_OFF") != "" {
+			opts = append(opts, server.WithGameOptions(chinchon.WithLayOff()))
+		}
+		if os.Getenv("DATA_DIR") != "" && os.Getenv("REDIS_URL") != "" {
+			fmt.Println("Invalid DATA_DIR. Games are stored either in DATA_DIR or in REDIS_URL, not both.")
+			os.Exit(1)
+		}
+		if os.Getenv("DATABASE_URL") != "" && (os.Getenv("DATA_DIR") != "" || os.Getenv("REDIS_URL") != "") {
+			fmt.Println("Invalid DATABASE_URL. Games are stored either in DATABASE_URL, DATA_DIR or REDIS_URL, not several.")
+			os.Exit(1)
+		}
+		if databaseURL := os.Getenv("DATABASE_URL"); databaseURL != "" {
+			store, err := openSQLGameStore(databaseURL)
+			if err != nil {
+				fmt.Printf("Invalid DATABASE_URL: %v\n", err)
+				os.Exit(1)
+			}
+			opts = append(opts, server.WithGameStore(store, server.DefaultSnapshotEvery))
+		}
+		if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
+			redisOpts, err := redis.ParseURL(redisURL)
+			if err != nil {
+				fmt.Printf("Invalid REDIS_URL: %v\n", err)
+				os.Exit(1)
+			}
+			store := redisstore.New(redis.NewClient(redisOpts))
+			opts = append(opts, server.WithGameStore(store, server.DefaultSnapshotEvery))
+			// Without a list of nodes, cluster nodes claim the games they host in Redis.
+			if os.Getenv("CLUSTER_SELF") != "" && os.Getenv("CLUSTER_NODES") == "" {
+				opts = append(opts, server.WithGameRegistry(store))
+			}
+		}
+		if self := os.Getenv("CLUSTER_SELF"); self != "" {
+			if os.Getenv("CLUSTER_NODES") == "" && os.Getenv("REDIS_URL") == "" {
+				fmt.Println("Invalid CLUSTER_SELF. Please provide the cluster's CLUSTER_NODES, or a REDIS_URL to claim games in.")
+				os.Exit(1)
+			}
+			var nodes []string
+			if value := os.Getenv("CLUSTER_NODES"); value != "" {
+				nodes = strings.Split(value, ",")
+			}
+			opts = append(opts, server.WithCluster(self, nodes...))
+		}
+		if dir := os.Getenv("DATA_DIR"); dir != "" {
+			store, err := server.NewFileGameStore(dir)
+			if err != nil {
+				fmt.Printf("Invalid DATA_DIR: %v\n", err)
+				os.Exit(1)
+			}
+			opts = append(opts, server.WithGameStore(store, server.DefaultSnapshotEvery))
+			opts = append(opts, server.WithLeaderboardFile(filepath.Join(dir, "leaderboard.json")))
+			opts = append(opts, server.WithDailyResultsFile(filepath.Join(dir, "daily.json")))
+		}
+		if (os.Getenv("TLS_CERT_FILE") == "") != (os.Getenv("TLS_KEY_FILE") == "") {
+			fmt.Println("Invalid TLS_CERT_FILE. Please provide both TLS_CERT_FILE and TLS_KEY_FILE.")
+			os.Exit(1)
+		}
+		if certFile := os.Getenv("TLS_CERT_FILE"); certFile != "" {
+			opts = append(opts, server.WithTLS(certFile, os.Getenv("TLS_KEY_FILE")))
+		}
+		if hosts := os.Getenv("AUTOCERT_HOSTS"); hosts != "" {
+			cacheDir := os.Getenv("AUTOCERT_CACHE_DIR")
+			if cacheDir == "" {
+				cacheDir = "autocert"
+			}
+			opts = append(opts, server.WithAutocert(cacheDir, strings.Split(hosts, ",")...))
+		}
+		if interval, ok := durationEnv("PING_INTERVAL"); ok {
+			opts = append(opts, server.WithPingInterval(interval))
+		}
+		if value := os.Getenv("FULL_STATE_EVERY"); value != "" {
+			deltas, err := strconv.Atoi(value)
+			if err != nil || deltas < 0 {
+				fmt.Println("Invalid FULL_STATE_EVERY. Please provide a number of deltas.")
+				os.Exit(1)
+			}
+			opts = append(opts, server.WithFullStateEvery(deltas))
+		}
+		if value := os.Getenv("MAX_GAMES"); value != "" {
+			games, err := strconv.Atoi(value)
+			if err != nil || games < 1 {
+				fmt.Println("Invalid MAX_GAMES. Please provide a number of games.")
+				os.Exit(1)
+			}
+			opts = append(opts, server.WithMaxGames(games))
+		}
+		if value := os.Getenv("MAX_CONNECTIONS"); value != "" {
+			connections, err := strconv.Atoi(value)
+			if err != nil || connections < 1 {
+				fmt.Println("Invalid MAX_CONNECTIONS. Please provide a number of connections.")
+				os.Exit(1)
+			}
+			opts = append(opts, server.WithMaxConnections(connections))
+		}
+		if refill, ok := durationEnv("RATE_LIMIT_REFILL"); ok {
+			burst := server.DefaultRateLimitBurst
+			if value := os.Getenv("RATE_LIMIT_BURST"); value != "" {
+				burst, err = strconv.Atoi(value)
+				if err != nil || burst < 1 {
+					fmt.Println("Invalid RATE_LIMIT_BURST. Please provide a positive number of messages.")
+					os.Exit(1)
+				}
+			}
+			opts = append(opts, server.WithRateLimit(burst, refill))
+		}
+		if os.Getenv("NOTIFY_WEBHOOKS") != "" {
+			opts = append(opts, server.WithTurnNotifier(server.WebhookNotifier{}))
+		}
+		if grpcPort := os.Getenv("GRPC_PORT"); grpcPort != "" {
+			opts = append(opts, server.WithGRPC(grpcPort))
+		}
+		if token := os.Getenv("ADMIN_TOKEN"); token != "" {
+			opts = append(opts, server.WithAdminToken(token))
+		}
+		if secret := os.Getenv("AUTH_SECRET"); secret != "" {
+			opts = append(opts, server.WithAuth([]byte(secret)))
+		}
+		if secret := os.Getenv("DAILY_SECRET"); secret != "" {
+			opts = append(opts, server.WithDailySecret([]byte(secret)))
+		}
+		switch os.Getenv("RATINGS") {
+		case "":
+		case "elo":
+			opts = append(opts, server.WithRatings(rating.New()))
+		case "glicko2":
+			opts = append(opts, server.WithRatings(rating.New(rating.WithGlicko2(rating.DefaultTau))))
+		default:
+			fmt.Println("Invalid RATINGS. Please provide elo or glicko2.")
+			os.Exit(1)
+		}
+		if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+			tp := setupTracing()
+			defer func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				if err := tp.Shutdown(ctx); err != nil {
+					slog.Error("failed to flush traces", "err", err)
+				}
+			}()
+			opts = append(opts, server.WithTracerProvider(tp))
+		}
+		server.New(port, opts...).Start()
 	case "player":
-		exampleclient.Player(playerNum-1, address)
+		exampleclient.Player(os.Getenv("GAME_ID"), playerNum-1, os.Getenv("TOKEN"), os.Getenv("NAME"), os.Getenv("PASSWORD"), address)
+	case "daily":
+		if len(os.Args) < 3 {
+			usage()
+		}
+		exampleclient.Daily(os.Args[2], os.Getenv("TOKEN"), address)
+	case "match":
+		if len(os.Args) < 3 {
+			usage()
+		}
+		exampleclient.Match(os.Args[2], address)
+	case "mygames":
+		if len(os.Args) < 3 {
+			usage()
+		}
+		exampleclient.MyGames(os.Args[2], os.Getenv("TOKEN"), address)
+	case "leaderboard":
+		if len(os.Args) == 3 {
+			address = os.Args[2]
+		}
+		exampleclient.Leaderboard(address)
+	case "games":
+		if len(os.Args) == 3 {
+			address = os.Args[2]
+		}
+		exampleclient.OpenGames(address)
+	case "tournament":
+		if len(os.Args) < 4 {
+			usage()
+		}
+		address = fmt.Sprintf("localhost:%v", port)
+		if len(os.Args) >= 5 {
+			address = os.Args[4]
+		}
+		exampleclient.Tournament(os.Args[2], os.Args[3], os.Getenv("TOURNAMENT_SECRET"), address)
+	case "puzzle":
+		if len(os.Args) < 3 {
+			usage()
+		}
+		exampleclient.Puzzles(os.Args[2])
+	case "history":
+		if len(os.Args) < 3 {
+			usage()
+		}
+		printHandHistory(os.Args[2])
+	case "export":
+		if len(os.Args) < 3 {
+			usage()
+		}
+		printNotation(os.Args[2])
+	case "tutorial":
+		path := "tutorials/basics.json"
+		if len(os.Args) >= 3 {
+			path = os.Args[2]
+		}
+		exampleclient.Tutorial(path)
 	case "bot":
-		botclient.Bot(playerNum-1, address, newbot.New(newbot.WithDefaultLogger))
+		botclient.Bot(os.Getenv("GAME_ID"), playerNum-1, os.Getenv("TOKEN"), address, newbot.New(newbot.WithDefaultLogger))
 	default:
-		fmt.Println("Invalid argument. Please provide either server, player, or bot.")
+		fmt.Println("Invalid argument. Please provide either server, player, daily, match, mygames, leaderboard, games, tournament, puzzle, tutorial, history, export, or bot.")
+	}
+}
+
+// durationEnv reads a duration (e.g. 90s) from an environment variable, if defined.
+func durationEnv(name string) (time.Duration, bool) {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0, false
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		fmt.Printf("Invalid %v. Please provide a duration, e.g. 90s.\n", name)
+		usage()
+	}
+	return d, true
+}
+
+// setupTracing exports the server's traces over OTLP/HTTP, configured with the standard
+// OTEL_EXPORTER_OTLP_* and OTEL_SERVICE_NAME environment variables.
+func setupTracing() *sdktrace.TracerProvider {
+	ctx := context.Background()
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		fmt.Printf("Failed to set up tracing: %v\n", err)
+		os.Exit(1)
+	}
+	res, err := resource.New(ctx, resource.WithAttributes(attribute.String("service.name", "chinchon")), resource.WithFromEnv())
+	if err != nil {
+		fmt.Printf("Failed to set up tracing: %v\n", err)
+		os.Exit(1)
+	}
+	return sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+}
+
+// setupLogging configures the default logger from LOG_LEVEL and LOG_FORMAT.
+func setupLogging() {
+	var level slog.Level
+	if value := os.Getenv("LOG_LEVEL"); value != "" {
+		if err := level.UnmarshalText([]byte(value)); err != nil {
+			fmt.Println("Invalid LOG_LEVEL. Please provide debug, info, warn or error.")
+			os.Exit(1)
+		}
+	}
+	opts := &slog.HandlerOptions{Level: level}
+	switch os.Getenv("LOG_FORMAT") {
+	case "", "text":
+		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, opts)))
+	case "json":
+		slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, opts)))
+	default:
+		fmt.Println("Invalid LOG_FORMAT. Please provide text or json.")
+		os.Exit(1)
+	}
+}
+
+// printHandHistory prints the hand history of the game persisted in the server's DATA_DIR,
+// in the language set by LOCALE.
+func printHandHistory(dir string) {
+	gs := restoreStoredGame(dir)
+	fmt.Print(chinchon.NewCatalog(os.Getenv("LOCALE")).HandHistory(*gs))
+}
+
+func printNotation(dir string) {
+	notation, err := chinchon.ExportNotation(restoreStoredGame(dir))
+	if err != nil {
+		fmt.Printf("Failed to export the game: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(notation)
+}
+
+// openSQLGameStore opens the game store in the database at the URL: postgres://... for
+// Postgres, or sqlite://path/to/file.db for SQLite.
+func openSQLGameStore(databaseURL string) (*server.SQLGameStore, error) {
+	driver, dialect, dsn := "postgres", server.Postgres, databaseURL
+	if path, ok := strings.CutPrefix(databaseURL, "sqlite://"); ok {
+		driver, dialect, dsn = "sqlite", server.SQLite, path
+	} else if !strings.HasPrefix(databaseURL, "postgres://") && !strings.HasPrefix(databaseURL, "postgresql://") {
+		return nil, errors.New("expected a postgres:// or sqlite:// URL")
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if dialect == server.SQLite {
+		db.SetMaxOpenConns(1) // SQLite doesn't take concurrent writes
+	}
+	return server.NewSQLGameStore(db, dialect)
+}
+
+// restoreStoredGame loads the hosted game persisted in the data directory, or exits.
+func restoreStoredGame(dir string) *chinchon.GameState {
+	store, err := server.NewFileGameStore(dir)
+	if err != nil {
+		fmt.Printf("Invalid data directory: %v\n", err)
+		os.Exit(1)
+	}
+	gs, err := server.RestoreGame(store, server.HostedGameID)
+	if err != nil {
+		fmt.Printf("Failed to load the game: %v\n", err)
+		os.Exit(1)
 	}
+	return gs
 }
 
 func usage() {
 	fmt.Println("usage: chinchon server")
 	fmt.Println("usage: chinchon player %number [address]")
 	fmt.Println("usage: chinchon bot %number [address]")
+	fmt.Println("usage: chinchon daily %name [address]")
+	fmt.Println("usage: chinchon mygames %name [address]")
+	fmt.Println("usage: chinchon leaderboard [address]")
+	fmt.Println("usage: chinchon games [address]")
+	fmt.Println("usage: chinchon match %name [address]")
+	fmt.Println("usage: chinchon tournament %id %name [address]")
+	fmt.Println("usage: chinchon puzzle path/to/puzzles.json")
+	fmt.Println("usage: chinchon tutorial [path/to/tutorial.json]")
+	fmt.Println("usage: chinchon history path/to/data/dir")
+	fmt.Println("usage: chinchon export path/to/data/dir")
 	fmt.Println("usage: e.g. chinchon player 1")
 	fmt.Println("usage: e.g. chinchon player 2")
 	fmt.Println("usage: e.g. chinchon player 1 localhost:8080")
 	fmt.Println("usage: e.g. chinchon bot 1 localhost:8080")
 	fmt.Println("usage: e.g. chinchon bot 2")
+	fmt.Println("usage: e.g. chinchon daily juan")
+	fmt.Println("usage: e.g. chinchon match juan")
+	fmt.Println("usage: e.g. chinchon puzzle puzzles/basics.json")
 	fmt.Println("Define the PORT environment variable for chinchon server to change the default port (8080).")
+	fmt.Println("Define the TLS_CERT_FILE and TLS_KEY_FILE environment variables for chinchon server to serve over TLS, or AUTOCERT_HOSTS to get certificates from Let's Encrypt.")
+	fmt.Println("Define the TLS_CA_FILE environment variable for clients to trust the server's self-signed certificate, e.g. chinchon player 1 wss://localhost:8080")
+	fmt.Println("Define the LONG_POLLING environment variable for clients to connect over HTTP long polling rather than WebSocket, which they otherwise fall back to when WebSocket is blocked")
+	fmt.Println("Define the LOG_LEVEL environment variable for chinchon server to change the log level (debug, info, warn or error; default info), and LOG_FORMAT=json to log JSON lines.")
+	fmt.Println("Define the PING_INTERVAL environment variable for chinchon server to change how often connections are pinged to detect dead ones (default 20s, 0s disables pings).")
+	fmt.Println("Define the FULL_STATE_EVERY environment variable for chinchon server to change how many game state deltas clients asking for them get between full game states (default 20).")
+	fmt.Println("Define the MAX_GAMES and MAX_CONNECTIONS environment variables for chinchon server to cap the games played and WebSocket connections open at once, turning away the rest with a retry-after.")
+	fmt.Println("Define the SEND_QUEUE_SIZE environment variable for chinchon server to change how many messages may be queued for each connection (default 32), and SEND_QUEUE_POLICY to drop_oldest (the default) or disconnect to choose what happens to slow clients past that.")
+	fmt.Println("Define the RATE_LIMIT_REFILL environment variable for chinchon server to let each connection send one message per that duration, e.g. 100ms, after a burst of RATE_LIMIT_BURST (default 20).")
+	fmt.Println("Define the DATABASE_URL environment variable for chinchon server to store games in Postgres (postgres://...) or SQLite (sqlite://path/to/file.db) instead of DATA_DIR.")
+	fmt.Println("Define the DAILY_SECRET environment variable for chinchon server to derive the daily challenges' seeds from it, dealing the same cards after a restart.")
+	fmt.Println("Define the ADMIN_TOKEN environment variable for chinchon server to enable the /admin endpoints, authenticated with that bearer token.")
+	fmt.Println("Define the OTEL_EXPORTER_OTLP_ENDPOINT environment variable for chinchon server to export traces of its work over OTLP/HTTP, e.g. http://localhost:4318.")
+	fmt.Println("Define the CLUSTER_SELF environment variable for chinchon server to run it as a node of a cluster, reachable at that address, hosting the games whose IDs hash to it among the comma-separated addresses in CLUSTER_NODES (or, with REDIS_URL and no CLUSTER_NODES, the games it claims first in Redis).")
+	fmt.Println("Define the NOTIFY_WEBHOOKS environment variable for chinchon server to POST to the URL players give in their hello message (notify) when it's their turn while they aren't connected.")
+	fmt.Println("Define the GRPC_PORT environment variable for chinchon server to also serve games over gRPC on that port.")
+	fmt.Println("Define the TEAMS environment variable for chinchon server to host a 2v2 game (players 1 and 3 against 2 and 4).")
+	fmt.Println("Define the SPECTATOR_DELAY environment variable for chinchon server to change the spectator delay (default 60s).")
+	fmt.Println("Define the BOT_PLAYERS environment variable for chinchon server to have the server's bot play as the given players, e.g. 2.")
+	fmt.Println("Define the BOT_TAKEOVER_TIMEOUT environment variable for chinchon server to let a bot play for players idle for 3 turn timeouts in a row, e.g. 30s.")
 	os.Exit(1)
 }
@@ -0,0 +1,39 @@
+package rating
+
+import "math"
+
+// DefaultKFactor is the most an Elo rating changes after an even game.
+const DefaultKFactor = 32
+
+// Elo scales the rating change by how convincing the win was: up to half more for a wide
+// margin, and half more for a chinchón.
+const (
+	maxMarginBonus = 0.5
+	marginForBonus = 100
+	chinchonBonus  = 0.5
+)
+
+// WithElo updates ratings with Elo (the default), with the given K-factor.
+func WithElo(kFactor float64) Option {
+	return func(r *Ratings) {
+		r.system = elo{kFactor: kFactor}
+	}
+}
+
+type elo struct {
+	kFactor float64
+}
+
+func (e elo) update(winner, loser Rating, result Result) (Rating, Rating) {
+	expected := 1 / (1 + math.Pow(10, (loser.Value-winner.Value)/400))
+
+	multiplier := 1 + maxMarginBonus*math.Min(math.Max(float64(result.Margin), 0)/marginForBonus, 1)
+	if result.Chinchon {
+		multiplier += chinchonBonus
+	}
+
+	change := e.kFactor * multiplier * (1 - expected)
+	winner.Value += change
+	loser.Value -= change
+	return winner, loser
+}
@@ -0,0 +1,89 @@
+package rating
+
+import "math"
+
+// DefaultTau constrains how much volatility changes with Glicko-2. Lower values (down to
+// about 0.3) suit games where skill changes slowly.
+const DefaultTau = 0.5
+
+// glickoScale converts between Glicko ratings and the Glicko-2 scale.
+const glickoScale = 173.7178
+
+// WithGlicko2 updates ratings with Glicko-2 (see http://www.glicko.net/glicko/glicko2.pdf),
+// with every game as its own rating period, and the given tau (e.g. DefaultTau). Unlike
+// Elo, the margin and chinchones don't change the update, only who won.
+func WithGlicko2(tau float64) Option {
+	return func(r *Ratings) {
+		r.system = glicko2{tau: tau}
+	}
+}
+
+type glicko2 struct {
+	tau float64
+}
+
+func (g glicko2) update(winner, loser Rating, result Result) (Rating, Rating) {
+	return g.period(winner, []Rating{loser}, []float64{1}), g.period(loser, []Rating{winner}, []float64{0})
+}
+
+// period returns the player's rating after a rating period of games against the opponents,
+// with scores 1 for a win and 0 for a loss, as in steps 2 to 8 of the Glicko-2 paper.
+func (g glicko2) period(player Rating, opponents []Rating, scores []float64) Rating {
+	mu, phi := (player.Value-DefaultRating)/glickoScale, player.Deviation/glickoScale
+
+	var vInverse, improvement float64
+	for i, opponent := range opponents {
+		opponentMu, opponentPhi := (opponent.Value-DefaultRating)/glickoScale, opponent.Deviation/glickoScale
+		gPhi := 1 / math.Sqrt(1+3*opponentPhi*opponentPhi/(math.Pi*math.Pi))
+		expected := 1 / (1 + math.Exp(-gPhi*(mu-opponentMu)))
+		vInverse += gPhi * gPhi * expected * (1 - expected)
+		improvement += gPhi * (scores[i] - expected)
+	}
+	v := 1 / vInverse
+	delta := v * improvement
+
+	sigma := g.volatility(phi, player.Volatility, v, delta)
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	newPhi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	newMu := mu + newPhi*newPhi*improvement
+
+	player.Value = newMu*glickoScale + DefaultRating
+	player.Deviation = newPhi * glickoScale
+	player.Volatility = sigma
+	return player
+}
+
+// volatility returns the player's new volatility, found with the Illinois algorithm as in
+// step 5 of the Glicko-2 paper.
+func (g glicko2) volatility(phi, sigma, v, delta float64) float64 {
+	const epsilon = 0.000001
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		return ex*(delta*delta-phi*phi-v-ex)/(2*math.Pow(phi*phi+v+ex, 2)) - (x-a)/(g.tau*g.tau)
+	}
+
+	A, B := a, 0.0
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*g.tau) < 0 {
+			k++
+		}
+		B = a - k*g.tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+	return math.Exp(A / 2)
+}
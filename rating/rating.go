@@ -0,0 +1,175 @@
+// Package rating keeps players' skill ratings from the results of their games, with either
+// Elo or Glicko-2, e.g. for matchmaking and leaderboards.
+package rating
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/devblac/chinchon/chinchon"
+)
+
+// Defaults for new players
+const (
+	DefaultRating     = 1500
+	DefaultDeviation  = 350
+	DefaultVolatility = 0.06
+)
+
+// Rating is a player's skill rating. Deviation and Volatility are only used by Glicko-2,
+// where Deviation measures how uncertain the rating is (e.g. it's high for new players).
+type Rating struct {
+	Value      float64 `json:"value"`
+	Deviation  float64 `json:"deviation"`
+	Volatility float64 `json:"volatility"`
+	Games      int     `json:"games"`
+}
+
+// newRating returns the rating of a player who hasn't played yet.
+func newRating() Rating {
+	return Rating{Value: DefaultRating, Deviation: DefaultDeviation, Volatility: DefaultVolatility}
+}
+
+// Result is the result of a game between two players, identified by whatever the caller uses
+// to tell players apart across games (e.g. their names).
+type Result struct {
+	WinnerID string `json:"winnerID"`
+	LoserID  string `json:"loserID"`
+
+	// Chinchon is true if the winner won with a chinchón.
+	Chinchon bool `json:"chinchon,omitempty"`
+
+	// Margin is how many points the winner won by (the loser's score minus theirs).
+	Margin int `json:"margin"`
+}
+
+// ResultsFromGame returns the results of an ended game, given the IDs of its players (a map
+// from PlayerID to their ID). Every player of the winning team beats every other player.
+// It returns no results for games that haven't ended.
+func ResultsFromGame(gs *chinchon.GameState, playerIDs map[int]string) []Result {
+	results := []Result{}
+	if !gs.IsGameEnded {
+		return results
+	}
+	lastRound := gs.RoundsLog[gs.RoundNumber]
+	wonWithChinchon := lastRound.WasChinchon && lastRound.WinnerPlayerID >= 0 &&
+		gs.Players[lastRound.WinnerPlayerID].Team == gs.WinnerTeamID
+	for winner := 0; winner < len(gs.Players); winner++ {
+		if gs.Players[winner].Team != gs.WinnerTeamID {
+			continue
+		}
+		for loser := 0; loser < len(gs.Players); loser++ {
+			if gs.Players[loser].Team == gs.WinnerTeamID {
+				continue
+			}
+			results = append(results, Result{
+				WinnerID: playerIDs[winner],
+				LoserID:  playerIDs[loser],
+				Chinchon: wonWithChinchon,
+				Margin:   gs.Players[loser].Score - gs.Players[winner].Score,
+			})
+		}
+	}
+	return results
+}
+
+// system updates the winner's and loser's ratings after a game.
+type system interface {
+	update(winner, loser Rating, result Result) (Rating, Rating)
+}
+
+// Ratings keeps every player's rating. It's safe for concurrent use.
+type Ratings struct {
+	mu      sync.Mutex
+	system  system
+	players map[string]Rating
+}
+
+// Option configures Ratings.
+type Option func(*Ratings)
+
+// New returns ratings where every player starts at DefaultRating. They're updated with Elo,
+// unless WithGlicko2 is given.
+func New(opts ...Option) *Ratings {
+	r := &Ratings{system: elo{kFactor: DefaultKFactor}, players: map[string]Rating{}}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// WithRatings starts from the given ratings (a map from player ID to their rating), e.g. as
+// previously returned by All.
+func WithRatings(ratings map[string]Rating) Option {
+	return func(r *Ratings) {
+		for id, rating := range ratings {
+			r.players[id] = rating
+		}
+	}
+}
+
+// Record updates the ratings of the result's players.
+func (r *Ratings) Record(result Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	winner, loser := r.get(result.WinnerID), r.get(result.LoserID)
+	winner, loser = r.system.update(winner, loser, result)
+	winner.Games++
+	loser.Games++
+	r.players[result.WinnerID], r.players[result.LoserID] = winner, loser
+}
+
+// Get returns the player's rating, or the rating of a new player if they haven't played.
+func (r *Ratings) Get(playerID string) Rating {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.get(playerID)
+}
+
+func (r *Ratings) get(playerID string) Rating {
+	if rating, ok := r.players[playerID]; ok {
+		return rating
+	}
+	return newRating()
+}
+
+// Set sets the player's rating, e.g. to restore one saved from All.
+func (r *Ratings) Set(playerID string, rating Rating) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.players[playerID] = rating
+}
+
+// All returns every rated player's rating, e.g. to save them.
+func (r *Ratings) All() map[string]Rating {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ratings := map[string]Rating{}
+	for id, rating := range r.players {
+		ratings[id] = rating
+	}
+	return ratings
+}
+
+// Entry is a player's position in the leaderboard.
+type Entry struct {
+	PlayerID string `json:"playerID"`
+	Rating
+}
+
+// Leaderboard returns the rated players, from the highest rating down.
+func (r *Ratings) Leaderboard() []Entry {
+	entries := []Entry{}
+	for id, rating := range r.All() {
+		entries = append(entries, Entry{PlayerID: id, Rating: rating})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Value != entries[j].Value {
+			return entries[i].Value > entries[j].Value
+		}
+		return entries[i].PlayerID < entries[j].PlayerID
+	})
+	return entries
+}
@@ -0,0 +1,93 @@
+package rating
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/devblac/chinchon/chinchon"
+)
+
+func TestGlicko2(t *testing.T) {
+	// The worked example of the Glicko-2 paper (http://www.glicko.net/glicko/glicko2.pdf)
+	player := Rating{Value: 1500, Deviation: 200, Volatility: 0.06}
+	opponents := []Rating{
+		{Value: 1400, Deviation: 30},
+		{Value: 1550, Deviation: 100},
+		{Value: 1700, Deviation: 300},
+	}
+	got := glicko2{tau: 0.5}.period(player, opponents, []float64{1, 0, 0})
+
+	for _, tt := range []struct {
+		name                 string
+		got, want, tolerance float64
+	}{
+		{"rating", got.Value, 1464.06, 0.01},
+		{"deviation", got.Deviation, 151.52, 0.01},
+		{"volatility", got.Volatility, 0.05999, 0.00001},
+	} {
+		if math.Abs(tt.got-tt.want) > tt.tolerance {
+			t.Errorf("Expected the %v to be %v, got %v", tt.name, tt.want, tt.got)
+		}
+	}
+
+	ratings := New(WithGlicko2(DefaultTau))
+	ratings.Record(Result{WinnerID: "a", LoserID: "b"})
+	winner, loser := ratings.Get("a"), ratings.Get("b")
+	if winner.Value <= DefaultRating || loser.Value >= DefaultRating || winner.Deviation >= DefaultDeviation || winner.Games != 1 {
+		t.Errorf("Expected the winner to gain what the loser lost and both to be more certain, got %+v and %+v", winner, loser)
+	}
+}
+
+func TestElo(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		result        Result
+		winner, loser float64
+	}{
+		{"even game", Result{Margin: 0}, 1516, 1484},
+		{"wide margin", Result{Margin: 200}, 1524, 1476},
+		{"wide margin and chinchón", Result{Margin: 100, Chinchon: true}, 1532, 1468},
+	} {
+		ratings := New()
+		tt.result.WinnerID, tt.result.LoserID = "a", "b"
+		ratings.Record(tt.result)
+		if winner, loser := ratings.Get("a").Value, ratings.Get("b").Value; winner != tt.winner || loser != tt.loser {
+			t.Errorf("%v: expected ratings %v and %v, got %v and %v", tt.name, tt.winner, tt.loser, winner, loser)
+		}
+	}
+
+	ratings := New(WithRatings(map[string]Rating{"a": {Value: 1900}, "b": {Value: 1500}}))
+	ratings.Record(Result{WinnerID: "a", LoserID: "b"})
+	if change := ratings.Get("a").Value - 1900; change <= 0 || change >= 4 {
+		t.Errorf("Expected the favorite to gain little, got %v", change)
+	}
+}
+
+func TestResultsFromGame(t *testing.T) {
+	playerIDs := map[int]string{0: "a", 1: "b", 2: "c", 3: "d"}
+
+	gs := chinchon.New(chinchon.WithTeams())
+	gs.Players[0].Score, gs.Players[1].Score, gs.Players[2].Score, gs.Players[3].Score = 40, 10, 30, 20
+	if err := gs.RunAction(chinchon.NewActionResign(2)); err != nil {
+		t.Fatal(err)
+	}
+	want := []Result{
+		{WinnerID: "b", LoserID: "a", Margin: 30},
+		{WinnerID: "b", LoserID: "c", Margin: 20},
+		{WinnerID: "d", LoserID: "a", Margin: 20},
+		{WinnerID: "d", LoserID: "c", Margin: 10},
+	}
+	if got := ResultsFromGame(gs, playerIDs); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected every winner to beat every loser, got %+v", got)
+	}
+
+	gs = chinchon.New()
+	if got := ResultsFromGame(gs, playerIDs); len(got) != 0 {
+		t.Errorf("Expected no results before the game ends, got %+v", got)
+	}
+	gs.Abort()
+	if got := ResultsFromGame(gs, playerIDs); len(got) != 0 {
+		t.Errorf("Expected no results for an aborted game, got %+v", got)
+	}
+}
@@ -0,0 +1,227 @@
+//go:build !tinygo
+// +build !tinygo
+
+// Package redisstore keeps games in Redis, so that several server instances (e.g. behind a
+// load balancer) can serve the same games: every instance appends the actions it runs to
+// the game's log in Redis, and is notified of the actions run by the others over pub/sub.
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/devblac/chinchon/server"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultPrefix is the prefix of every key the store uses.
+const DefaultPrefix = "chinchon:"
+
+// claimTTL is how long a game stays claimed by a cluster node (see ClaimGame), from when
+// it was first claimed.
+const claimTTL = 7 * 24 * time.Hour
+
+// Store is a server.SharedGameStore keeping each game in Redis as a hash with its last
+// snapshot, a sorted set with the actions run since (scored by seq), the seq of the last
+// action, and a channel on which actions are published as they're appended.
+type Store struct {
+	client *redis.Client
+	prefix string
+}
+
+// Option configures the store.
+type Option func(*Store)
+
+// WithPrefix sets the prefix of the store's keys, e.g. to share a Redis database.
+func WithPrefix(prefix string) Option {
+	return func(s *Store) {
+		s.prefix = prefix
+	}
+}
+
+// New creates a store on the client's Redis database.
+func New(client *redis.Client, opts ...Option) *Store {
+	s := &Store{client: client, prefix: DefaultPrefix}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+var (
+	_ server.SharedGameStore = (*Store)(nil)
+	_ server.GameRegistry    = (*Store)(nil)
+)
+
+func (s *Store) snapshotKey(gameID string) string { return s.prefix + "game:" + gameID + ":snapshot" }
+func (s *Store) actionsKey(gameID string) string  { return s.prefix + "game:" + gameID + ":actions" }
+func (s *Store) seqKey(gameID string) string      { return s.prefix + "game:" + gameID + ":seq" }
+func (s *Store) channel(gameID string) string     { return s.prefix + "game:" + gameID + ":updates" }
+func (s *Store) gamesKey() string                 { return s.prefix + "games" }
+func (s *Store) nodeKey(gameID string) string     { return s.prefix + "game:" + gameID + ":node" }
+
+// saveSnapshot keeps the snapshot unless there's one as recent, drops the actions it
+// includes, and indexes the game by the time it was saved.
+var saveSnapshot = redis.NewScript(`
+local current = tonumber(redis.call('HGET', KEYS[1], 'seq') or '-1')
+local seq = tonumber(ARGV[1])
+if seq <= current then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'seq', ARGV[1], 'snapshot', ARGV[2], 'ended', ARGV[3], 'updatedAt', ARGV[4])
+redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', ARGV[1])
+if seq > tonumber(redis.call('GET', KEYS[3]) or '0') then
+	redis.call('SET', KEYS[3], ARGV[1])
+end
+redis.call('ZADD', KEYS[4], ARGV[4], ARGV[5])
+return 1
+`)
+
+// appendAction appends the action if it's the game's next one, and publishes it.
+var appendAction = redis.NewScript(`
+local seq = tonumber(ARGV[1])
+if seq ~= tonumber(redis.call('GET', KEYS[1]) or '0') + 1 then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[1])
+redis.call('ZADD', KEYS[2], ARGV[1], ARGV[2])
+redis.call('PUBLISH', KEYS[3], ARGV[2])
+return 1
+`)
+
+func (s *Store) SaveSnapshot(gameID string, seq int, snapshot []byte) error {
+	keys := []string{s.snapshotKey(gameID), s.actionsKey(gameID), s.seqKey(gameID), s.gamesKey()}
+	ended := "0"
+	if server.SnapshotEnded(snapshot) {
+		ended = "1"
+	}
+	return saveSnapshot.Run(context.Background(), s.client, keys,
+		seq, string(snapshot), ended, time.Now().UnixMilli(), gameID).Err()
+}
+
+func (s *Store) AppendAction(gameID string, entry server.JournalEntry) error {
+	bs, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	keys := []string{s.seqKey(gameID), s.actionsKey(gameID), s.channel(gameID)}
+	appended, err := appendAction.Run(context.Background(), s.client, keys, entry.Seq, string(bs)).Int()
+	if err != nil {
+		return err
+	}
+	if appended == 0 {
+		return server.ErrSeqConflict
+	}
+	return nil
+}
+
+func (s *Store) LoadGame(gameID string) ([]byte, int, []server.JournalEntry, error) {
+	ctx := context.Background()
+	fields, err := s.client.HMGet(ctx, s.snapshotKey(gameID), "seq", "snapshot").Result()
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	seqField, snapshot := fields[0], fields[1]
+	if seqField == nil || snapshot == nil {
+		return nil, 0, nil, server.ErrGameNotFound
+	}
+	seq, err := strconv.Atoi(seqField.(string))
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	members, err := s.client.ZRangeByScore(ctx, s.actionsKey(gameID), &redis.ZRangeBy{
+		Min: "(" + strconv.Itoa(seq),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	entries := []server.JournalEntry{}
+	for _, member := range members {
+		var entry server.JournalEntry
+		if err := json.Unmarshal([]byte(member), &entry); err != nil {
+			return nil, 0, nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return []byte(snapshot.(string)), seq, entries, nil
+}
+
+func (s *Store) ListGames() ([]server.GameInfo, error) {
+	ctx := context.Background()
+	ids, err := s.client.ZRevRangeWithScores(ctx, s.gamesKey(), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	games := []server.GameInfo{}
+	for _, id := range ids {
+		gameID := id.Member.(string)
+		fields, err := s.client.HMGet(ctx, s.snapshotKey(gameID), "seq", "ended").Result()
+		if err != nil {
+			return nil, err
+		}
+		if fields[0] == nil {
+			continue // Deleted since
+		}
+		seq, _ := strconv.Atoi(fields[0].(string))
+		games = append(games, server.GameInfo{
+			ID:        gameID,
+			Seq:       seq,
+			Ended:     fields[1] == "1",
+			UpdatedAt: time.UnixMilli(int64(id.Score)),
+		})
+	}
+	return games, nil
+}
+
+// Subscribe calls onAction with every action appended to the game by any server instance,
+// including this one.
+func (s *Store) Subscribe(gameID string, onAction func(server.JournalEntry)) (func(), error) {
+	ctx := context.Background()
+	pubsub := s.client.Subscribe(ctx, s.channel(gameID))
+	// Actions appended from now on are guaranteed to be received.
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	go func() {
+		for message := range pubsub.Channel() {
+			var entry server.JournalEntry
+			if err := json.Unmarshal([]byte(message.Payload), &entry); err != nil {
+				slog.Warn("ignoring corrupt action from Redis", "gameID", gameID, "err", err)
+				continue
+			}
+			onAction(entry)
+		}
+	}()
+	return func() {
+		if err := pubsub.Close(); err != nil && !errors.Is(err, redis.ErrClosed) {
+			slog.Warn("failed to unsubscribe from Redis", "gameID", gameID, "err", err)
+		}
+	}, nil
+}
+
+// ClaimGame returns the cluster node hosting the game, making it node if none has claimed
+// it, for server.WithGameRegistry. Claims last a week.
+func (s *Store) ClaimGame(gameID, node string) (string, error) {
+	ctx := context.Background()
+	claimed, err := s.client.SetNX(ctx, s.nodeKey(gameID), node, claimTTL).Result()
+	if err != nil {
+		return "", err
+	}
+	if claimed {
+		return node, nil
+	}
+	owner, err := s.client.Get(ctx, s.nodeKey(gameID)).Result()
+	if errors.Is(err, redis.Nil) {
+		// The claim just expired.
+		return s.ClaimGame(gameID, node)
+	}
+	return owner, err
+}
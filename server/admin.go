@@ -0,0 +1,274 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devblac/chinchon/chinchon"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+// kickTimeout is how long kicking a player waits for their queued messages to be sent.
+const kickTimeout = time.Second
+
+// WithAdminToken enables the /admin endpoints, for operators to look into games being
+// played, finish stuck ones, kick and ban players, broadcast announcements, run
+// tournaments, and start exhibition games between bots. Requests must carry the token, as
+// in "Authorization: Bearer token".
+func WithAdminToken(token string) Option {
+	return func(s *server) {
+		s.adminToken = token
+	}
+}
+
+// AdminGame is a game being played, as operators see it: every hand included.
+type AdminGame struct {
+	Game  APIGame             `json:"game"`
+	State *chinchon.GameState `json:"state"`
+
+	// RemoteAddrs are the addresses of the connected players, by player ID.
+	RemoteAddrs map[int]string `json:"remoteAddrs"`
+}
+
+// AdminAnnouncement is the body of POST /admin/announcements.
+type AdminAnnouncement struct {
+	Text string `json:"text"`
+}
+
+// AdminBan is the body of POST /admin/bans.
+type AdminBan struct {
+	IP string `json:"ip"`
+}
+
+// bans are the IP addresses banned from connecting to the server. Behind a proxy, they're
+// the proxy's, so banning isn't useful there.
+type bans struct {
+	mu  sync.Mutex
+	ips map[string]bool
+}
+
+func newBans() *bans {
+	return &bans{ips: map[string]bool{}}
+}
+
+func (b *bans) add(ip string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.ips[ip] = true
+}
+
+func (b *bans) remove(ip string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	banned := b.ips[ip]
+	delete(b.ips, ip)
+	return banned
+}
+
+func (b *bans) list() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ips := []string{}
+	for ip := range b.ips {
+		ips = append(ips, ip)
+	}
+	sort.Strings(ips)
+	return ips
+}
+
+// banned returns whether the IP of the address (host:port, or just the host) is banned.
+func (b *bans) banned(addr string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.ips[hostOf(addr)]
+}
+
+func hostOf(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// rejectBanned responds 403 to requests from banned IP addresses, other than to the /admin
+// endpoints, which operators may share an address with players to use.
+func (s *server) rejectBanned(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.bans.banned(r.RemoteAddr) && !strings.HasPrefix(r.URL.Path, "/admin/") {
+			http.Error(w, "banned", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireAdmin responds 401 to requests without the admin token.
+func (s *server) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// routeAdmin registers the /admin endpoints, if enabled.
+func (s *server) routeAdmin(router *mux.Router) {
+	if s.adminToken == "" {
+		return
+	}
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(s.requireAdmin)
+	admin.HandleFunc("/games", s.handleAdminGames).Methods(http.MethodGet)
+	admin.HandleFunc("/games/{id}", s.handleAdminGame).Methods(http.MethodGet)
+	admin.HandleFunc("/games/{id}/finish", s.handleAdminFinishGame).Methods(http.MethodPost)
+	admin.HandleFunc("/games/{id}/players/{playerID}/kick", s.handleAdminKick).Methods(http.MethodPost)
+	admin.HandleFunc("/bans", s.handleAdminBans).Methods(http.MethodGet)
+	admin.HandleFunc("/bans", s.handleAdminBan).Methods(http.MethodPost)
+	admin.HandleFunc("/bans/{ip}", s.handleAdminUnban).Methods(http.MethodDelete)
+	admin.HandleFunc("/announcements", s.handleAdminAnnouncement).Methods(http.MethodPost)
+	admin.HandleFunc("/tournaments", s.handleAdminCreateTournament).Methods(http.MethodPost)
+	admin.HandleFunc("/tournaments/{id}/players", s.handleAdminTournamentRegister).Methods(http.MethodPost)
+	admin.HandleFunc("/tournaments/{id}/start", s.handleAdminStartTournament).Methods(http.MethodPost)
+	admin.HandleFunc("/exhibitions", s.handleAdminCreateExhibition).Methods(http.MethodPost)
+}
+
+// handleAdminGames lists the games being played.
+func (s *server) handleAdminGames(w http.ResponseWriter, r *http.Request) {
+	games := []APIGame{}
+	for _, room := range s.liveRooms() {
+		room.mu.Lock()
+		games = append(games, room.apiGame())
+		room.mu.Unlock()
+	}
+	writeJSON(w, games)
+}
+
+func (s *server) handleAdminGame(w http.ResponseWriter, r *http.Request) {
+	room, ok := s.existingRoom(mux.Vars(r)["id"])
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	room.mu.Lock()
+	defer room.mu.Unlock()
+	remoteAddrs := map[int]string{}
+	for playerID, out := range room.players {
+		if out != nil {
+			remoteAddrs[playerID] = out.conn.RemoteAddr().String()
+		}
+	}
+	writeJSON(w, AdminGame{Game: room.apiGame(), State: room.gameState, RemoteAddrs: remoteAddrs})
+}
+
+// handleAdminFinishGame ends a game being played without a winner (see
+// chinchon.GameState.Abort), e.g. if it's stuck.
+func (s *server) handleAdminFinishGame(w http.ResponseWriter, r *http.Request) {
+	room, ok := s.existingRoom(mux.Vars(r)["id"])
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	room.mu.Lock()
+	defer room.mu.Unlock()
+	if room.gameState.IsGameEnded {
+		http.Error(w, "game already ended", http.StatusConflict)
+		return
+	}
+	room.gameState.Abort()
+	room.gameStateChanged()
+	room.logger.Info("game finished by an operator")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAdminKick closes a player's connection, freeing their seat (unless held for them,
+// see WithReconnectGrace). With ban=true, their IP address is also banned.
+func (s *server) handleAdminKick(w http.ResponseWriter, r *http.Request) {
+	room, ok := s.existingRoom(mux.Vars(r)["id"])
+	playerID, err := strconv.Atoi(mux.Vars(r)["playerID"])
+	if !ok || err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	room.mu.Lock()
+	var out *outbox
+	if playerID >= 0 && playerID < len(room.players) {
+		out = room.players[playerID]
+	}
+	room.mu.Unlock()
+	if out == nil {
+		http.Error(w, "player isn't connected", http.StatusNotFound)
+		return
+	}
+
+	remoteAddr := out.conn.RemoteAddr().String()
+	ban := r.URL.Query().Get("ban") == "true"
+	if ban {
+		s.bans.add(hostOf(remoteAddr))
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), kickTimeout)
+	defer cancel()
+	out.Shutdown(ctx, websocket.ClosePolicyViolation, "kicked")
+	room.logger.Info("player kicked by an operator", "playerID", playerID, "remoteAddr", remoteAddr, "banned", ban)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) handleAdminBans(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.bans.list())
+}
+
+func (s *server) handleAdminBan(w http.ResponseWriter, r *http.Request) {
+	var ban AdminBan
+	if err := json.NewDecoder(r.Body).Decode(&ban); err != nil || net.ParseIP(ban.IP) == nil {
+		http.Error(w, "invalid ban, expected an ip", http.StatusBadRequest)
+		return
+	}
+	s.bans.add(ban.IP)
+	s.logger.Info("IP address banned by an operator", "ip", ban.IP)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) handleAdminUnban(w http.ResponseWriter, r *http.Request) {
+	ip := mux.Vars(r)["ip"]
+	if !s.bans.remove(ip) {
+		http.NotFound(w, r)
+		return
+	}
+	s.logger.Info("IP address unbanned by an operator", "ip", ip)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAdminAnnouncement sends a message to every player and spectator of the games being
+// played.
+func (s *server) handleAdminAnnouncement(w http.ResponseWriter, r *http.Request) {
+	var announcement AdminAnnouncement
+	if err := json.NewDecoder(r.Body).Decode(&announcement); err != nil || announcement.Text == "" {
+		http.Error(w, "invalid announcement, expected a text", http.StatusBadRequest)
+		return
+	}
+	msg := NewMessageAnnouncement(announcement.Text)
+	for _, room := range s.liveRooms() {
+		room.mu.Lock()
+		room.broadcast(msg)
+		for sp := range room.spectators {
+			sp.out.Send(msg)
+		}
+		room.mu.Unlock()
+	}
+	s.logger.Info("announcement sent by an operator", "text", announcement.Text)
+	w.WriteHeader(http.StatusNoContent)
+}
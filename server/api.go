@@ -0,0 +1,325 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/devblac/chinchon/chinchon"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The /api endpoints let dashboards and other tools query games over plain HTTP, without
+// opening a WebSocket. They only return what spectators may see: hands are only shown once
+// their round is finished.
+
+// APIGame is a game, live or stored.
+type APIGame struct {
+	ID string `json:"id"`
+
+	// Live is true if the game is being played on this server.
+	Live        bool        `json:"live"`
+	Ended       bool        `json:"ended"`
+	RoundNumber int         `json:"roundNumber,omitempty"`
+	Players     []APIPlayer `json:"players,omitempty"`
+
+	// Rules are the rules the game is played with, for games being played or loaded from
+	// the game store.
+	Rules *chinchon.Rules `json:"rules,omitempty"`
+
+	// UpdatedAt is when the game was last played: when a live one last changed, or a
+	// stored one was last saved.
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+
+	// State is the game as spectators see it, only for a single game.
+	State *chinchon.SpectatorGameState `json:"state,omitempty"`
+}
+
+// APIPlayer is a seat in a game.
+type APIPlayer struct {
+	PlayerID int `json:"playerID"`
+
+	// Name is the player's name, if they were matched in the lobby or gave a display name,
+	// and Avatar their avatar emoji, if any.
+	Name      string `json:"name,omitempty"`
+	Avatar    string `json:"avatar,omitempty"`
+	Team      int    `json:"team"`
+	Score     int    `json:"score"`
+	Connected bool   `json:"connected"`
+
+	// Bot is true if the seat is played by a hosted bot (see WithHostedBots).
+	Bot bool `json:"bot,omitempty"`
+
+	// RTTMillis is the round-trip time of the player's connection, in milliseconds, once
+	// measured.
+	RTTMillis int64 `json:"rttMillis,omitempty"`
+}
+
+// APIRound is a finished round of a game.
+type APIRound struct {
+	RoundNumber      int                       `json:"roundNumber"`
+	WinnerPlayerID   int                       `json:"winnerPlayerID"`
+	LoserPlayerID    int                       `json:"loserPlayerID"`
+	ClosedByPlayerID int                       `json:"closedByPlayerID"`
+	WasChinchon      bool                      `json:"wasChinchon"`
+	WasResigned      bool                      `json:"wasResigned"`
+	PenaltyPoints    map[int]int               `json:"penaltyPoints"`
+	ScoreChanges     map[int]int               `json:"scoreChanges"`
+	HandsDealt       map[int][]chinchon.Card   `json:"handsDealt"`
+	Groups           map[int][][]chinchon.Card `json:"groups,omitempty"`
+	UngroupedCards   map[int][]chinchon.Card   `json:"ungroupedCards,omitempty"`
+	Actions          int                       `json:"actions"`
+}
+
+// APIPlayerGame is a game a named player played, or is playing.
+type APIPlayerGame struct {
+	GameID   string `json:"gameID"`
+	PlayerID int    `json:"playerID"`
+	Ended    bool   `json:"ended"`
+	Won      bool   `json:"won"`
+	Score    int    `json:"score"`
+
+	// Opponents are the names of the other players, by player ID.
+	Opponents map[int]string `json:"opponents"`
+
+	EndedAt *time.Time `json:"endedAt,omitempty"`
+}
+
+// playerHistory keeps the games named players finished on this server, newest first. It
+// isn't persisted.
+type playerHistory struct {
+	mu    sync.Mutex
+	games map[string][]APIPlayerGame
+}
+
+func newPlayerHistory() *playerHistory {
+	return &playerHistory{games: map[string][]APIPlayerGame{}}
+}
+
+// record adds the room's ended game to the history of its named players. Must be called
+// with the room locked.
+func (h *playerHistory) record(r *room) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	endedAt := time.Now()
+	for playerID, name := range r.playerNames {
+		game := r.playerGame(playerID)
+		game.EndedAt = &endedAt
+		h.games[name] = append([]APIPlayerGame{game}, h.games[name]...)
+	}
+}
+
+func (h *playerHistory) of(name string) []APIPlayerGame {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]APIPlayerGame{}, h.games[name]...)
+}
+
+// playerGame returns the game as the player sees it. Must be called with the room locked.
+func (r *room) playerGame(playerID int) APIPlayerGame {
+	gs := r.gameState
+	opponents := map[int]string{}
+	for id, name := range r.playerNames {
+		if id != playerID {
+			opponents[id] = name
+		}
+	}
+	return APIPlayerGame{
+		GameID:    r.id,
+		PlayerID:  playerID,
+		Ended:     gs.IsGameEnded,
+		Won:       gs.IsGameEnded && gs.Players[playerID].Team == gs.WinnerTeamID,
+		Score:     gs.Players[playerID].Score,
+		Opponents: opponents,
+	}
+}
+
+// apiGame summarizes the room's game. Must be called with the room locked.
+func (r *room) apiGame() APIGame {
+	game := apiGameFrom(r.id, r.gameState)
+	game.Live = true
+	updatedAt := r.updatedAt
+	game.UpdatedAt = &updatedAt
+	for i := range game.Players {
+		if name := r.playerNames[i]; name != "" {
+			game.Players[i].Name = name
+		}
+		game.Players[i].Connected = r.players[i] != nil
+		game.Players[i].Bot = r.bots[i] != nil
+		game.Players[i].RTTMillis = r.rtts[i].Milliseconds()
+	}
+	return game
+}
+
+func apiGameFrom(gameID string, gs *chinchon.GameState) APIGame {
+	rules := gs.Rules()
+	game := APIGame{ID: gameID, Ended: gs.IsGameEnded, RoundNumber: gs.RoundNumber, Players: []APIPlayer{}, Rules: &rules}
+	for playerID, player := range gs.Players {
+		game.Players = append(game.Players, APIPlayer{PlayerID: playerID, Name: player.Name, Avatar: player.Avatar, Team: player.Team, Score: player.Score})
+	}
+	return game
+}
+
+// apiRounds returns the game's finished rounds. A round being laid off isn't finished yet:
+// its hands are still hidden.
+func apiRounds(gs *chinchon.GameState) []APIRound {
+	rounds := []APIRound{}
+	for roundNumber := 1; roundNumber < len(gs.RoundsLog); roundNumber++ {
+		if roundNumber == gs.RoundNumber && (!gs.IsRoundFinished || gs.IsLayingOff) && !gs.IsGameEnded {
+			break
+		}
+		roundLog := gs.RoundsLog[roundNumber]
+		handsDealt := map[int][]chinchon.Card{}
+		for playerID, hand := range roundLog.HandsDealt {
+			handsDealt[playerID] = hand.Cards
+		}
+		rounds = append(rounds, APIRound{
+			RoundNumber:      roundNumber,
+			WinnerPlayerID:   roundLog.WinnerPlayerID,
+			LoserPlayerID:    roundLog.LoserPlayerID,
+			ClosedByPlayerID: roundLog.ClosedByPlayerID,
+			WasChinchon:      roundLog.WasChinchon,
+			WasResigned:      roundLog.WasResigned,
+			PenaltyPoints:    roundLog.PenaltyPoints,
+			ScoreChanges:     roundLog.ScoreChanges,
+			HandsDealt:       handsDealt,
+			Groups:           roundLog.Groups,
+			UngroupedCards:   roundLog.UngroupedCards,
+			Actions:          len(roundLog.ActionsLog),
+		})
+	}
+	return rounds
+}
+
+// withGame calls fn with the game with the ID in the URL, live or stored, responding 404 if
+// there's none. Live games are locked while fn runs.
+func (s *server) withGame(w http.ResponseWriter, r *http.Request, fn func(game APIGame, gs *chinchon.GameState)) {
+	gameID := mux.Vars(r)["id"]
+	if room, ok := s.existingRoom(gameID); ok {
+		room.mu.Lock()
+		defer room.mu.Unlock()
+		fn(room.apiGame(), room.gameState)
+		return
+	}
+	gs, err := s.storedGame(gameID)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	fn(apiGameFrom(gameID, gs), gs)
+}
+
+// handleAPIGames lists the live games and the stored ones, most recently played first, as
+// filtered and paginated by the query parameters (see gameFilter). The Link header has the
+// URL of the next page, if any.
+func (s *server) handleAPIGames(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseGameFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	games, err := s.filterGames(r.Context(), filter)
+	if err != nil {
+		s.logger.Error("failed to list stored games", "err", err)
+		http.Error(w, "failed to list games", http.StatusInternalServerError)
+		return
+	}
+	games, next := filter.page(games)
+	if next != "" {
+		query := r.URL.Query()
+		query.Set("cursor", next)
+		w.Header().Set("Link", fmt.Sprintf("<%v?%v>; rel=\"next\"", r.URL.Path, query.Encode()))
+	}
+	writeJSON(w, games)
+}
+
+// filterGames returns the live games and the stored ones matching the filter, unsorted, and
+// no more than a page of the stored ones.
+func (s *server) filterGames(ctx context.Context, filter gameFilter) ([]APIGame, error) {
+	rooms := s.liveRooms()
+	games := []APIGame{}
+	live := map[string]bool{}
+	for _, room := range rooms {
+		room.mu.Lock()
+		if game := room.apiGame(); filter.matches(game) {
+			games = append(games, game)
+		}
+		room.mu.Unlock()
+		live[room.id] = true
+	}
+
+	if s.store != nil {
+		_, span := s.tracer.Start(ctx, "store.ListGames", trace.WithSpanKind(trace.SpanKindClient))
+		stored, err := s.store.ListGames()
+		endSpan(span, err)
+		if err != nil {
+			return nil, err
+		}
+		// Stored games are listed most recently played first, so once a page of them
+		// matches, the rest can't be in it.
+		matched := 0
+		for _, info := range stored {
+			if matched > filter.limit {
+				break
+			}
+			updatedAt := info.UpdatedAt
+			game := APIGame{ID: info.ID, Ended: info.Ended, UpdatedAt: &updatedAt}
+			if live[info.ID] || !filter.matchesListed(game) {
+				continue
+			}
+			if filter.needsGame() {
+				gs, err := s.storedGame(info.ID)
+				if err != nil {
+					s.logger.Warn("failed to load stored game", "gameID", info.ID, "err", err)
+					continue
+				}
+				game = apiGameFrom(info.ID, gs)
+				game.UpdatedAt = &updatedAt
+			}
+			if filter.matches(game) {
+				games = append(games, game)
+				matched++
+			}
+		}
+	}
+	return games, nil
+}
+
+func (s *server) handleAPIGame(w http.ResponseWriter, r *http.Request) {
+	s.withGame(w, r, func(game APIGame, gs *chinchon.GameState) {
+		state := gs.ToSpectatorGameState(false)
+		game.State = &state
+		writeJSON(w, game)
+	})
+}
+
+func (s *server) handleAPIGameRounds(w http.ResponseWriter, r *http.Request) {
+	s.withGame(w, r, func(_ APIGame, gs *chinchon.GameState) {
+		writeJSON(w, apiRounds(gs))
+	})
+}
+
+// handleAPIPlayerHistory lists the games of the player with the name in the URL: those
+// they're playing, then those they finished since the server started.
+func (s *server) handleAPIPlayerHistory(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["id"]
+
+	rooms := s.liveRooms()
+	games := []APIPlayerGame{}
+	for _, room := range rooms {
+		room.mu.Lock()
+		for playerID, playerName := range room.playerNames {
+			if playerName == name && !room.gameState.IsGameEnded {
+				games = append(games, room.playerGame(playerID))
+			}
+		}
+		room.mu.Unlock()
+	}
+	writeJSON(w, append(games, s.history.of(name)...))
+}
@@ -0,0 +1,39 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"testing"
+
+	"github.com/devblac/chinchon/chinchon"
+)
+
+func TestAPIRoundsLayOff(t *testing.T) {
+	hand := func(suits []string, numbers ...int) []chinchon.Card {
+		cards := []chinchon.Card{}
+		for i, number := range numbers {
+			cards = append(cards, chinchon.Card{Suit: suits[i], Number: number})
+		}
+		return cards
+	}
+	gs, err := chinchon.NewFromScenario(chinchon.Scenario{
+		Hands: map[int][]chinchon.Card{
+			0: hand([]string{chinchon.COPA, chinchon.ORO, chinchon.COPA, chinchon.ESPADA, chinchon.BASTO, chinchon.ORO, chinchon.ESPADA}, 4, 12, 11, 10, 12, 9, 6),
+			1: hand([]string{chinchon.COPA, chinchon.COPA, chinchon.COPA, chinchon.ESPADA, chinchon.ESPADA, chinchon.ESPADA, chinchon.BASTO}, 1, 2, 3, 1, 2, 3, 4),
+		},
+	}, chinchon.WithLayOff())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs.CloseRound(1)
+	if !gs.IsLayingOff || len(apiRounds(gs)) != 0 {
+		t.Fatal("Expected the round being laid off not to be listed, with its hands")
+	}
+	if err := gs.RunAction(chinchon.NewActionFinishLayOff(0)); err != nil {
+		t.Fatal(err)
+	}
+	if rounds := apiRounds(gs); len(rounds) != 1 || len(rounds[0].HandsDealt) != 2 {
+		t.Errorf("Expected the round to be listed with its hands once scored, got %+v", rounds)
+	}
+}
@@ -0,0 +1,154 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	errInvalidToken = errors.New("invalid session token")
+	errSeatClaimed  = errors.New("seat claimed by another player, a session token is required")
+)
+
+// WithReconnectGrace holds a disconnected player's seat for the grace period, so that
+// nobody else takes it while they reconnect: players get a session token (as with
+// WithAuth) when they first join a seat, and only that token may take the seat back until
+// the grace period is over. After that, anyone may take it. With WithAuth, seats are
+// always held.
+func WithReconnectGrace(grace time.Duration) Option {
+	return func(s *server) {
+		s.reconnectGrace = grace
+	}
+}
+
+// WithAuth requires players to prove that a seat is theirs: the first player to join a
+// seat (or to be matched to it in the lobby) gets a session token, signed with the secret,
+// and only hello messages with that token may join the seat after that. Without it,
+// anyone may take any free seat.
+//
+// Tokens are signed rather than stored, so with the same secret and a game store, they
+// remain valid when the server restarts.
+func WithAuth(secret []byte) Option {
+	return func(s *server) {
+		s.auth = &authenticator{secret: secret}
+		s.seatsAlwaysHeld = true
+	}
+}
+
+// sessionClaims are what a session token proves: who the player is in which game.
+type sessionClaims struct {
+	GameID   string `json:"g"`
+	PlayerID int    `json:"p"`
+	Name     string `json:"n,omitempty"`
+
+	// Nonce tells tokens for the same seat apart, so that only the latest one is valid.
+	Nonce string `json:"x"`
+}
+
+// authenticator issues and verifies session tokens.
+type authenticator struct {
+	secret []byte
+}
+
+// newRandomAuthenticator returns an authenticator with a random secret, for tokens that
+// don't need to outlive the server.
+func newRandomAuthenticator() *authenticator {
+	secret := make([]byte, 32)
+	_, _ = rand.Read(secret)
+	return &authenticator{secret: secret}
+}
+
+// issue returns a new token for the seat, with its claims.
+func (a *authenticator) issue(gameID string, playerID int, name string) (string, sessionClaims) {
+	nonce := make([]byte, 8)
+	_, _ = rand.Read(nonce)
+	claims := sessionClaims{GameID: gameID, PlayerID: playerID, Name: name, Nonce: hex.EncodeToString(nonce)}
+
+	payload, _ := json.Marshal(claims)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(a.sign(encoded)), claims
+}
+
+// verify returns the token's claims, if it was issued by this server.
+func (a *authenticator) verify(token string) (sessionClaims, error) {
+	var claims sessionClaims
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return claims, errInvalidToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, a.sign(encoded)) {
+		return claims, errInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || json.Unmarshal(payload, &claims) != nil {
+		return claims, errInvalidToken
+	}
+	return claims, nil
+}
+
+func (a *authenticator) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+// authenticate checks that the player may take the seat, returning a new token if the
+// seat was unclaimed (or "" if the player presented theirs). Must be called with the room
+// locked.
+func (s *server) authenticate(r *room, playerID int, token string) (string, error) {
+	if s.auth == nil {
+		return "", nil
+	}
+	if token == "" {
+		if r.sessions[playerID] != "" && !s.holdExpired(r, playerID) {
+			return "", errSeatClaimed
+		}
+		return s.claimSeat(r, playerID, ""), nil
+	}
+
+	claims, err := s.auth.verify(token)
+	if err != nil {
+		return "", err
+	}
+	// After a restart, sessions are forgotten, so any token signed for the seat is valid.
+	if claims.GameID != r.id || claims.PlayerID != playerID ||
+		(r.sessions[playerID] != "" && r.sessions[playerID] != claims.Nonce) {
+		return "", errInvalidToken
+	}
+	r.sessions[playerID] = claims.Nonce
+	return "", nil
+}
+
+// claimSeat issues a token for the seat, invalidating any previous one. The seat is held
+// for the reconnect grace period, in case the player is matched but doesn't show up. Must
+// be called with the room locked.
+func (s *server) claimSeat(r *room, playerID int, name string) string {
+	token, claims := s.auth.issue(r.id, playerID, name)
+	r.sessions[playerID] = claims.Nonce
+	r.heldUntil[playerID] = time.Now().Add(s.reconnectGrace)
+	return token
+}
+
+// holdSeat holds the seat of a player who just disconnected for the reconnect grace
+// period. Must be called with the room locked.
+func (s *server) holdSeat(r *room, playerID int) {
+	r.heldUntil[playerID] = time.Now().Add(s.reconnectGrace)
+}
+
+// holdExpired returns true if the seat's claim may be taken over by another player, which
+// is only the case with a reconnect grace period (and without WithAuth) once it's over.
+// Must be called with the room locked.
+func (s *server) holdExpired(r *room, playerID int) bool {
+	return !s.seatsAlwaysHeld && time.Now().After(r.heldUntil[playerID])
+}
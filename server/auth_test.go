@@ -0,0 +1,138 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestSessionTokens(t *testing.T) {
+	auth := &authenticator{secret: []byte("secret")}
+	token, claims := auth.issue("casa", 1, "juan")
+	if verified, err := auth.verify(token); err != nil || verified != claims {
+		t.Fatalf("Expected the token to be verified as %+v, got %+v and %v", claims, verified, err)
+	}
+
+	forger := &authenticator{secret: []byte("guessed")}
+	forged, _ := forger.issue("casa", 1, "juan")
+	encoded, signature, _ := strings.Cut(token, ".")
+	otherSeat, _ := auth.issue("casa", 2, "juan")
+	otherEncoded, _, _ := strings.Cut(otherSeat, ".")
+	for name, token := range map[string]string{
+		"other secret":      forged,
+		"swapped payload":   otherEncoded + "." + signature,
+		"missing signature": encoded,
+		"garbage":           "not.a-token",
+	} {
+		if _, err := auth.verify(token); !errors.Is(err, errInvalidToken) {
+			t.Errorf("%s: expected the token to be rejected, got %v", name, err)
+		}
+	}
+}
+
+func TestReconnectGrace(t *testing.T) {
+	s := New("0", WithReconnectGrace(time.Minute))
+	r, err := s.createRoom("casa", s.gameOptions, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.mu.Unlock()
+
+	token, err := s.authenticate(r, 0, "")
+	if err != nil || token == "" {
+		t.Fatalf("Expected the first player to claim the seat, got %q and %v", token, err)
+	}
+	if _, err := s.authenticate(r, 0, ""); !errors.Is(err, errSeatClaimed) {
+		t.Errorf("Expected the seat to be held during the grace period, got %v", err)
+	}
+	if newToken, err := s.authenticate(r, 0, token); err != nil || newToken != "" {
+		t.Errorf("Expected the player to take the seat back with their token, got %q and %v", newToken, err)
+	}
+
+	r.heldUntil[0] = time.Now().Add(-time.Second)
+	newToken, err := s.authenticate(r, 0, "")
+	if err != nil || newToken == "" {
+		t.Fatalf("Expected the seat to be claimed by another player once the hold expired, got %q and %v", newToken, err)
+	}
+	if _, err := s.authenticate(r, 0, token); !errors.Is(err, errInvalidToken) {
+		t.Errorf("Expected the previous player's token to be invalidated, got %v", err)
+	}
+}
+
+func TestSeatAuth(t *testing.T) {
+	s := New("0", WithAuth([]byte("secret")))
+	srv := httptest.NewServer(s.handler())
+	defer srv.Close()
+	defer s.polls.shutdown()
+
+	// join returns the session token the server sent, and whether the player got in.
+	join := func(token string) (string, bool) {
+		t.Helper()
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		if err := WsSend(conn, NewMessageHelloForGame("", 0, token)); err != nil {
+			t.Fatal(err)
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		newToken := ""
+		for {
+			messageType, message, err := WsReadAnyMessage(conn)
+			if err != nil {
+				return newToken, false
+			}
+			switch messageType {
+			case MessageTypeSession:
+				session, err := WsDeserializeMessage[MessageSession, MessageSession](message, MessageTypeSession)
+				if err != nil {
+					t.Fatal(err)
+				}
+				newToken = session.Token
+			case MessageTypeHeresGameState:
+				return newToken, true
+			}
+		}
+	}
+
+	token, ok := join("")
+	if !ok || token == "" {
+		t.Fatalf("Expected the first player to get the seat and a token, got %q", token)
+	}
+	// The connection is closed once joined, so the seat is free but claimed.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		s.roomsMu.Lock()
+		r := s.rooms[DefaultGameID]
+		s.roomsMu.Unlock()
+		r.mu.Lock()
+		left := r.players[0] == nil
+		r.mu.Unlock()
+		if left {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the first player to leave")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	forged, _ := (&authenticator{secret: []byte("guessed")}).issue(DefaultGameID, 0, "")
+	if _, ok := join(forged); ok {
+		t.Error("Expected a forged token to be rejected")
+	}
+	if _, ok := join(""); ok {
+		t.Error("Expected a claimed seat to require its token")
+	}
+	if _, ok := join(token); !ok {
+		t.Error("Expected the player to take the seat back with their token")
+	}
+}
@@ -0,0 +1,22 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"net/http"
+
+	"github.com/devblac/chinchon/chinchon"
+	"github.com/gorilla/mux"
+)
+
+// handleCardImage serves the default card set, as /cards/{assetID}.svg (see Card.AssetID).
+func (s *server) handleCardImage(w http.ResponseWriter, r *http.Request) {
+	assetID := mux.Vars(r)["assetID"]
+	if _, err := chinchon.CardFromAssetID(assetID); err != nil && assetID != chinchon.CardBackAssetID {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	http.ServeFileFS(w, r, assets, "assets/cards/"+assetID+".svg")
+}
@@ -0,0 +1,39 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/devblac/chinchon/chinchon"
+	"github.com/gorilla/mux"
+)
+
+func TestCardImages(t *testing.T) {
+	s := New("0")
+	get := func(assetID string) int {
+		w := httptest.NewRecorder()
+		r := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/cards/"+assetID+".svg", nil), map[string]string{"assetID": assetID})
+		s.handleCardImage(w, r)
+		return w.Code
+	}
+
+	// Every card of every deck type has an image, the French deck's kings included.
+	for _, suit := range []string{chinchon.ORO, chinchon.COPA, chinchon.ESPADA, chinchon.BASTO} {
+		for number := 1; number <= 13; number++ {
+			assetID := chinchon.Card{Suit: suit, Number: number}.AssetID()
+			if code := get(assetID); code != http.StatusOK {
+				t.Errorf("Expected %v to be served, got %d", assetID, code)
+			}
+		}
+	}
+	if code := get(chinchon.CardBackAssetID); code != http.StatusOK {
+		t.Errorf("Expected the back of the cards to be served, got %d", code)
+	}
+	if code := get("oro_14"); code != http.StatusNotFound {
+		t.Errorf("Expected an invalid card to be not found, got %d", code)
+	}
+}
@@ -0,0 +1,186 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/gorilla/websocket"
+)
+
+// maxRedirects is how many times WsJoin follows redirects to other nodes of a cluster.
+const maxRedirects = 3
+
+// maxGameIDAttempts is how many IDs a cluster node tries for a game it starts, until one
+// it hosts.
+const maxGameIDAttempts = 100
+
+// errGameElsewhere is returned when starting a game hosted by another node of the cluster.
+var errGameElsewhere = errors.New("game is hosted by another node")
+
+// WithCluster runs the server as one node of a cluster, each game being hosted by a single
+// node: by hashing its ID over the nodes (rendezvous hashing, so that adding or removing a
+// node only moves the games it gains or had), or with WithGameRegistry, by looking it up.
+// Players and spectators connecting to another node are sent to it (see MessageRedirect),
+// and games the node starts itself, e.g. matches, get IDs it hosts. Nodes are named by the
+// address clients reach them at, as in ServerURL, e.g. "wss://node1.example.com"; self is
+// this one's.
+func WithCluster(self string, nodes ...string) Option {
+	return func(s *server) {
+		if !slices.Contains(nodes, self) {
+			nodes = append(nodes, self)
+		}
+		s.cluster = &cluster{self: self, nodes: nodes}
+	}
+}
+
+// GameRegistry records which node of a cluster hosts each game, for WithGameRegistry (e.g.
+// redisstore.Store).
+type GameRegistry interface {
+	// ClaimGame returns the node hosting the game, making it node if there's none yet.
+	ClaimGame(gameID, node string) (string, error)
+}
+
+// WithGameRegistry makes the nodes of a cluster (see WithCluster) look up the node hosting
+// each game in the registry, rather than hashing its ID, so that games are hosted by the
+// first node to start them, or that clients connect to for them.
+func WithGameRegistry(registry GameRegistry) Option {
+	return func(s *server) {
+		s.gameRegistry = registry
+	}
+}
+
+// cluster are the nodes of the cluster the server is one of.
+type cluster struct {
+	self     string
+	nodes    []string
+	registry GameRegistry
+}
+
+// owner returns the node hosting the game.
+func (c *cluster) owner(gameID string) (string, error) {
+	if c.registry != nil {
+		return c.registry.ClaimGame(gameID, c.self)
+	}
+	var owner string
+	var highest uint64
+	for _, node := range c.nodes {
+		sum := sha256.Sum256([]byte(node + "\x00" + gameID))
+		if score := binary.BigEndian.Uint64(sum[:8]); owner == "" || score > highest {
+			owner, highest = node, score
+		}
+	}
+	return owner, nil
+}
+
+// gameOwner returns the node of the cluster hosting the game, and whether it's this one,
+// which hosts every game without WithCluster.
+func (s *server) gameOwner(gameID string) (string, bool, error) {
+	if s.cluster == nil {
+		return "", true, nil
+	}
+	if gameID == "" {
+		gameID = DefaultGameID
+	}
+	owner, err := s.cluster.owner(gameID)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to look up the node hosting game %q: %w", gameID, err)
+	}
+	return owner, owner == s.cluster.self, nil
+}
+
+// hostsGame returns whether the game is hosted by this node of the cluster, if any.
+func (s *server) hostsGame(gameID string) (bool, error) {
+	_, local, err := s.gameOwner(gameID)
+	return local, err
+}
+
+// checkHosted returns errGameElsewhere if the game is hosted by another node.
+func (s *server) checkHosted(gameID string) error {
+	owner, local, err := s.gameOwner(gameID)
+	if err != nil {
+		return err
+	}
+	if !local {
+		return fmt.Errorf("%w: %v", errGameElsewhere, owner)
+	}
+	return nil
+}
+
+// localGameID returns the ID, or else the first one with a "-2", "-3"... suffix, that this
+// node of the cluster hosts, for games it starts.
+func (s *server) localGameID(gameID string) (string, error) {
+	for attempt := 1; attempt <= maxGameIDAttempts; attempt++ {
+		id := gameID
+		if attempt > 1 {
+			id = fmt.Sprintf("%v-%d", gameID, attempt)
+		}
+		local, err := s.hostsGame(id)
+		if err != nil {
+			return "", err
+		}
+		if local {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("no ID for game %q is hosted by this node", gameID)
+}
+
+// redirect sends the client to the node hosting the game, if it isn't this one, returning
+// whether it did (or failed to tell).
+func (s *server) redirect(conn *websocket.Conn, r *http.Request, gameID string, logger *slog.Logger) bool {
+	owner, local, err := s.gameOwner(gameID)
+	if err != nil {
+		logger.Error("failed to route connection", "gameID", gameID, "err", err)
+		_ = WsSend(conn, NewMessageError(ErrorCodeServerError, "failed to find the game"))
+		return true
+	}
+	if local {
+		return false
+	}
+	logger.Debug("redirecting connection to the node hosting the game", "gameID", gameID, "node", owner)
+	_ = WsSend(conn, NewMessageRedirect(gameID, ServerURL(owner, r.URL.RequestURI(), true)))
+	_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "redirected"))
+	return true
+}
+
+// WsJoin connects to the WebSocket URL and sends the hello (e.g. a MessageHello or a
+// MessageSpectate), following the server's redirects to the node of its cluster hosting
+// the game (see WithCluster). It returns the connection, and the first message the server
+// sent on it, as WsReadAnyMessage does.
+func WsJoin(wsURL string, hello any) (*websocket.Conn, int, []byte, error) {
+	for redirects := 0; ; redirects++ {
+		conn, err := WsDial(wsURL)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		if err := WsSend(conn, hello); err != nil {
+			conn.Close()
+			return nil, 0, nil, err
+		}
+		messageType, message, err := WsReadAnyMessage(conn)
+		if err != nil {
+			conn.Close()
+			return nil, 0, nil, err
+		}
+		if messageType != MessageTypeRedirect {
+			return conn, messageType, message, nil
+		}
+		conn.Close()
+		redirect, err := WsDeserializeMessage[MessageRedirect, MessageRedirect](message, messageType)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		if redirects == maxRedirects {
+			return nil, 0, nil, fmt.Errorf("too many redirects, last to %v", redirect.URL)
+		}
+		wsURL = redirect.URL
+	}
+}
@@ -0,0 +1,187 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// registryFunc is a game registry that looks up the node hosting each game with a function.
+type registryFunc func(gameID string) (string, error)
+
+func (f registryFunc) ClaimGame(gameID, node string) (string, error) { return f(gameID) }
+
+// hostedBy returns a game registry that says every game is hosted by the node, or fails.
+func hostedBy(node string, err error) GameRegistry {
+	return registryFunc(func(string) (string, error) { return node, err })
+}
+
+func TestClusterOwner(t *testing.T) {
+	nodes := []string{"ws://a", "ws://b", "ws://c"}
+	hosted := map[string]int{}
+	for i := 0; i < 300; i++ {
+		gameID := fmt.Sprintf("mesa%d", i)
+		owner, _ := (&cluster{self: nodes[0], nodes: nodes}).owner(gameID)
+		for _, self := range nodes[1:] {
+			if other, _ := (&cluster{self: self, nodes: nodes}).owner(gameID); other != owner {
+				t.Fatalf("Expected every node to agree that %v hosts %v, got %v", owner, gameID, other)
+			}
+		}
+		hosted[owner]++
+
+		// Adding a node only moves games to it.
+		grown, _ := (&cluster{self: nodes[0], nodes: append(nodes[:3:3], "ws://d")}).owner(gameID)
+		if grown != owner && grown != "ws://d" {
+			t.Errorf("Expected %v to stay on %v or move to the new node, got %v", gameID, owner, grown)
+		}
+	}
+	for _, node := range nodes {
+		if hosted[node] < 50 {
+			t.Errorf("Expected games to be spread over the nodes, got %v", hosted)
+		}
+	}
+
+	for _, test := range []struct {
+		registry GameRegistry
+		owner    string
+		hasErr   bool
+	}{
+		{registry: hostedBy("ws://b", nil), owner: "ws://b"},
+		{registry: hostedBy("", errors.New("registry down")), hasErr: true},
+	} {
+		owner, err := (&cluster{self: "ws://a", nodes: nodes, registry: test.registry}).owner("casa")
+		if owner != test.owner || (err != nil) != test.hasErr {
+			t.Errorf("Expected the registry's node %q and error %v, got %q and %v", test.owner, test.hasErr, owner, err)
+		}
+	}
+}
+
+func TestLocalGameID(t *testing.T) {
+	if id, err := New("0").localGameID("casa"); id != "casa" || err != nil {
+		t.Errorf("Expected a server without a cluster to host every game, got %q and %v", id, err)
+	}
+
+	nodes := []string{"ws://a", "ws://b"}
+	for _, self := range nodes {
+		s := New("0", WithCluster(self, nodes...))
+		for i := 0; i < 20; i++ {
+			gameID := fmt.Sprintf("mesa%d", i)
+			id, err := s.localGameID(gameID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id != gameID && !strings.HasPrefix(id, gameID+"-") {
+				t.Errorf("Expected an ID for %v, got %v", gameID, id)
+			}
+			if local, _ := s.hostsGame(id); !local {
+				t.Errorf("Expected %v to get an ID hosted by %v, got %v", gameID, self, id)
+			}
+			if owner, _ := s.cluster.owner(gameID); owner == self && id != gameID {
+				t.Errorf("Expected %v to keep its ID on the node hosting it, got %v", gameID, id)
+			}
+		}
+	}
+
+	s := New("0", WithCluster("ws://a", nodes...), WithGameRegistry(hostedBy("ws://b", nil)))
+	if _, err := s.localGameID("casa"); err == nil {
+		t.Error("Expected no ID to be found when every game is hosted elsewhere")
+	}
+}
+
+// newTestCluster starts a node of a cluster for each of the options, returning their
+// addresses, as nodes are named.
+func newTestCluster(t *testing.T, opts ...[]Option) ([]string, []*server) {
+	srvs := []*httptest.Server{}
+	nodes := []string{}
+	for range opts {
+		srv := httptest.NewUnstartedServer(nil)
+		srvs = append(srvs, srv)
+		nodes = append(nodes, "ws://"+srv.Listener.Addr().String())
+	}
+	servers := []*server{}
+	for i, srv := range srvs {
+		s := New("0", append([]Option{WithCluster(nodes[i], nodes...)}, opts[i]...)...)
+		srv.Config.Handler = s.handler()
+		srv.Start()
+		t.Cleanup(srv.Close)
+		t.Cleanup(s.polls.shutdown)
+		servers = append(servers, s)
+	}
+	return nodes, servers
+}
+
+func TestClusterRedirect(t *testing.T) {
+	nodes, servers := newTestCluster(t, nil, nil)
+	gameID := ""
+	for i := 0; gameID == ""; i++ {
+		if owner, _ := servers[0].cluster.owner(fmt.Sprintf("mesa%d", i)); owner == nodes[1] {
+			gameID = fmt.Sprintf("mesa%d", i)
+		}
+	}
+
+	// Connecting to the other node is answered with a redirect.
+	conn, err := WsDial(ServerURL(nodes[0], "/ws", true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WsSend(conn, NewMessageHelloForGame(gameID, 0, "")); err != nil {
+		t.Fatal(err)
+	}
+	redirect, err := WsReadMessage[MessageRedirect, MessageRedirect](conn, MessageTypeRedirect)
+	conn.Close()
+	if err != nil || redirect.GameID != gameID || redirect.URL != ServerURL(nodes[1], "/ws", true) {
+		t.Fatalf("Expected a redirect to %v, got %+v and %v", nodes[1], redirect, err)
+	}
+
+	// WsJoin follows it, from either node.
+	for _, node := range nodes {
+		conn, messageType, _, err := WsJoin(ServerURL(node, "/ws", true), NewMessageHelloForGame(gameID, 0, ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+		if messageType == MessageTypeRedirect || messageType == MessageTypeError {
+			t.Errorf("Expected to join %v through %v, got message type %d", gameID, node, messageType)
+		}
+	}
+	if _, ok := servers[0].existingRoom(gameID); ok {
+		t.Errorf("Expected %v not to be played on %v", gameID, nodes[0])
+	}
+	if _, ok := servers[1].existingRoom(gameID); !ok {
+		t.Errorf("Expected %v to be played on %v", gameID, nodes[1])
+	}
+}
+
+func TestClusterRedirectLoop(t *testing.T) {
+	// Registries that disagree send clients back and forth.
+	addresses := make([]string, 2)
+	nodes, _ := newTestCluster(t,
+		[]Option{WithGameRegistry(registryFunc(func(string) (string, error) { return addresses[1], nil }))},
+		[]Option{WithGameRegistry(registryFunc(func(string) (string, error) { return addresses[0], nil }))},
+	)
+	copy(addresses, nodes)
+	if _, _, _, err := WsJoin(ServerURL(nodes[0], "/ws", true), NewMessageHelloForGame("casa", 0, "")); err == nil || !strings.Contains(err.Error(), "too many redirects") {
+		t.Errorf("Expected WsJoin to give up after %d redirects, got %v", maxRedirects, err)
+	}
+
+	// The default game is looked up on start, so the registry only fails for others.
+	nodes, _ = newTestCluster(t, []Option{WithGameRegistry(registryFunc(func(gameID string) (string, error) {
+		if gameID == DefaultGameID {
+			return "", nil
+		}
+		return "", errors.New("registry down")
+	}))})
+	conn, messageType, _, err := WsJoin(ServerURL(nodes[0], "/ws", true), NewMessageHelloForGame("casa", 0, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	if messageType != MessageTypeError {
+		t.Errorf("Expected an error when the node hosting the game can't be found, got message type %d", messageType)
+	}
+}
@@ -0,0 +1,554 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devblac/chinchon/chinchon"
+	"github.com/devblac/chinchon/examplebot/newbot"
+	"github.com/gorilla/websocket"
+)
+
+// In daily challenge mode every human plays seat 0 against the example bot in seat 1,
+// and everyone gets the same cards for the day.
+const (
+	dailyHumanPlayerID = 0
+	dailyBotPlayerID   = 1
+
+	// dailySessionGameID is the game ID of the session tokens proving a player's name in
+	// daily challenges. It isn't a valid game ID, so that seat tokens for a game can't be
+	// taken for sessions, nor sessions for seats.
+	dailySessionGameID = "daily:"
+)
+
+var (
+	errDailyFinished = errors.New("daily challenge already finished today")
+	errNameClaimed   = errors.New("name claimed by another player, a session token is required")
+)
+
+// WithDailyResultsFile saves the daily challenges' results to the JSON file after every
+// finished challenge, and restores them from it when the server starts. Without it,
+// they're lost on restart.
+func WithDailyResultsFile(path string) Option {
+	return func(s *server) {
+		s.dailyPath = path
+	}
+}
+
+// WithDailySecret derives the daily challenges' seeds from the secret, so that the same
+// cards are dealt each day after a restart. Without it, a random secret is used.
+func WithDailySecret(secret []byte) Option {
+	return func(s *server) {
+		s.dailySecret = secret
+	}
+}
+
+// DailyChallenge is the challenge for a given day. Its seed is only published once the day
+// is over, as it gives the whole deal away.
+type DailyChallenge struct {
+	Date string `json:"date"`
+	Seed int64  `json:"seed,omitempty"`
+}
+
+// DailyResult is the outcome of one player's attempt at a daily challenge.
+type DailyResult struct {
+	Name          string    `json:"name"`
+	Won           bool      `json:"won"`
+	Score         int       `json:"score"`
+	OpponentScore int       `json:"opponentScore"`
+	Rounds        int       `json:"rounds"`
+	FinishedAt    time.Time `json:"finishedAt"`
+}
+
+type dailyChallenges struct {
+	mu         sync.Mutex
+	path       string                   // where results are saved, if anywhere
+	secret     []byte                   // what seeds are derived from
+	results    map[string][]DailyResult // by date
+	unfinished map[string]*dailyGame    // by player name
+
+	// sessions are the nonces of the latest session token of each name, with auth.
+	sessions map[string]string
+}
+
+// dailyGame is a daily challenge in progress. It's kept after the player disconnects,
+// so that they can resume it later that day.
+type dailyGame struct {
+	challenge DailyChallenge
+	startedAt time.Time
+	playing   bool // true while a connection is playing it, with the challenges locked
+
+	// mu guards gameState, which the connection playing it changes while others may read
+	// it, e.g. to list the player's games.
+	mu        sync.Mutex
+	gameState *chinchon.GameState
+}
+
+// myGame summarizes the game, for the player to resume it.
+func (g *dailyGame) myGame() MyGame {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return MyGame{
+		ID:          "daily-" + g.challenge.Date,
+		Mode:        MyGameModeDaily,
+		PlayerID:    dailyHumanPlayerID,
+		RoundNumber: g.gameState.RoundNumber,
+		YourScore:   g.gameState.Players[dailyHumanPlayerID].Score,
+		TheirScore:  g.gameState.Players[dailyBotPlayerID].Score,
+	}
+}
+
+// newDailyChallenges returns the daily challenges, with the results saved at the path, if
+// any, and seeds derived from the secret, or a random one if nil.
+func newDailyChallenges(path string, secret []byte) (*dailyChallenges, error) {
+	if secret == nil {
+		secret = make([]byte, 32)
+		_, _ = rand.Read(secret)
+	}
+	d := &dailyChallenges{path: path, secret: secret, results: map[string][]DailyResult{}, unfinished: map[string]*dailyGame{}, sessions: map[string]string{}}
+	if path == "" {
+		return d, nil
+	}
+	bs, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return d, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bs, &d.results); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// challengeFor returns the challenge for the day t falls on (in UTC). Its seed is derived
+// from the secret, so that players can't work the deal out before playing.
+func (d *dailyChallenges) challengeFor(t time.Time) DailyChallenge {
+	date := t.UTC().Format(time.DateOnly)
+	mac := hmac.New(sha256.New, d.secret)
+	mac.Write([]byte("chinchon-daily-" + date))
+	return DailyChallenge{Date: date, Seed: int64(binary.BigEndian.Uint64(mac.Sum(nil)))}
+}
+
+// record adds the player's result to the day's, and saves them. Only their first finished
+// attempt counts, as they know the deal afterwards.
+func (d *dailyChallenges) record(date string, result DailyResult) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.unfinished, result.Name)
+	if d.finished(result.Name, date) {
+		return nil
+	}
+	d.results[date] = append(d.results[date], result)
+	return d.save()
+}
+
+// finished returns true if the player finished the day's challenge. Must be called with
+// the challenges locked.
+func (d *dailyChallenges) finished(name, date string) bool {
+	return slices.ContainsFunc(d.results[date], func(result DailyResult) bool { return result.Name == name })
+}
+
+// save writes the results to their file, if any. Must be called with the challenges
+// locked.
+func (d *dailyChallenges) save() error {
+	if d.path == "" {
+		return nil
+	}
+	bs, err := json.Marshal(d.results)
+	if err != nil {
+		return err
+	}
+	// Write then rename, so that a crash never leaves a partial file.
+	tmp := d.path + ".tmp"
+	if err := os.WriteFile(tmp, bs, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, d.path)
+}
+
+// authenticate checks that the name is the player's, with auth: the first player to use it
+// gets a session token for it, and only that token may use it after that. It returns the
+// new token, if any.
+func (d *dailyChallenges) authenticate(auth *authenticator, name, token string) (string, error) {
+	if auth == nil {
+		return "", nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if token == "" {
+		if d.sessions[name] != "" {
+			return "", errNameClaimed
+		}
+		token, claims := auth.issue(dailySessionGameID, dailyHumanPlayerID, name)
+		d.sessions[name] = claims.Nonce
+		return token, nil
+	}
+
+	claims, err := auth.verify(token)
+	if err != nil {
+		return "", err
+	}
+	// After a restart, sessions are forgotten, so any token signed for the name is valid.
+	if claims.GameID != dailySessionGameID || claims.Name != name ||
+		(d.sessions[name] != "" && d.sessions[name] != claims.Nonce) {
+		return "", errInvalidToken
+	}
+	d.sessions[name] = claims.Nonce
+	return "", nil
+}
+
+// unfinishedGame returns the player's unfinished game for today's challenge, if any.
+// Games from previous days can't be resumed anymore, so they're dropped.
+func (d *dailyChallenges) unfinishedGame(name string, challenge DailyChallenge) (*dailyGame, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	game, ok := d.unfinished[name]
+	if ok && game.challenge != challenge {
+		delete(d.unfinished, name)
+		return nil, false
+	}
+	return game, ok
+}
+
+// claim returns the player's game for today's challenge, resuming it if they have an
+// unfinished one. A game can only be played from one connection at a time, and only until
+// the player finishes it.
+func (d *dailyChallenges) claim(name string, challenge DailyChallenge) (game *dailyGame, resumed bool, err error) {
+	game, resumed = d.unfinishedGame(name, challenge)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.finished(name, challenge.Date) {
+		return nil, false, errDailyFinished
+	}
+	if resumed && game.playing {
+		return nil, false, fmt.Errorf("daily challenge for %v is already being played", name)
+	}
+	if !resumed {
+		game = &dailyGame{
+			challenge: challenge,
+			gameState: chinchon.New(chinchon.WithSeed(challenge.Seed)),
+			startedAt: time.Now(),
+		}
+		d.unfinished[name] = game
+	}
+	game.playing = true
+	return game, resumed, nil
+}
+
+func (d *dailyChallenges) release(game *dailyGame) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	game.playing = false
+}
+
+// ranking returns the day's results, best first: winners before losers, then by fewest
+// penalty points, then by fewest rounds needed.
+func (d *dailyChallenges) ranking(date string) []DailyResult {
+	d.mu.Lock()
+	ranking := append([]DailyResult{}, d.results[date]...)
+	d.mu.Unlock()
+
+	sort.SliceStable(ranking, func(i, j int) bool {
+		if ranking[i].Won != ranking[j].Won {
+			return ranking[i].Won
+		}
+		if ranking[i].Score != ranking[j].Score {
+			return ranking[i].Score < ranking[j].Score
+		}
+		return ranking[i].Rounds < ranking[j].Rounds
+	})
+	return ranking
+}
+
+func (d *dailyChallenges) abandon(name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.unfinished, name)
+}
+
+// Modes of MyGame.
+const (
+	MyGameModeDaily = "daily"
+	MyGameModeGame  = "game"
+)
+
+// MyGame is a summary of an unfinished game, for players to pick one to resume.
+type MyGame struct {
+	ID          string `json:"id"`
+	Mode        string `json:"mode"`
+	PlayerID    int    `json:"playerID"`
+	RoundNumber int    `json:"roundNumber"`
+	YourScore   int    `json:"yourScore"`
+
+	// TheirScore is the score of the opponent with the fewest points.
+	TheirScore int `json:"theirScore"`
+}
+
+// handleMyGames lists the unfinished games of the player named by the session token in the
+// Authorization header ("Bearer token"): their daily challenge, and the games, live or
+// stored, with a player of that name. Tokens only name players matched in the lobby or a
+// tournament, or who played a daily challenge.
+func (s *server) handleMyGames(w http.ResponseWriter, r *http.Request) {
+	name, err := s.sessionName(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	games := []MyGame{}
+	if game, ok := s.daily.unfinishedGame(name, s.daily.challengeFor(time.Now())); ok {
+		games = append(games, game.myGame())
+	}
+	listed, err := s.filterGames(r.Context(), gameFilter{player: name, state: GameStateActive, limit: maxGamesPageSize})
+	if err != nil {
+		s.logger.Error("failed to list stored games", "err", err)
+		http.Error(w, "failed to list games", http.StatusInternalServerError)
+		return
+	}
+	listed, _ = gameFilter{limit: maxGamesPageSize}.page(listed)
+	for _, game := range listed {
+		games = append(games, myGameFrom(game, name))
+	}
+	writeJSON(w, games)
+}
+
+// sessionName returns the player's name in the request's session token.
+func (s *server) sessionName(r *http.Request) (string, error) {
+	if s.auth == nil {
+		return "", errors.New("players aren't authenticated by this server")
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return "", errors.New("a session token is required")
+	}
+	claims, err := s.auth.verify(token)
+	if err != nil {
+		return "", err
+	}
+	if claims.Name == "" {
+		return "", errInvalidToken
+	}
+	return claims.Name, nil
+}
+
+// myGameFrom summarizes the game for the player with the name.
+func myGameFrom(game APIGame, name string) MyGame {
+	mine := MyGame{ID: game.ID, Mode: MyGameModeGame, RoundNumber: game.RoundNumber, TheirScore: -1}
+	team := -1
+	for _, player := range game.Players {
+		if player.Name == name && team == -1 {
+			mine.PlayerID, mine.YourScore, team = player.PlayerID, player.Score, player.Team
+		}
+	}
+	for _, player := range game.Players {
+		if player.Team != team && (mine.TheirScore == -1 || player.Score < mine.TheirScore) {
+			mine.TheirScore = player.Score
+		}
+	}
+	return mine
+}
+
+// handleDailyChallenge publishes today's challenge, or a past day's with e.g.
+// ?date=2024-06-23. Only past days' seeds are revealed, for players to check their deals.
+func (s *server) handleDailyChallenge(w http.ResponseWriter, r *http.Request) {
+	today := s.daily.challengeFor(time.Now())
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		date = today.Date
+	}
+	t, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		http.Error(w, "invalid date", http.StatusBadRequest)
+		return
+	}
+	challenge := s.daily.challengeFor(t)
+	switch {
+	case challenge.Date > today.Date:
+		http.Error(w, "no challenge for that date yet", http.StatusNotFound)
+		return
+	case challenge.Date == today.Date:
+		challenge.Seed = 0
+	}
+	writeJSON(w, challenge)
+}
+
+func (s *server) handleDailyLeaderboard(w http.ResponseWriter, r *http.Request) {
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		date = s.daily.challengeFor(time.Now()).Date
+	}
+	writeJSON(w, s.daily.ranking(date))
+}
+
+func (s *server) handleDailyWebSocket(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+
+	logger := s.logger.With("name", name, "remoteAddr", r.RemoteAddr)
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Warn("failed to upgrade connection to WebSocket", "err", err)
+		return
+	}
+	defer conn.Close()
+	defer s.keepAlive(conn, nil)()
+
+	// The hello message is read for protocol compatibility, but the seat is always the same.
+	hello, err := WsReadMessage[MessageHello, MessageHello](conn, MessageTypeHello)
+	if err != nil {
+		logger.Warn("invalid hello message", "err", err)
+		return
+	}
+	token, err := s.daily.authenticate(s.auth, name, hello.Token)
+	if err != nil {
+		logger.Warn("failed to authenticate daily challenge player", "err", err)
+		return
+	}
+	if token != "" {
+		if err := WsSend(conn, NewMessageSession(token)); err != nil {
+			return
+		}
+	}
+
+	s.stats.playerConnected()
+	defer s.stats.playerDisconnected()
+
+	challenge := s.daily.challengeFor(time.Now())
+	logger = logger.With("gameID", "daily-"+challenge.Date)
+	game, resumed, err := s.daily.claim(name, challenge)
+	if err != nil {
+		logger.Warn("failed to claim daily challenge", "err", err)
+		if errors.Is(err, errDailyFinished) {
+			_ = WsSend(conn, NewMessageError(ErrorCodeGameEnded, err.Error()))
+		}
+		return
+	}
+	defer s.daily.release(game)
+	if resumed {
+		logger.Info("daily challenge resumed")
+	} else {
+		s.stats.gameStarted()
+		logger.Info("daily challenge started")
+	}
+	bot := newbot.New()
+
+	for {
+		game.mu.Lock()
+		gameState := game.gameState
+		err := runBotTurns(gameState, dailyBotPlayerID, bot)
+		msg, _ := NewMessageHeresGameState(gameState.ToClientGameState(dailyHumanPlayerID))
+		var result *DailyResult
+		if gameState.IsGameEnded {
+			result = &DailyResult{
+				Name:          name,
+				Won:           gameState.WinnerPlayerID == dailyHumanPlayerID,
+				Score:         gameState.Players[dailyHumanPlayerID].Score,
+				OpponentScore: gameState.Players[dailyBotPlayerID].Score,
+				Rounds:        gameState.RoundNumber,
+				FinishedAt:    time.Now(),
+			}
+		}
+		game.mu.Unlock()
+		if err != nil {
+			logger.Error("daily challenge bot failed", "err", err)
+			s.daily.abandon(name)
+			s.stats.gameAbandoned()
+			return
+		}
+
+		if err := WsSend(conn, msg); err != nil {
+			return
+		}
+
+		if result != nil {
+			s.stats.gameFinished(game.startedAt)
+			s.stats.botGameFinished(result.Won)
+			if err := s.daily.record(challenge.Date, *result); err != nil {
+				logger.Error("failed to save the daily challenges' results", "err", err)
+			}
+			logger.Info("daily challenge finished")
+			return
+		}
+
+		if err := readHumanAction(conn, game, dailyHumanPlayerID, logger); err != nil {
+			logger.Info("daily challenge left", "err", err)
+			return
+		}
+	}
+}
+
+// readHumanAction blocks until the player sends an action and runs it. Other message
+// types are ignored, and the player is told about rejected actions so they can try again.
+func readHumanAction(conn *websocket.Conn, game *dailyGame, playerID int, logger *slog.Logger) error {
+	for {
+		messageType, message, err := WsReadAnyMessage(conn)
+		if err != nil {
+			return err
+		}
+		if messageType != MessageTypeAction {
+			continue
+		}
+		action, err := WsDeserializeMessage[chinchon.Action, MessageAction](message, MessageTypeAction)
+		if err != nil {
+			return err
+		}
+		if (*action).GetPlayerID() != playerID {
+			logger.Warn("player tried to run action for another player", "action", (*action).GetName(), "actionPlayerID", (*action).GetPlayerID())
+			continue
+		}
+		game.mu.Lock()
+		err = game.gameState.RunAction(*action)
+		game.mu.Unlock()
+		if err != nil {
+			logger.Info("failed to run action", "action", (*action).GetName(), "err", err)
+			if err := WsSend(conn, newActionErrorMessage(err)); err != nil {
+				return err
+			}
+			continue
+		}
+		return nil
+	}
+}
+
+// runBotTurns lets the bot play until it has no possible actions left.
+func runBotTurns(gameState *chinchon.GameState, botPlayerID int, bot chinchon.Bot) error {
+	for !gameState.IsGameEnded {
+		action := bot.ChooseAction(gameState.ToClientGameState(botPlayerID))
+		if action == nil {
+			return nil
+		}
+		if err := gameState.RunAction(action); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("failed to write JSON response", "err", err)
+	}
+}
@@ -0,0 +1,141 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDailyResults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daily.json")
+	d, err := newDailyChallenges(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenge := d.challengeFor(time.Now())
+	if _, _, err := d.claim("juan", challenge); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.record(challenge.Date, DailyResult{Name: "juan", Score: 40}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := d.claim("juan", challenge); !errors.Is(err, errDailyFinished) {
+		t.Errorf("Expected a finished challenge not to be claimed again, got %v", err)
+	}
+	if err := d.record(challenge.Date, DailyResult{Name: "juan", Won: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := newDailyChallenges(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ranking := restored.ranking(challenge.Date); len(ranking) != 1 || ranking[0].Score != 40 {
+		t.Errorf("Expected only the first attempt to be restored, got %+v", ranking)
+	}
+}
+
+func TestDailyNames(t *testing.T) {
+	d, _ := newDailyChallenges("", nil)
+	auth := newRandomAuthenticator()
+	token, err := d.authenticate(auth, "juan", "")
+	if err != nil || token == "" {
+		t.Fatalf("Expected the first player to use the name to get a token, got %q and %v", token, err)
+	}
+	if _, err := d.authenticate(auth, "juan", ""); !errors.Is(err, errNameClaimed) {
+		t.Errorf("Expected the name to require its token, got %v", err)
+	}
+	if _, err := d.authenticate(auth, "pedro", token); !errors.Is(err, errInvalidToken) {
+		t.Errorf("Expected another name's token to be rejected, got %v", err)
+	}
+	if _, err := d.authenticate(auth, "juan", token); err != nil {
+		t.Errorf("Expected the name's token to be accepted, got %v", err)
+	}
+	if _, err := d.authenticate(nil, "juan", ""); err != nil {
+		t.Errorf("Expected names not to be claimed without auth, got %v", err)
+	}
+
+	// Even after a restart, a seat in a game named like the sessions isn't one.
+	restarted, _ := newDailyChallenges("", nil)
+	seatToken, _ := auth.issue("daily", dailyHumanPlayerID, "juan")
+	if _, err := restarted.authenticate(auth, "juan", seatToken); !errors.Is(err, errInvalidToken) {
+		t.Errorf("Expected a seat token to be rejected as a session, got %v", err)
+	}
+	if validGameID.MatchString(dailySessionGameID) {
+		t.Errorf("Expected %q not to be a valid game ID", dailySessionGameID)
+	}
+}
+
+func TestMyGames(t *testing.T) {
+	s := New("0", WithAuth([]byte("secret")))
+	token, err := s.daily.authenticate(s.auth, "juan", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.daily.claim("juan", s.daily.challengeFor(time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	room, err := s.createRoom("casa", s.gameOptions, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	room.setPlayerName(1, "juan")
+	room.mu.Unlock()
+
+	myGames := func(token string) (int, []MyGame) {
+		req := httptest.NewRequest(http.MethodGet, "/games/mine", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		s.handleMyGames(w, req)
+		var games []MyGame
+		_ = json.NewDecoder(w.Body).Decode(&games)
+		return w.Code, games
+	}
+	code, games := myGames(token)
+	if code != http.StatusOK || len(games) != 2 || games[0].Mode != MyGameModeDaily || games[1].ID != "casa" || games[1].PlayerID != 1 {
+		t.Errorf("Expected the daily challenge and the game, got %d %+v", code, games)
+	}
+	seatToken, _ := s.auth.issue("casa", 0, "")
+	for _, token := range []string{"", "forged." + token, seatToken} {
+		if code, _ := myGames(token); code != http.StatusUnauthorized {
+			t.Errorf("Expected token %q to be refused, got %d", token, code)
+		}
+	}
+}
+
+func TestDailySeeds(t *testing.T) {
+	s := New("0", WithDailySecret([]byte("secret")))
+	other, _ := newDailyChallenges("", []byte("another secret"))
+	today := s.daily.challengeFor(time.Now())
+	if today != s.daily.challengeFor(time.Now()) || today.Seed == other.challengeFor(time.Now()).Seed {
+		t.Fatal("Expected seeds to be derived from the date and the secret")
+	}
+
+	challenge := func(query string) (int, DailyChallenge) {
+		w := httptest.NewRecorder()
+		s.handleDailyChallenge(w, httptest.NewRequest(http.MethodGet, "/daily"+query, nil))
+		var challenge DailyChallenge
+		_ = json.NewDecoder(w.Body).Decode(&challenge)
+		return w.Code, challenge
+	}
+	if code, published := challenge(""); code != http.StatusOK || published.Date != today.Date || published.Seed != 0 {
+		t.Errorf("Expected today's challenge without its seed, got %d %+v", code, published)
+	}
+	yesterday := s.daily.challengeFor(time.Now().AddDate(0, 0, -1))
+	if code, published := challenge("?date=" + yesterday.Date); code != http.StatusOK || published != yesterday {
+		t.Errorf("Expected yesterday's seed to be revealed, got %d %+v", code, published)
+	}
+	tomorrow := time.Now().AddDate(0, 0, 1).UTC().Format(time.DateOnly)
+	for _, query := range []string{"?date=" + tomorrow, "?date=today"} {
+		if code, _ := challenge(query); code == http.StatusOK {
+			t.Errorf("Expected %q to be refused", query)
+		}
+	}
+}
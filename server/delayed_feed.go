@@ -0,0 +1,75 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import "time"
+
+// DefaultSpectatorDelay is how far behind the live game spectator feeds run, so that
+// spectators can't relay hidden information to players in time for it to matter.
+const DefaultSpectatorDelay = 60 * time.Second
+
+// WithSpectatorDelay sets the delay applied to spectator feeds that reveal every hand.
+// Players, and spectators who only see public information, always receive state
+// instantly. A zero delay disables it.
+func WithSpectatorDelay(delay time.Duration) Option {
+	return func(s *server) {
+		s.spectatorDelay = delay
+	}
+}
+
+type delayedMessage struct {
+	message any
+	sentAt  time.Time
+}
+
+// delayedFeed delivers messages in order, each one no earlier than delay after it was sent.
+type delayedFeed struct {
+	delay   time.Duration
+	deliver func(message any)
+	queue   chan delayedMessage
+	done    chan struct{}
+}
+
+func newDelayedFeed(delay time.Duration, deliver func(message any)) *delayedFeed {
+	f := &delayedFeed{
+		delay:   delay,
+		deliver: deliver,
+		queue:   make(chan delayedMessage, 1024),
+		done:    make(chan struct{}),
+	}
+	go f.run()
+	return f
+}
+
+// Send queues the message for delivery. If the queue is full, the message is dropped,
+// since a feed this far behind is useless anyway.
+func (f *delayedFeed) Send(message any) {
+	select {
+	case f.queue <- delayedMessage{message: message, sentAt: time.Now()}:
+	default:
+	}
+}
+
+// Close stops the feed. Messages still in the queue are never delivered.
+func (f *delayedFeed) Close() {
+	close(f.done)
+}
+
+func (f *delayedFeed) run() {
+	for {
+		select {
+		case <-f.done:
+			return
+		case m := <-f.queue:
+			if wait := time.Until(m.sentAt.Add(f.delay)); wait > 0 {
+				select {
+				case <-f.done:
+					return
+				case <-time.After(wait):
+				}
+			}
+			f.deliver(m.message)
+		}
+	}
+}
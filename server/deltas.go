@@ -0,0 +1,158 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/devblac/chinchon/chinchon"
+)
+
+// DefaultFullStateEvery is how many deltas a connection that asked for them gets between
+// full game states, unless changed with WithFullStateEvery.
+const DefaultFullStateEvery = 20
+
+// WithFullStateEvery sets how many deltas (see MessageGameStateDelta) connections get
+// between full game states, so that they can't stay out of sync for long.
+func WithFullStateEvery(deltas int) Option {
+	return func(s *server) {
+		s.fullStateEvery = deltas
+	}
+}
+
+var errNoSnapshot = errors.New("got a game state delta before any full game state")
+
+// deltaEncoder turns the game states an outbox delivers into deltas from the previous one
+// it delivered, with a full one every so often. It's only used by the outbox's goroutine.
+type deltaEncoder struct {
+	interval int
+	fields   map[string]json.RawMessage // of the last game state delivered, if any
+	deltas   int                        // delivered since it was a full one
+}
+
+func newDeltaEncoder(interval int) *deltaEncoder {
+	return &deltaEncoder{interval: interval}
+}
+
+// resync makes the next game state a full one, e.g. for a client that got out of sync.
+func (e *deltaEncoder) resync() {
+	e.fields = nil
+}
+
+// encode returns the game state message to deliver: a delta, unless it's time for a full
+// game state, or the delta isn't any smaller.
+func (e *deltaEncoder) encode(message any) any {
+	var gameState json.RawMessage
+	switch m := message.(type) {
+	case MessageHeresGameState:
+		gameState = m.GameState
+	case MessageHeresSpectatorGameState:
+		gameState = m.GameState
+	default:
+		return message
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(gameState, &fields); err != nil {
+		return message
+	}
+	previous := e.fields
+	e.fields = fields
+	if previous == nil || e.deltas >= e.interval {
+		e.deltas = 0
+		return message
+	}
+
+	delta := NewMessageGameStateDelta(diffFields(previous, fields))
+	if changes, err := json.Marshal(delta.Changes); err != nil || len(changes) >= len(gameState) {
+		e.deltas = 0
+		return message
+	}
+	e.deltas++
+	return delta
+}
+
+// diffFields returns the fields of next that aren't the same in previous, and null for
+// those it no longer has.
+func diffFields(previous, next map[string]json.RawMessage) map[string]json.RawMessage {
+	changes := map[string]json.RawMessage{}
+	for name, value := range next {
+		if !bytes.Equal(previous[name], value) {
+			changes[name] = value
+		}
+	}
+	for name := range previous {
+		if _, ok := next[name]; !ok {
+			changes[name] = json.RawMessage("null")
+		}
+	}
+	return changes
+}
+
+// StateAssembler rebuilds the game states a client gets as deltas (see
+// MessageGameStateDelta), for players and spectators alike: pass it every full game state
+// with Snapshot, and every delta with Apply.
+type StateAssembler struct {
+	fields map[string]json.RawMessage
+}
+
+// Snapshot sets the full game state, as in MessageHeresGameState.GameState or
+// MessageHeresSpectatorGameState.GameState.
+func (a *StateAssembler) Snapshot(gameState json.RawMessage) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(gameState, &fields); err != nil {
+		return err
+	}
+	a.fields = fields
+	return nil
+}
+
+// Apply updates the game state with the delta, returning it whole, e.g. for
+// MessageHeresGameState.Deserialize. Clients missing the full game state should ask for it
+// with a MessageGimmeGameState.
+func (a *StateAssembler) Apply(delta MessageGameStateDelta) (json.RawMessage, error) {
+	if a.fields == nil {
+		return nil, errNoSnapshot
+	}
+	for name, value := range delta.Changes {
+		if string(value) == "null" {
+			delete(a.fields, name)
+		} else {
+			a.fields[name] = value
+		}
+	}
+	return json.Marshal(a.fields)
+}
+
+// ClientGameState returns the player's game state in a MessageHeresGameState or
+// MessageGameStateDelta read from the connection, keeping it for the next deltas. Clients
+// should still check that it's in sync (see chinchon.ClientGameState.IsInSync).
+func (a *StateAssembler) ClientGameState(messageType int, message []byte) (*chinchon.ClientGameState, error) {
+	switch messageType {
+	case MessageTypeHeresGameState:
+		var m MessageHeresGameState
+		if err := json.Unmarshal(message, &m); err != nil {
+			return nil, err
+		}
+		if err := a.Snapshot(m.GameState); err != nil {
+			return nil, err
+		}
+		gs, err := m.Deserialize()
+		return &gs, err
+	case MessageTypeGameStateDelta:
+		delta, err := WsDeserializeMessage[MessageGameStateDelta, MessageGameStateDelta](message, messageType)
+		if err != nil {
+			return nil, err
+		}
+		gameState, err := a.Apply(*delta)
+		if err != nil {
+			return nil, err
+		}
+		gs, err := MessageHeresGameState{GameState: gameState}.Deserialize()
+		return &gs, err
+	}
+	return nil, fmt.Errorf("Expected a game state, got message type %d", messageType)
+}
@@ -0,0 +1,119 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/devblac/chinchon/chinchon"
+)
+
+// withoutNulls decodes the game state, leaving out null fields, as they're the same as
+// missing ones to clients.
+func withoutNulls(t *testing.T, gameState json.RawMessage) map[string]any {
+	var fields map[string]any
+	if err := json.Unmarshal(gameState, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range fields {
+		if value == nil {
+			delete(fields, name)
+		}
+	}
+	return fields
+}
+
+func TestDeltaRoundTrip(t *testing.T) {
+	big := `"` + strings.Repeat("x", 100) + `"`
+	states := []string{
+		`{"big":` + big + `,"a":{"x":1},"b":1}`,
+		`{"big":` + big + `,"a":null,"b":1}`, // becomes null
+		`{"big":` + big + `,"b":2}`,          // goes missing
+		`{"big":` + big + `,"a":2,"b":2}`,    // comes back, but it's time for a full state
+		`{"big":` + big + `,"a":2,"b":3}`,
+		`{"a":3}`, // the delta isn't any smaller
+	}
+	expectedTypes := []int{MessageTypeHeresGameState, MessageTypeGameStateDelta, MessageTypeGameStateDelta, MessageTypeHeresGameState, MessageTypeGameStateDelta, MessageTypeHeresGameState}
+
+	e := newDeltaEncoder(2)
+	var a StateAssembler
+	for i, state := range states {
+		message := MessageHeresGameState{WebsocketMessage: WebsocketMessage{Type: MessageTypeHeresGameState}, GameState: json.RawMessage(state)}
+		var gameState json.RawMessage
+		messageType := -1
+		switch encoded := e.encode(message).(type) {
+		case MessageHeresGameState:
+			if err := a.Snapshot(encoded.GameState); err != nil {
+				t.Fatal(err)
+			}
+			gameState, messageType = encoded.GameState, encoded.Type
+		case MessageGameStateDelta:
+			// Deltas go through JSON, as null changes do.
+			bs, _ := json.Marshal(encoded)
+			var delta MessageGameStateDelta
+			if err := json.Unmarshal(bs, &delta); err != nil {
+				t.Fatal(err)
+			}
+			var err error
+			if gameState, err = a.Apply(delta); err != nil {
+				t.Fatal(err)
+			}
+			messageType = encoded.Type
+		}
+		if messageType != expectedTypes[i] {
+			t.Errorf("Expected state %d to be sent as message type %d, got %d", i, expectedTypes[i], messageType)
+		}
+		if got, expected := withoutNulls(t, gameState), withoutNulls(t, json.RawMessage(state)); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected state %d to be rebuilt as %v, got %v", i, expected, got)
+		}
+	}
+}
+
+func TestDeltaGame(t *testing.T) {
+	const interval = 5
+	e := newDeltaEncoder(interval)
+	var a StateAssembler
+	gs := chinchon.New()
+	deltas, totalDeltas := 0, 0
+	for i := 0; i < 200 && !gs.IsGameEnded; i++ {
+		message, err := NewMessageHeresGameState(gs.ToClientGameState(0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		bs, _ := json.Marshal(e.encode(message))
+		var m WebsocketMessage
+		if err := json.Unmarshal(bs, &m); err != nil {
+			t.Fatal(err)
+		}
+		messageType := m.Type
+		if messageType == MessageTypeGameStateDelta {
+			deltas++
+			totalDeltas++
+		} else {
+			deltas = 0
+		}
+		if deltas > interval {
+			t.Fatalf("Expected a full game state at least every %d deltas", interval)
+		}
+		rebuilt, err := a.ClientGameState(messageType, bs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, _ := message.Deserialize()
+		if rebuilt.Fingerprint() != expected.Fingerprint() || !reflect.DeepEqual(rebuilt.PossibleActions, expected.PossibleActions) {
+			t.Fatalf("Expected the game state after %d actions to be rebuilt from the deltas", i)
+		}
+
+		actions := gs.CalculatePossibleActions()
+		if err := gs.RunAction(actions[i%len(actions)]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if totalDeltas == 0 {
+		t.Error("Expected the game states to be sent as deltas")
+	}
+}
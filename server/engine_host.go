@@ -0,0 +1,131 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/devblac/chinchon/chinchon"
+)
+
+var (
+	errSeatTaken   = errors.New("seat already taken")
+	errInvalidSeat = errors.New("invalid player ID")
+)
+
+// EngineHost hosts a single game for in-process clients, without any network transport.
+// It's meant for embedding Chinchón matches in other Go programs (e.g. desktop games,
+// other services). All methods are safe for concurrent use.
+type EngineHost struct {
+	mu        sync.Mutex
+	gameState *chinchon.GameState
+	clients   map[int]*InProcessClient
+}
+
+// InProcessClient is a player seated at an EngineHost.
+type InProcessClient struct {
+	host     *EngineHost
+	playerID int
+	states   chan chinchon.ClientGameState
+}
+
+// NewEngineHost creates a host for a new game, configured with the engine's options.
+func NewEngineHost(opts ...func(*chinchon.GameState)) *EngineHost {
+	return &EngineHost{gameState: chinchon.New(opts...), clients: map[int]*InProcessClient{}}
+}
+
+// Join seats a client as the given player. The client immediately receives the current state.
+func (h *EngineHost) Join(playerID int) (*InProcessClient, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.gameState.Players[playerID]; !ok {
+		return nil, fmt.Errorf("%w: %d", errInvalidSeat, playerID)
+	}
+	if h.clients[playerID] != nil {
+		return nil, fmt.Errorf("%w: %d", errSeatTaken, playerID)
+	}
+
+	c := &InProcessClient{host: h, playerID: playerID, states: make(chan chinchon.ClientGameState, 1)}
+	h.clients[playerID] = c
+	c.deliver(h.gameState.ToClientGameState(playerID))
+	return c, nil
+}
+
+// AddBot seats a bot as the given player, which plays on its own until the game ends.
+func (h *EngineHost) AddBot(playerID int, bot chinchon.Bot) error {
+	c, err := h.Join(playerID)
+	if err != nil {
+		return err
+	}
+	go func() {
+		for state := range c.States() {
+			if state.IsGameEnded {
+				return
+			}
+			if action := bot.ChooseAction(state); action != nil {
+				_ = c.RunAction(action)
+			}
+		}
+	}()
+	return nil
+}
+
+// GameState returns a copy of the full game state, serialized, e.g. for saving it.
+func (h *EngineHost) GameState() ([]byte, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.gameState.Serialize()
+}
+
+// States returns the channel on which the client receives game states. Like over the
+// network, a slow client only gets the latest state. It's closed when the client leaves.
+func (c *InProcessClient) States() <-chan chinchon.ClientGameState {
+	return c.states
+}
+
+// RunAction runs the action as this client's player, and sends the resulting state to
+// every client. Unlike over the network, engine errors are returned as-is.
+func (c *InProcessClient) RunAction(action chinchon.Action) error {
+	h := c.host
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.clients[c.playerID] != c {
+		return errors.New("client left the game")
+	}
+	if action.GetPlayerID() != c.playerID {
+		return fmt.Errorf("player %d can't run actions for player %d", c.playerID, action.GetPlayerID())
+	}
+	if err := h.gameState.RunAction(action); err != nil {
+		return err
+	}
+	for playerID, client := range h.clients {
+		client.deliver(h.gameState.ToClientGameState(playerID))
+	}
+	return nil
+}
+
+// Leave frees the client's seat, so that another client may join as the same player.
+func (c *InProcessClient) Leave() {
+	h := c.host
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.clients[c.playerID] == c {
+		delete(h.clients, c.playerID)
+		close(c.states)
+	}
+}
+
+// deliver replaces any undelivered state with the new one. Must be called with the host locked.
+func (c *InProcessClient) deliver(state chinchon.ClientGameState) {
+	select {
+	case <-c.states:
+	default:
+	}
+	c.states <- state
+}
@@ -0,0 +1,245 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/devblac/chinchon/chinchon"
+	"github.com/devblac/chinchon/examplebot/newbot"
+)
+
+var errInvalidBots = errors.New("invalid bots")
+
+// DefaultExhibitionDelay is how long exhibition bots wait before each action, for
+// spectators to follow the game.
+const DefaultExhibitionDelay = time.Second
+
+// Names of the built-in bot strategies, for exhibition games.
+const (
+	// BotStrategyNewbot is the example bot, as hosted bots and tournament bots play.
+	BotStrategyNewbot = "newbot"
+
+	// BotStrategyRandom plays any possible action but resigning, as a baseline.
+	BotStrategyRandom = "random"
+)
+
+// WithBotStrategy adds a bot strategy for exhibition games, e.g. a new version of a bot
+// to compare with the built-in ones. Each seat gets its own bot from newBot.
+func WithBotStrategy(name string, newBot func() chinchon.Bot) Option {
+	return func(s *server) {
+		s.botStrategies[name] = newBot
+	}
+}
+
+func defaultBotStrategies() map[string]func() chinchon.Bot {
+	return map[string]func() chinchon.Bot{
+		BotStrategyNewbot: func() chinchon.Bot { return newbot.New() },
+		BotStrategyRandom: func() chinchon.Bot { return randomBot{} },
+	}
+}
+
+// AdminExhibition is the body of POST /admin/exhibitions.
+type AdminExhibition struct {
+	// ID is the game's ID, or a random one if empty.
+	ID string `json:"id,omitempty"`
+
+	// Bots are the bot strategies playing, one per seat, e.g. ["newbot", "random"].
+	Bots []string `json:"bots"`
+
+	// Rules are the game's house rules, if any (see houseRules). Its players must match
+	// the bots.
+	Rules json.RawMessage `json:"rules,omitempty"`
+
+	// Delay is how long bots wait before each action, e.g. "500ms" (DefaultExhibitionDelay
+	// by default).
+	Delay string `json:"delay,omitempty"`
+}
+
+// APIExhibition is an exhibition game being played, for spectators to watch.
+type APIExhibition struct {
+	Game APIGame  `json:"game"`
+	Bots []string `json:"bots"`
+}
+
+// exhibition is the state of a game played by bots, paced for spectators.
+type exhibition struct {
+	bots  []string // strategy names, by player ID
+	delay time.Duration
+}
+
+// createExhibition starts a game between the bot strategies, which play it at the pace of
+// the delay until it ends.
+func (s *server) createExhibition(gameID string, bots []string, gameOptions []func(*chinchon.GameState), delay time.Duration) (*room, error) {
+	for _, name := range bots {
+		if s.botStrategies[name] == nil {
+			return nil, fmt.Errorf("%w: unknown bot %q", errInvalidBots, name)
+		}
+	}
+	if players := numSeats(gameOptions); players != len(bots) {
+		return nil, fmt.Errorf("%w: expected %d bots, got %d", errInvalidBots, players, len(bots))
+	}
+	r, err := s.createRoom(gameID, gameOptions, nil)
+	if err != nil {
+		return nil, err
+	}
+	r.exhibition = &exhibition{bots: bots, delay: delay}
+	for playerID, name := range bots {
+		r.bots[playerID] = s.botStrategies[name]()
+		// Strategies registered with long names just go unnamed in the game.
+		_ = r.gameState.SetPlayerProfile(playerID, name, "")
+	}
+	r.logger.Info("exhibition started", "bots", bots, "delay", delay)
+	go s.runExhibition(r)
+	return r, nil
+}
+
+// runExhibition lets the room's bots play one action per delay, until the game ends.
+func (s *server) runExhibition(r *room) {
+	ticker := time.NewTicker(r.exhibition.delay)
+	defer ticker.Stop()
+	for range ticker.C {
+		r.mu.Lock()
+		done := r.gameState.IsGameEnded || !r.playExhibitionTurn()
+		r.mu.Unlock()
+		if done {
+			s.closeRoomIfDone(r)
+			return
+		}
+	}
+}
+
+// playExhibitionTurn lets the first bot that can play one action, and sends the resulting
+// game state. It returns false if none could. Must be called with the room locked.
+func (r *room) playExhibitionTurn() bool {
+	for playerID := range r.players {
+		action := r.bots[playerID].ChooseAction(r.gameState.ToClientGameState(playerID))
+		if action == nil {
+			continue
+		}
+		if err := r.runAction(context.Background(), action, false); err != nil {
+			r.logger.Error("exhibition bot failed to play", "playerID", playerID, "err", err)
+			return false
+		}
+		r.gameStateChanged()
+		return true
+	}
+	r.logger.Error("no exhibition bot can play")
+	return false
+}
+
+// apiExhibition returns the exhibition game. Must be called with the room locked.
+func (r *room) apiExhibition() APIExhibition {
+	game := r.apiGame()
+	for i := range game.Players {
+		game.Players[i].Name = r.exhibition.bots[i]
+	}
+	return APIExhibition{Game: game, Bots: r.exhibition.bots}
+}
+
+// handleAPIExhibitions lists the exhibition games being played.
+func (s *server) handleAPIExhibitions(w http.ResponseWriter, r *http.Request) {
+	exhibitions := []APIExhibition{}
+	for _, room := range s.liveRooms() {
+		room.mu.Lock()
+		if room.exhibition != nil {
+			exhibitions = append(exhibitions, room.apiExhibition())
+		}
+		room.mu.Unlock()
+	}
+	writeJSON(w, exhibitions)
+}
+
+// handleAPIBotStrategies lists the bot strategies exhibition games may be played with.
+func (s *server) handleAPIBotStrategies(w http.ResponseWriter, r *http.Request) {
+	names := []string{}
+	for name := range s.botStrategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	writeJSON(w, names)
+}
+
+// handleAdminCreateExhibition starts a game between bots, for spectators to watch, e.g. for
+// demos, or to compare bots.
+func (s *server) handleAdminCreateExhibition(w http.ResponseWriter, r *http.Request) {
+	var newExhibition AdminExhibition
+	if err := json.NewDecoder(r.Body).Decode(&newExhibition); err != nil {
+		http.Error(w, "invalid exhibition", http.StatusBadRequest)
+		return
+	}
+	delay := DefaultExhibitionDelay
+	if newExhibition.Delay != "" {
+		d, err := time.ParseDuration(newExhibition.Delay)
+		if err != nil || d <= 0 {
+			http.Error(w, "invalid delay", http.StatusBadRequest)
+			return
+		}
+		delay = d
+	}
+	gameOptions, err := s.houseRules(newExhibition.Rules)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if newExhibition.ID == "" {
+		if newExhibition.ID, err = s.localGameID(newGameID()); err != nil {
+			s.logger.Error("failed to pick a game ID", "err", err)
+			http.Error(w, "failed to start the exhibition", http.StatusInternalServerError)
+			return
+		}
+	}
+	room, err := s.createExhibition(newExhibition.ID, newExhibition.Bots, gameOptions, delay)
+	switch {
+	case errors.Is(err, errGameExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, errGameElsewhere):
+		http.Error(w, err.Error(), http.StatusMisdirectedRequest)
+		return
+	case errors.Is(err, errServerFull):
+		writeServerFull(w)
+		return
+	case errors.Is(err, errInvalidGameID), errors.Is(err, errInvalidBots):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		s.logger.Error("failed to start an exhibition", "gameID", newExhibition.ID, "err", err)
+		http.Error(w, "failed to start the exhibition", http.StatusInternalServerError)
+		return
+	}
+
+	exhibition := room.apiExhibition()
+	room.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(exhibition)
+}
+
+// randomBot is BotStrategyRandom.
+type randomBot struct{}
+
+func (randomBot) ChooseAction(gs chinchon.ClientGameState) chinchon.Action {
+	actions, err := gs.Actions()
+	if err != nil {
+		return nil
+	}
+	candidates := []chinchon.Action{}
+	for _, action := range actions {
+		if name := action.GetName(); name != chinchon.RESIGN && name != chinchon.RESIGN_ROUND {
+			candidates = append(candidates, action)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[rand.Intn(len(candidates))]
+}
@@ -0,0 +1,179 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devblac/chinchon/chinchon"
+)
+
+// DefaultGamesPageSize is the number of games GET /api/games lists per page, unless asked
+// for more or fewer, up to maxGamesPageSize.
+const (
+	DefaultGamesPageSize = 100
+	maxGamesPageSize     = 1000
+)
+
+// Game states GET /api/games filters by.
+const (
+	GameStateActive   = "active"
+	GameStateFinished = "finished"
+)
+
+// gameFilter is which games GET /api/games lists, from its query parameters:
+//
+//   - player: only games with a player of that name
+//   - state: only active or finished games
+//   - rules: only games with those rules, as a partial chinchon.Rules in JSON, e.g.
+//     {"maxPoints":50}
+//   - since, until: only games last played in that time range (RFC 3339)
+//   - limit: how many games per page (DefaultGamesPageSize by default)
+//   - cursor: where the page starts, from the previous page's Link header
+type gameFilter struct {
+	player       string
+	state        string
+	rules        json.RawMessage
+	since, until time.Time
+	limit        int
+	after        *gameCursor
+}
+
+// gameCursor is the last game of a page, for the next page to start after it.
+type gameCursor struct {
+	updatedAt time.Time
+	id        string
+}
+
+// parseGameFilter returns the filter in the query parameters.
+func parseGameFilter(query url.Values) (gameFilter, error) {
+	filter := gameFilter{player: query.Get("player"), state: query.Get("state"), limit: DefaultGamesPageSize}
+	if filter.state != "" && filter.state != GameStateActive && filter.state != GameStateFinished {
+		return gameFilter{}, fmt.Errorf("invalid state %q, expected %v or %v", filter.state, GameStateActive, GameStateFinished)
+	}
+	if value := query.Get("rules"); value != "" {
+		var rules chinchon.Rules
+		decoder := json.NewDecoder(strings.NewReader(value))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&rules); err != nil {
+			return gameFilter{}, fmt.Errorf("invalid rules: %w", err)
+		}
+		filter.rules = json.RawMessage(value)
+	}
+	for name, t := range map[string]*time.Time{"since": &filter.since, "until": &filter.until} {
+		if value := query.Get(name); value != "" {
+			var err error
+			if *t, err = time.Parse(time.RFC3339, value); err != nil {
+				return gameFilter{}, fmt.Errorf("invalid %v, expected an RFC 3339 time", name)
+			}
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxGamesPageSize {
+			return gameFilter{}, fmt.Errorf("invalid limit, expected 1 to %d", maxGamesPageSize)
+		}
+		filter.limit = n
+	}
+	if value := query.Get("cursor"); value != "" {
+		cursor, err := decodeGameCursor(value)
+		if err != nil {
+			return gameFilter{}, errors.New("invalid cursor")
+		}
+		filter.after = &cursor
+	}
+	return filter, nil
+}
+
+// needsGame returns true if stored games must be loaded to be filtered, as their players
+// and rules aren't listed by the game store.
+func (f gameFilter) needsGame() bool {
+	return f.player != "" || f.rules != nil
+}
+
+// matchesListed returns true if the game may be listed, going by what the game store
+// lists of it.
+func (f gameFilter) matchesListed(game APIGame) bool {
+	switch {
+	case f.state == GameStateActive && game.Ended, f.state == GameStateFinished && !game.Ended:
+		return false
+	case !f.since.IsZero() && game.UpdatedAt.Before(f.since), !f.until.IsZero() && game.UpdatedAt.After(f.until):
+		return false
+	case f.after != nil && !gameListedBefore(f.after.updatedAt, f.after.id, game):
+		return false
+	}
+	return true
+}
+
+// matches returns true if the game should be listed.
+func (f gameFilter) matches(game APIGame) bool {
+	if !f.matchesListed(game) {
+		return false
+	}
+	if f.player != "" && !slices.ContainsFunc(game.Players, func(p APIPlayer) bool { return p.Name == f.player }) {
+		return false
+	}
+	if f.rules != nil {
+		// The game has the rules if setting them changes nothing.
+		if game.Rules == nil {
+			return false
+		}
+		rules := *game.Rules
+		if err := json.Unmarshal(f.rules, &rules); err != nil || rules != *game.Rules {
+			return false
+		}
+	}
+	return true
+}
+
+// page sorts the games, most recently played first, and returns the first page of them,
+// with the cursor of the next page if there are more.
+func (f gameFilter) page(games []APIGame) ([]APIGame, string) {
+	sort.Slice(games, func(i, j int) bool {
+		return gameListedBefore(*games[i].UpdatedAt, games[i].ID, games[j])
+	})
+	if len(games) <= f.limit {
+		return games, ""
+	}
+	last := games[f.limit-1]
+	return games[:f.limit], gameCursor{updatedAt: *last.UpdatedAt, id: last.ID}.encode()
+}
+
+// gameListedBefore returns true if the game last played at updatedAt with the ID is listed
+// before the other game.
+func gameListedBefore(updatedAt time.Time, id string, other APIGame) bool {
+	if !updatedAt.Equal(*other.UpdatedAt) {
+		return updatedAt.After(*other.UpdatedAt)
+	}
+	return id < other.ID
+}
+
+func (c gameCursor) encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(c.updatedAt.UnixNano(), 10) + "/" + c.id))
+}
+
+func decodeGameCursor(value string) (gameCursor, error) {
+	bs, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return gameCursor{}, err
+	}
+	nanos, id, ok := strings.Cut(string(bs), "/")
+	if !ok {
+		return gameCursor{}, errors.New("missing game ID")
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return gameCursor{}, err
+	}
+	return gameCursor{updatedAt: time.Unix(0, n), id: id}, nil
+}
@@ -0,0 +1,147 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listedStore is a game store that only lists its games.
+type listedStore struct {
+	games []GameInfo
+}
+
+func (s listedStore) SaveSnapshot(gameID string, seq int, snapshot []byte) error { return nil }
+func (s listedStore) AppendAction(gameID string, entry JournalEntry) error       { return nil }
+func (s listedStore) LoadGame(gameID string) ([]byte, int, []JournalEntry, error) {
+	return nil, 0, nil, ErrGameNotFound
+}
+func (s listedStore) ListGames() ([]GameInfo, error) { return s.games, nil }
+
+func TestGamesPage(t *testing.T) {
+	at := func(minute int) *time.Time {
+		t := time.Date(2024, 6, 23, 12, minute, 0, 0, time.UTC)
+		return &t
+	}
+	games := func() []APIGame {
+		return []APIGame{
+			{ID: "d", UpdatedAt: at(1)},
+			{ID: "c", UpdatedAt: at(2)},
+			{ID: "a", UpdatedAt: at(2)},
+			{ID: "b", UpdatedAt: at(2)},
+			{ID: "e", UpdatedAt: at(3)},
+		}
+	}
+	ids := func(games []APIGame) string {
+		var b strings.Builder
+		for _, game := range games {
+			b.WriteString(game.ID)
+		}
+		return b.String()
+	}
+
+	for _, test := range []struct {
+		limit int
+		pages []string
+	}{
+		{limit: 5, pages: []string{"eabcd"}},
+		{limit: 10, pages: []string{"eabcd"}},
+		{limit: 2, pages: []string{"ea", "bc", "d"}},
+		{limit: 3, pages: []string{"eab", "cd"}},
+		{limit: 1, pages: []string{"e", "a", "b", "c", "d"}},
+	} {
+		filter := gameFilter{limit: test.limit}
+		for i, expected := range test.pages {
+			listed := []APIGame{}
+			for _, game := range games() {
+				if filter.matchesListed(game) {
+					listed = append(listed, game)
+				}
+			}
+			page, next := filter.page(listed)
+			if ids(page) != expected {
+				t.Fatalf("Expected page %d of %d to be %v, got %v", i+1, test.limit, expected, ids(page))
+			}
+			if isLast := i == len(test.pages)-1; isLast != (next == "") {
+				t.Fatalf("Expected page %d of %d to have a next cursor only if it's not the last, got %q", i+1, test.limit, next)
+			}
+			if next != "" {
+				cursor, err := decodeGameCursor(next)
+				if err != nil {
+					t.Fatal(err)
+				}
+				filter.after = &cursor
+			}
+		}
+	}
+}
+
+func TestGameCursors(t *testing.T) {
+	cursor := gameCursor{updatedAt: time.Date(2024, 6, 23, 12, 0, 0, 5, time.UTC), id: "casa/1"}
+	decoded, err := decodeGameCursor(cursor.encode())
+	if err != nil || !decoded.updatedAt.Equal(cursor.updatedAt) || decoded.id != cursor.id {
+		t.Errorf("Expected the cursor to be decoded as it was, got %+v and %v", decoded, err)
+	}
+
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	for _, value := range []string{"not base64!", encode("1719144000"), encode("noon/casa"), encode("/casa"), encode("99999999999999999999/casa")} {
+		if _, err := parseGameFilter(url.Values{"cursor": {value}}); err == nil {
+			t.Errorf("Expected cursor %q to be rejected", value)
+		}
+	}
+}
+
+func TestAPIGamesLookahead(t *testing.T) {
+	s := New("0")
+	stored := []GameInfo{}
+	for i, id := range []string{"c", "b", "a"} {
+		stored = append(stored, GameInfo{ID: id, Ended: true, UpdatedAt: time.Date(2024, 6, 23, 12, 3-i, 0, 0, time.UTC)})
+	}
+	s.store = listedStore{games: stored}
+
+	list := func(query string) ([]APIGame, string) {
+		w := httptest.NewRecorder()
+		s.handleAPIGames(w, httptest.NewRequest(http.MethodGet, "/api/games?"+query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected the games to be listed, got %d", w.Code)
+		}
+		var games []APIGame
+		_ = json.NewDecoder(w.Body).Decode(&games)
+		return games, w.Header().Get("Link")
+	}
+	for _, test := range []struct {
+		limit    string
+		games    int
+		hasNext  bool
+		nextPage int
+	}{
+		{limit: "2", games: 2, hasNext: true, nextPage: 1},
+		{limit: "3", games: 3},
+		{limit: "4", games: 3},
+	} {
+		games, link := list("state=finished&limit=" + test.limit)
+		if len(games) != test.games || (link != "") != test.hasNext {
+			t.Errorf("Expected %d games and a next page %v with limit %v, got %d and %q", test.games, test.hasNext, test.limit, len(games), link)
+			continue
+		}
+		if !test.hasNext {
+			continue
+		}
+		next, err := url.Parse(strings.TrimSuffix(strings.TrimPrefix(link, "<"), `>; rel="next"`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		games, link = list(next.RawQuery)
+		if len(games) != test.nextPage || games[0].ID != "a" || link != "" {
+			t.Errorf("Expected the next page to have the last game, got %+v and %q", games, link)
+		}
+	}
+}
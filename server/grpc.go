@@ -0,0 +1,273 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/devblac/chinchon/chinchonpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// WithGRPC also serves games over gRPC on the port (see chinchonpb.GameService), which is
+// easier to use than the WebSocket protocol from languages other than Go. gRPC players
+// always need the session token JoinGame gives them, even without WithAuth.
+func WithGRPC(port string) Option {
+	return func(s *server) {
+		s.grpcPort = port
+	}
+}
+
+// startGRPC serves the gRPC service in the background, over TLS if configured.
+func (s *server) startGRPC(tlsConfig *tls.Config) {
+	lis, err := net.Listen("tcp", ":"+s.grpcPort)
+	if err != nil {
+		fatal(s.logger, "failed to listen for gRPC", "err", err)
+	}
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(s.grpcRejectBannedUnary),
+		grpc.StreamInterceptor(s.grpcRejectBannedStream),
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	s.grpcServer = grpc.NewServer(opts...)
+	chinchonpb.RegisterGameServiceServer(s.grpcServer, &grpcService{s: s})
+	go func() {
+		s.logger.Info("gRPC server running", "port", s.grpcPort)
+		if err := s.grpcServer.Serve(lis); err != nil {
+			s.logger.Error("gRPC server stopped", "err", err)
+		}
+	}()
+}
+
+type grpcService struct {
+	chinchonpb.UnimplementedGameServiceServer
+	s *server
+}
+
+func (g *grpcService) JoinGame(ctx context.Context, req *chinchonpb.JoinGameRequest) (*chinchonpb.JoinGameResponse, error) {
+	s := g.s
+	// gRPC clients aren't redirected, but told which node of the cluster to call instead.
+	if err := s.checkHosted(req.GetGameId()); err != nil {
+		return nil, grpcStatus(err)
+	}
+	// Tokens were only issued to players who got in.
+	checksPassword, wasProtected := req.GetToken() == "", false
+	if checksPassword {
+		hash := s.passwords.hash(cmp.Or(req.GetGameId(), DefaultGameID))
+		if err := checkPassword(hash, req.GetPassword()); err != nil {
+			return nil, grpcStatus(err)
+		}
+		wasProtected = hash != nil
+	}
+	r, err := s.room(req.GetGameId())
+	if err != nil {
+		return nil, grpcStatus(err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if checksPassword {
+		if err := s.passwords.recheck(r.id, wasProtected, req.GetPassword()); err != nil {
+			return nil, grpcStatus(err)
+		}
+	}
+
+	playerID := int(req.GetPlayerId())
+	if playerID < 0 || playerID >= len(r.players) {
+		return nil, status.Errorf(codes.InvalidArgument, "%v: %d", errInvalidSeat, playerID)
+	}
+	// Connected players may only be joined by themselves, e.g. to get their state.
+	if r.bots[playerID] != nil || (r.players[playerID] != nil && req.GetToken() == "") {
+		return nil, status.Errorf(codes.AlreadyExists, "%v: %d", errSeatTaken, playerID)
+	}
+	token, err := s.authenticate(r, playerID, req.GetToken())
+	if err != nil {
+		return nil, grpcStatus(err)
+	}
+	if token == "" {
+		token = req.GetToken()
+	}
+
+	state, err := chinchonpb.FromClientGameState(r.gameState.ToClientGameState(playerID))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &chinchonpb.JoinGameResponse{Token: token, State: state}, nil
+}
+
+func (g *grpcService) StreamState(req *chinchonpb.StreamStateRequest, stream chinchonpb.GameService_StreamStateServer) error {
+	s := g.s
+	if req.GetToken() == "" {
+		return status.Error(codes.InvalidArgument, "a session token from JoinGame is required")
+	}
+	playerID := int(req.GetPlayerId())
+	if err := s.checkHosted(req.GetGameId()); err != nil {
+		return grpcStatus(err)
+	}
+
+	conn := &grpcConnection{stream: stream, closed: make(chan struct{})}
+	out := newConnectionOutbox(conn, s.sendQueue)
+	// The stream may only be sent on until this returns.
+	defer func() {
+		out.Close()
+		<-out.done
+	}()
+	room, _, err := s.join(req.GetGameId(), playerID, req.GetToken(), "", out, s.gameOptions)
+	if err != nil {
+		return grpcStatus(err)
+	}
+	defer s.closeRoomIfDone(room)
+	s.stats.playerConnected()
+	defer s.stats.playerDisconnected()
+	room.playerConnected(playerID, out)
+	room.mu.Unlock()
+
+	select {
+	case <-stream.Context().Done():
+		room.logger.Info("player closed their gRPC stream, freeing slot", "playerID", playerID)
+	case <-conn.closed:
+	}
+	s.playerDisconnected(room, playerID, out)
+	return conn.status
+}
+
+func (g *grpcService) SubmitAction(ctx context.Context, req *chinchonpb.SubmitActionRequest) (*chinchonpb.SubmitActionResponse, error) {
+	s := g.s
+	room, ok := s.existingRoom(req.GetGameId())
+	if !ok {
+		return nil, status.Error(codes.NotFound, "game isn't being played")
+	}
+	action, err := chinchonpb.ToAction(req.GetAction())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "a session token from JoinGame is required")
+	}
+
+	room.mu.Lock()
+	defer room.mu.Unlock()
+	playerID := action.GetPlayerID()
+	if _, err := s.authenticate(room, playerID, req.GetToken()); err != nil {
+		return nil, grpcStatus(err)
+	}
+	room.playerIsBack(playerID)
+	if err := room.runAction(ctx, action, false); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	_, span := room.startSpan(ctx, "room.gameStateChanged")
+	room.gameStateChanged()
+	span.End()
+
+	state, err := chinchonpb.FromClientGameState(room.gameState.ToClientGameState(playerID))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &chinchonpb.SubmitActionResponse{State: state}, nil
+}
+
+// grpcRejectBannedUnary fails calls from banned IP addresses (see WithAdminToken).
+func (s *server) grpcRejectBannedUnary(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := s.grpcCheckBanned(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *server) grpcRejectBannedStream(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.grpcCheckBanned(stream.Context()); err != nil {
+		return err
+	}
+	return handler(srv, stream)
+}
+
+func (s *server) grpcCheckBanned(ctx context.Context) error {
+	if p, ok := peer.FromContext(ctx); ok && s.bans.banned(p.Addr.String()) {
+		return status.Error(codes.PermissionDenied, "banned")
+	}
+	return nil
+}
+
+// grpcStatus converts an error joining a game to a gRPC status.
+func grpcStatus(err error) error {
+	switch {
+	case errors.Is(err, errInvalidGameID), errors.Is(err, errInvalidSeat):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, errSeatTaken):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, errInvalidToken), errors.Is(err, errSeatClaimed), errors.Is(err, errWrongPassword):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, errGameElsewhere):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, errServerFull):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// grpcConnection is a player's StreamState call, which only streams game states. It's
+// closed once the game ends.
+type grpcConnection struct {
+	stream chinchonpb.GameService_StreamStateServer
+
+	once   sync.Once
+	closed chan struct{}
+	status error // what the call returns, once closed
+}
+
+func (c *grpcConnection) Send(message any) error {
+	msg, ok := message.(MessageHeresGameState)
+	if !ok {
+		return nil
+	}
+	state, err := msg.Deserialize()
+	if err != nil {
+		return err
+	}
+	pbState, err := chinchonpb.FromClientGameState(state)
+	if err != nil {
+		return err
+	}
+	if err := c.stream.Send(pbState); err != nil {
+		return err
+	}
+	if state.IsGameEnded {
+		c.close(nil)
+	}
+	return nil
+}
+
+func (c *grpcConnection) SendClose(closeCode int, text string) error {
+	c.close(status.Error(codes.Unavailable, text))
+	return nil
+}
+
+func (c *grpcConnection) Close() error {
+	c.close(status.Error(codes.Unavailable, "connection closed"))
+	return nil
+}
+
+func (c *grpcConnection) RemoteAddr() net.Addr {
+	if p, ok := peer.FromContext(c.stream.Context()); ok {
+		return p.Addr
+	}
+	return &net.TCPAddr{}
+}
+
+func (c *grpcConnection) close(err error) {
+	c.once.Do(func() {
+		c.status = err
+		close(c.closed)
+	})
+}
@@ -0,0 +1,158 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/devblac/chinchon/chinchonpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newGRPCClient serves the server's gRPC service in memory, returning a client of it.
+func newGRPCClient(t *testing.T, s *server) chinchonpb.GameServiceClient {
+	lis := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	chinchonpb.RegisterGameServiceServer(grpcServer, &grpcService{s: s})
+	go func() { _ = grpcServer.Serve(lis) }()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return chinchonpb.NewGameServiceClient(conn)
+}
+
+func TestGRPCPassword(t *testing.T) {
+	s := New("0", WithGRPC("0"))
+	hash, err := hashPassword("s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.passwords.set("casa", hash)
+	client := newGRPCClient(t, s)
+	ctx := context.Background()
+
+	for _, password := range []string{"", "wrong"} {
+		_, err := client.JoinGame(ctx, &chinchonpb.JoinGameRequest{GameId: "casa", PlayerId: 0, Password: password})
+		if status.Code(err) != codes.PermissionDenied {
+			t.Errorf("Expected password %q to be refused, got %v", password, err)
+		}
+	}
+	joined, err := client.JoinGame(ctx, &chinchonpb.JoinGameRequest{GameId: "casa", PlayerId: 0, Password: "s3cret"})
+	if err != nil || joined.GetToken() == "" {
+		t.Fatalf("Expected the password to let the player in with a token, got %v", err)
+	}
+	if _, err := client.JoinGame(ctx, &chinchonpb.JoinGameRequest{GameId: "casa", PlayerId: 0, Token: joined.GetToken()}); err != nil {
+		t.Errorf("Expected the player to rejoin with their token alone, got %v", err)
+	}
+}
+
+func TestGRPCGame(t *testing.T) {
+	s := New("0", WithGRPC("0"), WithAuth([]byte("secret")))
+	client := newGRPCClient(t, s)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tokens := map[int32]string{}
+	var state *chinchonpb.ClientGameState
+	for _, playerID := range []int32{0, 1} {
+		joined, err := client.JoinGame(ctx, &chinchonpb.JoinGameRequest{GameId: "casa", PlayerId: playerID})
+		if err != nil || joined.GetToken() == "" {
+			t.Fatalf("Expected player %d to join with a token, got %v", playerID, err)
+		}
+		tokens[playerID] = joined.GetToken()
+		state = joined.GetState()
+	}
+	for _, test := range []struct {
+		req  *chinchonpb.JoinGameRequest
+		code codes.Code
+	}{
+		{req: &chinchonpb.JoinGameRequest{GameId: "casa", PlayerId: 2}, code: codes.InvalidArgument},
+		{req: &chinchonpb.JoinGameRequest{GameId: "no/pe", PlayerId: 0}, code: codes.InvalidArgument},
+		{req: &chinchonpb.JoinGameRequest{GameId: "casa", PlayerId: 0}, code: codes.PermissionDenied},
+		{req: &chinchonpb.JoinGameRequest{GameId: "casa", PlayerId: 0, Token: tokens[1]}, code: codes.PermissionDenied},
+	} {
+		if _, err := client.JoinGame(ctx, test.req); status.Code(err) != test.code {
+			t.Errorf("Expected joining %+v to fail with %v, got %v", test.req, test.code, err)
+		}
+	}
+
+	turn := state.GetTurnPlayerId()
+	stream, err := client.StreamState(ctx, &chinchonpb.StreamStateRequest{GameId: "casa", PlayerId: turn, Token: tokens[turn]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamed, err := stream.Recv()
+	if err != nil || streamed.GetYouPlayerId() != turn {
+		t.Fatalf("Expected the player's state to be streamed, got %v", err)
+	}
+	if _, err := client.JoinGame(ctx, &chinchonpb.JoinGameRequest{GameId: "casa", PlayerId: turn}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("Expected the connected player's seat to be taken, got %v", err)
+	}
+
+	action := streamed.GetPossibleActions()[0]
+	for _, test := range []struct {
+		req  *chinchonpb.SubmitActionRequest
+		code codes.Code
+	}{
+		{req: &chinchonpb.SubmitActionRequest{GameId: "mesa", Token: tokens[turn], Action: action}, code: codes.NotFound},
+		{req: &chinchonpb.SubmitActionRequest{GameId: "casa", Action: action}, code: codes.InvalidArgument},
+		{req: &chinchonpb.SubmitActionRequest{GameId: "casa", Token: tokens[1-turn], Action: action}, code: codes.PermissionDenied},
+		{req: &chinchonpb.SubmitActionRequest{GameId: "casa", Token: tokens[turn], Action: &chinchonpb.Action{Name: "fly", PlayerId: turn}}, code: codes.InvalidArgument},
+		{req: &chinchonpb.SubmitActionRequest{GameId: "casa", Token: tokens[1-turn], Action: &chinchonpb.Action{Name: action.GetName(), PlayerId: 1 - turn}}, code: codes.FailedPrecondition},
+	} {
+		if _, err := client.SubmitAction(ctx, test.req); status.Code(err) != test.code {
+			t.Errorf("Expected submitting %+v to fail with %v, got %v", test.req, test.code, err)
+		}
+	}
+
+	submitted, err := client.SubmitAction(ctx, &chinchonpb.SubmitActionRequest{GameId: "casa", Token: tokens[turn], Action: action})
+	if err != nil || submitted.GetState().GetLastActionLog().GetAction().GetName() != action.GetName() {
+		t.Fatalf("Expected the action to be played, got %v", err)
+	}
+	for {
+		streamed, err = stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if streamed.GetLastActionLog().GetAction().GetName() == action.GetName() {
+			break
+		}
+	}
+}
+
+func TestGRPCStatus(t *testing.T) {
+	for _, test := range []struct {
+		err  error
+		code codes.Code
+	}{
+		{err: errInvalidGameID, code: codes.InvalidArgument},
+		{err: fmt.Errorf("%w: %d", errInvalidSeat, 7), code: codes.InvalidArgument},
+		{err: errSeatTaken, code: codes.AlreadyExists},
+		{err: errInvalidToken, code: codes.PermissionDenied},
+		{err: errSeatClaimed, code: codes.PermissionDenied},
+		{err: errWrongPassword, code: codes.PermissionDenied},
+		{err: errGameElsewhere, code: codes.FailedPrecondition},
+		{err: errServerFull, code: codes.ResourceExhausted},
+		{err: errors.New("disk full"), code: codes.Internal},
+	} {
+		if code := status.Code(grpcStatus(test.err)); code != test.code {
+			t.Errorf("Expected %v to be %v, got %v", test.err, test.code, code)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"net/http"
+
+	"github.com/devblac/chinchon/chinchon"
+)
+
+// handleHandHistory serves the hand history of the game given by the game query parameter
+// (the default game if empty), live or stored, as Markdown, in the language given by the
+// locale one. Only finished games have one: the hands dealt in the round being played
+// would give them away.
+func (s *server) handleHandHistory(w http.ResponseWriter, r *http.Request) {
+	gameID := r.URL.Query().Get("game")
+	catalog := chinchon.NewCatalog(r.URL.Query().Get("locale"))
+	var history string
+	if room, ok := s.existingRoom(gameID); ok {
+		room.mu.Lock()
+		if room.gameState.IsGameEnded {
+			history = catalog.HandHistory(*room.gameState)
+		}
+		room.mu.Unlock()
+	} else if gs, err := s.storedGame(gameID); err == nil && gs.IsGameEnded {
+		// Games that aren't being played, e.g. finished ones, may be in the game store.
+		history = catalog.HandHistory(*gs)
+	}
+	if history == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	_, _ = w.Write([]byte(history))
+}
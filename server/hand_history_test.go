@@ -0,0 +1,40 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/devblac/chinchon/chinchon"
+)
+
+func TestHandHistoryOfLiveGames(t *testing.T) {
+	s := New("0")
+	r, err := s.room("casa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	history := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.handleHandHistory(w, httptest.NewRequest(http.MethodGet, "/history?game=casa", nil))
+		return w
+	}
+
+	r.mu.Lock()
+	hand := chinchon.NewCatalog("").HandHistory(*r.gameState)
+	r.mu.Unlock()
+	if w := history(); w.Code != http.StatusNotFound || strings.Contains(w.Body.String(), hand) {
+		t.Errorf("Expected the hands of the round being played not to be served, got %d %q", w.Code, w.Body.String())
+	}
+
+	r.mu.Lock()
+	r.gameState.Abort()
+	r.mu.Unlock()
+	if w := history(); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), hand) {
+		t.Errorf("Expected the hand history of the finished game, got %d %q", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,90 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"context"
+	"sort"
+
+	"github.com/devblac/chinchon/chinchon"
+	"github.com/devblac/chinchon/examplebot/newbot"
+)
+
+// WithHostedBots seats the example bot as the given players in every game, playing from
+// within the server, so that a single human can play right away. Humans can't take the
+// bots' seats, and the lobby only matches players into the other ones. Games against
+// hosted bots aren't rated.
+func WithHostedBots(playerIDs ...int) Option {
+	return func(s *server) {
+		s.hostedBots = append(s.hostedBots, playerIDs...)
+	}
+}
+
+// newHostedBots returns the bots for a new game, by player ID.
+func (s *server) newHostedBots() map[int]chinchon.Bot {
+	bots := map[int]chinchon.Bot{}
+	for _, playerID := range s.hostedBots {
+		bots[playerID] = newbot.New()
+	}
+	return bots
+}
+
+// humanSeatsFor returns the player IDs of the seats that aren't taken by hosted bots.
+func (s *server) humanSeatsFor(seats int, hostedBots []int) []int {
+	isBot := map[int]bool{}
+	for _, playerID := range hostedBots {
+		if playerID < 0 || playerID >= seats {
+			fatal(s.logger, "invalid hosted bot player ID", "playerID", playerID, "players", seats)
+		}
+		isBot[playerID] = true
+	}
+	humanSeats := []int{}
+	for playerID := 0; playerID < seats; playerID++ {
+		if !isBot[playerID] {
+			humanSeats = append(humanSeats, playerID)
+		}
+	}
+	if len(humanSeats) == 0 {
+		fatal(s.logger, "every seat is taken by a hosted bot")
+	}
+	return humanSeats
+}
+
+// runHostedBotTurns lets the hosted bots play for as long as any of them can, returning
+// true if any did. Must be called with the room locked.
+func (r *room) runHostedBotTurns() bool {
+	playerIDs := []int{}
+	for playerID := range r.bots {
+		playerIDs = append(playerIDs, playerID)
+	}
+	sort.Ints(playerIDs)
+
+	playedAny := false
+	for played := true; played && !r.gameState.IsGameEnded; {
+		played = false
+		for _, playerID := range playerIDs {
+			action := r.bots[playerID].ChooseAction(r.gameState.ToClientGameState(playerID))
+			if action == nil {
+				continue
+			}
+			if err := r.runAction(context.Background(), action, false); err != nil {
+				r.logger.Error("hosted bot failed to play", "playerID", playerID, "err", err)
+				return playedAny
+			}
+			played, playedAny = true, true
+		}
+	}
+	return playedAny
+}
+
+// runBotTurns lets the hosted bots, and those standing in for idle players, play until
+// it's a human's turn. Exhibition bots play at their own pace instead. Must be called
+// with the room locked.
+func (r *room) runBotTurns() {
+	if r.exhibition != nil {
+		return
+	}
+	for r.runHostedBotTurns() || r.runTakeoverTurns() {
+	}
+}
@@ -0,0 +1,154 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/devblac/chinchon/chinchon"
+)
+
+var errGameExists = errors.New("game already exists")
+
+// APINewGame is the body of POST /api/games.
+type APINewGame struct {
+	// ID is the new game's ID, or a random one if empty.
+	ID string `json:"id,omitempty"`
+
+	// Rules are the game's house rules, if any (see houseRules).
+	Rules json.RawMessage `json:"rules,omitempty"`
+
+	// Password, if any, is asked of players and spectators to join the game, and of anyone
+	// starting a game with its ID again once it ends.
+	Password string `json:"password,omitempty"`
+}
+
+// houseRules returns the options for a game with the house rules: a partial chinchon.Rules,
+// as JSON, whose fields override the server's rules (see WithGameOptions). Without any,
+// they're the server's options. Unknown rules are rejected rather than ignored, jokers
+// included, as the engine doesn't support them.
+func (s *server) houseRules(raw json.RawMessage) ([]func(*chinchon.GameState), error) {
+	if len(raw) == 0 {
+		return s.gameOptions, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err == nil {
+		if _, ok := fields["jokers"]; ok {
+			return nil, errors.New("invalid rules: jokers aren't supported")
+		}
+	}
+	rules := chinchon.New(s.gameOptions...).Rules()
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("invalid rules: %w", err)
+	}
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	for _, playerID := range s.hostedBots {
+		if playerID >= rules.Players {
+			return nil, fmt.Errorf("invalid rules: the server's bots play as player %d", playerID+1)
+		}
+	}
+	return rules.Options(), nil
+}
+
+// createRoom starts a game with the options, failing if it's being played or stored
+// unfinished, or hosted by another node of the cluster. The room is returned locked, for it
+// to be set up before anyone joins it, and protected by the password hash, if any.
+func (s *server) createRoom(gameID string, gameOptions []func(*chinchon.GameState), passwordHash []byte) (*room, error) {
+	if !validGameID.MatchString(gameID) {
+		return nil, fmt.Errorf("%w: %q", errInvalidGameID, gameID)
+	}
+	if err := s.checkHosted(gameID); err != nil {
+		return nil, err
+	}
+	s.roomsMu.Lock()
+	defer s.roomsMu.Unlock()
+	if _, ok := s.rooms[gameID]; ok {
+		return nil, errGameExists
+	}
+	if gs, err := s.storedGame(gameID); err == nil && !gs.IsGameEnded {
+		return nil, errGameExists
+	}
+	s.logger.Info("starting game", "gameID", gameID)
+	r, err := s.newRoom(gameID, gameOptions, passwordHash)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	return r, nil
+}
+
+// handleAPICreateGame starts a game, with house rules if given, responding with it so that
+// players can join it.
+func (s *server) handleAPICreateGame(w http.ResponseWriter, r *http.Request) {
+	var newGame APINewGame
+	if err := json.NewDecoder(r.Body).Decode(&newGame); err != nil {
+		http.Error(w, "invalid game", http.StatusBadRequest)
+		return
+	}
+	gameOptions, err := s.houseRules(newGame.Rules)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if newGame.ID == "" {
+		if newGame.ID, err = s.localGameID(newGameID()); err != nil {
+			s.logger.Error("failed to pick a game ID", "err", err)
+			http.Error(w, "failed to start the game", http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := s.passwords.check(newGame.ID, newGame.Password); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	var passwordHash []byte
+	if newGame.Password != "" {
+		if passwordHash, err = hashPassword(newGame.Password); err != nil {
+			http.Error(w, fmt.Sprintf("invalid password: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	room, err := s.createRoom(newGame.ID, gameOptions, passwordHash)
+	switch {
+	case errors.Is(err, errGameExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, errGameElsewhere):
+		http.Error(w, err.Error(), http.StatusMisdirectedRequest)
+		return
+	case errors.Is(err, errServerFull):
+		writeServerFull(w)
+		return
+	case errors.Is(err, errInvalidGameID):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		s.logger.Error("failed to start a game", "gameID", newGame.ID, "err", err)
+		http.Error(w, "failed to start the game", http.StatusInternalServerError)
+		return
+	}
+
+	game := room.apiGame()
+	room.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(game)
+}
+
+// newGameID returns a random ID for a game started without one.
+func newGameID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "game-" + hex.EncodeToString(b)
+}
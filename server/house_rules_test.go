@@ -0,0 +1,27 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestHouseRules(t *testing.T) {
+	s := New("0")
+	if _, err := s.houseRules(json.RawMessage(`{"maxPoints": 50, "chinchonMode": "wins_round"}`)); err != nil {
+		t.Errorf("Expected valid house rules to be accepted, got %v", err)
+	}
+	for _, rules := range []string{
+		`{"jokers": true}`,
+		`{"maxPoint": 50}`,
+		`{"maxPoints": -1}`,
+		`[]`,
+	} {
+		if _, err := s.houseRules(json.RawMessage(rules)); err == nil || !strings.HasPrefix(err.Error(), "invalid") {
+			t.Errorf("Expected house rules %v to be rejected, got %v", rules, err)
+		}
+	}
+}
@@ -0,0 +1,472 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devblac/chinchon/chinchon"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultSnapshotEvery is the number of journaled actions between snapshots.
+const DefaultSnapshotEvery = 50
+
+// ErrGameNotFound is returned by a GameStore for games it has never saved.
+var ErrGameNotFound = errors.New("game not found")
+
+// GameStore is a storage backend for games. Rather than writing the full state after every
+// action, games are stored as a snapshot plus an append-only log of the actions run since,
+// and snapshots are only taken every so often to keep recovery fast. The log is written
+// ahead: actions are appended as they're run, with the decks they dealt, so that games are
+// rebuilt exactly as they were played however long ago the last snapshot was.
+type GameStore interface {
+	// SaveSnapshot stores the game's state after its first seq actions. Entries up to seq
+	// may then be discarded.
+	SaveSnapshot(gameID string, seq int, snapshot []byte) error
+
+	// AppendAction appends an entry to the game's action log.
+	AppendAction(gameID string, entry JournalEntry) error
+
+	// LoadGame returns the game's last snapshot, the seq it was taken at, and the entries
+	// appended since. It returns ErrGameNotFound if there is no snapshot.
+	LoadGame(gameID string) (snapshot []byte, seq int, entries []JournalEntry, err error)
+
+	// ListGames returns every stored game, most recently snapshotted first.
+	ListGames() ([]GameInfo, error)
+}
+
+// GameInfo describes a stored game, as of its last snapshot. Games are snapshotted when
+// they end, so Ended is up to date.
+type GameInfo struct {
+	ID        string    `json:"id"`
+	Seq       int       `json:"seq"`
+	Ended     bool      `json:"ended"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// SnapshotEnded returns whether the game in the snapshot has ended, for GameStore
+// implementations to list it.
+func SnapshotEnded(snapshot []byte) bool {
+	var gs struct {
+		IsGameEnded bool `json:"isGameEnded"`
+	}
+	_ = json.Unmarshal(snapshot, &gs)
+	return gs.IsGameEnded
+}
+
+// withPasswordHash adds the game's password hash to its snapshot, if it has one. The
+// engine ignores it when loading the snapshot.
+func withPasswordHash(snapshot []byte, hash []byte) []byte {
+	if hash == nil || len(snapshot) < 2 || snapshot[0] != '{' {
+		return snapshot
+	}
+	field, _ := json.Marshal(hash)
+	field = append([]byte(`{"passwordHash":`), field...)
+	if snapshot[1] != '}' {
+		field = append(field, ',')
+	}
+	return append(field, snapshot[1:]...)
+}
+
+// snapshotPasswordHash returns the password hash saved in the snapshot, if any.
+func snapshotPasswordHash(snapshot []byte) []byte {
+	var protected struct {
+		PasswordHash []byte `json:"passwordHash"`
+	}
+	_ = json.Unmarshal(snapshot, &protected)
+	return protected.PasswordHash
+}
+
+// sortGames sorts games from the most recently snapshotted.
+func sortGames(games []GameInfo) {
+	sort.Slice(games, func(i, j int) bool {
+		if !games[i].UpdatedAt.Equal(games[j].UpdatedAt) {
+			return games[i].UpdatedAt.After(games[j].UpdatedAt)
+		}
+		return games[i].ID < games[j].ID
+	})
+}
+
+// JournalEntry is an action in a game's action log.
+type JournalEntry struct {
+	// Seq is the position of the action in the game, starting from 1.
+	Seq         int             `json:"seq"`
+	Action      json.RawMessage `json:"action"`
+	PlayedByBot bool            `json:"playedByBot,omitempty"`
+
+	// Deals are the decks the action dealt, if it started a round or refilled the draw
+	// pile, for it to deal them again when replayed.
+	Deals []chinchon.Deal `json:"deals,omitempty"`
+}
+
+// WithGameStore persists the hosted games in the store, each one under its game ID, and
+// resumes them from there when they're joined again. A snapshot is taken every
+// snapshotEvery actions.
+func WithGameStore(store GameStore, snapshotEvery int) Option {
+	return func(s *server) {
+		s.store, s.snapshotEvery = store, snapshotEvery
+	}
+}
+
+// HostedGameID is the ID under which the server's default game is stored.
+const HostedGameID = "main"
+
+// gameJournal writes a game's actions to its store as they are run.
+type gameJournal struct {
+	store         GameStore
+	gameID        string
+	snapshotEvery int
+	tracer        trace.Tracer // if nil, the global one
+
+	seq         int // Number of actions run in the game
+	snapshotSeq int // Number of actions run when the last snapshot was taken
+
+	// passwordHash is the game's password hash, if any, saved with its snapshots.
+	passwordHash []byte
+
+	// dealRound and dealRefills are the round the game was in after its last journaled
+	// action, and the number of refills of its draw pile by then.
+	dealRound   int
+	dealRefills int
+}
+
+// RestoreGame loads a game from the store, e.g. to inspect the server's hosted game (stored
+// as HostedGameID) while it's not running.
+func RestoreGame(store GameStore, gameID string) (*chinchon.GameState, error) {
+	j := &gameJournal{store: store, gameID: gameID}
+	return j.restore()
+}
+
+// restore loads the journaled game, replaying the actions after its last snapshot.
+func (j *gameJournal) restore() (*chinchon.GameState, error) {
+	_, span := j.startSpan(context.Background(), "store.LoadGame")
+	snapshot, seq, entries, err := j.store.LoadGame(j.gameID)
+	endSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+
+	gs, err := chinchon.Load(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt snapshot: %w", err)
+	}
+	if j.passwordHash == nil {
+		j.passwordHash = snapshotPasswordHash(snapshot)
+	}
+	j.seq, j.snapshotSeq = seq, seq
+	j.markDeals(gs)
+	for _, entry := range entries {
+		if entry.Seq <= j.seq {
+			continue // Already in the snapshot
+		}
+		if err := j.apply(gs, entry); err != nil {
+			return nil, err
+		}
+	}
+	return gs, nil
+}
+
+// apply runs a journaled action on the game, dealing what it dealt when it was first run.
+func (j *gameJournal) apply(gs *chinchon.GameState, entry JournalEntry) error {
+	action, err := chinchon.DeserializeAction(entry.Action)
+	if err != nil {
+		return fmt.Errorf("corrupt action %d: %w", entry.Seq, err)
+	}
+	gs.StackDeals(entry.Deals...)
+	run := gs.RunAction
+	if entry.PlayedByBot {
+		run = gs.RunTakeoverAction
+	}
+	if err := run(action); err != nil {
+		return fmt.Errorf("failed to replay action %d: %w", entry.Seq, err)
+	}
+	j.seq = entry.Seq
+	j.markDeals(gs)
+	return nil
+}
+
+// markDeals notes how far the game has dealt, for the next journaled action's deals.
+func (j *gameJournal) markDeals(gs *chinchon.GameState) {
+	j.dealRound, j.dealRefills = gs.RoundNumber, len(gs.RoundsLog[gs.RoundNumber].Refills)
+}
+
+// record appends an action that was just run on the game, and snapshots the game when due.
+func (j *gameJournal) record(ctx context.Context, gs *chinchon.GameState, action chinchon.Action, playedByBot bool) error {
+	j.seq++
+	entry := JournalEntry{
+		Seq:         j.seq,
+		Action:      chinchon.SerializeAction(action),
+		PlayedByBot: playedByBot,
+		Deals:       gs.DealsSince(j.dealRound, j.dealRefills),
+	}
+	j.markDeals(gs)
+	_, span := j.startSpan(ctx, "store.AppendAction")
+	err := j.store.AppendAction(j.gameID, entry)
+	endSpan(span, err)
+	if err != nil {
+		return err
+	}
+	if j.seq-j.snapshotSeq >= j.snapshotEvery {
+		return j.snapshot(ctx, gs)
+	}
+	return nil
+}
+
+func (j *gameJournal) snapshot(ctx context.Context, gs *chinchon.GameState) error {
+	bs, err := gs.Save()
+	if err != nil {
+		return err
+	}
+	bs = withPasswordHash(bs, j.passwordHash)
+	_, span := j.startSpan(ctx, "store.SaveSnapshot")
+	err = j.store.SaveSnapshot(j.gameID, j.seq, bs)
+	endSpan(span, err)
+	if err != nil {
+		return err
+	}
+	j.snapshotSeq = j.seq
+	return nil
+}
+
+// restoreGame resumes the persisted game, or starts (and persists) a new one if there is
+// none, or if it has ended.
+func (r *room) restoreGame(gameOptions []func(*chinchon.GameState)) (*chinchon.GameState, error) {
+	gs, err := r.journal.restore()
+	if errors.Is(err, ErrGameNotFound) || (err == nil && gs.IsGameEnded) {
+		// A new game continues the ended one's seqs, so that its actions are never mixed up
+		// with the ended one's.
+		r.journal.seq++
+		if err := r.journal.snapshot(context.Background(), chinchon.New(gameOptions...)); err != nil {
+			return nil, err
+		}
+		// Another server instance sharing the store may have started it first.
+		return r.journal.restore()
+	}
+	return gs, err
+}
+
+// recoverGames resumes every unfinished game in the game store, e.g. after a crash, so that
+// hosted bots and turn timers carry on without waiting for someone to join them.
+func (s *server) recoverGames() {
+	games, err := s.store.ListGames()
+	if err != nil {
+		s.logger.Error("failed to list stored games to recover", "err", err)
+		return
+	}
+	recovered := 0
+	for _, game := range games {
+		if game.Ended || !validGameID.MatchString(game.ID) {
+			continue
+		}
+		if hosted, err := s.hostsGame(game.ID); err != nil || !hosted {
+			continue // Recovered by the node of the cluster hosting it
+		}
+		if _, err := s.room(game.ID); err != nil {
+			s.logger.Error("failed to recover game", "gameID", game.ID, "err", err)
+			continue
+		}
+		recovered++
+	}
+	s.logger.Info("recovered unfinished games", "games", recovered)
+}
+
+// storedGame loads a game from the game store, if the server has one.
+func (s *server) storedGame(gameID string) (*chinchon.GameState, error) {
+	if gameID == "" {
+		gameID = DefaultGameID
+	}
+	if s.store == nil {
+		return nil, ErrGameNotFound
+	}
+	return RestoreGame(s.store, gameID)
+}
+
+// handleStoredGames lists the games in the game store, finished or not.
+func (s *server) handleStoredGames(w http.ResponseWriter, r *http.Request) {
+	if s.store == nil {
+		writeJSON(w, []GameInfo{})
+		return
+	}
+	games, err := s.store.ListGames()
+	if err != nil {
+		s.logger.Error("failed to list stored games", "err", err)
+		http.Error(w, "failed to list games", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, games)
+}
+
+// FileGameStore is a GameStore keeping each game in two files in a directory: the last
+// snapshot, and the action log since, in JSON lines. Both are flushed to disk before
+// writes return, so that they survive the machine crashing too.
+type FileGameStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewFileGameStore creates a store in the directory, creating it if needed.
+func NewFileGameStore(dir string) (*FileGameStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileGameStore{dir: dir}, nil
+}
+
+type fileSnapshot struct {
+	Seq   int             `json:"seq"`
+	State json.RawMessage `json:"state"`
+}
+
+func (f *FileGameStore) SaveSnapshot(gameID string, seq int, snapshot []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bs, err := json.Marshal(fileSnapshot{Seq: seq, State: snapshot})
+	if err != nil {
+		return err
+	}
+	// Write then rename, so that a crash never leaves a partial snapshot.
+	tmp := f.snapshotPath(gameID) + ".tmp"
+	if err := writeFileSynced(tmp, bs); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, f.snapshotPath(gameID)); err != nil {
+		return err
+	}
+	// Older entries are skipped on load anyway, so failing to truncate is harmless.
+	if err := os.Truncate(f.actionsPath(gameID), 0); err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("failed to truncate action log", "gameID", gameID, "err", err)
+	}
+	return nil
+}
+
+func (f *FileGameStore) AppendAction(gameID string, entry JournalEntry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bs, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.actionsPath(gameID), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err := file.Write(append(bs, '\n')); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+func (f *FileGameStore) LoadGame(gameID string) ([]byte, int, []JournalEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bs, err := os.ReadFile(f.snapshotPath(gameID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, 0, nil, ErrGameNotFound
+	}
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	var snapshot fileSnapshot
+	if err := json.Unmarshal(bs, &snapshot); err != nil {
+		return nil, 0, nil, err
+	}
+
+	entries := []JournalEntry{}
+	file, err := os.Open(f.actionsPath(gameID))
+	if errors.Is(err, os.ErrNotExist) {
+		return snapshot.State, snapshot.Seq, entries, nil
+	}
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A crash may leave the last line half written.
+			slog.Warn("ignoring corrupt action log entry", "gameID", gameID, "err", err)
+			break
+		}
+		entries = append(entries, entry)
+	}
+	return snapshot.State, snapshot.Seq, entries, scanner.Err()
+}
+
+func (f *FileGameStore) ListGames() ([]GameInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	paths, err := filepath.Glob(filepath.Join(f.dir, "*.snapshot.json"))
+	if err != nil {
+		return nil, err
+	}
+	games := []GameInfo{}
+	for _, path := range paths {
+		bs, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		var snapshot fileSnapshot
+		if err := json.Unmarshal(bs, &snapshot); err != nil {
+			slog.Warn("ignoring corrupt snapshot", "path", path, "err", err)
+			continue
+		}
+		games = append(games, GameInfo{
+			ID:        strings.TrimSuffix(filepath.Base(path), ".snapshot.json"),
+			Seq:       snapshot.Seq,
+			Ended:     SnapshotEnded(snapshot.State),
+			UpdatedAt: info.ModTime(),
+		})
+	}
+	sortGames(games)
+	return games, nil
+}
+
+func (f *FileGameStore) snapshotPath(gameID string) string {
+	return filepath.Join(f.dir, gameID+".snapshot.json")
+}
+
+func (f *FileGameStore) actionsPath(gameID string) string {
+	return filepath.Join(f.dir, gameID+".actions.jsonl")
+}
+
+// writeFileSynced is os.WriteFile, flushing the file to disk before returning.
+func writeFileSynced(path string, bs []byte) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(bs); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
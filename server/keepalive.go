@@ -0,0 +1,112 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultPingInterval is how often the server pings WebSocket connections, to tell those
+// whose other end is gone from those that are just quiet.
+const DefaultPingInterval = 20 * time.Second
+
+// controlWriteTimeout is how long writing a ping or pong may take.
+const controlWriteTimeout = 5 * time.Second
+
+// WithPingInterval changes how often the server pings WebSocket connections. Connections
+// that don't answer a ping before the next one is due are closed, which frees the player's
+// seat, or holds it for them with WithReconnectGrace. Zero disables pings.
+func WithPingInterval(interval time.Duration) Option {
+	return func(s *server) {
+		s.pingInterval = interval
+	}
+}
+
+// Connection qualities, by round-trip time (see ConnectionQuality).
+const (
+	ConnectionGood = "good"
+	ConnectionFair = "fair"
+	ConnectionPoor = "poor"
+)
+
+// ConnectionQuality rates a connection by its round-trip time: good up to 150ms, fair up
+// to 400ms, and poor beyond.
+func ConnectionQuality(rtt time.Duration) string {
+	switch {
+	case rtt <= 150*time.Millisecond:
+		return ConnectionGood
+	case rtt <= 400*time.Millisecond:
+		return ConnectionFair
+	}
+	return ConnectionPoor
+}
+
+// keepAlive pings the connection until the returned function is called, and makes reads
+// from it fail once a pong is overdue. Pings may be sent while other goroutines write.
+//
+// Pings carry the time they were sent, so that onRTT, if any, is called with the round-trip
+// time of every pong. Pongs are handled while reading from the connection, so onRTT is
+// called by the reading goroutine.
+func (s *server) keepAlive(conn *websocket.Conn, onRTT func(time.Duration)) (stop func()) {
+	if s.pingInterval <= 0 {
+		return func() {}
+	}
+	pongWait := 2 * s.pingInterval
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(data string) error {
+		now := time.Now()
+		if sent, err := strconv.ParseInt(data, 10, 64); err == nil && onRTT != nil {
+			// Clients echo whatever they got, so the time is only trusted if it makes sense.
+			if rtt := now.Sub(time.Unix(0, sent)); rtt >= 0 && rtt <= pongWait {
+				onRTT(rtt)
+			}
+		}
+		return conn.SetReadDeadline(now.Add(pongWait))
+	})
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(s.pingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				now := time.Now()
+				sent := []byte(strconv.FormatInt(now.UnixNano(), 10))
+				if err := conn.WriteControl(websocket.PingMessage, sent, now.Add(controlWriteTimeout)); err != nil {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// wsClientKeepAlive answers the server's pings, and makes reads from the connection fail
+// once a ping is overdue, i.e. the server has been silent for twice the longest time
+// between pings so far. Until the second ping, there's no telling how often they come.
+func wsClientKeepAlive(conn *websocket.Conn) {
+	var lastPing time.Time
+	var longestGap time.Duration
+	conn.SetPingHandler(func(data string) error {
+		now := time.Now()
+		if !lastPing.IsZero() {
+			longestGap = max(longestGap, now.Sub(lastPing))
+			conn.SetReadDeadline(now.Add(2 * longestGap))
+		}
+		lastPing = now
+
+		err := conn.WriteControl(websocket.PongMessage, []byte(data), now.Add(controlWriteTimeout))
+		if errors.Is(err, websocket.ErrCloseSent) {
+			return nil
+		}
+		return err
+	})
+}
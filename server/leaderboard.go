@@ -0,0 +1,227 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/devblac/chinchon/rating"
+	"github.com/gorilla/mux"
+)
+
+// DefaultLeaderboardSize is the number of players the leaderboard shows, unless asked for
+// more or fewer.
+const DefaultLeaderboardSize = 100
+
+// WithLeaderboardFile saves the players' statistics (and their ratings, with WithRatings)
+// to the JSON file after every game, and restores them from it when the server starts.
+// Without it, they're lost on restart.
+func WithLeaderboardFile(path string) Option {
+	return func(s *server) {
+		s.leaderboardPath = path
+	}
+}
+
+// PlayerStats are the results of a player matched in the lobby, across the games they
+// finished.
+type PlayerStats struct {
+	Name       string `json:"name"`
+	Games      int    `json:"games"`
+	Wins       int    `json:"wins"`
+	Losses     int    `json:"losses"`
+	Chinchones int    `json:"chinchones"`
+
+	// Rounds are the rounds they played, and PointsConceded the points they scored in them,
+	// so AveragePointsConceded is per round.
+	Rounds                int     `json:"rounds"`
+	PointsConceded        int     `json:"pointsConceded"`
+	AveragePointsConceded float64 `json:"averagePointsConceded"`
+
+	// Rating is their rating's value, with WithRatings.
+	Rating float64 `json:"rating,omitempty"`
+}
+
+// leaderboard keeps the statistics of the players matched in the lobby.
+type leaderboard struct {
+	mu      sync.Mutex
+	path    string // where they're saved, if anywhere
+	players map[string]*PlayerStats
+	ratings *rating.Ratings
+}
+
+// leaderboardFile is how the leaderboard is saved.
+type leaderboardFile struct {
+	Players map[string]*PlayerStats  `json:"players"`
+	Ratings map[string]rating.Rating `json:"ratings,omitempty"`
+}
+
+// newLeaderboard returns the leaderboard saved at the path, if any, restoring the saved
+// ratings too.
+func newLeaderboard(path string, ratings *rating.Ratings) (*leaderboard, error) {
+	l := &leaderboard{path: path, players: map[string]*PlayerStats{}, ratings: ratings}
+	if path == "" {
+		return l, nil
+	}
+	bs, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file leaderboardFile
+	if err := json.Unmarshal(bs, &file); err != nil {
+		return nil, err
+	}
+	if file.Players != nil {
+		l.players = file.Players
+	}
+	if ratings != nil {
+		for name, r := range file.Ratings {
+			ratings.Set(name, r)
+		}
+	}
+	return l, nil
+}
+
+// record adds the room's ended game to the statistics of its named players, unless it was
+// aborted, and saves them. Must be called with the room locked, after the ratings are
+// updated.
+func (l *leaderboard) record(r *room) {
+	gs := r.gameState
+	if gs.IsGameAborted || len(r.playerNames) == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for playerID, name := range r.playerNames {
+		stats := l.players[name]
+		if stats == nil {
+			stats = &PlayerStats{Name: name}
+			l.players[name] = stats
+		}
+		stats.Games++
+		if gs.Players[playerID].Team == gs.WinnerTeamID {
+			stats.Wins++
+		} else {
+			stats.Losses++
+		}
+		// The game ended in its last round, so every round is finished.
+		for _, round := range gs.RoundsLog[1:] {
+			if round.WasChinchon && round.WinnerPlayerID == playerID {
+				stats.Chinchones++
+			}
+			stats.Rounds++
+			stats.PointsConceded += round.ScoreChanges[playerID]
+		}
+		if stats.Rounds > 0 {
+			stats.AveragePointsConceded = float64(stats.PointsConceded) / float64(stats.Rounds)
+		}
+	}
+	if err := l.save(); err != nil {
+		r.logger.Error("failed to save the leaderboard", "err", err)
+	}
+}
+
+// save writes the leaderboard to its file, if any. Must be called with it locked.
+func (l *leaderboard) save() error {
+	if l.path == "" {
+		return nil
+	}
+	file := leaderboardFile{Players: l.players}
+	if l.ratings != nil {
+		file.Ratings = l.ratings.All()
+	}
+	bs, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	// Write then rename, so that a crash never leaves a partial file.
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, bs, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
+
+// stats returns the player's statistics, with their rating.
+func (l *leaderboard) stats(name string) (PlayerStats, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stats, ok := l.players[name]
+	if !ok {
+		return PlayerStats{}, false
+	}
+	return l.withRating(*stats), true
+}
+
+func (l *leaderboard) withRating(stats PlayerStats) PlayerStats {
+	if l.ratings != nil {
+		stats.Rating = l.ratings.Get(stats.Name).Value
+	}
+	return stats
+}
+
+// top returns the first n players, by rating with WithRatings, or else by wins (and then
+// fewest losses).
+func (l *leaderboard) top(n int) []PlayerStats {
+	l.mu.Lock()
+	entries := []PlayerStats{}
+	for _, stats := range l.players {
+		entries = append(entries, l.withRating(*stats))
+	}
+	l.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		switch {
+		case a.Rating != b.Rating:
+			return a.Rating > b.Rating
+		case a.Wins != b.Wins:
+			return a.Wins > b.Wins
+		case a.Losses != b.Losses:
+			return a.Losses < b.Losses
+		}
+		return a.Name < b.Name
+	})
+	if n < len(entries) {
+		entries = entries[:n]
+	}
+	return entries
+}
+
+// handleAPILeaderboard serves the top players, as many as the limit query parameter says
+// (DefaultLeaderboardSize by default).
+func (s *server) handleAPILeaderboard(w http.ResponseWriter, r *http.Request) {
+	limit := DefaultLeaderboardSize
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	writeJSON(w, s.leaderboard.top(limit))
+}
+
+func (s *server) handleAPIPlayerStats(w http.ResponseWriter, r *http.Request) {
+	stats, ok := s.leaderboard.stats(mux.Vars(r)["id"])
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, stats)
+}
+
+// leaderboardMessage returns the leaderboard as a lobby message.
+func (s *server) leaderboardMessage() MessageHeresLeaderboard {
+	return NewMessageHeresLeaderboard(s.leaderboard.top(DefaultLeaderboardSize))
+}
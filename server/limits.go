@@ -0,0 +1,76 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryAfter is how long clients turned away by a full server (see WithMaxGames and
+// WithMaxConnections) are told to wait before trying again.
+const RetryAfter = 30 * time.Second
+
+var errServerFull = errors.New("server is full")
+
+// WithMaxGames caps the games being played at once. When there are that many, new games
+// aren't started: players are told to try again later (see ErrorCodeServerFull), and
+// those matched in the lobby wait there until a game ends.
+func WithMaxGames(games int) Option {
+	return func(s *server) {
+		s.maxGames = games
+	}
+}
+
+// WithMaxConnections caps the WebSocket connections of players, spectators and those in
+// the lobby at once. Connections over the cap are refused with 503 Service Unavailable and
+// a Retry-After header.
+func WithMaxConnections(connections int) Option {
+	return func(s *server) {
+		s.maxConnections = connections
+	}
+}
+
+// checkGameLimit returns errServerFull if no more games may be started. Must be called with
+// the server's rooms locked.
+func (s *server) checkGameLimit() error {
+	if s.maxGames > 0 && len(s.rooms) >= s.maxGames {
+		return fmt.Errorf("%w: %d games being played", errServerFull, len(s.rooms))
+	}
+	return nil
+}
+
+// limitConnections refuses connections over WithMaxConnections.
+func (s *server) limitConnections(next http.Handler) http.Handler {
+	if s.maxConnections <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Handlers return once the connection is closed.
+		if s.connections.Add(1) > int64(s.maxConnections) {
+			s.connections.Add(-1)
+			s.logger.Info("refusing connection, server is full", "remoteAddr", r.RemoteAddr)
+			writeServerFull(w)
+			return
+		}
+		defer s.connections.Add(-1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeServerFull responds 503 Service Unavailable, for the client to retry after
+// RetryAfter.
+func writeServerFull(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(RetryAfter.Seconds())))
+	http.Error(w, errServerFull.Error(), http.StatusServiceUnavailable)
+}
+
+func newServerFullMessage() MessageError {
+	msg := NewMessageError(ErrorCodeServerFull, "the server is full, try again later")
+	msg.RetryAfter = int(RetryAfter.Seconds())
+	return msg
+}
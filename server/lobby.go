@@ -0,0 +1,284 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/devblac/chinchon/chinchon"
+	"github.com/devblac/chinchon/rating"
+)
+
+// With ratings, players are only matched with others rated within matchRatingGap of them,
+// a gap that widens by matchRatingGapPerSecond while they wait, so that nobody waits forever.
+const (
+	matchRatingGap          = 100
+	matchRatingGapPerSecond = 10
+)
+
+// lobby is the queue of players looking for a game.
+type lobby struct {
+	mu    sync.Mutex
+	queue []*lobbyEntry
+}
+
+// lobbyEntry is a player waiting in the lobby. Once matched, their game is sent on matched.
+type lobbyEntry struct {
+	name     string
+	rating   float64
+	queuedAt time.Time
+	matched  chan MessageMatchFound
+}
+
+// WithRatings matches players of similar ratings in the lobby, and updates their ratings
+// with the results of the games they're matched in.
+func WithRatings(ratings *rating.Ratings) Option {
+	return func(s *server) {
+		s.ratings = ratings
+	}
+}
+
+// handleLobbyWebSocket queues the player for a game when they ask for a match, and tells
+// them which game to join once there are enough players for one.
+func (s *server) handleLobbyWebSocket(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.With("remoteAddr", r.RemoteAddr)
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Warn("failed to upgrade connection to WebSocket", "err", err)
+		return
+	}
+	defer conn.Close()
+	defer s.keepAlive(conn, nil)()
+
+	// Players may ask for the leaderboard and the open games any number of times before
+	// finding a match.
+	var findMatch *MessageFindMatch
+	for findMatch == nil {
+		messageType, message, err := WsReadAnyMessage(conn)
+		if err != nil {
+			logger.Info("left the lobby before finding a match", "err", err)
+			return
+		}
+		switch messageType {
+		case MessageTypeGimmeLeaderboard:
+			if err := WsSend(conn, s.leaderboardMessage()); err != nil {
+				return
+			}
+			continue
+		case MessageTypeListGames:
+			if err := WsSend(conn, NewMessageOpenGames(s.openGames())); err != nil {
+				return
+			}
+			continue
+		}
+		if findMatch, err = WsDeserializeMessage[MessageFindMatch, MessageFindMatch](message, MessageTypeFindMatch); err != nil {
+			logger.Warn("invalid find match message", "err", err)
+			return
+		}
+	}
+	logger = logger.With("name", findMatch.Name)
+
+	entry := &lobbyEntry{name: findMatch.Name, queuedAt: time.Now(), matched: make(chan MessageMatchFound, 1)}
+	if s.ratings != nil {
+		entry.rating = s.ratings.Get(entry.name).Value
+	}
+	s.lobby.mu.Lock()
+	s.lobby.queue = append(s.lobby.queue, entry)
+	s.lobby.mu.Unlock()
+	logger.Info("looking for a match")
+
+	// The player leaves the lobby by disconnecting.
+	disconnected := make(chan struct{})
+	go func() {
+		defer close(disconnected)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	// The rating gap widens over time, and games end when the server is full, so matches
+	// are retried while waiting.
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	s.matchmake()
+	for {
+		select {
+		case matchFound := <-entry.matched:
+			if err := WsSend(conn, matchFound); err != nil {
+				logger.Warn("failed to tell player about their match", "gameID", matchFound.GameID, "err", err)
+			}
+			return
+		case <-disconnected:
+			if !s.lobby.leave(entry) {
+				// Matched just as they left, so their seat stays empty until they come back.
+				logger.Info("left the lobby after being matched")
+			}
+			return
+		case <-ticker.C:
+			if s.ratings != nil || s.maxGames > 0 {
+				s.matchmake()
+			}
+		}
+	}
+}
+
+// leave removes the player from the queue, returning false if they were already matched.
+func (l *lobby) leave(entry *lobbyEntry) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.leaveLocked(entry)
+}
+
+func (l *lobby) leaveLocked(entry *lobbyEntry) bool {
+	for i, e := range l.queue {
+		if e == entry {
+			l.queue = append(l.queue[:i], l.queue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// matchmake starts a game for every group of queued players that can be matched, longest
+// waiting first. If the server is full (see WithMaxGames), they keep waiting.
+func (s *server) matchmake() {
+	for {
+		s.lobby.mu.Lock()
+		group := s.lobby.nextMatch(len(s.humanSeats), s.ratings != nil)
+		s.lobby.mu.Unlock()
+		if group == nil {
+			return
+		}
+		err := s.startMatch(group)
+		if errors.Is(err, errServerFull) {
+			s.lobby.requeue(group)
+			return
+		}
+		if err != nil {
+			s.logger.Error("failed to start a match", "err", err)
+		}
+	}
+}
+
+// requeue puts the players back in the queue, in the place they had.
+func (l *lobby) requeue(group []*lobbyEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.queue = append(l.queue, group...)
+	sort.SliceStable(l.queue, func(i, j int) bool {
+		return l.queue[i].queuedAt.Before(l.queue[j].queuedAt)
+	})
+}
+
+// nextMatch removes and returns the first group of players that can play together, if
+// any. Must be called with the lobby locked.
+func (l *lobby) nextMatch(seats int, byRating bool) []*lobbyEntry {
+	for i, first := range l.queue {
+		candidates := []*lobbyEntry{}
+		for _, e := range l.queue[i+1:] {
+			if !byRating || math.Abs(e.rating-first.rating) <= first.ratingGap() {
+				candidates = append(candidates, e)
+			}
+		}
+		if len(candidates) < seats-1 {
+			continue
+		}
+		if byRating {
+			sort.SliceStable(candidates, func(a, b int) bool {
+				return math.Abs(candidates[a].rating-first.rating) < math.Abs(candidates[b].rating-first.rating)
+			})
+		}
+		group := append([]*lobbyEntry{first}, candidates[:seats-1]...)
+		for _, e := range group {
+			l.leaveLocked(e)
+		}
+		return group
+	}
+	return nil
+}
+
+// ratingGap is how far from the player's rating their opponents may be rated.
+func (e *lobbyEntry) ratingGap() float64 {
+	return matchRatingGap + matchRatingGapPerSecond*time.Since(e.queuedAt).Seconds()
+}
+
+// startMatch starts a new game for the players, in the order they're seated (around any
+// hosted bots), and tells them to join it.
+func (s *server) startMatch(group []*lobbyEntry) error {
+	gameID, err := s.localGameID(newMatchGameID())
+	if err != nil {
+		return err
+	}
+	room, err := s.room(gameID)
+	if err != nil {
+		return err
+	}
+
+	room.mu.Lock()
+	names, tokens := []string{}, []string{}
+	for i, entry := range group {
+		playerID := s.humanSeats[i]
+		room.setPlayerName(playerID, entry.name)
+		names = append(names, entry.name)
+		// Seats are claimed right away, so that only matched players may take them.
+		token := ""
+		if s.auth != nil {
+			token = s.claimSeat(room, playerID, entry.name)
+		}
+		tokens = append(tokens, token)
+	}
+	room.mu.Unlock()
+
+	room.logger.Info("matched players", "names", names)
+	for i, entry := range group {
+		entry.matched <- NewMessageMatchFound(gameID, s.humanSeats[i], tokens[i])
+	}
+	return nil
+}
+
+// newMatchGameID returns a random ID for a matched game.
+func newMatchGameID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "match-" + hex.EncodeToString(b)
+}
+
+// gameEnded records the result of the game, updates the ratings of its players if it was
+// matched in the lobby or a tournament, and advances its tournament. Must be called with
+// the room locked, once.
+func (r *room) gameEnded() {
+	r.stats.gameFinished(r.startedAt)
+	// Snapshotting ended games keeps their stored state final, e.g. for listing them.
+	if r.journal != nil {
+		if err := r.journal.snapshot(context.Background(), r.gameState); err != nil {
+			r.logger.Error("failed to persist the ended game", "err", err)
+		}
+	}
+	r.history.record(r)
+	r.replays.record(r)
+	if r.ratings != nil && len(r.playerNames) == len(r.players) {
+		for _, result := range rating.ResultsFromGame(r.gameState, r.playerNames) {
+			r.ratings.Record(result)
+		}
+	}
+	r.leaderboard.record(r)
+	if r.tournamentMatch != nil {
+		r.tournamentMatch.ended(r)
+	}
+}
+
+// numSeats returns the number of players in games with the options.
+func numSeats(gameOptions []func(*chinchon.GameState)) int {
+	return len(chinchon.New(gameOptions...).Players)
+}
@@ -0,0 +1,100 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// queued returns a lobby entry for the player, queued the given time ago.
+func queued(name string, rating float64, ago time.Duration) *lobbyEntry {
+	return &lobbyEntry{name: name, rating: rating, queuedAt: time.Now().Add(-ago), matched: make(chan MessageMatchFound, 1)}
+}
+
+// entryNames returns the names of the players, as in "[a b]".
+func entryNames(entries []*lobbyEntry) string {
+	names := []string{}
+	for _, e := range entries {
+		names = append(names, e.name)
+	}
+	return fmt.Sprint(names)
+}
+
+func TestNextMatch(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		queue    []*lobbyEntry
+		seats    int
+		byRating bool
+		match    string
+		left     string
+	}{
+		{
+			name:  "in order without ratings",
+			queue: []*lobbyEntry{queued("a", 1500, 0), queued("b", 2500, 0), queued("c", 1500, 0)},
+			seats: 2, match: "[a b]", left: "[c]",
+		},
+		{
+			name:  "too few players",
+			queue: []*lobbyEntry{queued("a", 1500, 0), queued("b", 1500, 0)},
+			seats: 3, match: "[]", left: "[a b]",
+		},
+		{
+			name:  "within the rating gap",
+			queue: []*lobbyEntry{queued("a", 1500, 0), queued("b", 1700, 0), queued("c", 1580, 0)},
+			seats: 2, byRating: true, match: "[a c]", left: "[b]",
+		},
+		{
+			name:  "closest rating first",
+			queue: []*lobbyEntry{queued("a", 1500, 0), queued("b", 1450, 0), queued("c", 1490, 0), queued("d", 1560, 0)},
+			seats: 3, byRating: true, match: "[a c b]", left: "[d]",
+		},
+		{
+			name:  "nobody within the gap",
+			queue: []*lobbyEntry{queued("a", 1500, 0), queued("b", 1700, 0)},
+			seats: 2, byRating: true, match: "[]", left: "[a b]",
+		},
+		{
+			name:  "gap widened while waiting",
+			queue: []*lobbyEntry{queued("a", 1500, 15*time.Second), queued("b", 1700, 0)},
+			seats: 2, byRating: true, match: "[a b]", left: "[]",
+		},
+		{
+			name:  "later players matched first",
+			queue: []*lobbyEntry{queued("a", 1000, 0), queued("b", 1700, 0), queued("c", 1750, 0)},
+			seats: 2, byRating: true, match: "[b c]", left: "[a]",
+		},
+	} {
+		l := &lobby{queue: test.queue}
+		if match := entryNames(l.nextMatch(test.seats, test.byRating)); match != test.match || entryNames(l.queue) != test.left {
+			t.Errorf("Expected %v matched and %v left (%v), got %v and %v", test.match, test.left, test.name, match, entryNames(l.queue))
+		}
+	}
+}
+
+func TestMatchmakeRequeue(t *testing.T) {
+	// The default game takes the only one allowed.
+	s := New("0", WithMaxGames(1))
+	a, b, c := queued("a", 0, 3*time.Second), queued("b", 0, time.Second), queued("c", 0, 2*time.Second)
+	s.lobby.queue = []*lobbyEntry{a, b}
+	s.matchmake()
+	if entryNames(s.lobby.queue) != "[a b]" || len(a.matched) != 0 {
+		t.Fatalf("Expected the players to keep waiting while the server is full, got %v", entryNames(s.lobby.queue))
+	}
+
+	s.lobby.queue = []*lobbyEntry{c}
+	s.lobby.requeue([]*lobbyEntry{a, b})
+	if entryNames(s.lobby.queue) != "[a c b]" {
+		t.Errorf("Expected players to be requeued in the order they came, got %v", entryNames(s.lobby.queue))
+	}
+
+	s.maxGames = 0
+	s.lobby.queue = []*lobbyEntry{a, b}
+	s.matchmake()
+	if len(s.lobby.queue) != 0 || len(a.matched) != 1 || len(b.matched) != 1 {
+		t.Errorf("Expected the players to be matched once there's room, got %v waiting", entryNames(s.lobby.queue))
+	}
+}
@@ -0,0 +1,24 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"log/slog"
+	"os"
+)
+
+// WithLogger sets the logger for the server's structured logs, which carry the game ID,
+// player ID, remote address and action name where relevant. Without it, the server logs
+// to slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *server) {
+		s.logger = logger
+	}
+}
+
+// fatal logs an error the server can't start (or go on) with, and exits.
+func fatal(logger *slog.Logger, msg string, args ...any) {
+	logger.Error(msg, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,376 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+// The /poll endpoints are a fallback for clients whose network blocks WebSockets. Each
+// long-polling session relays to a WebSocket connection to the server's own handlers, made
+// in memory, so that it speaks the same messages, as JSON:
+//
+//   - POST /poll/sessions with {"path": "/ws"} (or any other WebSocket endpoint, with its
+//     query) opens a session, responding with {"id": "..."}
+//   - POST /poll/sessions/{id} sends the message in the body
+//   - GET /poll/sessions/{id} responds with the messages received since the last one, as a
+//     JSON array, waiting up to pollWait for some, or 410 Gone once the session is closed
+//   - DELETE /poll/sessions/{id} closes the session
+//
+// WsDial falls back to them by itself.
+const (
+	// pollWait is how long a poll waits for messages, under the usual proxy timeouts.
+	pollWait = 25 * time.Second
+
+	// pollIdleTimeout is how long a session may go without polls before it's closed, as
+	// if its connection dropped.
+	pollIdleTimeout = time.Minute
+
+	// maxPollBacklog is the number of messages a session keeps until polled, past which
+	// it's closed, as its client isn't keeping up.
+	maxPollBacklog = 256
+
+	// maxPollMessageSize is the size in bytes of the largest message a client may send.
+	maxPollMessageSize = 64 << 10
+)
+
+// APIPollSession is the body of POST /poll/sessions, and of its response.
+type APIPollSession struct {
+	// Path is the WebSocket endpoint the session connects to, e.g. "/lobby/ws".
+	Path string `json:"path,omitempty"`
+	ID   string `json:"id,omitempty"`
+}
+
+// pollSessions are the long-polling sessions, and the in-memory listener the server's
+// handlers serve their WebSocket connections on.
+type pollSessions struct {
+	listener *pipeListener
+	server   *http.Server
+
+	mu           sync.Mutex
+	sessions     map[string]*pollSession
+	shuttingDown bool
+}
+
+// pollSession is a long-polling client's WebSocket connection, as the client end.
+type pollSession struct {
+	id   string
+	conn *websocket.Conn
+
+	mu       sync.Mutex
+	messages []json.RawMessage
+	closed   bool
+	polling  int // polls waiting
+	lastPoll time.Time
+	wake     chan struct{}
+
+	writeMu sync.Mutex
+}
+
+// routeLongPolling registers the /poll endpoints, and serves the router in memory for
+// them.
+func (s *server) routeLongPolling(router *mux.Router) {
+	s.polls = &pollSessions{listener: newPipeListener(), sessions: map[string]*pollSession{}}
+	s.polls.server = &http.Server{Handler: router}
+	go func() { _ = s.polls.server.Serve(s.polls.listener) }()
+	go s.polls.closeIdle()
+
+	router.HandleFunc("/poll/sessions", s.handlePollOpen).Methods(http.MethodPost)
+	router.HandleFunc("/poll/sessions/{id}", s.handlePollReceive).Methods(http.MethodGet)
+	router.HandleFunc("/poll/sessions/{id}", s.handlePollSend).Methods(http.MethodPost)
+	router.HandleFunc("/poll/sessions/{id}", s.handlePollClose).Methods(http.MethodDelete)
+}
+
+// handlePollOpen opens a session, connecting to the WebSocket endpoint as the client.
+func (s *server) handlePollOpen(w http.ResponseWriter, r *http.Request) {
+	var open APIPollSession
+	if err := json.NewDecoder(r.Body).Decode(&open); err != nil {
+		http.Error(w, "invalid session, expected a path", http.StatusBadRequest)
+		return
+	}
+	target, err := url.Parse(open.Path)
+	if err != nil || !strings.HasPrefix(target.Path, "/") || !strings.HasSuffix(target.Path, "/ws") {
+		http.Error(w, "invalid path, expected a WebSocket endpoint", http.StatusBadRequest)
+		return
+	}
+
+	// The handlers see the client's address, e.g. for bans.
+	remoteAddr := pipeAddr(r.RemoteAddr)
+	dialer := websocket.Dialer{
+		NetDialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return s.polls.listener.dial(ctx, remoteAddr)
+		},
+		HandshakeTimeout: pollWait,
+	}
+	conn, resp, err := dialer.DialContext(r.Context(), "ws://chinchon"+target.RequestURI(), nil)
+	if err != nil {
+		if resp == nil {
+			s.logger.Warn("failed to open long-polling session", "remoteAddr", r.RemoteAddr, "err", err)
+			http.Error(w, "failed to open session", http.StatusInternalServerError)
+			return
+		}
+		// E.g. a full server's 503, with its Retry-After.
+		if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		http.Error(w, "failed to open session", resp.StatusCode)
+		return
+	}
+
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	session := &pollSession{id: hex.EncodeToString(b), conn: conn, lastPoll: time.Now(), wake: make(chan struct{}, 1)}
+	s.polls.mu.Lock()
+	s.polls.sessions[session.id] = session
+	s.polls.mu.Unlock()
+	go s.polls.relay(session)
+	s.logger.Debug("long-polling session opened", "remoteAddr", r.RemoteAddr, "path", target.Path)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(APIPollSession{ID: session.id})
+}
+
+// relay keeps the messages the session's connection receives until they're polled, until
+// it's closed. The session is forgotten once they're all polled (or it goes idle).
+func (p *pollSessions) relay(session *pollSession) {
+	defer session.conn.Close()
+	for {
+		_, message, err := session.conn.ReadMessage()
+		session.mu.Lock()
+		if err == nil && len(session.messages) == maxPollBacklog {
+			err = errors.New("too many messages not polled")
+		}
+		if err != nil {
+			session.closed = true
+		} else {
+			session.messages = append(session.messages, message)
+		}
+		session.mu.Unlock()
+		session.signal()
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (session *pollSession) signal() {
+	select {
+	case session.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (p *pollSessions) session(r *http.Request) (*pollSession, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	session, ok := p.sessions[mux.Vars(r)["id"]]
+	return session, ok
+}
+
+// handlePollReceive responds with the messages received since the last poll, once there
+// are any or after pollWait.
+func (s *server) handlePollReceive(w http.ResponseWriter, r *http.Request) {
+	session, ok := s.polls.session(r)
+	if !ok {
+		http.Error(w, "session closed", http.StatusGone)
+		return
+	}
+	session.mu.Lock()
+	session.polling++
+	session.mu.Unlock()
+	defer func() {
+		session.mu.Lock()
+		session.polling--
+		session.lastPoll = time.Now()
+		session.mu.Unlock()
+	}()
+
+	timeout := time.NewTimer(pollWait)
+	defer timeout.Stop()
+	for {
+		session.mu.Lock()
+		messages, closed := session.messages, session.closed
+		session.messages = nil
+		session.mu.Unlock()
+		if len(messages) > 0 || s.polls.stopping() {
+			writeJSON(w, append([]json.RawMessage{}, messages...))
+			return
+		}
+		if closed {
+			s.polls.forget(session)
+			http.Error(w, "session closed", http.StatusGone)
+			return
+		}
+		select {
+		case <-session.wake:
+		case <-timeout.C:
+			writeJSON(w, []json.RawMessage{})
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// handlePollSend sends the message in the body.
+func (s *server) handlePollSend(w http.ResponseWriter, r *http.Request) {
+	session, ok := s.polls.session(r)
+	if !ok {
+		http.Error(w, "session closed", http.StatusGone)
+		return
+	}
+	message, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPollMessageSize))
+	if err != nil || !json.Valid(message) {
+		http.Error(w, "invalid message", http.StatusBadRequest)
+		return
+	}
+	session.writeMu.Lock()
+	defer session.writeMu.Unlock()
+	if err := session.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+		http.Error(w, "session closed", http.StatusGone)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (p *pollSessions) forget(session *pollSession) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.sessions, session.id)
+}
+
+func (s *server) handlePollClose(w http.ResponseWriter, r *http.Request) {
+	session, ok := s.polls.session(r)
+	if !ok {
+		http.Error(w, "session closed", http.StatusGone)
+		return
+	}
+	session.conn.Close()
+	s.polls.forget(session)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// closeIdle closes the sessions that stopped polling, and forgets them once closed, until
+// the listener is closed.
+func (p *pollSessions) closeIdle() {
+	ticker := time.NewTicker(pollIdleTimeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-p.listener.closed:
+			return
+		}
+		p.closeIdleSessions()
+	}
+}
+
+// closeIdleSessions closes the sessions that went pollIdleTimeout without polls, and
+// forgets those already closed.
+func (p *pollSessions) closeIdleSessions() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, session := range p.sessions {
+		session.mu.Lock()
+		if session.polling == 0 && time.Since(session.lastPoll) > pollIdleTimeout {
+			if session.closed {
+				delete(p.sessions, session.id)
+			}
+			session.conn.Close()
+		}
+		session.mu.Unlock()
+	}
+}
+
+// shutdown makes polls respond right away, for the server to shut down without waiting
+// for them, and stops serving new sessions.
+func (p *pollSessions) shutdown() {
+	p.mu.Lock()
+	p.shuttingDown = true
+	for _, session := range p.sessions {
+		session.signal()
+	}
+	p.mu.Unlock()
+	p.listener.Close()
+}
+
+func (p *pollSessions) stopping() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.shuttingDown
+}
+
+// pipeListener is a net.Listener for connections made in memory with dial.
+type pipeListener struct {
+	conns     chan net.Conn
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newPipeListener() *pipeListener {
+	return &pipeListener{conns: make(chan net.Conn), closed: make(chan struct{})}
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *pipeListener) Close() error {
+	l.closeOnce.Do(func() { close(l.closed) })
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr {
+	return pipeAddr("pipe")
+}
+
+// dial returns a connection to the listener, whose accepted end has the remote address.
+func (l *pipeListener) dial(ctx context.Context, remoteAddr net.Addr) (net.Conn, error) {
+	client, server := net.Pipe()
+	select {
+	case l.conns <- pipeConn{Conn: server, remoteAddr: remoteAddr}:
+		return client, nil
+	case <-l.closed:
+	case <-ctx.Done():
+	}
+	client.Close()
+	server.Close()
+	return nil, net.ErrClosed
+}
+
+// pipeConn is the accepted end of a connection made with pipeListener.dial.
+type pipeConn struct {
+	net.Conn
+	remoteAddr net.Addr
+}
+
+func (c pipeConn) RemoteAddr() net.Addr {
+	return c.remoteAddr
+}
+
+// pipeAddr is the address of either end of a connection made in memory.
+type pipeAddr string
+
+func (a pipeAddr) Network() string { return "pipe" }
+func (a pipeAddr) String() string  { return string(a) }
@@ -0,0 +1,146 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ForceLongPolling makes WsDial connect over long polling without trying WebSocket first,
+// e.g. to test the fallback.
+var ForceLongPolling bool
+
+// pollDial connects to the WebSocket URL's endpoint over long polling (see the /poll
+// endpoints), returning a WebSocket connection made in memory which relays to it, for
+// clients to use like any other. It never negotiates WsSubprotocolProtobuf.
+func pollDial(wsURL string) (*websocket.Conn, error) {
+	u, err := url.Parse(wsURL)
+	if err != nil {
+		return nil, err
+	}
+	base := fmt.Sprintf("%v://%v", strings.Replace(u.Scheme, "ws", "http", 1), u.Host)
+	client := HTTPClient()
+	client.Timeout = pollWait + 10*time.Second
+
+	body, _ := json.Marshal(APIPollSession{Path: u.RequestURI()})
+	resp, err := client.Post(base+"/poll/sessions", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("failed to open long-polling session: %v", resp.Status)
+	}
+	var session APIPollSession
+	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+		return nil, err
+	}
+	bridge := &pollBridge{client: client, url: base + "/poll/sessions/" + session.ID}
+
+	// The connection's other end is served in memory, relaying to the session.
+	listener := newPipeListener()
+	go func() {
+		_ = http.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			listener.Close()
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				bridge.close()
+				return
+			}
+			bridge.relay(conn)
+		}))
+	}()
+	dialer := websocket.Dialer{
+		NetDialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return listener.dial(ctx, pipeAddr("pipe"))
+		},
+	}
+	conn, _, err := dialer.Dial("ws://chinchon/", nil)
+	if err != nil {
+		listener.Close()
+		bridge.close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// pollBridge relays a WebSocket connection made in memory to a long-polling session.
+type pollBridge struct {
+	client *http.Client
+	url    string
+}
+
+// relay sends the connection's messages to the session, and polls the session's messages
+// for the connection, until either is closed.
+func (b *pollBridge) relay(conn *websocket.Conn) {
+	defer conn.Close()
+	go func() {
+		defer conn.Close()
+		for {
+			messages, err := b.poll()
+			if err != nil {
+				_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, err.Error()))
+				return
+			}
+			for _, message := range messages {
+				if err := conn.WriteMessage(websocket.TextMessage, message); err != nil {
+					b.close()
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			b.close()
+			return
+		}
+		resp, err := b.client.Post(b.url, "application/json", bytes.NewReader(message))
+		if err != nil {
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent {
+			return
+		}
+	}
+}
+
+// poll returns the session's next messages.
+func (b *pollBridge) poll() ([]json.RawMessage, error) {
+	resp, err := b.client.Get(b.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("long-polling session ended: %v", resp.Status)
+	}
+	var messages []json.RawMessage
+	err = json.NewDecoder(resp.Body).Decode(&messages)
+	return messages, err
+}
+
+// close closes the session.
+func (b *pollBridge) close() {
+	req, err := http.NewRequest(http.MethodDelete, b.url, nil)
+	if err != nil {
+		return
+	}
+	if resp, err := b.client.Do(req); err == nil {
+		resp.Body.Close()
+	}
+}
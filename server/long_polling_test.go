@@ -0,0 +1,91 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLongPolling(t *testing.T) {
+	s := New("0")
+	srv := httptest.NewServer(s.handler())
+	defer srv.Close()
+	defer s.polls.shutdown()
+
+	resp, err := http.Post(srv.URL+"/poll/sessions", "application/json", bytes.NewBufferString(`{"path": "/ws"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var session APIPollSession
+	err = json.NewDecoder(resp.Body).Decode(&session)
+	resp.Body.Close()
+	if err != nil || resp.StatusCode != http.StatusCreated || session.ID == "" {
+		t.Fatalf("Expected a session to be opened, got %v and %+v (%v)", resp.Status, session, err)
+	}
+	sessionURL := srv.URL + "/poll/sessions/" + session.ID
+
+	hello, _ := json.Marshal(NewMessageHello(0))
+	resp, err = http.Post(sessionURL, "application/json", bytes.NewReader(hello))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected the hello to be relayed, got %v", resp.Status)
+	}
+
+	poll := func() (int, []WebsocketMessage) {
+		t.Helper()
+		resp, err := http.Get(sessionURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var messages []WebsocketMessage
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&messages); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return resp.StatusCode, messages
+	}
+	gotState := false
+	for i := 0; i < 5 && !gotState; i++ {
+		status, messages := poll()
+		if status != http.StatusOK {
+			t.Fatalf("Expected the session to stay open, got %v", status)
+		}
+		for _, message := range messages {
+			gotState = gotState || message.Type == MessageTypeHeresGameState
+		}
+	}
+	if !gotState {
+		t.Fatal("Expected the game state to be relayed")
+	}
+
+	s.polls.mu.Lock()
+	idle := s.polls.sessions[session.ID]
+	s.polls.mu.Unlock()
+	idle.mu.Lock()
+	idle.lastPoll = time.Now().Add(-2 * pollIdleTimeout)
+	idle.mu.Unlock()
+	s.polls.closeIdleSessions()
+	status := http.StatusOK
+	for i := 0; i < 5 && status == http.StatusOK; i++ {
+		status, _ = poll()
+	}
+	if status != http.StatusGone {
+		t.Errorf("Expected an idle session to expire, got %v", status)
+	}
+	s.polls.mu.Lock()
+	defer s.polls.mu.Unlock()
+	if len(s.polls.sessions) != 0 {
+		t.Errorf("Expected the expired session to be forgotten, got %d", len(s.polls.sessions))
+	}
+}
@@ -0,0 +1,116 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// notifyTimeout is how long a turn notification may take to be sent.
+const notifyTimeout = 10 * time.Second
+
+// TurnNotification tells a player who isn't connected that it's their turn, e.g. in a
+// correspondence game played over days.
+type TurnNotification struct {
+	GameID      string `json:"gameID"`
+	PlayerID    int    `json:"playerID"`
+	Name        string `json:"name,omitempty"`
+	RoundNumber int    `json:"roundNumber"`
+
+	// Target is where the player asked to be notified (see MessageHello), e.g. a URL.
+	Target string `json:"-"`
+}
+
+// TurnNotifier notifies players who aren't connected when it's their turn. Implement it
+// to notify them through Web Push or any other service, with the targets players give in
+// their hello message, or use WebhookNotifier.
+type TurnNotifier interface {
+	NotifyTurn(ctx context.Context, notification TurnNotification) error
+}
+
+// WithTurnNotifier notifies players who gave a target in their hello message when it's
+// their turn while they aren't connected.
+func WithTurnNotifier(notifier TurnNotifier) Option {
+	return func(s *server) {
+		s.turnNotifier = notifier
+	}
+}
+
+// WebhookNotifier is a TurnNotifier which POSTs notifications as JSON to the players'
+// targets, which are http or https URLs. As the server makes requests to any such URL, it
+// should be kept away from services it shouldn't reach.
+type WebhookNotifier struct {
+	// Client makes the requests, or http.DefaultClient if nil.
+	Client *http.Client
+}
+
+func (n WebhookNotifier) NotifyTurn(ctx context.Context, notification TurnNotification) error {
+	target, err := url.Parse(notification.Target)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		return fmt.Errorf("invalid webhook URL %q", notification.Target)
+	}
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %v", resp.Status)
+	}
+	return nil
+}
+
+// setNotifyTarget sets where to notify the player when it's their turn, replacing any
+// previous target, as the seat may have changed hands. Must be called with the room locked.
+func (r *room) setNotifyTarget(playerID int, target string) {
+	if target == "" {
+		delete(r.notifyTargets, playerID)
+		return
+	}
+	r.notifyTargets[playerID] = target
+}
+
+// notifyTurn notifies the player whose turn it is, if the turn just passed to them and
+// they aren't connected. Must be called with the room locked.
+func (r *room) notifyTurn() {
+	gs := r.gameState
+	playerID := gs.TurnPlayerID
+	if playerID == r.lastTurnPlayerID {
+		return
+	}
+	r.lastTurnPlayerID = playerID
+	target := r.notifyTargets[playerID]
+	if r.notifier == nil || target == "" || gs.IsGameEnded || r.players[playerID] != nil {
+		return
+	}
+	notification := TurnNotification{GameID: r.id, PlayerID: playerID, Name: gs.Players[playerID].Name, RoundNumber: gs.RoundNumber, Target: target}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		if err := r.notifier.NotifyTurn(ctx, notification); err != nil {
+			r.logger.Warn("failed to notify player of their turn", "playerID", playerID, "err", err)
+			return
+		}
+		r.logger.Debug("notified player of their turn", "playerID", playerID)
+	}()
+}
@@ -0,0 +1,59 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"net/http"
+
+	"github.com/devblac/chinchon/chinchon"
+)
+
+// APIOpenGame is a game being played with free seats, for players to pick one to join
+// rather than agreeing on a game and player numbers beforehand.
+type APIOpenGame struct {
+	ID string `json:"id"`
+
+	// Creator is the name of the first player to join the game with one, if any.
+	Creator string         `json:"creator,omitempty"`
+	Rules   chinchon.Rules `json:"rules"`
+
+	// FreeSeats are the player IDs of the seats nobody is playing, holding or was matched
+	// into.
+	FreeSeats []int `json:"freeSeats"`
+
+	// Password is set if joining the game takes a password.
+	Password bool `json:"password,omitempty"`
+}
+
+// openGames returns the games being played with free seats.
+func (s *server) openGames() []APIOpenGame {
+	games := []APIOpenGame{}
+	for _, room := range s.liveRooms() {
+		room.mu.Lock()
+		freeSeats := []int{}
+		for playerID := range room.players {
+			if s.seatFree(room, playerID) {
+				freeSeats = append(freeSeats, playerID)
+			}
+		}
+		if !room.gameState.IsGameEnded && len(freeSeats) > 0 {
+			games = append(games, APIOpenGame{ID: room.id, Creator: room.creator, Rules: room.gameState.Rules(), FreeSeats: freeSeats, Password: s.passwords.protected(room.id)})
+		}
+		room.mu.Unlock()
+	}
+	return games
+}
+
+// seatFree returns true if a player could take the seat with a hello message, without a
+// session token. Must be called with the room locked.
+func (s *server) seatFree(r *room, playerID int) bool {
+	if r.players[playerID] != nil || r.bots[playerID] != nil || r.playerNames[playerID] != "" {
+		return false
+	}
+	return s.auth == nil || r.sessions[playerID] == "" || s.holdExpired(r, playerID)
+}
+
+func (s *server) handleAPIOpenGames(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.openGames())
+}
@@ -0,0 +1,229 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultSendQueueSize is the maximum number of queued messages (other than game states)
+// per connection, unless set with WithSendQueue.
+const DefaultSendQueueSize = 32
+
+// SendQueuePolicy is what happens when a connection's send queue is full, as its client
+// doesn't read messages as fast as they're sent.
+type SendQueuePolicy string
+
+const (
+	// SendQueueDropOldest, the default, drops the oldest queued message.
+	SendQueueDropOldest SendQueuePolicy = "drop_oldest"
+
+	// SendQueueDisconnect closes the connection, for the client to reconnect and catch up.
+	SendQueueDisconnect SendQueuePolicy = "disconnect"
+)
+
+// sendQueue is the size and overflow policy of connections' send queues.
+type sendQueue struct {
+	size   int
+	policy SendQueuePolicy
+}
+
+// WithSendQueue sets how many messages (other than game states, of which only the latest
+// is kept) may be queued for each connection, and what happens past that (DefaultSendQueueSize
+// and SendQueueDropOldest by default). Either way, a slow client never holds up the game.
+func WithSendQueue(size int, policy SendQueuePolicy) Option {
+	return func(s *server) {
+		s.sendQueue = sendQueue{size: size, policy: policy}
+	}
+}
+
+// outbox is a connection's outbound queue. Sending never blocks: a slow client only gets
+// the latest game state once it catches up, rather than every intermediate one, and other
+// messages are queued up to the queue's size, past which its policy applies.
+//
+// It's also the only writer of its connection, as gorilla/websocket requires.
+type outbox struct {
+	conn      connection
+	sendQueue sendQueue
+
+	mu        sync.Mutex
+	gameState any // the latest MessageHeresGameState or MessageHeresSpectatorGameState
+	queue     []any
+	closed    bool
+	resync    bool // for deltas to send the next game state whole
+
+	// deltas, if the client asked for them, encodes game states as they're delivered.
+	deltas *deltaEncoder
+
+	// closeCode and closeText, if set by Shutdown, are sent after the queued messages.
+	closeCode int
+	closeText string
+
+	wake chan struct{}
+	done chan struct{}
+}
+
+// connection is what an outbox delivers messages to.
+type connection interface {
+	Send(message any) error
+
+	// SendClose tells the client that the connection is closing, with a WebSocket close
+	// code and text.
+	SendClose(closeCode int, text string) error
+	Close() error
+	RemoteAddr() net.Addr
+}
+
+// wsConnection is a WebSocket connection.
+type wsConnection struct {
+	*websocket.Conn
+}
+
+func (c wsConnection) Send(message any) error {
+	return WsSend(c.Conn, message)
+}
+
+func (c wsConnection) SendClose(closeCode int, text string) error {
+	return c.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(closeCode, text))
+}
+
+func newOutbox(conn *websocket.Conn, queue sendQueue) *outbox {
+	return newConnectionOutbox(wsConnection{conn}, queue)
+}
+
+func newConnectionOutbox(conn connection, queue sendQueue) *outbox {
+	o := &outbox{conn: conn, sendQueue: queue, wake: make(chan struct{}, 1), done: make(chan struct{})}
+	go o.run()
+	return o
+}
+
+// Send queues the message for delivery.
+func (o *outbox) Send(message any) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return
+	}
+	switch message.(type) {
+	case MessageHeresGameState, MessageHeresSpectatorGameState:
+		o.gameState = message
+	default:
+		if len(o.queue) >= o.sendQueue.size {
+			if o.sendQueue.policy == SendQueueDisconnect {
+				o.overflowed()
+				return
+			}
+			o.queue = o.queue[1:]
+		}
+		o.queue = append(o.queue, message)
+	}
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// overflowed closes the connection of a client too slow to keep up, which is then handled
+// as any other disconnection. Must be called with the outbox locked.
+func (o *outbox) overflowed() {
+	slog.Warn("send queue full, disconnecting slow client", "remoteAddr", o.conn.RemoteAddr().String())
+	o.closed = true
+	o.queue, o.gameState = nil, nil
+	close(o.wake)
+	// Closing doesn't wait for a pending write, which fails.
+	o.conn.Close()
+}
+
+// SendDeltas makes the outbox deliver game states as deltas from the previous one it
+// delivered, with a full one every interval deltas (see MessageGameStateDelta). Must be
+// called before anything is sent.
+func (o *outbox) SendDeltas(interval int) {
+	o.deltas = newDeltaEncoder(interval)
+}
+
+// Resync makes the outbox deliver the next game state whole, if sending deltas.
+func (o *outbox) Resync() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.resync = true
+}
+
+// Close stops the outbox. Queued messages are discarded.
+func (o *outbox) Close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.closed {
+		o.closed = true
+		o.queue, o.gameState = nil, nil
+		close(o.wake)
+	}
+}
+
+// Shutdown stops the outbox after delivering the queued messages and a close message with
+// the code and text, then closes the connection. It gives up when the context is done.
+func (o *outbox) Shutdown(ctx context.Context, closeCode int, text string) {
+	o.mu.Lock()
+	if !o.closed {
+		o.closed = true
+		o.closeCode, o.closeText = closeCode, text
+		close(o.wake)
+	}
+	o.mu.Unlock()
+
+	select {
+	case <-o.done:
+	case <-ctx.Done():
+	}
+	o.conn.Close()
+}
+
+func (o *outbox) run() {
+	defer close(o.done)
+	for range o.wake {
+		o.deliver()
+	}
+
+	// Only set when shutting down, after which nothing else is queued.
+	if o.closeCode != 0 {
+		o.deliver()
+		if err := o.conn.SendClose(o.closeCode, o.closeText); err != nil {
+			slog.Warn("failed to close connection", "err", err)
+		}
+	}
+}
+
+// deliver sends the queued messages, latest game state last.
+func (o *outbox) deliver() {
+	o.mu.Lock()
+	messages := o.queue
+	if o.gameState != nil {
+		messages = append(messages, o.gameState)
+	}
+	o.queue, o.gameState = nil, nil
+	resync := o.resync
+	o.resync = false
+	o.mu.Unlock()
+
+	if o.deltas != nil {
+		if resync {
+			o.deltas.resync()
+		}
+		if len(messages) > 0 {
+			messages[len(messages)-1] = o.deltas.encode(messages[len(messages)-1])
+		}
+	}
+
+	for _, message := range messages {
+		if err := o.conn.Send(message); err != nil {
+			slog.Info("failed to deliver queued message", "err", err)
+			return
+		}
+	}
+}
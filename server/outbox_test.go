@@ -0,0 +1,113 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stalledConnection is a connection whose client doesn't read until it's released.
+type stalledConnection struct {
+	release chan struct{}
+
+	mu       sync.Mutex
+	received []any
+	closed   bool
+}
+
+func newStalledConnection() *stalledConnection {
+	return &stalledConnection{release: make(chan struct{})}
+}
+
+func (c *stalledConnection) Send(message any) error {
+	<-c.release
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.received = append(c.received, message)
+	return nil
+}
+
+func (c *stalledConnection) SendClose(closeCode int, text string) error { return nil }
+
+func (c *stalledConnection) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+func (c *stalledConnection) RemoteAddr() net.Addr {
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}
+}
+
+// stall sends a first message and waits for the outbox to be stuck delivering it, so that
+// the following ones are queued.
+func (c *stalledConnection) stall(t *testing.T, out *outbox) {
+	t.Helper()
+	out.Send(MessageEmote{Emote: "stall"})
+	deadline := time.Now().Add(time.Second)
+	for {
+		out.mu.Lock()
+		queued := len(out.queue)
+		out.mu.Unlock()
+		if queued == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the outbox to start delivering")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSendQueueDropOldest(t *testing.T) {
+	conn := newStalledConnection()
+	out := newConnectionOutbox(conn, sendQueue{size: 2, policy: SendQueueDropOldest})
+	conn.stall(t, out)
+	for _, text := range []string{"1", "2", "3"} {
+		out.Send(MessageEmote{Emote: text})
+	}
+	close(conn.release)
+	out.Shutdown(context.Background(), 1000, "bye")
+
+	texts := []string{}
+	for _, message := range conn.received {
+		texts = append(texts, message.(MessageEmote).Emote)
+	}
+	if len(texts) != 3 || texts[0] != "stall" || texts[1] != "2" || texts[2] != "3" {
+		t.Errorf("Expected the oldest queued message to be dropped, got %v", texts)
+	}
+}
+
+func TestSendQueueDisconnect(t *testing.T) {
+	conn := newStalledConnection()
+	out := newConnectionOutbox(conn, sendQueue{size: 2, policy: SendQueueDisconnect})
+	conn.stall(t, out)
+	out.Send(MessageEmote{Emote: "1"})
+	out.Send(MessageEmote{Emote: "2"})
+
+	conn.mu.Lock()
+	closed := conn.closed
+	conn.mu.Unlock()
+	if closed {
+		t.Fatal("Expected the connection to stay open until the queue overflows")
+	}
+
+	out.Send(MessageEmote{Emote: "3"})
+	conn.mu.Lock()
+	closed = conn.closed
+	conn.mu.Unlock()
+	if !closed {
+		t.Error("Expected the connection to be closed once the queue overflows")
+	}
+	close(conn.release)
+	<-out.done
+	if len(conn.received) != 1 {
+		t.Errorf("Expected the queued messages to be discarded, got %v", conn.received)
+	}
+}
@@ -0,0 +1,105 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"errors"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var errWrongPassword = errors.New("wrong room password")
+
+// roomPasswords are the bcrypt hashes of the passwords of the games started with one (see
+// APINewGame), by game ID. They're kept after the games end, so that a club's standing
+// room may be started again with the same ID by those who know its password, but not by
+// anyone else. With a game store, they're saved with the games' snapshots, so that they
+// survive restarts.
+type roomPasswords struct {
+	mu     sync.Mutex
+	hashes map[string][]byte
+
+	// stored looks up the hash of a game that isn't being played in the game store, if
+	// any, e.g. after a restart.
+	stored func(gameID string) []byte
+}
+
+func newRoomPasswords() *roomPasswords {
+	return &roomPasswords{hashes: map[string][]byte{}}
+}
+
+// hashPassword returns the password's hash, for set.
+func hashPassword(password string) ([]byte, error) {
+	return bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+}
+
+// set protects the game with the password with the hash.
+func (p *roomPasswords) set(gameID string, hash []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.hashes[gameID] = hash
+}
+
+// hash returns the game's password hash, or nil if it has none.
+func (p *roomPasswords) hash(gameID string) []byte {
+	if hash := p.known(gameID); hash != nil || p.stored == nil {
+		return hash
+	}
+	hash := p.stored(gameID)
+	if hash != nil {
+		p.set(gameID, hash)
+	}
+	return hash
+}
+
+// known returns the game's password hash, if it was set since the server started, without
+// looking it up in the game store.
+func (p *roomPasswords) known(gameID string) []byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.hashes[gameID]
+}
+
+// protected returns true if joining the game takes a password.
+func (p *roomPasswords) protected(gameID string) bool {
+	return p.hash(gameID) != nil
+}
+
+// check returns errWrongPassword unless the game has no password, or it's the given one.
+func (p *roomPasswords) check(gameID string, password string) error {
+	return checkPassword(p.hash(gameID), password)
+}
+
+// recheck checks the password again if the game turned out to be protected after it was
+// first checked, when it wasn't (wasProtected), e.g. because it was started with one in
+// between. It doesn't look the hash up in the game store, so that it can be called with the
+// server's rooms locked.
+func (p *roomPasswords) recheck(gameID string, wasProtected bool, password string) error {
+	if wasProtected {
+		return nil
+	}
+	return checkPassword(p.known(gameID), password)
+}
+
+func checkPassword(hash []byte, password string) error {
+	// Comparing is slow on purpose, so it's done unlocked.
+	if hash == nil || bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil {
+		return nil
+	}
+	return errWrongPassword
+}
+
+// storedPasswordHash returns the password hash saved with the game in the game store, if
+// it isn't being played (as then it's known already).
+func (s *server) storedPasswordHash(gameID string) []byte {
+	if _, ok := s.existingRoom(gameID); ok || !validGameID.MatchString(gameID) {
+		return nil
+	}
+	snapshot, _, _, err := s.store.LoadGame(gameID)
+	if err != nil {
+		return nil
+	}
+	return snapshotPasswordHash(snapshot)
+}
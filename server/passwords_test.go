@@ -0,0 +1,56 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRoomPasswordsSurviveRestarts(t *testing.T) {
+	store, err := NewFileGameStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, _ := hashPassword("mate")
+	s := New("0", WithGameStore(store, DefaultSnapshotEvery))
+	r, err := s.createRoom("club", s.gameOptions, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.mu.Unlock()
+
+	restarted := New("0", WithGameStore(store, DefaultSnapshotEvery))
+	if _, _, err := restarted.join("club", 0, "", "wrong", nil, restarted.gameOptions); !errors.Is(err, errWrongPassword) {
+		t.Fatalf("Expected the password to be asked after a restart, got %v", err)
+	}
+	r, _, err = restarted.join("club", 0, "", "mate", nil, restarted.gameOptions)
+	if err != nil {
+		t.Fatalf("Expected the password to let the player in, got %v", err)
+	}
+	r.mu.Unlock()
+
+	// Ended games keep it too, for the room to be started again
+	if err := store.SaveSnapshot("club", 1, withPasswordHash([]byte(`{"isGameEnded":true}`), hash)); err != nil {
+		t.Fatal(err)
+	}
+	if err := New("0", WithGameStore(store, DefaultSnapshotEvery)).passwords.check("club", ""); !errors.Is(err, errWrongPassword) {
+		t.Errorf("Expected an ended game to keep its password, got %v", err)
+	}
+}
+
+func TestRoomPasswordRecheck(t *testing.T) {
+	p := newRoomPasswords()
+	if err := p.recheck("club", false, ""); err != nil {
+		t.Fatalf("Expected an unprotected game to need no password, got %v", err)
+	}
+	hash, _ := hashPassword("mate")
+	p.set("club", hash)
+	if err := p.recheck("club", false, ""); !errors.Is(err, errWrongPassword) {
+		t.Errorf("Expected a game protected since it was checked to be checked again, got %v", err)
+	}
+	if err := p.recheck("club", false, "mate"); err != nil {
+		t.Errorf("Expected the password to pass the check again, got %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+// setProfile sets how the player is shown in the game, from their hello: names given by the
+// lobby or a tournament win over theirs, while their avatar is kept across reconnections
+// unless they send another. Others get the game state again if it changed, and the player
+// gets it on connecting. Must be called with the room locked, before playerConnected.
+func (r *room) setProfile(playerID int, name, avatar string) {
+	if matched := r.playerNames[playerID]; matched != "" {
+		name = matched
+	}
+	player := r.gameState.Players[playerID]
+	if name == "" {
+		name = player.Name
+	}
+	if avatar == "" {
+		avatar = player.Avatar
+	}
+	if name == player.Name && avatar == player.Avatar {
+		return
+	}
+	if err := r.gameState.SetPlayerProfile(playerID, name, avatar); err != nil {
+		r.logger.Warn("failed to set player profile", "playerID", playerID, "err", err)
+		return
+	}
+	for i, playerOut := range r.players {
+		if i == playerID || playerOut == nil {
+			continue
+		}
+		msg, _ := NewMessageHeresGameState(r.gameState.ToClientGameState(i))
+		playerOut.Send(msg)
+	}
+	r.notifySpectators()
+}
+
+// setPlayerName names the seat for a player matched in the lobby or a tournament, which
+// shows in the game too, if it makes a valid display name. Must be called with the room
+// locked.
+func (r *room) setPlayerName(playerID int, name string) {
+	r.playerNames[playerID] = name
+	if err := r.gameState.SetPlayerProfile(playerID, name, r.gameState.Players[playerID].Avatar); err != nil {
+		r.logger.Debug("player name isn't a valid display name", "playerID", playerID, "err", err)
+	}
+}
@@ -0,0 +1,90 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// rateLimitCloseTimeout is how long closing a connection for going over the rate limit
+// waits for its queued messages to be sent.
+const rateLimitCloseTimeout = time.Second
+
+// DefaultRateLimitBurst is the number of messages a connection may send at once with
+// WithRateLimit.
+const DefaultRateLimitBurst = 20
+
+// WithRateLimit limits the messages each WebSocket connection may send: burst at once, and
+// then one more every refill. Messages over the limit are ignored, and connections that
+// keep sending them are closed with a policy violation.
+func WithRateLimit(burst int, refill time.Duration) Option {
+	return func(s *server) {
+		s.rateLimitBurst, s.rateLimitRefill = burst, refill
+	}
+}
+
+// rateLimiter is a token bucket for a connection's messages. A nil rateLimiter allows
+// every message.
+type rateLimiter struct {
+	burst  int
+	refill time.Duration
+
+	tokens float64
+	last   time.Time
+
+	// dropped goes up with every message over the limit, and down with every allowed one.
+	// Going over burst means the limit is being ignored, rather than briefly exceeded.
+	dropped int
+}
+
+// newRateLimiter returns a rate limiter for a new connection, or nil without WithRateLimit.
+func (s *server) newRateLimiter() *rateLimiter {
+	if s.rateLimitRefill <= 0 {
+		return nil
+	}
+	return &rateLimiter{
+		burst:  s.rateLimitBurst,
+		refill: s.rateLimitRefill,
+		tokens: float64(s.rateLimitBurst),
+		last:   time.Now(),
+	}
+}
+
+// allow returns whether the connection may send another message now.
+func (l *rateLimiter) allow() bool {
+	if l == nil {
+		return true
+	}
+	now := time.Now()
+	l.tokens += float64(now.Sub(l.last)) / float64(l.refill)
+	if l.tokens > float64(l.burst) {
+		l.tokens = float64(l.burst)
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		l.dropped++
+		return false
+	}
+	l.tokens--
+	if l.dropped > 0 {
+		l.dropped--
+	}
+	return true
+}
+
+// abusive returns whether the connection keeps going over the limit, and should be closed.
+func (l *rateLimiter) abusive() bool {
+	return l != nil && l.dropped > l.burst
+}
+
+// closeRateLimited tells the connection it went over the rate limit, and closes it.
+func closeRateLimited(out *outbox) {
+	ctx, cancel := context.WithTimeout(context.Background(), rateLimitCloseTimeout)
+	defer cancel()
+	out.Shutdown(ctx, websocket.ClosePolicyViolation, "rate limit exceeded")
+}
@@ -0,0 +1,67 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := New("0", WithRateLimit(3, time.Hour)).newRateLimiter()
+	allowed := 0
+	for i := 0; i < 5; i++ {
+		if l.allow() {
+			allowed++
+		}
+	}
+	if allowed != 3 {
+		t.Errorf("Expected a burst of 3 messages to be allowed, got %d", allowed)
+	}
+	if l.abusive() {
+		t.Error("Expected briefly going over the limit not to be abusive")
+	}
+	l.last = l.last.Add(-time.Hour)
+	if !l.allow() {
+		t.Error("Expected another message to be allowed after the refill")
+	}
+}
+
+func TestRateLimitBurst(t *testing.T) {
+	s := New("0", WithRateLimit(3, time.Hour))
+	srv := httptest.NewServer(s.handler())
+	defer srv.Close()
+	defer s.polls.shutdown()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := WsSend(conn, NewMessageHello(0)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		// Writes fail once the server closes the connection.
+		_ = WsSend(conn, MessageGimmeGameState{WebsocketMessage: WebsocketMessage{Type: MessageTypeGimmeGameState}})
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+		var closeErr *websocket.CloseError
+		if !errors.As(err, &closeErr) || closeErr.Code != websocket.ClosePolicyViolation {
+			t.Errorf("Expected the burst to be closed with a policy violation, got %v", err)
+		}
+		return
+	}
+}
@@ -0,0 +1,68 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/devblac/chinchon/chinchon"
+	"github.com/gorilla/mux"
+)
+
+// maxReplays is the number of finished games whose replays are kept in memory.
+const maxReplays = 1000
+
+// replays keeps the replay files of the games finished on this server, dropping the oldest
+// past maxReplays. They aren't persisted, but finished games in the game store have theirs.
+type replays struct {
+	mu    sync.Mutex
+	files map[string]chinchon.ReplayFile
+	order []string // game IDs, oldest first
+}
+
+func newReplays() *replays {
+	return &replays{files: map[string]chinchon.ReplayFile{}}
+}
+
+// record keeps the room's ended game's replay. Must be called with the room locked.
+func (rs *replays) record(r *room) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if _, ok := rs.files[r.id]; !ok {
+		rs.order = append(rs.order, r.id)
+	}
+	rs.files[r.id] = r.gameState.ReplayFile()
+	if len(rs.order) > maxReplays {
+		delete(rs.files, rs.order[0])
+		rs.order = rs.order[1:]
+	}
+}
+
+func (rs *replays) get(gameID string) (chinchon.ReplayFile, bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	file, ok := rs.files[gameID]
+	return file, ok
+}
+
+// handleAPIGameReplay serves the replay file of the finished game with the ID in the URL,
+// which chinchon.ReplayFile.Replay rebuilds the game from. Games being played have none, as
+// it would show every hand.
+func (s *server) handleAPIGameReplay(w http.ResponseWriter, r *http.Request) {
+	gameID := mux.Vars(r)["id"]
+	file, ok := s.replays.get(gameID)
+	if !ok {
+		gs, err := s.storedGame(gameID)
+		if err != nil || !gs.IsGameEnded {
+			http.NotFound(w, r)
+			return
+		}
+		file = gs.ReplayFile()
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", gameID+".replay.json"))
+	writeJSON(w, file)
+}
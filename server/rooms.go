@@ -0,0 +1,251 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/devblac/chinchon/chinchon"
+	"github.com/devblac/chinchon/rating"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultGameID is the game players join when their hello message doesn't name one. It's
+// the server's hosted game from before it hosted many, and is stored as HostedGameID.
+const DefaultGameID = HostedGameID
+
+var errInvalidGameID = errors.New("invalid game ID")
+
+// validGameID restricts game IDs to what's safe to use in URLs and file names.
+var validGameID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// room is a game hosted by the server, with the connections of its players.
+type room struct {
+	// mu guards the game: gameState, players and takeover.
+	mu sync.Mutex
+
+	id        string
+	logger    *slog.Logger // with the game ID
+	tracer    trace.Tracer
+	gameState *chinchon.GameState
+	players   []*outbox
+	startedAt time.Time
+	updatedAt time.Time // when the game last changed
+	stats     *statsCollector
+	takeover  *botTakeover
+	journal   *gameJournal
+	ended     bool
+
+	// playerNames are the names of the players matched in the lobby, by player ID, whose
+	// ratings are updated when the game ends.
+	playerNames map[int]string
+	ratings     *rating.Ratings
+	history     *playerHistory
+	replays     *replays
+	leaderboard *leaderboard
+
+	// sessions are the nonces of the session tokens of claimed seats, by player ID, and
+	// heldUntil when their claim may be taken over by another player.
+	sessions  map[int]string
+	heldUntil map[int]time.Time
+
+	// bots are the hosted bots playing in the game, by player ID.
+	bots map[int]chinchon.Bot
+
+	// spectators are the connections watching the game.
+	spectators map[*spectator]bool
+
+	// creator is the name of the first player to join the game with one, if any.
+	creator string
+
+	// tournamentMatch is the tournament match the game is for, if any.
+	tournamentMatch *tournamentMatch
+
+	// exhibition is set if the game is played by bots, paced for spectators.
+	exhibition *exhibition
+
+	// rtts are the latest round-trip times of the connected players' WebSocket
+	// connections, by player ID.
+	rtts map[int]time.Duration
+
+	// unsubscribe stops updates from other server instances, with a shared game store.
+	unsubscribe func()
+
+	// notifyTargets are where to notify players when it's their turn, by player ID (see
+	// WithTurnNotifier), and lastTurnPlayerID whose turn it was when last checked.
+	notifier         TurnNotifier
+	notifyTargets    map[int]string
+	lastTurnPlayerID int
+}
+
+// newRoom starts the game with the given ID and engine options, resuming it from the game
+// store if it's persisted and hasn't ended. A password hash protects it before anyone can
+// join it, and is otherwise restored from the store, if any. Must be called with the
+// server's rooms locked.
+func (s *server) newRoom(gameID string, gameOptions []func(*chinchon.GameState), passwordHash []byte) (*room, error) {
+	if err := s.checkGameLimit(); err != nil {
+		return nil, err
+	}
+	r := &room{id: gameID, logger: s.logger.With("gameID", gameID), tracer: s.tracer, startedAt: time.Now(), updatedAt: time.Now(), stats: s.stats, playerNames: map[int]string{}, ratings: s.ratings, history: s.history, replays: s.replays, leaderboard: s.leaderboard, sessions: map[int]string{}, heldUntil: map[int]time.Time{}, spectators: map[*spectator]bool{}, bots: s.newHostedBots(), rtts: map[int]time.Duration{}, notifier: s.turnNotifier, notifyTargets: map[int]string{}}
+	if s.takeoverConfig != nil {
+		r.takeover = newBotTakeover(s.takeoverConfig.turnTimeout, s.takeoverConfig.maxTimeouts)
+	}
+	if s.store != nil {
+		r.journal = &gameJournal{store: s.store, gameID: gameID, snapshotEvery: s.snapshotEvery, tracer: s.tracer, passwordHash: passwordHash}
+		gameState, err := r.restoreGame(gameOptions)
+		if err != nil {
+			return nil, err
+		}
+		r.gameState = gameState
+		passwordHash = r.journal.passwordHash
+	} else {
+		r.gameState = chinchon.New(gameOptions...)
+	}
+	r.players = make([]*outbox, len(r.gameState.Players))
+	r.lastTurnPlayerID = r.gameState.TurnPlayerID
+	if r.journal != nil {
+		if err := r.subscribe(); err != nil {
+			return nil, err
+		}
+	}
+	if passwordHash != nil {
+		s.passwords.set(gameID, passwordHash)
+	}
+	// Hosted bots may have the first turn.
+	r.runHostedBotTurns()
+	s.rooms[gameID] = r
+	s.stats.gameStarted()
+	return r, nil
+}
+
+// room returns the game with the given ID, starting it if it isn't being played.
+func (s *server) room(gameID string) (*room, error) {
+	s.roomsMu.Lock()
+	defer s.roomsMu.Unlock()
+	return s.roomLocked(gameID, s.gameOptions)
+}
+
+// roomLocked is room, with the server's rooms locked, starting the game with the engine
+// options if needed.
+func (s *server) roomLocked(gameID string, gameOptions []func(*chinchon.GameState)) (*room, error) {
+	if gameID == "" {
+		gameID = DefaultGameID
+	}
+	if !validGameID.MatchString(gameID) {
+		return nil, fmt.Errorf("%w: %q", errInvalidGameID, gameID)
+	}
+	if r, ok := s.rooms[gameID]; ok {
+		return r, nil
+	}
+	s.logger.Info("starting game", "gameID", gameID)
+	return s.newRoom(gameID, gameOptions, nil)
+}
+
+// existingRoom returns the game with the given ID, if it's being played.
+func (s *server) existingRoom(gameID string) (*room, bool) {
+	if gameID == "" {
+		gameID = DefaultGameID
+	}
+	s.roomsMu.Lock()
+	defer s.roomsMu.Unlock()
+	r, ok := s.rooms[gameID]
+	return r, ok
+}
+
+// liveRooms returns the games being played, by ID.
+func (s *server) liveRooms() []*room {
+	s.roomsMu.Lock()
+	defer s.roomsMu.Unlock()
+	rooms := make([]*room, 0, len(s.rooms))
+	for _, r := range s.rooms {
+		rooms = append(rooms, r)
+	}
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].id < rooms[j].id })
+	return rooms
+}
+
+// join seats a connection as the player in the game with the given ID, starting the game
+// with the engine options if needed, and replacing the seat's connection as the seat
+// policy says. Players of games with a password must give it, unless they present their
+// session token. The room is returned locked, with a new session token if the player
+// claimed the seat.
+func (s *server) join(gameID string, playerID int, token string, password string, out *outbox, gameOptions []func(*chinchon.GameState)) (*room, string, error) {
+	// Tokens are checked once seated (see authenticate), and were only issued to players
+	// who got in.
+	checksPassword := s.auth == nil || token == ""
+	wasProtected := false
+	if checksPassword {
+		hash := s.passwords.hash(cmp.Or(gameID, DefaultGameID))
+		if err := checkPassword(hash, password); err != nil {
+			return nil, "", err
+		}
+		wasProtected = hash != nil
+	}
+
+	// The rooms stay locked until the player is seated, so that the room can't be closed
+	// in between.
+	s.roomsMu.Lock()
+	defer s.roomsMu.Unlock()
+
+	r, err := s.roomLocked(gameID, gameOptions)
+	if err != nil {
+		return nil, "", err
+	}
+	r.mu.Lock()
+	if checksPassword {
+		// The game may have been started with a password since it was checked.
+		if err := s.passwords.recheck(r.id, wasProtected, password); err != nil {
+			r.mu.Unlock()
+			return nil, "", err
+		}
+	}
+	if playerID < 0 || playerID >= len(r.players) {
+		r.mu.Unlock()
+		return nil, "", fmt.Errorf("%w: %d", errInvalidSeat, playerID)
+	}
+	if r.bots[playerID] != nil || (r.players[playerID] != nil && !s.mayReplace(token)) {
+		r.mu.Unlock()
+		return nil, "", fmt.Errorf("%w: %d", errSeatTaken, playerID)
+	}
+	newToken, err := s.authenticate(r, playerID, token)
+	if err != nil {
+		r.mu.Unlock()
+		return nil, "", err
+	}
+	if r.players[playerID] != nil {
+		r.replaceConnection(playerID)
+	}
+	r.players[playerID] = out
+	return r, newToken, nil
+}
+
+// closeRoomIfDone forgets the game once it has ended and every player has left, so that
+// a new game may be started with the same ID.
+func (s *server) closeRoomIfDone(r *room) {
+	s.roomsMu.Lock()
+	defer s.roomsMu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.gameState.IsGameEnded || s.rooms[r.id] != r {
+		return
+	}
+	for _, playerOut := range r.players {
+		if playerOut != nil {
+			return
+		}
+	}
+	delete(s.rooms, r.id)
+	if r.unsubscribe != nil {
+		r.unsubscribe()
+	}
+	r.logger.Info("closed finished game")
+}
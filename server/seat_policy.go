@@ -0,0 +1,61 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"context"
+
+	"github.com/gorilla/websocket"
+)
+
+// SeatPolicy is what happens when a player joins a seat that's already connected, e.g.
+// from a second tab, or after a network change the server hasn't noticed yet.
+type SeatPolicy string
+
+const (
+	// SeatPolicyReject, the default, turns the new connection away.
+	SeatPolicyReject SeatPolicy = "reject"
+
+	// SeatPolicyKickOlder closes the older connection, for the new one to take the seat.
+	// With WithAuth (or WithReconnectGrace), only players with the seat's token may do so.
+	SeatPolicyKickOlder SeatPolicy = "kick_older"
+)
+
+// Reasons of MessageSeatTaken.
+const (
+	// SeatTakenReasonOccupied tells a new connection that the seat was already connected.
+	SeatTakenReasonOccupied = "occupied"
+
+	// SeatTakenReasonReplaced tells a connection that a newer one took its seat.
+	SeatTakenReasonReplaced = "replaced"
+)
+
+// WithSeatPolicy sets what happens when a player joins a seat that's already connected
+// (SeatPolicyReject by default). Either way, the connection turned away is told why, with
+// a MessageSeatTaken.
+func WithSeatPolicy(policy SeatPolicy) Option {
+	return func(s *server) {
+		s.seatPolicy = policy
+	}
+}
+
+// mayReplace returns true if a player joining the connected seat with the token closes
+// its connection. The token is authenticated afterwards, before doing so.
+func (s *server) mayReplace(token string) bool {
+	return s.seatPolicy == SeatPolicyKickOlder && (s.auth == nil || token != "")
+}
+
+// replaceConnection tells the seat's connection that a newer one took the seat, and closes
+// it once told. Must be called with the room locked.
+func (r *room) replaceConnection(playerID int) {
+	out := r.players[playerID]
+	r.players[playerID] = nil
+	out.Send(NewMessageSeatTaken(playerID, SeatTakenReasonReplaced))
+	r.logger.Info("player joined again, closing their older connection", "playerID", playerID)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), kickTimeout)
+		defer cancel()
+		out.Shutdown(ctx, websocket.CloseNormalClosure, "seat taken by a newer connection")
+	}()
+}
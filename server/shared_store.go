@@ -0,0 +1,126 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/devblac/chinchon/chinchon"
+)
+
+// ErrSeqConflict is returned by a SharedGameStore when appending an action whose seq was
+// already taken, i.e. another server instance ran an action on the game first.
+var ErrSeqConflict = errors.New("action seq already taken")
+
+// maxActionAttempts is how many times an action is run before giving up, when other server
+// instances keep running actions on the same game first.
+const maxActionAttempts = 3
+
+// SharedGameStore is a GameStore shared by several server instances (e.g. behind a load
+// balancer), which may all host the same games: each instance runs the game's actions
+// in the order they were appended to the store, whichever instance ran them.
+//
+// A shared store must reject appended actions that aren't the game's next one with
+// ErrSeqConflict, and ignore snapshots older than, or as old as, the last one (so that
+// when instances start a game at the same time, the first one's deal is kept).
+type SharedGameStore interface {
+	GameStore
+
+	// Subscribe calls onAction with every action appended to the game, by any instance,
+	// until unsubscribe is called.
+	Subscribe(gameID string, onAction func(JournalEntry)) (unsubscribe func(), err error)
+}
+
+// subscribe keeps the game up to date with the actions run by other server instances, if
+// its store is shared.
+func (r *room) subscribe() error {
+	store, ok := r.journal.store.(SharedGameStore)
+	if !ok {
+		return nil
+	}
+	// The game may have changed before the subscription started.
+	unsubscribe, err := store.Subscribe(r.id, r.remoteAction)
+	if err != nil {
+		return err
+	}
+	r.unsubscribe = unsubscribe
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.resync()
+}
+
+// remoteAction runs an action appended to the store, if this instance hasn't run it yet,
+// and sends the resulting game state to the players connected to this instance.
+func (r *room) remoteAction(entry JournalEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entry.Seq <= r.journal.seq {
+		return // Run by this instance, or already caught up with
+	}
+	if err := r.runRemoteAction(entry); err != nil {
+		r.logger.Warn("failed to run action from another instance, reloading game", "seq", entry.Seq, "err", err)
+		if err := r.resync(); err != nil {
+			r.logger.Error("failed to reload game", "err", err)
+			return
+		}
+	}
+	r.gameStateChanged()
+}
+
+// runRemoteAction runs the action appended to the store, if it's the game's next one.
+// Must be called with the room locked.
+func (r *room) runRemoteAction(entry JournalEntry) error {
+	if entry.Seq != r.journal.seq+1 {
+		return fmt.Errorf("missed actions %d to %d", r.journal.seq+1, entry.Seq-1)
+	}
+	return r.journal.apply(r.gameState, entry)
+}
+
+// resync reloads the game from the store. Must be called with the room locked.
+func (r *room) resync() error {
+	gs, err := r.journal.restore()
+	if err != nil {
+		return err
+	}
+	r.gameState = gs
+	return nil
+}
+
+// runAction runs the action on the game, and journals it if the game is persisted. If
+// another server instance ran an action on the game first, the game is brought up to date
+// and the action run again, if it's still possible. Must be called with the room locked.
+func (r *room) runAction(ctx context.Context, action chinchon.Action, playedByBot bool) error {
+	for attempt := 1; ; attempt++ {
+		run := r.gameState.RunAction
+		if playedByBot {
+			run = r.gameState.RunTakeoverAction
+		}
+		_, span := r.startSpan(ctx, "chinchon.RunAction", attrPlayerID.Int(action.GetPlayerID()), attrAction.String(action.GetName()), attrPlayedByBot.Bool(playedByBot))
+		err := run(action)
+		endSpan(span, err)
+		if err != nil {
+			return err
+		}
+		if r.journal == nil {
+			return nil
+		}
+
+		err = r.journal.record(ctx, r.gameState, action, playedByBot)
+		if !errors.Is(err, ErrSeqConflict) {
+			if err != nil {
+				r.logger.Error("failed to persist action", "err", err)
+			}
+			return nil
+		}
+		if err := r.resync(); err != nil {
+			return err
+		}
+		if attempt == maxActionAttempts {
+			return err
+		}
+	}
+}
@@ -0,0 +1,83 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ShutdownTimeout is how long the server waits for queued messages to reach clients when
+// shutting down, before closing their connections anyway.
+const ShutdownTimeout = 10 * time.Second
+
+// Shutdown stops the server for maintenance: it stops accepting connections, snapshots
+// every game being played to the game store (if any), so that they resume when the server
+// restarts, and tells every player and spectator before closing their connections.
+func (s *server) Shutdown(ctx context.Context) error {
+	var err error
+	if s.polls != nil {
+		s.polls.shutdown()
+	}
+	if s.httpServer != nil {
+		// Connections upgraded to WebSocket aren't waited for, so this returns right away.
+		err = s.httpServer.Shutdown(ctx)
+	}
+
+	grpcStopped := make(chan struct{})
+	if s.grpcServer != nil {
+		// Waits for calls to end, which streams do once shut down below.
+		go func() {
+			s.grpcServer.GracefulStop()
+			close(grpcStopped)
+		}()
+	}
+
+	rooms := s.liveRooms()
+	outs := []*outbox{}
+	for _, r := range rooms {
+		r.mu.Lock()
+		if r.takeover != nil && r.takeover.timer != nil {
+			r.takeover.timer.Stop()
+		}
+		if r.journal != nil && !r.gameState.IsGameEnded {
+			if err := r.journal.snapshot(ctx, r.gameState); err != nil {
+				r.logger.Error("failed to snapshot game on shutdown", "err", err)
+			}
+		}
+		for _, out := range r.players {
+			if out != nil {
+				out.Send(NewMessageMaintenance())
+				outs = append(outs, out)
+			}
+		}
+		for sp := range r.spectators {
+			sp.out.Send(NewMessageMaintenance())
+			outs = append(outs, sp.out)
+		}
+		r.mu.Unlock()
+	}
+
+	var wg sync.WaitGroup
+	for _, out := range outs {
+		wg.Add(1)
+		go func(out *outbox) {
+			defer wg.Done()
+			out.Shutdown(ctx, websocket.CloseServiceRestart, "maintenance")
+		}(out)
+	}
+	wg.Wait()
+	if s.grpcServer != nil {
+		select {
+		case <-grpcStopped:
+		case <-ctx.Done():
+			s.grpcServer.Stop()
+		}
+	}
+	s.logger.Info("shut down", "games", len(rooms), "connections", len(outs))
+	return err
+}
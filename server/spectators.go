@@ -0,0 +1,101 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"log/slog"
+
+	"github.com/gorilla/websocket"
+)
+
+// spectator is a connection watching a game. Spectators never see hidden cards live: they
+// either get the public view as it happens, or every hand through a delayed feed.
+type spectator struct {
+	out    *outbox
+	reveal bool
+	feed   *delayedFeed // with reveal and a spectator delay
+}
+
+// send sends the game state, as the spectator sees it. Must be called with the room locked.
+func (sp *spectator) send(r *room) {
+	msg, err := NewMessageHeresSpectatorGameState(r.gameState.ToSpectatorGameState(sp.reveal))
+	if err != nil {
+		r.logger.Error("failed to marshal spectator game state", "err", err)
+		return
+	}
+	if sp.feed != nil {
+		sp.feed.Send(msg)
+		return
+	}
+	sp.out.Send(msg)
+}
+
+// notifySpectators sends the game state to every spectator. Must be called with the room
+// locked.
+func (r *room) notifySpectators() {
+	for sp := range r.spectators {
+		sp.send(r)
+	}
+}
+
+// spectate streams the game to the connection, until it's closed.
+func (s *server) spectate(conn *websocket.Conn, spectate MessageSpectate, logger *slog.Logger) {
+	room, ok := s.existingRoom(spectate.GameID)
+	if !ok {
+		logger.Info("can't spectate game which isn't being played", "gameID", spectate.GameID)
+		return
+	}
+
+	logger = room.logger.With("remoteAddr", conn.RemoteAddr().String())
+	if err := s.passwords.check(room.id, spectate.Password); err != nil {
+		logger.Info("can't spectate game", "err", err)
+		_ = WsSend(conn, NewMessageError(ErrorCodeWrongPassword, err.Error()))
+		return
+	}
+	sp := &spectator{out: newOutbox(conn, s.sendQueue), reveal: spectate.Reveal}
+	if spectate.Deltas {
+		sp.out.SendDeltas(s.fullStateEvery)
+	}
+	defer sp.out.Close()
+	if sp.reveal && s.spectatorDelay > 0 {
+		sp.feed = newDelayedFeed(s.spectatorDelay, sp.out.Send)
+		defer sp.feed.Close()
+	}
+
+	room.mu.Lock()
+	room.spectators[sp] = true
+	sp.send(room)
+	room.mu.Unlock()
+	logger.Info("spectator connected")
+
+	defer func() {
+		room.mu.Lock()
+		delete(room.spectators, sp)
+		room.mu.Unlock()
+	}()
+
+	limiter := s.newRateLimiter()
+	for {
+		messageType, _, err := WsReadAnyMessage(conn)
+		if err != nil {
+			logger.Info("spectator left", "err", err)
+			return
+		}
+		if !limiter.allow() {
+			if limiter.abusive() {
+				logger.Warn("spectator kept going over the rate limit, disconnecting")
+				closeRateLimited(sp.out)
+				return
+			}
+			continue
+		}
+		// Spectators may only ask for the state again, e.g. if they got out of sync.
+		if messageType == MessageTypeGimmeGameState {
+			room.mu.Lock()
+			sp.out.Resync()
+			sp.send(room)
+			room.mu.Unlock()
+		}
+	}
+}
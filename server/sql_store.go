@@ -0,0 +1,184 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SQLDialect is the flavor of SQL spoken by a SQLGameStore's database.
+type SQLDialect string
+
+const (
+	SQLite   SQLDialect = "sqlite"
+	Postgres SQLDialect = "postgres"
+)
+
+// SQLGameStore is a GameStore keeping games in a SQLite or Postgres database, in two
+// tables: games, with each game's last snapshot, and game_actions, with the action log
+// since. Finished games stay in the database, so they can be queried for history.
+//
+// It works with any database/sql driver for the dialect (e.g. modernc.org/sqlite or
+// github.com/lib/pq), which the program must import.
+type SQLGameStore struct {
+	db      *sql.DB
+	dialect SQLDialect
+}
+
+// NewSQLGameStore creates a store in the database, creating its tables if needed.
+func NewSQLGameStore(db *sql.DB, dialect SQLDialect) (*SQLGameStore, error) {
+	if dialect != SQLite && dialect != Postgres {
+		return nil, fmt.Errorf("unsupported SQL dialect %q", dialect)
+	}
+	s := &SQLGameStore{db: db, dialect: dialect}
+	for _, statement := range []string{
+		`CREATE TABLE IF NOT EXISTS games (
+			id TEXT PRIMARY KEY,
+			seq INTEGER NOT NULL,
+			snapshot TEXT NOT NULL,
+			ended BOOLEAN NOT NULL,
+			updated_at BIGINT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS game_actions (
+			game_id TEXT NOT NULL,
+			seq INTEGER NOT NULL,
+			action TEXT NOT NULL,
+			played_by_bot BOOLEAN NOT NULL,
+			deals TEXT NOT NULL DEFAULT '',
+			PRIMARY KEY (game_id, seq)
+		)`,
+	} {
+		if _, err := db.Exec(statement); err != nil {
+			return nil, fmt.Errorf("failed to create tables: %w", err)
+		}
+	}
+	// Tables created before actions were logged with their deals lack the column.
+	if _, err := db.Exec(`SELECT deals FROM game_actions LIMIT 0`); err != nil {
+		if _, err := db.Exec(`ALTER TABLE game_actions ADD COLUMN deals TEXT NOT NULL DEFAULT ''`); err != nil {
+			return nil, fmt.Errorf("failed to add the deals column: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// query adapts the query's ? placeholders to the dialect.
+func (s *SQLGameStore) query(query string) string {
+	if s.dialect != Postgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+func (s *SQLGameStore) SaveSnapshot(gameID string, seq int, snapshot []byte) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(s.query(`INSERT INTO games (id, seq, snapshot, ended, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET seq = excluded.seq, snapshot = excluded.snapshot,
+		ended = excluded.ended, updated_at = excluded.updated_at`),
+		gameID, seq, string(snapshot), SnapshotEnded(snapshot), time.Now().UnixMilli())
+	if err != nil {
+		return err
+	}
+	// Like with files, the action log restarts from the snapshot.
+	if _, err := tx.Exec(s.query(`DELETE FROM game_actions WHERE game_id = ?`), gameID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLGameStore) AppendAction(gameID string, entry JournalEntry) error {
+	deals := ""
+	if len(entry.Deals) > 0 {
+		bs, err := json.Marshal(entry.Deals)
+		if err != nil {
+			return err
+		}
+		deals = string(bs)
+	}
+	_, err := s.db.Exec(s.query(`INSERT INTO game_actions (game_id, seq, action, played_by_bot, deals) VALUES (?, ?, ?, ?, ?)`),
+		gameID, entry.Seq, string(entry.Action), entry.PlayedByBot, deals)
+	return err
+}
+
+func (s *SQLGameStore) LoadGame(gameID string) ([]byte, int, []JournalEntry, error) {
+	var (
+		snapshot string
+		seq      int
+	)
+	err := s.db.QueryRow(s.query(`SELECT snapshot, seq FROM games WHERE id = ?`), gameID).Scan(&snapshot, &seq)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, nil, ErrGameNotFound
+	}
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	rows, err := s.db.Query(s.query(`SELECT seq, action, played_by_bot, deals FROM game_actions WHERE game_id = ? ORDER BY seq`), gameID)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	defer rows.Close()
+
+	entries := []JournalEntry{}
+	for rows.Next() {
+		var (
+			entry  JournalEntry
+			action string
+			deals  string
+		)
+		if err := rows.Scan(&entry.Seq, &action, &entry.PlayedByBot, &deals); err != nil {
+			return nil, 0, nil, err
+		}
+		entry.Action = []byte(action)
+		if deals != "" {
+			if err := json.Unmarshal([]byte(deals), &entry.Deals); err != nil {
+				return nil, 0, nil, fmt.Errorf("corrupt deals of action %d: %w", entry.Seq, err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return []byte(snapshot), seq, entries, rows.Err()
+}
+
+func (s *SQLGameStore) ListGames() ([]GameInfo, error) {
+	rows, err := s.db.Query(s.query(`SELECT id, seq, ended, updated_at FROM games ORDER BY updated_at DESC, id`))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	games := []GameInfo{}
+	for rows.Next() {
+		var (
+			game      GameInfo
+			updatedAt int64
+		)
+		if err := rows.Scan(&game.ID, &game.Seq, &game.Ended, &updatedAt); err != nil {
+			return nil, err
+		}
+		game.UpdatedAt = time.UnixMilli(updatedAt)
+		games = append(games, game)
+	}
+	return games, rows.Err()
+}
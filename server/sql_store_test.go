@@ -0,0 +1,111 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/devblac/chinchon/chinchon"
+	_ "modernc.org/sqlite"
+)
+
+// openSQLiteStore opens a store in the SQLite database at the path, as after a restart.
+func openSQLiteStore(t *testing.T, path string) *SQLGameStore {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	store, err := NewSQLGameStore(db, SQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestSQLGameStore(t *testing.T) {
+	store := openSQLiteStore(t, filepath.Join(t.TempDir(), "games.db"))
+	if _, _, _, err := store.LoadGame("casa"); !errors.Is(err, ErrGameNotFound) {
+		t.Fatalf("Expected an unknown game not to be found, got %v", err)
+	}
+
+	gs := chinchon.New()
+	gs.Abort()
+	ended, _ := gs.Save()
+	if err := store.SaveSnapshot("casa", 0, ended); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond) // Snapshots are timed to the millisecond
+	live, _ := chinchon.New().Save()
+	if err := store.SaveSnapshot("main", 0, live); err != nil {
+		t.Fatal(err)
+	}
+	entry := JournalEntry{Seq: 1, Action: []byte(`{"name":"draw_from_deck","playerID":0}`), PlayedByBot: true, Deals: []chinchon.Deal{{Cards: []chinchon.Card{{Suit: chinchon.ORO, Number: 1}}}}}
+	if err := store.AppendAction("main", entry); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, seq, entries, err := store.LoadGame("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(snapshot) != string(live) || seq != 0 || len(entries) != 1 || !entries[0].PlayedByBot || len(entries[0].Deals) != 1 {
+		t.Errorf("Expected the snapshot and the action logged since, got seq %d and %+v", seq, entries)
+	}
+	games, err := store.ListGames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 2 || games[0].ID != "main" || games[0].Ended || games[1].ID != "casa" || !games[1].Ended {
+		t.Errorf("Expected both games, most recently snapshotted first, got %+v", games)
+	}
+
+	// The action log restarts from a new snapshot
+	if err := store.SaveSnapshot("main", 1, live); err != nil {
+		t.Fatal(err)
+	}
+	if _, seq, entries, _ := store.LoadGame("main"); seq != 1 || len(entries) != 0 {
+		t.Errorf("Expected the action log to be discarded with a new snapshot, got seq %d and %+v", seq, entries)
+	}
+}
+
+func TestSQLGameStoreRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "games.db")
+	j := &gameJournal{store: openSQLiteStore(t, path), gameID: HostedGameID, snapshotEvery: DefaultSnapshotEvery}
+	gs := chinchon.New()
+	if err := j.snapshot(context.Background(), gs); err != nil {
+		t.Fatal(err)
+	}
+	action := chinchon.NewActionDrawFromDeck(gs.TurnPlayerID)
+	if err := gs.RunAction(action); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.record(context.Background(), gs, action, false); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := RestoreGame(openSQLiteStore(t, path), HostedGameID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Fingerprint() != gs.Fingerprint() {
+		t.Error("Expected the game to survive a restart")
+	}
+}
+
+func TestSQLQueryPlaceholders(t *testing.T) {
+	postgres := &SQLGameStore{dialect: Postgres}
+	if query := postgres.query(`SELECT seq FROM games WHERE id = ? AND seq > ?`); query != `SELECT seq FROM games WHERE id = $1 AND seq > $2` {
+		t.Errorf("Expected numbered placeholders for Postgres, got %q", query)
+	}
+	sqlite := &SQLGameStore{dialect: SQLite}
+	if query := sqlite.query(`SELECT seq FROM games WHERE id = ?`); query != `SELECT seq FROM games WHERE id = ?` {
+		t.Errorf("Expected SQLite's placeholders to be kept, got %q", query)
+	}
+}
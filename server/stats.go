@@ -0,0 +1,127 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"embed"
+	"net/http"
+	"sync"
+	"time"
+)
+
+//go:embed assets
+var assets embed.FS
+
+// ServerStats is a snapshot of live server statistics, as served by /stats.
+type ServerStats struct {
+	ActiveGames              int     `json:"activeGames"`
+	PlayersOnline            int     `json:"playersOnline"`
+	GamesFinished            int     `json:"gamesFinished"`
+	GamesLastHour            int     `json:"gamesLastHour"`
+	AverageGameLengthSeconds float64 `json:"averageGameLengthSeconds"`
+	BotWins                  int     `json:"botWins"`
+	HumanWins                int     `json:"humanWins"`
+	HumanWinRate             float64 `json:"humanWinRate"`
+}
+
+// statsCollector is fed by the game handlers; all methods are safe for concurrent use.
+type statsCollector struct {
+	mu            sync.Mutex
+	activeGames   int
+	playersOnline int
+	botWins       int
+	humanWins     int
+
+	// gamesFinished and totalLength are the count and added lengths of every finished game,
+	// and finishedLastHour the times the ones of the last hour finished at, oldest first.
+	gamesFinished    int
+	totalLength      time.Duration
+	finishedLastHour []time.Time
+}
+
+func (c *statsCollector) playerConnected() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.playersOnline++
+}
+
+func (c *statsCollector) playerDisconnected() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.playersOnline--
+}
+
+func (c *statsCollector) gameStarted() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.activeGames++
+}
+
+// gameAbandoned is for games that stop without finishing, e.g. a daily challenge whose
+// player disconnected.
+func (c *statsCollector) gameAbandoned() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.activeGames--
+}
+
+func (c *statsCollector) gameFinished(startedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.activeGames--
+	c.gamesFinished++
+	c.totalLength += time.Since(startedAt)
+	c.finishedLastHour = append(c.pruneFinished(), time.Now())
+}
+
+// pruneFinished drops the games that finished over an hour ago from finishedLastHour,
+// returning it. Must be called with the collector locked.
+func (c *statsCollector) pruneFinished() []time.Time {
+	i := 0
+	for i < len(c.finishedLastHour) && time.Since(c.finishedLastHour[i]) > time.Hour {
+		i++
+	}
+	c.finishedLastHour = c.finishedLastHour[i:]
+	return c.finishedLastHour
+}
+
+// botGameFinished records the result of a human vs bot game.
+func (c *statsCollector) botGameFinished(humanWon bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if humanWon {
+		c.humanWins++
+	} else {
+		c.botWins++
+	}
+}
+
+func (c *statsCollector) snapshot() ServerStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := ServerStats{
+		ActiveGames:   c.activeGames,
+		PlayersOnline: c.playersOnline,
+		GamesFinished: c.gamesFinished,
+		GamesLastHour: len(c.pruneFinished()),
+		BotWins:       c.botWins,
+		HumanWins:     c.humanWins,
+	}
+	if c.gamesFinished > 0 {
+		stats.AverageGameLengthSeconds = c.totalLength.Seconds() / float64(c.gamesFinished)
+	}
+	if c.botWins+c.humanWins > 0 {
+		stats.HumanWinRate = float64(c.humanWins) / float64(c.botWins+c.humanWins)
+	}
+	return stats
+}
+
+func (s *server) handleStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.stats.snapshot())
+}
+
+func (s *server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	http.ServeFileFS(w, r, assets, "assets/dashboard.html")
+}
@@ -0,0 +1,29 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatsCollector(t *testing.T) {
+	c := &statsCollector{}
+	c.gameStarted()
+	c.gameStarted()
+	c.gameFinished(time.Now().Add(-10 * time.Minute))
+	c.gameFinished(time.Now().Add(-20 * time.Minute))
+	c.finishedLastHour[0] = time.Now().Add(-2 * time.Hour) // as if it finished long ago
+
+	stats := c.snapshot()
+	if stats.ActiveGames != 0 || stats.GamesFinished != 2 || stats.GamesLastHour != 1 {
+		t.Errorf("Expected 2 games finished, 1 in the last hour, got %+v", stats)
+	}
+	if stats.AverageGameLengthSeconds < 15*60 || stats.AverageGameLengthSeconds > 15*60+5 {
+		t.Errorf("Expected games to last 15 minutes on average, got %v seconds", stats.AverageGameLengthSeconds)
+	}
+	if len(c.finishedLastHour) != 1 {
+		t.Errorf("Expected games finished over an hour ago to be forgotten, got %d kept", len(c.finishedLastHour))
+	}
+}
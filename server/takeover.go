@@ -0,0 +1,146 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/devblac/chinchon/chinchon"
+	"github.com/devblac/chinchon/examplebot/newbot"
+)
+
+// DefaultMaxIdleTimeouts is the number of consecutive turn timeouts before a bot takes over.
+const DefaultMaxIdleTimeouts = 3
+
+// botTakeover is the state of the bot takeover rule: players who let their turn time out
+// maxTimeouts times in a row get a bot playing for them, until they come back.
+type botTakeover struct {
+	turnTimeout time.Duration
+	maxTimeouts int
+	bot         chinchon.Bot
+
+	timer      *time.Timer
+	timerTurn  int // incremented on every new timer, so stale timers can be told apart
+	timeouts   map[int]int
+	takenOver  map[int]bool
+	timingTurn int // player ID whose turn is being timed
+}
+
+// WithBotTakeover enables the bot takeover rule: a player who idles past maxTimeouts
+// consecutive turn timeouts is replaced by a bot for the rest of the session, or until
+// they send any message or reconnect. Bot-played actions are marked in the action log.
+func WithBotTakeover(turnTimeout time.Duration, maxTimeouts int) Option {
+	return func(s *server) {
+		s.takeoverConfig = &botTakeover{turnTimeout: turnTimeout, maxTimeouts: maxTimeouts}
+	}
+}
+
+// newBotTakeover returns the bot takeover state for a new game.
+func newBotTakeover(turnTimeout time.Duration, maxTimeouts int) *botTakeover {
+	return &botTakeover{
+		turnTimeout: turnTimeout,
+		maxTimeouts: maxTimeouts,
+		bot:         newbot.New(),
+		timeouts:    map[int]int{},
+		takenOver:   map[int]bool{},
+	}
+}
+
+// playerIsBack resets the player's idle count, and reclaims their seat from the bot.
+// Must be called with the room locked.
+func (r *room) playerIsBack(playerID int) {
+	if r.takeover == nil {
+		return
+	}
+	r.takeover.timeouts[playerID] = 0
+	if r.takeover.takenOver[playerID] {
+		r.takeover.takenOver[playerID] = false
+		r.logger.Info("player reclaimed their seat from the bot", "playerID", playerID)
+		// Bots standing in for other players may have been waiting for a human to play against.
+		r.gameStateChanged()
+	}
+}
+
+// runTakeoverTurns lets the bot play for every taken over player, for as long as it's
+// their turn, returning true if it played. Bots don't play against each other, so if every
+// human player is taken over, the game waits for someone to come back. Must be called with
+// the room locked.
+func (r *room) runTakeoverTurns() bool {
+	if r.takeover == nil || r.allTakenOver() {
+		return false
+	}
+	played := false
+	for !r.gameState.IsGameEnded && r.takeover.takenOver[r.gameState.TurnPlayerID] {
+		playerID := r.gameState.TurnPlayerID
+		action := r.takeover.bot.ChooseAction(r.gameState.ToClientGameState(playerID))
+		if action == nil {
+			return played
+		}
+		if err := r.runAction(context.Background(), action, true); err != nil {
+			r.logger.Error("bot failed to play for player", "playerID", playerID, "err", err)
+			return played
+		}
+		played = true
+	}
+	return played
+}
+
+// allTakenOver returns true if the bot is standing in for every player that isn't a
+// hosted bot.
+func (r *room) allTakenOver() bool {
+	for playerID := range r.players {
+		if r.bots[playerID] == nil && !r.takeover.takenOver[playerID] {
+			return false
+		}
+	}
+	return true
+}
+
+// startTurnTimer (re)starts the timer for the current turn. Must be called with the room locked.
+func (r *room) startTurnTimer() {
+	t := r.takeover
+	if t == nil {
+		return
+	}
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	if r.gameState.IsGameEnded {
+		return
+	}
+
+	t.timerTurn++
+	t.timingTurn = r.gameState.TurnPlayerID
+	timerTurn := t.timerTurn
+	t.timer = time.AfterFunc(t.turnTimeout, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if t.timerTurn != timerTurn {
+			return // A newer timer replaced this one
+		}
+		r.turnTimedOut(t.timingTurn)
+	})
+}
+
+// turnTimedOut counts a timeout for the player, and hands their seat to the bot once
+// they reach the limit. Must be called with the room locked.
+func (r *room) turnTimedOut(playerID int) {
+	t := r.takeover
+	t.timeouts[playerID]++
+	r.logger.Info("player timed out", "playerID", playerID, "timeoutsInARow", t.timeouts[playerID])
+
+	if t.timeouts[playerID] < t.maxTimeouts {
+		r.startTurnTimer()
+		return
+	}
+
+	t.takenOver[playerID] = true
+	r.logger.Info("bot takes over for idle player", "playerID", playerID)
+	if r.allTakenOver() {
+		r.logger.Info("every player is idle, waiting for someone to come back")
+		return
+	}
+	r.gameStateChanged()
+}
@@ -0,0 +1,101 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// WithTLS serves over TLS (https:// and wss://, and gRPC with WithGRPC) with the
+// certificate and key in the PEM files.
+func WithTLS(certFile, keyFile string) Option {
+	return func(s *server) {
+		s.tlsConfig = func() (*tls.Config, error) {
+			cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+			if err != nil {
+				return nil, err
+			}
+			return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+		}
+	}
+}
+
+// WithAutocert serves over TLS like WithTLS, with certificates for the hosts obtained
+// from Let's Encrypt, and cached in the directory. The server must be reachable on port
+// 443 for the hosts, to prove that it serves them.
+func WithAutocert(cacheDir string, hosts ...string) Option {
+	return func(s *server) {
+		s.tlsConfig = func() (*tls.Config, error) {
+			m := &autocert.Manager{
+				Prompt:     autocert.AcceptTOS,
+				HostPolicy: autocert.HostWhitelist(hosts...),
+				Cache:      autocert.DirCache(cacheDir),
+			}
+			return m.TLSConfig(), nil
+		}
+	}
+}
+
+// ClientTLSConfig configures the TLS connections of clients to wss:// and https:// servers,
+// e.g. with RootCAs to trust a self-signed certificate. If nil, the system's are used.
+var ClientTLSConfig *tls.Config
+
+// ServerURL returns the URL of the path (e.g. /ws) on the server at the address, which is
+// either host:port, to connect without TLS, or a URL whose scheme (ws, wss, http or https)
+// says whether to use it. WebSocket URLs are returned with ws, HTTP ones otherwise.
+func ServerURL(address string, path string, ws bool) string {
+	secure := false
+	if u, err := url.Parse(address); err == nil && strings.Contains(address, "://") {
+		secure = u.Scheme == "wss" || u.Scheme == "https"
+		address = u.Host
+	}
+
+	scheme := "http"
+	if ws {
+		scheme = "ws"
+	}
+	if secure {
+		scheme += "s"
+	}
+	return fmt.Sprintf("%v://%v%v", scheme, address, path)
+}
+
+// WsDial opens a WebSocket connection to the URL, with ClientTLSConfig. The connection
+// answers the server's pings while it's being read from, and reads fail if they stop.
+//
+// If the upgrade to WebSocket fails, e.g. because a proxy blocks it, the connection is
+// made over long polling instead (see pollDial), and used the same.
+func WsDial(wsURL string) (*websocket.Conn, error) {
+	if ForceLongPolling {
+		return pollDial(wsURL)
+	}
+	dialer := *websocket.DefaultDialer
+	dialer.TLSClientConfig = ClientTLSConfig
+	conn, resp, err := dialer.Dial(wsURL, nil)
+	if err != nil {
+		// A full server would turn long polling away too.
+		if resp != nil && resp.StatusCode == http.StatusServiceUnavailable {
+			return nil, err
+		}
+		pollConn, pollErr := pollDial(wsURL)
+		if pollErr != nil {
+			return nil, fmt.Errorf("%w (and over long polling: %v)", err, pollErr)
+		}
+		return pollConn, nil
+	}
+	wsClientKeepAlive(conn)
+	return conn, nil
+}
+
+// HTTPClient returns a client for the server's HTTP endpoints, with ClientTLSConfig.
+func HTTPClient() *http.Client {
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: ClientTLSConfig}}
+}
@@ -0,0 +1,674 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/devblac/chinchon/chinchon"
+	"github.com/devblac/chinchon/examplebot/newbot"
+	"github.com/gorilla/mux"
+)
+
+// Tournament formats.
+const (
+	// TournamentSingleElimination pairs the players in registration order, and then the
+	// winners of each round, until one is left. With an odd number of players, the last one
+	// gets a bye.
+	TournamentSingleElimination = "single_elimination"
+
+	// TournamentRoundRobin plays every player against every other once, one round at a time.
+	// The winner is the one with the most wins (and then the fewest losses, and then the
+	// first registered).
+	TournamentRoundRobin = "round_robin"
+)
+
+// Tournament statuses.
+const (
+	TournamentRegistering = "registering"
+	TournamentRunning     = "running"
+	TournamentFinished    = "finished"
+)
+
+var (
+	errTournamentNotFound  = errors.New("tournament not found")
+	errTournamentStarted   = errors.New("tournament already started")
+	errTournamentNameTaken = errors.New("name already registered")
+	errTooFewPlayers       = errors.New("tournaments need at least 2 players")
+	errWrongSecret         = errors.New("wrong registration secret")
+)
+
+// Tournament is the state of a tournament's bracket, as served at /api/tournaments/{id} and
+// sent to its WebSocket subscribers on every change.
+type Tournament struct {
+	ID      string             `json:"id"`
+	Name    string             `json:"name"`
+	Format  string             `json:"format"`
+	Status  string             `json:"status"`
+	Players []TournamentPlayer `json:"players"`
+
+	// Rounds are the matches of each round. Round robin rounds are all known when the
+	// tournament starts, single elimination ones once the previous round is over.
+	Rounds    [][]TournamentMatch  `json:"rounds"`
+	Standings []TournamentStanding `json:"standings"`
+	Winner    string               `json:"winner,omitempty"`
+}
+
+// TournamentPlayer is a player registered in a tournament. Bots are played by the server.
+type TournamentPlayer struct {
+	Name string `json:"name"`
+	Bot  bool   `json:"bot,omitempty"`
+}
+
+// TournamentMatch is a game between two players of a tournament, or a bye for a single
+// one, which they win without playing.
+type TournamentMatch struct {
+	Players []string `json:"players"`
+	GameID  string   `json:"gameID,omitempty"` // once started
+	Winner  string   `json:"winner,omitempty"` // once over
+}
+
+// TournamentStanding is a player's results in a tournament, byes not included.
+type TournamentStanding struct {
+	Name   string `json:"name"`
+	Wins   int    `json:"wins"`
+	Losses int    `json:"losses"`
+}
+
+// TournamentRegistration is the response to registering a human player in a tournament.
+// Only those following the tournament with the secret are told which game to join when
+// the player's match starts.
+type TournamentRegistration struct {
+	Secret string `json:"secret,omitempty"`
+}
+
+// NewTournament is the body of POST /admin/tournaments.
+type NewTournament struct {
+	Name   string `json:"name"`
+	Format string `json:"format"`
+}
+
+// tournaments are the tournaments created on this server. They're only kept in memory.
+type tournaments struct {
+	mu    sync.Mutex
+	byID  map[string]*tournament
+	order []string // IDs, oldest first
+}
+
+func newTournaments() *tournaments {
+	return &tournaments{byID: map[string]*tournament{}}
+}
+
+// tournament is a tournament, guarded by the tournaments' lock.
+type tournament struct {
+	Tournament
+	s      *server
+	logger *slog.Logger
+
+	// round is the index of the round being played, -1 until the tournament starts.
+	round int
+
+	// matchFound are the messages telling human players which game to join, by name, while
+	// their match is being played.
+	matchFound map[string]MessageMatchFound
+
+	// secrets are the registration secrets of human players, by name.
+	secrets map[string]string
+
+	// subscribers are the connections following the tournament, with the name of the player
+	// they're for, if any.
+	subscribers map[*outbox]string
+}
+
+// tournamentMatch is a match of a tournament, as its room knows it.
+type tournamentMatch struct {
+	t     *tournament
+	round int
+	index int
+}
+
+func (ts *tournaments) get(id string) (*tournament, bool) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	t, ok := ts.byID[id]
+	return t, ok
+}
+
+// list returns the tournaments, oldest first.
+func (ts *tournaments) list() []Tournament {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	list := []Tournament{}
+	for _, id := range ts.order {
+		list = append(list, ts.byID[id].snapshot())
+	}
+	return list
+}
+
+// createTournament opens a tournament for registration.
+func (s *server) createTournament(nt NewTournament) (Tournament, error) {
+	if nt.Format != TournamentSingleElimination && nt.Format != TournamentRoundRobin {
+		return Tournament{}, fmt.Errorf("invalid format %q, expected %v or %v", nt.Format, TournamentSingleElimination, TournamentRoundRobin)
+	}
+	id := newTournamentID()
+	t := &tournament{
+		Tournament:  Tournament{ID: id, Name: nt.Name, Format: nt.Format, Status: TournamentRegistering, Players: []TournamentPlayer{}, Rounds: [][]TournamentMatch{}, Standings: []TournamentStanding{}},
+		s:           s,
+		logger:      s.logger.With("tournamentID", id),
+		round:       -1,
+		matchFound:  map[string]MessageMatchFound{},
+		secrets:     map[string]string{},
+		subscribers: map[*outbox]string{},
+	}
+	s.tournaments.mu.Lock()
+	defer s.tournaments.mu.Unlock()
+	s.tournaments.byID[id] = t
+	s.tournaments.order = append(s.tournaments.order, id)
+	t.logger.Info("tournament created", "name", nt.Name, "format", nt.Format)
+	return t.snapshot(), nil
+}
+
+// registerTournamentPlayer adds the player to the tournament, if it hasn't started,
+// returning their registration.
+func (s *server) registerTournamentPlayer(id string, player TournamentPlayer) (TournamentRegistration, error) {
+	s.tournaments.mu.Lock()
+	defer s.tournaments.mu.Unlock()
+	t, ok := s.tournaments.byID[id]
+	if !ok {
+		return TournamentRegistration{}, errTournamentNotFound
+	}
+	if t.Status != TournamentRegistering {
+		return TournamentRegistration{}, errTournamentStarted
+	}
+	for _, p := range t.Players {
+		if p.Name == player.Name {
+			return TournamentRegistration{}, errTournamentNameTaken
+		}
+	}
+	registration := TournamentRegistration{}
+	if !player.Bot {
+		registration.Secret = newTournamentSecret()
+		t.secrets[player.Name] = registration.Secret
+	}
+	t.Players = append(t.Players, player)
+	t.updateStandings()
+	t.notify()
+	t.logger.Info("player registered in tournament", "name", player.Name, "bot", player.Bot)
+	return registration, nil
+}
+
+// checkSecret returns whether the secret is the one the human player registered with.
+// Must be called with the tournaments locked.
+func (t *tournament) checkSecret(name string, secret string) error {
+	expected, ok := t.secrets[name]
+	if !ok || subtle.ConstantTimeCompare([]byte(expected), []byte(secret)) != 1 {
+		return errWrongSecret
+	}
+	return nil
+}
+
+// startTournament closes registration and starts the first round's games.
+func (s *server) startTournament(id string) error {
+	s.tournaments.mu.Lock()
+	t, ok := s.tournaments.byID[id]
+	if !ok {
+		s.tournaments.mu.Unlock()
+		return errTournamentNotFound
+	}
+	if t.Status != TournamentRegistering {
+		s.tournaments.mu.Unlock()
+		return errTournamentStarted
+	}
+	if len(t.Players) < 2 {
+		s.tournaments.mu.Unlock()
+		return errTooFewPlayers
+	}
+	t.Status = TournamentRunning
+	if t.Format == TournamentRoundRobin {
+		t.Rounds = roundRobin(t.playerNames())
+	}
+	matches := t.advance()
+	t.notify()
+	s.tournaments.mu.Unlock()
+
+	t.logger.Info("tournament started", "players", len(t.Players))
+	for _, m := range matches {
+		go s.startTournamentMatch(m)
+	}
+	return nil
+}
+
+// startTournamentMatch starts the match's game, seating the server's bot for bot players,
+// and tells human players to join it.
+func (s *server) startTournamentMatch(m tournamentMatch) {
+	t := m.t
+	s.tournaments.mu.Lock()
+	players := t.Rounds[m.round][m.index].Players
+	isBot := map[string]bool{}
+	for _, p := range t.Players {
+		isBot[p.Name] = p.Bot
+	}
+	s.tournaments.mu.Unlock()
+
+	gameID, err := s.localGameID(fmt.Sprintf("%v-%d-%d", t.ID, m.round+1, m.index+1))
+	if err != nil {
+		t.logger.Error("failed to start a tournament match", "err", err)
+		return
+	}
+	room, err := s.room(gameID)
+	if errors.Is(err, errServerFull) {
+		t.logger.Warn("server is full, retrying tournament match later", "gameID", gameID)
+		time.AfterFunc(RetryAfter, func() { s.startTournamentMatch(m) })
+		return
+	}
+	if err != nil {
+		t.logger.Error("failed to start a tournament match", "gameID", gameID, "err", err)
+		return
+	}
+
+	room.mu.Lock()
+	matchFound := map[string]MessageMatchFound{}
+	for i, name := range players {
+		playerID := s.humanSeats[i]
+		room.setPlayerName(playerID, name)
+		if isBot[name] {
+			room.bots[playerID] = newbot.New()
+			continue
+		}
+		// Seats are claimed right away, so that only the players in the match may take them.
+		token := ""
+		if s.auth != nil {
+			token = s.claimSeat(room, playerID, name)
+		}
+		matchFound[name] = NewMessageMatchFound(gameID, playerID, token)
+	}
+	room.tournamentMatch = &m
+
+	s.tournaments.mu.Lock()
+	t.Rounds[m.round][m.index].GameID = gameID
+	for name, msg := range matchFound {
+		t.matchFound[name] = msg
+		t.sendTo(name, msg)
+	}
+	t.notify()
+	s.tournaments.mu.Unlock()
+
+	room.logger.Info("tournament match started", "tournamentID", t.ID, "names", players)
+	// Bots may have the first turn, or play the whole game if there are no humans.
+	room.gameStateChanged()
+	room.mu.Unlock()
+	if len(matchFound) == 0 {
+		s.closeRoomIfDone(room)
+	}
+}
+
+// ended records the winner of the room's ended game, and starts the next matches if the
+// round is over. Must be called with the room locked.
+func (m *tournamentMatch) ended(r *room) {
+	t := m.t
+	winner := r.playerNames[matchWinner(r.gameState)]
+
+	t.s.tournaments.mu.Lock()
+	match := &t.Rounds[m.round][m.index]
+	match.Winner = winner
+	for _, name := range match.Players {
+		delete(t.matchFound, name)
+	}
+	t.updateStandings()
+	next := t.advance()
+	t.notify()
+	t.s.tournaments.mu.Unlock()
+
+	r.logger.Info("tournament match ended", "tournamentID", t.ID, "winner", winner)
+	for _, m := range next {
+		go t.s.startTournamentMatch(m)
+	}
+}
+
+// matchWinner returns the player ID of the winner of a two-player game. Aborted games are
+// won by the player with the fewest points, or the first one if tied.
+func matchWinner(gs *chinchon.GameState) int {
+	winner := 0
+	for playerID, p := range gs.Players {
+		if gs.IsGameAborted && p.Score < gs.Players[winner].Score || !gs.IsGameAborted && p.Team == gs.WinnerTeamID {
+			winner = playerID
+		}
+	}
+	return winner
+}
+
+// advance moves on to the next round once the one being played is over, returning the
+// matches to start. Byes are won right away. Must be called with the tournaments locked.
+func (t *tournament) advance() []tournamentMatch {
+	for {
+		if t.round >= 0 {
+			for _, match := range t.Rounds[t.round] {
+				if match.Winner == "" {
+					return nil
+				}
+			}
+		}
+		t.round++
+
+		if t.Format == TournamentSingleElimination && t.round == len(t.Rounds) {
+			names := t.playerNames()
+			if t.round > 0 {
+				names = []string{}
+				for _, match := range t.Rounds[t.round-1] {
+					names = append(names, match.Winner)
+				}
+			}
+			if len(names) == 1 {
+				t.finish(names[0])
+				return nil
+			}
+			t.Rounds = append(t.Rounds, pairings(names))
+		}
+		if t.round == len(t.Rounds) {
+			t.finish(t.Standings[0].Name)
+			return nil
+		}
+
+		matches := []tournamentMatch{}
+		for i := range t.Rounds[t.round] {
+			match := &t.Rounds[t.round][i]
+			if len(match.Players) == 1 {
+				match.Winner = match.Players[0]
+				continue
+			}
+			matches = append(matches, tournamentMatch{t: t, round: t.round, index: i})
+		}
+		if len(matches) > 0 {
+			return matches
+		}
+	}
+}
+
+func (t *tournament) finish(winner string) {
+	t.Status = TournamentFinished
+	t.Winner = winner
+	t.logger.Info("tournament finished", "winner", winner)
+}
+
+func (t *tournament) playerNames() []string {
+	names := []string{}
+	for _, p := range t.Players {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// updateStandings counts the wins and losses of every player, best first.
+func (t *tournament) updateStandings() {
+	standings := map[string]*TournamentStanding{}
+	t.Standings = []TournamentStanding{}
+	for _, name := range t.playerNames() {
+		standings[name] = &TournamentStanding{Name: name}
+	}
+	for _, round := range t.Rounds {
+		for _, match := range round {
+			if len(match.Players) < 2 || match.Winner == "" {
+				continue
+			}
+			for _, name := range match.Players {
+				if name == match.Winner {
+					standings[name].Wins++
+				} else {
+					standings[name].Losses++
+				}
+			}
+		}
+	}
+	for _, name := range t.playerNames() {
+		t.Standings = append(t.Standings, *standings[name])
+	}
+	sort.SliceStable(t.Standings, func(i, j int) bool {
+		a, b := t.Standings[i], t.Standings[j]
+		if a.Wins != b.Wins {
+			return a.Wins > b.Wins
+		}
+		return a.Losses < b.Losses
+	})
+}
+
+// pairings pairs the players in order, the last one getting a bye if they're odd.
+func pairings(names []string) []TournamentMatch {
+	matches := []TournamentMatch{}
+	for i := 0; i < len(names); i += 2 {
+		matches = append(matches, TournamentMatch{Players: names[i:min(i+2, len(names))]})
+	}
+	return matches
+}
+
+// roundRobin schedules every player against every other with the circle method: the first
+// player stays put while the others rotate around them. With an odd number of players,
+// each one gets a bye in one of the rounds.
+func roundRobin(names []string) [][]TournamentMatch {
+	circle := append([]string{}, names...)
+	if len(circle)%2 == 1 {
+		circle = append(circle, "") // the bye
+	}
+	n := len(circle)
+	rounds := [][]TournamentMatch{}
+	for r := 0; r < n-1; r++ {
+		round := []TournamentMatch{}
+		for i := 0; i < n/2; i++ {
+			a, b := circle[i], circle[n-1-i]
+			switch {
+			case a == "":
+				round = append(round, TournamentMatch{Players: []string{b}})
+			case b == "":
+				round = append(round, TournamentMatch{Players: []string{a}})
+			default:
+				round = append(round, TournamentMatch{Players: []string{a, b}})
+			}
+		}
+		rounds = append(rounds, round)
+		circle = append([]string{circle[0], circle[n-1]}, circle[1:n-1]...)
+	}
+	return rounds
+}
+
+// snapshot returns a copy of the tournament's state, which can be used after unlocking.
+func (t *tournament) snapshot() Tournament {
+	c := t.Tournament
+	c.Players = append([]TournamentPlayer{}, t.Players...)
+	c.Standings = append([]TournamentStanding{}, t.Standings...)
+	c.Rounds = [][]TournamentMatch{}
+	for _, round := range t.Rounds {
+		c.Rounds = append(c.Rounds, append([]TournamentMatch{}, round...))
+	}
+	return c
+}
+
+// notify sends the tournament's state to its subscribers. Must be called with the
+// tournaments locked.
+func (t *tournament) notify() {
+	msg := NewMessageTournamentUpdate(t.snapshot())
+	for out := range t.subscribers {
+		out.Send(msg)
+	}
+}
+
+// sendTo sends the message to the subscribers for the player. Must be called with the
+// tournaments locked.
+func (t *tournament) sendTo(name string, msg any) {
+	for out, subscriber := range t.subscribers {
+		if subscriber == name {
+			out.Send(msg)
+		}
+	}
+}
+
+// newTournamentID returns a random ID for a tournament, which prefixes its games' IDs.
+func newTournamentID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "tournament-" + hex.EncodeToString(b)
+}
+
+// newTournamentSecret returns a random registration secret.
+func newTournamentSecret() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// tournamentsSupported returns whether the server's games can be played in tournaments,
+// i.e. they're between two humans.
+func (s *server) tournamentsSupported() bool {
+	return len(s.humanSeats) == 2 && len(s.hostedBots) == 0
+}
+
+func (s *server) handleAPITournaments(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.tournaments.list())
+}
+
+func (s *server) handleAPITournament(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.tournaments.get(mux.Vars(r)["id"])
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	s.tournaments.mu.Lock()
+	defer s.tournaments.mu.Unlock()
+	writeJSON(w, t.snapshot())
+}
+
+// handleAPITournamentRegister registers a human player in the tournament, with the name in
+// the body, responding with their TournamentRegistration.
+func (s *server) handleAPITournamentRegister(w http.ResponseWriter, r *http.Request) {
+	var player TournamentPlayer
+	if err := json.NewDecoder(r.Body).Decode(&player); err != nil || player.Name == "" {
+		http.Error(w, "invalid player, expected a name", http.StatusBadRequest)
+		return
+	}
+	player.Bot = false
+	s.writeTournamentRegistration(w, r, player)
+}
+
+// handleAdminCreateTournament creates a tournament, responding with it.
+func (s *server) handleAdminCreateTournament(w http.ResponseWriter, r *http.Request) {
+	if !s.tournamentsSupported() {
+		http.Error(w, "tournaments need two-player games without hosted bots", http.StatusConflict)
+		return
+	}
+	var nt NewTournament
+	if err := json.NewDecoder(r.Body).Decode(&nt); err != nil || nt.Name == "" {
+		http.Error(w, "invalid tournament, expected a name and a format", http.StatusBadRequest)
+		return
+	}
+	t, err := s.createTournament(nt)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(t)
+}
+
+// handleAdminTournamentRegister registers a player in the tournament, which may be a bot,
+// responding with their TournamentRegistration (without a secret for bots).
+func (s *server) handleAdminTournamentRegister(w http.ResponseWriter, r *http.Request) {
+	var player TournamentPlayer
+	if err := json.NewDecoder(r.Body).Decode(&player); err != nil || player.Name == "" {
+		http.Error(w, "invalid player, expected a name", http.StatusBadRequest)
+		return
+	}
+	s.writeTournamentRegistration(w, r, player)
+}
+
+// writeTournamentRegistration registers the player in the tournament, responding with
+// their registration.
+func (s *server) writeTournamentRegistration(w http.ResponseWriter, r *http.Request, player TournamentPlayer) {
+	registration, err := s.registerTournamentPlayer(mux.Vars(r)["id"], player)
+	if err != nil {
+		s.writeTournamentResult(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(registration)
+}
+
+func (s *server) handleAdminStartTournament(w http.ResponseWriter, r *http.Request) {
+	s.writeTournamentResult(w, r, s.startTournament(mux.Vars(r)["id"]))
+}
+
+// writeTournamentResult responds to a request changing a tournament.
+func (s *server) writeTournamentResult(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, errTournamentNotFound):
+		http.NotFound(w, r)
+	default:
+		http.Error(w, err.Error(), http.StatusConflict)
+	}
+}
+
+// handleTournamentWebSocket sends the tournament's state to the connection on every
+// change. With the name and secret query parameters, it also tells that player which game
+// to join when their match starts, with a MessageMatchFound.
+func (s *server) handleTournamentWebSocket(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.tournaments.get(mux.Vars(r)["id"])
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	name := r.URL.Query().Get("name")
+	if name != "" {
+		s.tournaments.mu.Lock()
+		err := t.checkSecret(name, r.URL.Query().Get("secret"))
+		s.tournaments.mu.Unlock()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	}
+	logger := t.logger.With("remoteAddr", r.RemoteAddr, "name", name)
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Warn("failed to upgrade connection to WebSocket", "err", err)
+		return
+	}
+	defer conn.Close()
+	defer s.keepAlive(conn, nil)()
+	out := newOutbox(conn, s.sendQueue)
+	defer out.Close()
+
+	s.tournaments.mu.Lock()
+	t.subscribers[out] = name
+	out.Send(NewMessageTournamentUpdate(t.snapshot()))
+	if msg, ok := t.matchFound[name]; ok && name != "" {
+		out.Send(msg)
+	}
+	s.tournaments.mu.Unlock()
+	logger.Info("following tournament")
+
+	defer func() {
+		s.tournaments.mu.Lock()
+		delete(t.subscribers, out)
+		s.tournaments.mu.Unlock()
+	}()
+
+	// Subscribers don't send anything, but reading notices when they leave.
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			logger.Info("stopped following tournament", "err", err)
+			return
+		}
+	}
+}
@@ -0,0 +1,217 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devblac/chinchon/chinchon"
+	"github.com/gorilla/websocket"
+)
+
+// newTestTournament creates a tournament with the players registered, without starting it.
+func newTestTournament(t *testing.T, s *server, format string, names ...string) *tournament {
+	created, err := s.createTournament(NewTournament{Name: "Copa", Format: format})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		if _, err := s.registerTournamentPlayer(created.ID, TournamentPlayer{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tournament, _ := s.tournaments.get(created.ID)
+	return tournament
+}
+
+// playTournamentRound records the winners of the matches to start, by index, advancing the tournament.
+func playTournamentRound(tournament *tournament, matches []tournamentMatch, winners ...string) []tournamentMatch {
+	for i, m := range matches {
+		tournament.Rounds[m.round][m.index].Winner = winners[i]
+	}
+	tournament.updateStandings()
+	return tournament.advance()
+}
+
+func TestSingleEliminationAdvance(t *testing.T) {
+	s := New("0")
+	tournament := newTestTournament(t, s, TournamentSingleElimination, "a", "b", "c")
+	tournament.Status = TournamentRunning
+
+	matches := tournament.advance()
+	if len(matches) != 1 || tournament.round != 0 || len(tournament.Rounds[0]) != 2 {
+		t.Fatalf("Expected a match and a bye in the first round, got %+v", tournament.Rounds)
+	}
+	if bye := tournament.Rounds[0][1]; len(bye.Players) != 1 || bye.Winner != "c" {
+		t.Errorf("Expected the last player to get a bye, won right away, got %+v", bye)
+	}
+	if next := playTournamentRound(tournament, nil); next != nil {
+		t.Errorf("Expected no matches to start before the round is over, got %+v", next)
+	}
+
+	matches = playTournamentRound(tournament, matches, "a")
+	if len(matches) != 1 || tournament.round != 1 || fmt.Sprint(tournament.Rounds[1][0].Players) != "[a c]" {
+		t.Fatalf("Expected the winners to meet in the second round, got %+v", tournament.Rounds)
+	}
+	if next := playTournamentRound(tournament, matches, "c"); next != nil || tournament.Status != TournamentFinished || tournament.Winner != "c" {
+		t.Errorf("Expected the tournament to be won by the last one standing, got %v %q", tournament.Status, tournament.Winner)
+	}
+	for _, standing := range tournament.Standings {
+		if standing.Name == "c" && (standing.Wins != 1 || standing.Losses != 0) {
+			t.Errorf("Expected byes not to count as wins, got %+v", standing)
+		}
+	}
+}
+
+func TestRoundRobinAdvance(t *testing.T) {
+	s := New("0")
+	tournament := newTestTournament(t, s, TournamentRoundRobin, "a", "b", "c")
+	tournament.Status = TournamentRunning
+	tournament.Rounds = roundRobin(tournament.playerNames())
+
+	beats := func(a, b string) bool { return a < b } // a beats everyone, b beats c
+	matches := tournament.advance()
+	for rounds := 0; matches != nil; rounds++ {
+		if rounds == 3 {
+			t.Fatal("Expected the tournament to finish after 3 rounds")
+		}
+		winners := []string{}
+		for _, m := range matches {
+			players := tournament.Rounds[m.round][m.index].Players
+			if beats(players[0], players[1]) {
+				winners = append(winners, players[0])
+			} else {
+				winners = append(winners, players[1])
+			}
+		}
+		matches = playTournamentRound(tournament, matches, winners...)
+	}
+	if tournament.Status != TournamentFinished || tournament.Winner != "a" {
+		t.Errorf("Expected the player with the most wins to win the tournament, got %v %q", tournament.Status, tournament.Winner)
+	}
+	expected := []TournamentStanding{{Name: "a", Wins: 2}, {Name: "b", Wins: 1, Losses: 1}, {Name: "c", Losses: 2}}
+	if fmt.Sprint(tournament.Standings) != fmt.Sprint(expected) {
+		t.Errorf("Expected standings %v without byes, got %v", expected, tournament.Standings)
+	}
+}
+
+func TestRoundRobinOddPlayers(t *testing.T) {
+	names := []string{"a", "b", "c", "d", "e"}
+	rounds := roundRobin(names)
+	if len(rounds) != len(names) {
+		t.Fatalf("Expected %d rounds, got %d", len(names), len(rounds))
+	}
+	played := map[string]int{}
+	byes := map[string]int{}
+	for _, round := range rounds {
+		seen := map[string]bool{}
+		for _, match := range round {
+			for _, name := range match.Players {
+				if seen[name] {
+					t.Errorf("Expected %v to play once per round, got %+v", name, round)
+				}
+				seen[name] = true
+			}
+			if len(match.Players) == 1 {
+				byes[match.Players[0]]++
+				continue
+			}
+			played[strings.Join(match.Players, " ")]++
+		}
+	}
+	for i, a := range names {
+		if byes[a] != 1 {
+			t.Errorf("Expected %v to get exactly one bye, got %d", a, byes[a])
+		}
+		for _, b := range names[i+1:] {
+			if n := played[a+" "+b] + played[b+" "+a]; n != 1 {
+				t.Errorf("Expected %v and %v to play once, got %d", a, b, n)
+			}
+		}
+	}
+}
+
+func TestMatchWinner(t *testing.T) {
+	for _, test := range []struct {
+		scores   [2]int
+		abort    bool
+		expected int
+	}{
+		{scores: [2]int{30, 10}, abort: true, expected: 1},
+		{scores: [2]int{10, 30}, abort: true, expected: 0},
+		{scores: [2]int{20, 20}, abort: true, expected: 0},
+	} {
+		gs := chinchon.New()
+		for playerID, score := range test.scores {
+			gs.Players[playerID].Score = score
+		}
+		gs.Abort()
+		if winner := matchWinner(gs); winner != test.expected {
+			t.Errorf("Expected player %d to win the aborted game with scores %v, got %d", test.expected, test.scores, winner)
+		}
+	}
+
+	gs := chinchon.New()
+	gs.IsGameEnded, gs.WinnerTeamID = true, gs.Players[1].Team
+	if winner := matchWinner(gs); winner != 1 {
+		t.Errorf("Expected the winning team's player to win, got %d", winner)
+	}
+}
+
+func TestTournamentSecrets(t *testing.T) {
+	s := New("0")
+	srv := httptest.NewServer(s.handler())
+	defer srv.Close()
+	defer s.polls.shutdown()
+	tournament := newTestTournament(t, s, TournamentSingleElimination)
+
+	resp, err := http.Post(srv.URL+"/api/tournaments/"+tournament.ID+"/players", "application/json", bytes.NewReader([]byte(`{"name": "juan"}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var registration TournamentRegistration
+	_ = json.NewDecoder(resp.Body).Decode(&registration)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || registration.Secret == "" {
+		t.Fatalf("Expected a registration secret, got %v %+v", resp.Status, registration)
+	}
+	s.tournaments.mu.Lock()
+	tournament.matchFound["juan"] = NewMessageMatchFound("casa", 0, "token")
+	s.tournaments.mu.Unlock()
+
+	dial := func(query url.Values) (*websocket.Conn, *http.Response, error) {
+		return websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/tournaments/"+tournament.ID+"/ws?"+query.Encode(), nil)
+	}
+	for _, secret := range []string{"", "wrong"} {
+		conn, resp, err := dial(url.Values{"name": {"juan"}, "secret": {secret}})
+		if err == nil {
+			conn.Close()
+		}
+		if resp == nil || resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected following the tournament as juan with secret %q to be forbidden, got %v", secret, err)
+		}
+	}
+
+	conn, _, err := dial(url.Values{"name": {"juan"}, "secret": {registration.Secret}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := WsReadMessage[MessageTournamentUpdate, MessageTournamentUpdate](conn, MessageTypeTournamentUpdate); err != nil {
+		t.Fatal(err)
+	}
+	matchFound, err := WsReadMessage[MessageMatchFound, MessageMatchFound](conn, MessageTypeMatchFound)
+	if err != nil || matchFound.Token != "token" {
+		t.Errorf("Expected the player with the secret to be told their match, got %+v and %v", matchFound, err)
+	}
+}
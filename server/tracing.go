@@ -0,0 +1,59 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName names the server's instrumentation in the traces.
+const tracerName = "github.com/devblac/chinchon/server"
+
+// WithTracerProvider traces the server's work with OpenTelemetry: the messages players
+// send, the actions the engine runs for them, sending the resulting game states, and the
+// game store's calls. Without it, the global tracer provider is used (see
+// otel.SetTracerProvider), which does nothing unless set.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(s *server) {
+		s.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// Attributes of the server's spans.
+var (
+	attrGameID      = attribute.Key("chinchon.game_id")
+	attrPlayerID    = attribute.Key("chinchon.player_id")
+	attrMessageType = attribute.Key("chinchon.message_type")
+	attrAction      = attribute.Key("chinchon.action")
+	attrPlayedByBot = attribute.Key("chinchon.played_by_bot")
+	attrSeq         = attribute.Key("chinchon.seq")
+)
+
+// endSpan ends the span, marking it failed if there was an error.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// startSpan starts a span for the room's game, under the one in the context if any.
+func (r *room) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return r.tracer.Start(ctx, name, trace.WithAttributes(append(attrs, attrGameID.String(r.id))...))
+}
+
+// startSpan starts a span for a call to the game store.
+func (j *gameJournal) startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	tracer := j.tracer
+	if tracer == nil {
+		tracer = otel.Tracer(tracerName)
+	}
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrGameID.String(j.gameID), attrSeq.Int(j.seq)))
+}
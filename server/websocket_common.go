@@ -6,33 +6,53 @@ package server
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 
 	"github.com/gorilla/websocket"
 )
 
+// WsSend sends the message, as JSON or as protobuf (see WsSubprotocolProtobuf).
 func WsSend(conn *websocket.Conn, message any) error {
-	bs, err := json.Marshal(message)
+	frameType, bs, err := wsEncode(conn, message)
 	if err != nil {
-		log.Printf("Failed to marshal message: %v", err)
+		slog.Error("failed to marshal message", "err", err)
 	}
-	if err := conn.WriteMessage(websocket.TextMessage, bs); err != nil {
-		log.Println("Failed to write message:", err)
+	if err := conn.WriteMessage(frameType, bs); err != nil {
+		slog.Debug("failed to write message", "err", err)
 	}
 	return err
 }
 
 func WsReadMessage[U any, T IWebsocketMessage[U]](conn *websocket.Conn, expectedType int) (*U, error) {
-	messageType, message, err := conn.ReadMessage()
-	if messageType != websocket.TextMessage {
-		return nil, fmt.Errorf("Expected text message, got %d with error %v", messageType, err)
-	}
+	frameType, frame, err := conn.ReadMessage()
 	if err != nil {
 		return nil, fmt.Errorf("Failed to read message from client: %v", err)
 	}
+	message, err := wsDecode(conn, frameType, frame)
+	if err != nil {
+		return nil, err
+	}
 	return WsDeserializeMessage[U, T](message, expectedType)
 }
 
+// WsReadAnyMessage reads the next message from the connection, returning its type
+// alongside its JSON so the caller can dispatch with WsDeserializeMessage.
+func WsReadAnyMessage(conn *websocket.Conn) (int, []byte, error) {
+	frameType, frame, err := conn.ReadMessage()
+	if err != nil {
+		return 0, nil, fmt.Errorf("Failed to read message: %v", err)
+	}
+	message, err := wsDecode(conn, frameType, frame)
+	if err != nil {
+		return 0, nil, err
+	}
+	var m WebsocketMessage
+	if err := json.Unmarshal(message, &m); err != nil {
+		return 0, nil, fmt.Errorf("Failed to unmarshal message: %v", err)
+	}
+	return m.Type, message, nil
+}
+
 func WsDeserializeMessage[U any, T IWebsocketMessage[U]](message []byte, expectedType int) (*U, error) {
 	var m T
 	if err := json.Unmarshal(message, &m); err != nil {
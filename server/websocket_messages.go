@@ -5,6 +5,9 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/devblac/chinchon/chinchon"
 )
@@ -14,8 +17,62 @@ const (
 	MessageTypeHeresGameState
 	MessageTypeAction
 	MessageTypeGimmeGameState
+	MessageTypeEmote
+	MessageTypePlayerStatus
+	MessageTypeFindMatch
+	MessageTypeMatchFound
+	MessageTypeSession
+	MessageTypeSpectate
+	MessageTypeHeresSpectatorGameState
+	MessageTypeMaintenance
+	MessageTypeAnnouncement
+	MessageTypeGimmeLeaderboard
+	MessageTypeHeresLeaderboard
+	MessageTypeTournamentUpdate
+	MessageTypeError
+	MessageTypeListGames
+	MessageTypeOpenGames
+	MessageTypeSeatTaken
+	MessageTypePlayerLatency
+	MessageTypeGameStateDelta
+	MessageTypeRedirect
 )
 
+// Codes of MessageError, for clients to tell what went wrong.
+const (
+	ErrorCodeNotYourTurn       = "not_your_turn"
+	ErrorCodeActionNotPossible = "action_not_possible"
+	ErrorCodeGameEnded         = "game_ended"
+	ErrorCodeNothingToUndo     = "nothing_to_undo"
+	ErrorCodeInvalidEmote      = "invalid_emote"
+	ErrorCodeServerError       = "server_error"
+	ErrorCodeInvalidRules      = "invalid_rules"
+	ErrorCodeInvalidProfile    = "invalid_profile"
+	ErrorCodeServerFull        = "server_full"
+	ErrorCodeWrongPassword     = "wrong_password"
+)
+
+// Emotes is the closed set of quick-chat phrases players may send to each other.
+// Free text is deliberately not supported, so there's nothing to moderate. New ones are
+// only ever appended, so that older clients' emotes stay valid.
+var Emotes = []string{
+	"¡Bien jugado!",
+	"😤",
+	"⏳",
+	"😅",
+	"Apurate",
+}
+
+// IsValidEmote returns true if the emote is one of the predefined Emotes.
+func IsValidEmote(emote string) bool {
+	for _, e := range Emotes {
+		if e == emote {
+			return true
+		}
+	}
+	return false
+}
+
 type IWebsocketMessage[T any] interface {
 	GetType() int
 	Deserialize() (T, error)
@@ -29,17 +86,53 @@ func (m WebsocketMessage) GetType() int {
 	return m.Type
 }
 
+// MessageHello is the first message on a connection, telling the server which player is
+// connecting to which game. Games are started when first joined, and an empty GameID joins
+// the default game. If the server requires authentication, Token is the session token the
+// player got when they first joined the seat.
 type MessageHello struct {
 	WebsocketMessage
-	PlayerID int `json:"playerID"`
+	GameID   string `json:"gameID,omitempty"`
+	PlayerID int    `json:"playerID"`
+	Token    string `json:"token,omitempty"`
+
+	// Name is optional. It's the player's display name in the game (see
+	// chinchon.ValidateProfile), unless they were matched in the lobby or a tournament, and
+	// the first player to join a game with one shows as its creator to those browsing open
+	// games.
+	Name string `json:"name,omitempty"`
+
+	// Avatar is an optional emoji shown next to the player's name.
+	Avatar string `json:"avatar,omitempty"`
+
+	// Rules are optional house rules for the game, if this hello starts it: a partial
+	// chinchon.Rules, e.g. {"maxPoints": 50}, over the server's rules. Players see the rules
+	// they got in their game state.
+	Rules json.RawMessage `json:"rules,omitempty"`
+
+	// Deltas asks for game states as deltas from the previous one (see
+	// MessageGameStateDelta), rather than whole.
+	Deltas bool `json:"deltas,omitempty"`
+
+	// Password is the game's, if it was started with one (see APINewGame).
+	Password string `json:"password,omitempty"`
+
+	// Notify is where to notify the player when it's their turn while they aren't
+	// connected, e.g. a URL, with WithTurnNotifier. It's forgotten if a later hello for the
+	// seat has none.
+	Notify string `json:"notify,omitempty"`
 }
 
 func NewMessageHello(playerID int) MessageHello {
-	return MessageHello{WebsocketMessage: WebsocketMessage{Type: MessageTypeHello}, PlayerID: playerID}
+	return NewMessageHelloForGame("", playerID, "")
+}
+
+func NewMessageHelloForGame(gameID string, playerID int, token string) MessageHello {
+	return MessageHello{WebsocketMessage: WebsocketMessage{Type: MessageTypeHello}, GameID: gameID, PlayerID: playerID, Token: token}
 }
 
-func (m MessageHello) Deserialize() (int, error) {
-	return m.PlayerID, nil
+func (m MessageHello) Deserialize() (MessageHello, error) {
+	return m, nil
 }
 
 type MessageHeresGameState struct {
@@ -79,3 +172,332 @@ func NewMessageAction(action chinchon.Action) (MessageAction, error) {
 func (a MessageAction) Deserialize() (chinchon.Action, error) {
 	return chinchon.DeserializeAction(a.Action)
 }
+
+type MessageEmote struct {
+	WebsocketMessage
+	PlayerID int    `json:"playerID"`
+	Emote    string `json:"emote"`
+}
+
+func NewMessageEmote(playerID int, emote string) MessageEmote {
+	return MessageEmote{WebsocketMessage: WebsocketMessage{Type: MessageTypeEmote}, PlayerID: playerID, Emote: emote}
+}
+
+func (m MessageEmote) Deserialize() (MessageEmote, error) {
+	if !IsValidEmote(m.Emote) {
+		return m, fmt.Errorf("unknown emote: [%v]", m.Emote)
+	}
+	return m, nil
+}
+
+// MessagePlayerStatus tells players that someone (re)connected to or left the game.
+type MessagePlayerStatus struct {
+	WebsocketMessage
+	PlayerID  int  `json:"playerID"`
+	Connected bool `json:"connected"`
+}
+
+func NewMessagePlayerStatus(playerID int, connected bool) MessagePlayerStatus {
+	return MessagePlayerStatus{WebsocketMessage: WebsocketMessage{Type: MessageTypePlayerStatus}, PlayerID: playerID, Connected: connected}
+}
+
+func (m MessagePlayerStatus) Deserialize() (MessagePlayerStatus, error) {
+	return m, nil
+}
+
+// MessageFindMatch asks the lobby to find a game for the player, with whoever else is
+// looking for one.
+type MessageFindMatch struct {
+	WebsocketMessage
+	Name string `json:"name"`
+}
+
+func NewMessageFindMatch(name string) MessageFindMatch {
+	return MessageFindMatch{WebsocketMessage: WebsocketMessage{Type: MessageTypeFindMatch}, Name: name}
+}
+
+func (m MessageFindMatch) Deserialize() (MessageFindMatch, error) {
+	if m.Name == "" {
+		return m, fmt.Errorf("name is required")
+	}
+	return m, nil
+}
+
+// MessageGimmeLeaderboard asks the lobby for the leaderboard, before or instead of finding
+// a match.
+type MessageGimmeLeaderboard struct {
+	WebsocketMessage
+}
+
+func NewMessageGimmeLeaderboard() MessageGimmeLeaderboard {
+	return MessageGimmeLeaderboard{WebsocketMessage: WebsocketMessage{Type: MessageTypeGimmeLeaderboard}}
+}
+
+func (m MessageGimmeLeaderboard) Deserialize() (MessageGimmeLeaderboard, error) {
+	return m, nil
+}
+
+// MessageListGames asks the lobby for the games with free seats, before or instead of
+// finding a match.
+type MessageListGames struct {
+	WebsocketMessage
+}
+
+func NewMessageListGames() MessageListGames {
+	return MessageListGames{WebsocketMessage: WebsocketMessage{Type: MessageTypeListGames}}
+}
+
+func (m MessageListGames) Deserialize() (MessageListGames, error) {
+	return m, nil
+}
+
+// MessageOpenGames is the lobby's answer to MessageListGames, as also served at
+// /api/open-games. Players join one with a hello message for one of its free seats.
+type MessageOpenGames struct {
+	WebsocketMessage
+	Games []APIOpenGame `json:"games"`
+}
+
+func NewMessageOpenGames(games []APIOpenGame) MessageOpenGames {
+	return MessageOpenGames{WebsocketMessage: WebsocketMessage{Type: MessageTypeOpenGames}, Games: games}
+}
+
+func (m MessageOpenGames) Deserialize() (MessageOpenGames, error) {
+	return m, nil
+}
+
+// MessageHeresLeaderboard is the lobby's answer to MessageGimmeLeaderboard: the top players,
+// as also served at /api/leaderboard.
+type MessageHeresLeaderboard struct {
+	WebsocketMessage
+	Players []PlayerStats `json:"players"`
+}
+
+func NewMessageHeresLeaderboard(players []PlayerStats) MessageHeresLeaderboard {
+	return MessageHeresLeaderboard{WebsocketMessage: WebsocketMessage{Type: MessageTypeHeresLeaderboard}, Players: players}
+}
+
+func (m MessageHeresLeaderboard) Deserialize() (MessageHeresLeaderboard, error) {
+	return m, nil
+}
+
+// MessageTournamentUpdate is a tournament's bracket, sent to those following it whenever
+// it changes.
+type MessageTournamentUpdate struct {
+	WebsocketMessage
+	Tournament Tournament `json:"tournament"`
+}
+
+func NewMessageTournamentUpdate(tournament Tournament) MessageTournamentUpdate {
+	return MessageTournamentUpdate{WebsocketMessage: WebsocketMessage{Type: MessageTypeTournamentUpdate}, Tournament: tournament}
+}
+
+func (m MessageTournamentUpdate) Deserialize() (MessageTournamentUpdate, error) {
+	return m, nil
+}
+
+// MessageMatchFound tells a player in the lobby that their game is starting, and which
+// player they are in it. They join it with a hello message for the game, with the session
+// token if the server requires authentication.
+type MessageMatchFound struct {
+	WebsocketMessage
+	GameID   string `json:"gameID"`
+	PlayerID int    `json:"playerID"`
+	Token    string `json:"token,omitempty"`
+}
+
+func NewMessageMatchFound(gameID string, playerID int, token string) MessageMatchFound {
+	return MessageMatchFound{WebsocketMessage: WebsocketMessage{Type: MessageTypeMatchFound}, GameID: gameID, PlayerID: playerID, Token: token}
+}
+
+func (m MessageMatchFound) Deserialize() (MessageMatchFound, error) {
+	return m, nil
+}
+
+// MessageSession gives a player the session token for their seat, when they join an
+// unclaimed one on a server requiring authentication, or holding seats for players to
+// reconnect. They need it to join it again.
+type MessageSession struct {
+	WebsocketMessage
+	Token string `json:"token"`
+}
+
+func NewMessageSession(token string) MessageSession {
+	return MessageSession{WebsocketMessage: WebsocketMessage{Type: MessageTypeSession}, Token: token}
+}
+
+func (m MessageSession) Deserialize() (MessageSession, error) {
+	return m, nil
+}
+
+// MessageSpectate is the first message on a spectator's connection, instead of a hello
+// message, to watch the game with the given ID (the default game if empty). With Reveal,
+// they see every hand, but delayed (see WithSpectatorDelay).
+type MessageSpectate struct {
+	WebsocketMessage
+	GameID string `json:"gameID,omitempty"`
+	Reveal bool   `json:"reveal,omitempty"`
+
+	// Deltas asks for game states as deltas, and Password is the game's, as in
+	// MessageHello.
+	Deltas   bool   `json:"deltas,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+func NewMessageSpectate(gameID string, reveal bool) MessageSpectate {
+	return MessageSpectate{WebsocketMessage: WebsocketMessage{Type: MessageTypeSpectate}, GameID: gameID, Reveal: reveal}
+}
+
+func (m MessageSpectate) Deserialize() (MessageSpectate, error) {
+	return m, nil
+}
+
+type MessageHeresSpectatorGameState struct {
+	WebsocketMessage
+	GameState json.RawMessage `json:"gameState"`
+}
+
+func NewMessageHeresSpectatorGameState(gameState chinchon.SpectatorGameState) (MessageHeresSpectatorGameState, error) {
+	bs, err := json.Marshal(gameState)
+	return MessageHeresSpectatorGameState{WebsocketMessage: WebsocketMessage{Type: MessageTypeHeresSpectatorGameState}, GameState: bs}, err
+}
+
+func (gs MessageHeresSpectatorGameState) Deserialize() (chinchon.SpectatorGameState, error) {
+	var spectatorGameState chinchon.SpectatorGameState
+	err := json.Unmarshal(gs.GameState, &spectatorGameState)
+	return spectatorGameState, err
+}
+
+// MessageMaintenance tells players and spectators that the server is restarting for
+// maintenance, right before it closes their connection. Games resume once it's back.
+type MessageMaintenance struct {
+	WebsocketMessage
+}
+
+func NewMessageMaintenance() MessageMaintenance {
+	return MessageMaintenance{WebsocketMessage: WebsocketMessage{Type: MessageTypeMaintenance}}
+}
+
+func (m MessageMaintenance) Deserialize() (MessageMaintenance, error) {
+	return m, nil
+}
+
+// MessageAnnouncement is a message from the server's operators to players and spectators.
+type MessageAnnouncement struct {
+	WebsocketMessage
+	Text string `json:"text"`
+}
+
+func NewMessageAnnouncement(text string) MessageAnnouncement {
+	return MessageAnnouncement{WebsocketMessage: WebsocketMessage{Type: MessageTypeAnnouncement}, Text: text}
+}
+
+func (m MessageAnnouncement) Deserialize() (MessageAnnouncement, error) {
+	return m, nil
+}
+
+// MessageError tells a player that the server rejected the message they just sent, e.g.
+// an action when it's not their turn. Message is meant for humans, and Code (one of the
+// ErrorCode constants) for clients.
+type MessageError struct {
+	WebsocketMessage
+	Code    string `json:"code"`
+	Message string `json:"message"`
+
+	// RetryAfter is how many seconds to wait before trying again, for ErrorCodeServerFull.
+	RetryAfter int `json:"retryAfter,omitempty"`
+}
+
+func NewMessageError(code string, message string) MessageError {
+	return MessageError{WebsocketMessage: WebsocketMessage{Type: MessageTypeError}, Code: code, Message: message}
+}
+
+func (m MessageError) Deserialize() (MessageError, error) {
+	return m, nil
+}
+
+// newActionErrorMessage returns the error message for an action the game rejected.
+func newActionErrorMessage(err error) MessageError {
+	code := ErrorCodeServerError
+	switch {
+	case errors.Is(err, chinchon.ErrNotYourTurn):
+		code = ErrorCodeNotYourTurn
+	case errors.Is(err, chinchon.ErrActionNotPossible):
+		code = ErrorCodeActionNotPossible
+	case errors.Is(err, chinchon.ErrGameIsEnded):
+		code = ErrorCodeGameEnded
+	case errors.Is(err, chinchon.ErrNothingToUndo):
+		code = ErrorCodeNothingToUndo
+	}
+	return NewMessageError(code, err.Error())
+}
+
+// MessageSeatTaken tells a client why it's being disconnected from its seat: it was
+// already connected (SeatTakenReasonOccupied), or a newer connection took it
+// (SeatTakenReasonReplaced, see WithSeatPolicy).
+type MessageSeatTaken struct {
+	WebsocketMessage
+	PlayerID int    `json:"playerID"`
+	Reason   string `json:"reason"`
+}
+
+func NewMessageSeatTaken(playerID int, reason string) MessageSeatTaken {
+	return MessageSeatTaken{WebsocketMessage: WebsocketMessage{Type: MessageTypeSeatTaken}, PlayerID: playerID, Reason: reason}
+}
+
+func (m MessageSeatTaken) Deserialize() (MessageSeatTaken, error) {
+	return m, nil
+}
+
+// MessagePlayerLatency tells players the round-trip time of someone's connection, as the
+// server measures it with every ping (see WithPingInterval), for them to show how good it
+// is.
+type MessagePlayerLatency struct {
+	WebsocketMessage
+	PlayerID  int    `json:"playerID"`
+	RTTMillis int64  `json:"rttMillis"`
+	Quality   string `json:"quality"`
+}
+
+func NewMessagePlayerLatency(playerID int, rtt time.Duration) MessagePlayerLatency {
+	return MessagePlayerLatency{WebsocketMessage: WebsocketMessage{Type: MessageTypePlayerLatency}, PlayerID: playerID, RTTMillis: rtt.Milliseconds(), Quality: ConnectionQuality(rtt)}
+}
+
+func (m MessagePlayerLatency) Deserialize() (MessagePlayerLatency, error) {
+	return m, nil
+}
+
+// MessageGameStateDelta is a game state, for players and spectators who asked for deltas
+// (see MessageHello.Deltas), as the top-level fields that changed from the previous one
+// they got, e.g. the last action log and the turn: null for those that are gone. They still
+// get full game states every so often (see WithFullStateEvery), and when they ask for it
+// with a MessageGimmeGameState. StateAssembler puts them back together.
+type MessageGameStateDelta struct {
+	WebsocketMessage
+	Changes map[string]json.RawMessage `json:"changes"`
+}
+
+func NewMessageGameStateDelta(changes map[string]json.RawMessage) MessageGameStateDelta {
+	return MessageGameStateDelta{WebsocketMessage: WebsocketMessage{Type: MessageTypeGameStateDelta}, Changes: changes}
+}
+
+func (m MessageGameStateDelta) Deserialize() (MessageGameStateDelta, error) {
+	return m, nil
+}
+
+// MessageRedirect tells a client which connected to the wrong node of a cluster (see
+// WithCluster) to connect to the node hosting the game instead, at the URL, and send its
+// hello again. The server closes the connection right after. WsJoin follows it.
+type MessageRedirect struct {
+	WebsocketMessage
+	GameID string `json:"gameID"`
+	URL    string `json:"url"`
+}
+
+func NewMessageRedirect(gameID string, url string) MessageRedirect {
+	return MessageRedirect{WebsocketMessage: WebsocketMessage{Type: MessageTypeRedirect}, GameID: gameID, URL: url}
+}
+
+func (m MessageRedirect) Deserialize() (MessageRedirect, error) {
+	return m, nil
+}
@@ -4,121 +4,499 @@
 package server
 
 import (
-	"encoding/json"
-	"log"
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/devblac/chinchon/chinchon"
+	"github.com/devblac/chinchon/rating"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
 )
 
 var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
 		return true
 	},
+	Subprotocols: []string{WsSubprotocolProtobuf},
 }
 
-// TODO: resources shouldn't be shared between goroutines! It's not panicking due to insufficient testing for now.
 type server struct {
-	gameState *chinchon.GameState
-	port      string
-	players   []*websocket.Conn
+	// roomsMu guards rooms, the games being played, by ID.
+	roomsMu sync.Mutex
+	rooms   map[string]*room
+
+	port    string
+	daily   *dailyChallenges
+	stats   *statsCollector
+	lobby   lobby
+	ratings *rating.Ratings
+	history *playerHistory
+	replays *replays
+	bans    *bans
+
+	passwords   *roomPasswords
+	tournaments *tournaments
+
+	// turnNotifier notifies players who aren't connected when it's their turn, if set.
+	turnNotifier TurnNotifier
+
+	// botStrategies are the bots exhibition games may be played with, by name.
+	botStrategies map[string]func() chinchon.Bot
+
+	leaderboard     *leaderboard
+	leaderboardPath string
+	dailyPath       string
+	dailySecret     []byte
+	logger          *slog.Logger
+	auth            *authenticator
+
+	// hostedBots are the player IDs of the seats the server's bots play in every game, and
+	// humanSeats those of the other ones.
+	hostedBots []int
+	humanSeats []int
+
+	// reconnectGrace is how long disconnected players' seats are held, unless
+	// seatsAlwaysHeld, with WithAuth.
+	reconnectGrace  time.Duration
+	seatsAlwaysHeld bool
+	seatPolicy      SeatPolicy
+	polls           *pollSessions // once started
+	sendQueue       sendQueue
+
+	spectatorDelay time.Duration
+	gameOptions    []func(*chinchon.GameState)
+	takeoverConfig *botTakeover
+	store          GameStore
+	snapshotEvery  int
+	fullStateEvery int
+
+	rateLimitBurst  int
+	rateLimitRefill time.Duration
+	pingInterval    time.Duration
+	adminToken      string
+	tracer          trace.Tracer
+	cluster         *cluster // with WithCluster
+	gameRegistry    GameRegistry
+	maxGames        int
+	maxConnections  int
+	connections     atomic.Int64 // WebSocket connections, with maxConnections
+
+	httpServer *http.Server
+	tlsConfig  func() (*tls.Config, error) // with WithTLS or WithAutocert
+	grpcPort   string
+	grpcServer *grpc.Server
 }
 
-func New(port string) *server {
-	return &server{gameState: chinchon.New(), port: port, players: []*websocket.Conn{nil, nil}}
+// Option configures the server at creation time.
+type Option func(*server)
+
+// WithGameOptions sets the engine options (e.g. chinchon.WithTeams) for every hosted game.
+func WithGameOptions(opts ...func(*chinchon.GameState)) Option {
+	return func(s *server) {
+		s.gameOptions = append(s.gameOptions, opts...)
+	}
+}
+
+// New creates a server hosting many games at once, each one identified by the game ID
+// players send in their hello message. The default game is started right away.
+func New(port string, opts ...Option) *server {
+	s := &server{
+		rooms:          map[string]*room{},
+		port:           port,
+		stats:          &statsCollector{},
+		history:        newPlayerHistory(),
+		replays:        newReplays(),
+		bans:           newBans(),
+		passwords:      newRoomPasswords(),
+		tournaments:    newTournaments(),
+		botStrategies:  defaultBotStrategies(),
+		spectatorDelay: DefaultSpectatorDelay,
+		pingInterval:   DefaultPingInterval,
+		fullStateEvery: DefaultFullStateEvery,
+		tracer:         otel.Tracer(tracerName),
+		seatPolicy:     SeatPolicyReject,
+		sendQueue:      sendQueue{size: DefaultSendQueueSize, policy: SendQueueDropOldest},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.logger == nil {
+		s.logger = slog.Default()
+	}
+	if s.store != nil {
+		s.passwords.stored = s.storedPasswordHash
+	}
+	var err error
+	if s.leaderboard, err = newLeaderboard(s.leaderboardPath, s.ratings); err != nil {
+		fatal(s.logger, "failed to restore the leaderboard", "err", err)
+	}
+	if s.daily, err = newDailyChallenges(s.dailyPath, s.dailySecret); err != nil {
+		fatal(s.logger, "failed to restore the daily challenges' results", "err", err)
+	}
+	s.humanSeats = s.humanSeatsFor(numSeats(s.gameOptions), s.hostedBots)
+	if (s.reconnectGrace > 0 || s.grpcPort != "") && s.auth == nil {
+		// Tokens are only needed to reconnect to this server.
+		s.auth = newRandomAuthenticator()
+	}
+	if s.cluster != nil {
+		s.cluster.registry = s.gameRegistry
+	}
+	// In a cluster, the default game is hosted by one of the nodes.
+	if hosted, err := s.hostsGame(DefaultGameID); err != nil {
+		fatal(s.logger, "failed to find the node hosting the default game", "err", err)
+	} else if hosted {
+		if _, err := s.room(DefaultGameID); err != nil {
+			fatal(s.logger, "failed to restore the persisted game", "err", err)
+		}
+	}
+	if s.store != nil {
+		s.recoverGames()
+	}
+	return s
 }
 
+// Start serves until the process gets SIGTERM or SIGINT, then shuts the server down (see
+// Shutdown) so that games resume after a restart.
 func (s *server) Start() {
+	s.httpServer = &http.Server{Addr: ":" + s.port, Handler: s.handler()}
+
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, syscall.SIGTERM, os.Interrupt)
+		s.logger.Info("shutting down", "signal", (<-stop).String())
+
+		ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+		defer cancel()
+		if err := s.Shutdown(ctx); err != nil {
+			s.logger.Error("failed to shut down cleanly", "err", err)
+		}
+	}()
+
+	var tlsConfig *tls.Config
+	if s.tlsConfig != nil {
+		var err error
+		if tlsConfig, err = s.tlsConfig(); err != nil {
+			fatal(s.logger, "failed to configure TLS", "err", err)
+		}
+		s.httpServer.TLSConfig = tlsConfig
+	}
+	if s.grpcPort != "" {
+		s.startGRPC(tlsConfig)
+	}
+
+	s.logger.Info("server running", "port", s.port, "tls", tlsConfig != nil)
+	var err error
+	if tlsConfig != nil {
+		// The certificates are in the TLS config.
+		err = s.httpServer.ListenAndServeTLS("", "")
+	} else {
+		err = s.httpServer.ListenAndServe()
+	}
+	if !errors.Is(err, http.ErrServerClosed) {
+		fatal(s.logger, "server failed", "err", err)
+	}
+	<-shutdownDone
+}
+
+// handler routes the server's endpoints.
+func (s *server) handler() http.Handler {
 	router := mux.NewRouter()
-	router.HandleFunc("/ws", s.handleWebSocket)
-	log.Printf("Server running on port %v\n", s.port)
-	log.Fatal(http.ListenAndServe(":"+s.port, router))
+	router.Handle("/ws", s.limitConnections(http.HandlerFunc(s.handleWebSocket)))
+	router.Handle("/lobby/ws", s.limitConnections(http.HandlerFunc(s.handleLobbyWebSocket)))
+	router.HandleFunc("/daily", s.handleDailyChallenge).Methods(http.MethodGet)
+	router.HandleFunc("/daily/leaderboard", s.handleDailyLeaderboard).Methods(http.MethodGet)
+	router.Handle("/daily/ws", s.limitConnections(http.HandlerFunc(s.handleDailyWebSocket)))
+	router.HandleFunc("/games/mine", s.handleMyGames).Methods(http.MethodGet)
+	router.HandleFunc("/games", s.handleStoredGames).Methods(http.MethodGet)
+	router.HandleFunc("/api/games", s.handleAPIGames).Methods(http.MethodGet)
+	router.HandleFunc("/api/games", s.handleAPICreateGame).Methods(http.MethodPost)
+	router.HandleFunc("/api/open-games", s.handleAPIOpenGames).Methods(http.MethodGet)
+	router.HandleFunc("/api/games/{id}", s.handleAPIGame).Methods(http.MethodGet)
+	router.HandleFunc("/api/games/{id}/rounds", s.handleAPIGameRounds).Methods(http.MethodGet)
+	router.HandleFunc("/api/games/{id}/replay", s.handleAPIGameReplay).Methods(http.MethodGet)
+	router.HandleFunc("/api/players/{id}/history", s.handleAPIPlayerHistory).Methods(http.MethodGet)
+	router.HandleFunc("/api/players/{id}/stats", s.handleAPIPlayerStats).Methods(http.MethodGet)
+	router.HandleFunc("/api/leaderboard", s.handleAPILeaderboard).Methods(http.MethodGet)
+	router.HandleFunc("/api/tournaments", s.handleAPITournaments).Methods(http.MethodGet)
+	router.HandleFunc("/api/tournaments/{id}", s.handleAPITournament).Methods(http.MethodGet)
+	router.HandleFunc("/api/tournaments/{id}/players", s.handleAPITournamentRegister).Methods(http.MethodPost)
+	router.Handle("/tournaments/{id}/ws", s.limitConnections(http.HandlerFunc(s.handleTournamentWebSocket)))
+	router.HandleFunc("/api/exhibitions", s.handleAPIExhibitions).Methods(http.MethodGet)
+	router.HandleFunc("/api/bots", s.handleAPIBotStrategies).Methods(http.MethodGet)
+	router.HandleFunc("/stats", s.handleStats).Methods(http.MethodGet)
+	router.HandleFunc("/dashboard", s.handleDashboard).Methods(http.MethodGet)
+	router.HandleFunc("/history", s.handleHandHistory).Methods(http.MethodGet)
+	router.HandleFunc("/cards/{assetID}.svg", s.handleCardImage).Methods(http.MethodGet)
+	s.routeAdmin(router)
+	s.routeLongPolling(router)
+	router.Use(s.rejectBanned)
+	return router
 }
 
 func (s *server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.With("remoteAddr", r.RemoteAddr)
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Println("Failed to upgrade connection to WebSocket:", err)
+		logger.Warn("failed to upgrade connection to WebSocket", "err", err)
 		return
 	}
 	defer conn.Close()
+	// Set once the player is seated. Pongs are handled by this goroutine's reads, so it
+	// needs no locking.
+	var onRTT func(time.Duration)
+	defer s.keepAlive(conn, func(rtt time.Duration) {
+		if onRTT != nil {
+			onRTT(rtt)
+		}
+	})()
 
-	playerID, err := WsReadMessage[int, MessageHello](conn, MessageTypeHello)
+	// The first message is a hello from a player, or a spectator asking to watch.
+	messageType, message, err := WsReadAnyMessage(conn)
 	if err != nil {
-		log.Println(err)
+		logger.Warn("failed to read first message", "err", err)
 		return
 	}
-
-	if *playerID < 0 || *playerID > 1 {
-		log.Println("Invalid player ID")
+	if messageType == MessageTypeSpectate {
+		spectate, err := WsDeserializeMessage[MessageSpectate, MessageSpectate](message, messageType)
+		if err != nil {
+			logger.Warn("invalid spectate message", "err", err)
+			return
+		}
+		if s.redirect(conn, r, spectate.GameID, logger) {
+			return
+		}
+		s.spectate(conn, *spectate, logger)
+		return
+	}
+	hello, err := WsDeserializeMessage[MessageHello, MessageHello](message, MessageTypeHello)
+	if err != nil {
+		logger.Warn("invalid hello message", "err", err)
+		return
+	}
+	if s.redirect(conn, r, hello.GameID, logger) {
+		return
+	}
+	playerID := hello.PlayerID
+	gameOptions, err := s.houseRules(hello.Rules)
+	if err != nil {
+		logger.Info("invalid house rules", "gameID", hello.GameID, "err", err)
+		_ = WsSend(conn, NewMessageError(ErrorCodeInvalidRules, err.Error()))
 		return
 	}
-	if s.players[*playerID] != nil {
-		log.Println("Player already connected")
+	if err := chinchon.ValidateProfile(hello.Name, hello.Avatar); err != nil {
+		logger.Info("invalid profile", "gameID", hello.GameID, "err", err)
+		_ = WsSend(conn, NewMessageError(ErrorCodeInvalidProfile, err.Error()))
 		return
 	}
-	s.players[*playerID] = conn
 
-	msg, _ := NewMessageHeresGameState(s.gameState.ToClientGameState(*playerID))
-	if err := WsSend(conn, msg); err != nil {
-		log.Println(err)
+	out := newOutbox(conn, s.sendQueue)
+	if hello.Deltas {
+		out.SendDeltas(s.fullStateEvery)
+	}
+	defer out.Close()
+	room, token, err := s.join(hello.GameID, playerID, hello.Token, hello.Password, out, gameOptions)
+	if err != nil {
+		logger.Warn("failed to join game", "gameID", hello.GameID, "playerID", playerID, "err", err)
+		// Nothing was queued, so the connection may still be written to.
+		switch {
+		case errors.Is(err, errSeatTaken):
+			_ = WsSend(conn, NewMessageSeatTaken(playerID, SeatTakenReasonOccupied))
+		case errors.Is(err, errServerFull):
+			_ = WsSend(conn, newServerFullMessage())
+		case errors.Is(err, errWrongPassword):
+			_ = WsSend(conn, NewMessageError(ErrorCodeWrongPassword, err.Error()))
+		}
 		return
 	}
-	log.Println("Player", *playerID, "connected")
+	logger = room.logger.With("playerID", playerID, "remoteAddr", r.RemoteAddr)
+	if room.creator == "" {
+		room.creator = hello.Name
+	}
+	if token != "" {
+		out.Send(NewMessageSession(token))
+	}
+	defer s.closeRoomIfDone(room)
+	s.stats.playerConnected()
+	defer s.stats.playerDisconnected()
 
+	room.setProfile(playerID, hello.Name, hello.Avatar)
+	room.setNotifyTarget(playerID, hello.Notify)
+	room.playerConnected(playerID, out)
+	room.mu.Unlock()
+	onRTT = func(rtt time.Duration) {
+		room.mu.Lock()
+		defer room.mu.Unlock()
+		room.rttMeasured(playerID, out, rtt)
+	}
+
+	defer s.playerDisconnected(room, playerID, out)
+	limiter := s.newRateLimiter()
 	for {
-		log.Println("Waiting for action/state_request from player", *playerID)
-		_, message, err := conn.ReadMessage()
+		logger.Debug("waiting for message")
+		messageType, message, err := WsReadAnyMessage(conn)
 		if err != nil {
-			log.Println("Failed to read message from client, freeing slot:", err)
-			s.players[*playerID] = nil
-			break
+			logger.Info("failed to read message from client, freeing slot", "err", err)
+			return
 		}
-
-		var wsMessage WebsocketMessage
-		if err := json.Unmarshal(message, &wsMessage); err != nil {
-			log.Println("Failed to unmarshal message:", err)
-			break
+		if !limiter.allow() {
+			if limiter.abusive() {
+				logger.Warn("player kept going over the rate limit, disconnecting")
+				closeRateLimited(out)
+				return
+			}
+			logger.Debug("ignoring message over the rate limit")
+			continue
 		}
 
-		switch wsMessage.Type {
+		ctx, span := room.startSpan(r.Context(), "ws.message", attrPlayerID.Int(playerID), attrMessageType.Int(messageType))
+		room.mu.Lock()
+		if room.players[playerID] != out {
+			// A newer connection took the seat, and this one is closing.
+			room.mu.Unlock()
+			span.End()
+			return
+		}
+		room.playerIsBack(playerID)
+		switch messageType {
 		case MessageTypeAction:
-			log.Println("Got action message:", string(message))
 			action, err := WsDeserializeMessage[chinchon.Action, MessageAction](message, MessageTypeAction)
 			if err != nil {
-				log.Println(err)
+				logger.Warn("invalid action message", "err", err)
+				room.mu.Unlock()
+				endSpan(span, err)
 				return
 			}
-			if (*action).GetPlayerID() != *playerID {
-				log.Fatal("Player", *playerID, " tried to run action for player", (*action).GetPlayerID())
+			actionLogger := logger.With("action", (*action).GetName())
+			if (*action).GetPlayerID() != playerID {
+				actionLogger.Warn("player tried to run action for another player", "actionPlayerID", (*action).GetPlayerID())
+				room.mu.Unlock()
+				span.End()
+				return
 			}
-			err = s.gameState.RunAction(*action)
+			err = room.runAction(ctx, *action, false)
 			if err != nil {
-				// TODO write back to the connection
-				log.Println("Failed to run action:", err)
+				actionLogger.Info("failed to run action", "err", err)
+				out.Send(newActionErrorMessage(err))
 				break
 			}
 
-			log.Println("Ran action message:", string(message))
-
-			for i, playerConn := range s.players {
-				log.Println("Sending game state to player", i)
-				msg, _ := NewMessageHeresGameState(s.gameState.ToClientGameState(i))
-				if err := WsSend(playerConn, msg); err != nil {
-					log.Println(err)
-					return
-				}
+			actionLogger.Debug("ran action")
+			_, broadcastSpan := room.startSpan(ctx, "room.gameStateChanged")
+			room.gameStateChanged()
+			broadcastSpan.End()
+		case MessageTypeEmote:
+			emote, err := WsDeserializeMessage[MessageEmote, MessageEmote](message, MessageTypeEmote)
+			if err != nil {
+				logger.Info("invalid emote", "err", err)
+				out.Send(NewMessageError(ErrorCodeInvalidEmote, err.Error()))
+				break
 			}
+			// Players can only emote as themselves.
+			room.broadcast(NewMessageEmote(playerID, emote.Emote))
 		case MessageTypeGimmeGameState:
-			log.Println("Got state request message:", string(message))
+			logger.Debug("got state request")
 
-			msg, _ := NewMessageHeresGameState(s.gameState.ToClientGameState(*playerID))
-			if err := WsSend(conn, msg); err != nil {
-				log.Println(err)
-				return
-			}
+			msg, _ := NewMessageHeresGameState(room.gameState.ToClientGameState(playerID))
+			out.Resync()
+			out.Send(msg)
+		}
+		room.mu.Unlock()
+		span.End()
+	}
+}
+
+// playerConnected sends the game to a player who just joined it, and tells the others.
+// Must be called with the room locked.
+func (r *room) playerConnected(playerID int, out *outbox) {
+	msg, _ := NewMessageHeresGameState(r.gameState.ToClientGameState(playerID))
+	out.Send(msg)
+	r.logger.Info("player connected", "playerID", playerID)
+	r.notifyOthers(playerID, NewMessagePlayerStatus(playerID, true))
+	r.playerIsBack(playerID)
+	r.startTurnTimer()
+}
+
+// playerDisconnected frees the player's seat, holding it for them to reconnect if the
+// server does, and tells the others. Connections replaced by a newer one for the seat
+// already left it.
+func (s *server) playerDisconnected(r *room, playerID int, out *outbox) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.players[playerID] != out {
+		return
+	}
+	r.players[playerID] = nil
+	delete(r.rtts, playerID)
+	s.holdSeat(r, playerID)
+	r.notifyOthers(playerID, NewMessagePlayerStatus(playerID, false))
+}
+
+// rttMeasured notes the round-trip time of the player's connection, and tells every
+// player. Must be called with the room locked.
+func (r *room) rttMeasured(playerID int, out *outbox, rtt time.Duration) {
+	if r.players[playerID] != out {
+		return // Replaced by a newer connection
+	}
+	r.rtts[playerID] = rtt
+	r.broadcast(NewMessagePlayerLatency(playerID, rtt))
+}
+
+// gameStateChanged lets bots play any turns that are theirs or that they're standing in
+// for, and sends the resulting game state to every player. Must be called with the room
+// locked.
+func (r *room) gameStateChanged() {
+	r.runBotTurns()
+	r.updatedAt = time.Now()
+
+	if r.gameState.IsGameEnded && !r.ended {
+		r.ended = true
+		r.gameEnded()
+	}
+
+	for i, playerOut := range r.players {
+		if playerOut == nil {
+			continue
+		}
+		r.logger.Debug("sending game state", "playerID", i)
+		msg, _ := NewMessageHeresGameState(r.gameState.ToClientGameState(i))
+		playerOut.Send(msg)
+	}
+	r.notifySpectators()
+
+	r.startTurnTimer()
+	r.notifyTurn()
+}
+
+// notifyOthers sends a message to every connected player except the given one.
+func (r *room) notifyOthers(playerID int, message any) {
+	for i, playerOut := range r.players {
+		if i == playerID || playerOut == nil {
+			continue
+		}
+		playerOut.Send(message)
+	}
+}
+
+// broadcast sends the same message to every connected player.
+func (r *room) broadcast(message any) {
+	for _, playerOut := range r.players {
+		if playerOut == nil {
+			continue
 		}
+		playerOut.Send(message)
 	}
 }
@@ -0,0 +1,173 @@
+//go:build !tinygo
+// +build !tinygo
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/devblac/chinchon/chinchonpb"
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// WsSubprotocolProtobuf is the WebSocket subprotocol clients ask for (in the
+// Sec-WebSocket-Protocol header) to exchange binary Protocol Buffers frames rather than
+// JSON text ones. Messages mean the same in both. Each frame is the message
+//
+//	message WsFrame {
+//	  int32 type = 1;                            // the MessageType
+//	  oneof payload {
+//	    chinchonpb.ClientGameState game_state = 2; // MessageHeresGameState
+//	    chinchonpb.Action action = 3;              // MessageAction
+//	    bytes json = 15;                           // any other message, as JSON
+//	  }
+//	}
+//
+// so that game states and actions, the messages bots exchange the most, aren't JSON.
+// Clients may still send other messages as text frames.
+const WsSubprotocolProtobuf = "chinchon.protobuf"
+
+// Field numbers of the frames' protobuf encoding (see WsSubprotocolProtobuf).
+const (
+	frameFieldType      protowire.Number = 1
+	frameFieldGameState protowire.Number = 2
+	frameFieldAction    protowire.Number = 3
+	frameFieldJSON      protowire.Number = 15
+)
+
+// wsEncode returns the frame type and bytes of the message, in the connection's encoding.
+func wsEncode(conn *websocket.Conn, message any) (int, []byte, error) {
+	if conn.Subprotocol() != WsSubprotocolProtobuf {
+		bs, err := json.Marshal(message)
+		return websocket.TextMessage, bs, err
+	}
+	bs, err := encodeProtobufFrame(message)
+	return websocket.BinaryMessage, bs, err
+}
+
+func encodeProtobufFrame(message any) ([]byte, error) {
+	var (
+		messageType int
+		field       = frameFieldJSON
+		payload     []byte
+		err         error
+	)
+	switch m := message.(type) {
+	case MessageHeresGameState:
+		messageType, field = m.Type, frameFieldGameState
+		payload, err = gameStateToProtobuf(m)
+	case MessageAction:
+		messageType, field = m.Type, frameFieldAction
+		payload, err = actionToProtobuf(m)
+	default:
+		if payload, err = json.Marshal(message); err == nil {
+			var wsMessage WebsocketMessage
+			err = json.Unmarshal(payload, &wsMessage)
+			messageType = wsMessage.Type
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	bs := protowire.AppendTag(nil, frameFieldType, protowire.VarintType)
+	bs = protowire.AppendVarint(bs, uint64(messageType))
+	bs = protowire.AppendTag(bs, field, protowire.BytesType)
+	return protowire.AppendBytes(bs, payload), nil
+}
+
+func gameStateToProtobuf(m MessageHeresGameState) ([]byte, error) {
+	clientGameState, err := m.Deserialize()
+	if err != nil {
+		return nil, err
+	}
+	pbState, err := chinchonpb.FromClientGameState(clientGameState)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(pbState)
+}
+
+func actionToProtobuf(m MessageAction) ([]byte, error) {
+	action, err := m.Deserialize()
+	if err != nil {
+		return nil, err
+	}
+	pbAction, err := chinchonpb.FromAction(action)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(pbAction)
+}
+
+// wsDecode returns the message in a frame read from the connection as JSON, whatever its
+// encoding, for WsDeserializeMessage. Binary frames are only accepted from connections
+// that negotiated WsSubprotocolProtobuf.
+func wsDecode(conn *websocket.Conn, frameType int, bs []byte) ([]byte, error) {
+	switch {
+	case frameType == websocket.TextMessage:
+		return bs, nil
+	case frameType == websocket.BinaryMessage && conn.Subprotocol() == WsSubprotocolProtobuf:
+		return decodeProtobufFrame(bs)
+	}
+	return nil, fmt.Errorf("Expected text message, got %d", frameType)
+}
+
+func decodeProtobufFrame(bs []byte) ([]byte, error) {
+	var (
+		messageType int
+		field       protowire.Number
+		payload     []byte
+	)
+	for len(bs) > 0 {
+		num, typ, n := protowire.ConsumeTag(bs)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		bs = bs[n:]
+		switch {
+		case num == frameFieldType && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(bs)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			messageType, bs = int(v), bs[n:]
+		case typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(bs)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			field, payload, bs = num, v, bs[n:]
+		default:
+			// Unknown fields are skipped, as protobuf decoders do.
+			n := protowire.ConsumeFieldValue(num, typ, bs)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			bs = bs[n:]
+		}
+	}
+
+	switch field {
+	case frameFieldJSON:
+		return payload, nil
+	case frameFieldAction:
+		var pbAction chinchonpb.Action
+		if err := proto.Unmarshal(payload, &pbAction); err != nil {
+			return nil, err
+		}
+		action, err := chinchonpb.ToAction(&pbAction)
+		if err != nil {
+			return nil, err
+		}
+		msg, err := NewMessageAction(action)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(msg)
+	}
+	// Servers don't get game states, and clients may only decode them with chinchonpb.
+	return nil, fmt.Errorf("unexpected field %d in protobuf frame of message type %d", field, messageType)
+}